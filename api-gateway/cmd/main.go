@@ -2,7 +2,9 @@ package main
 
 import (
 	"api-gateway/internal/config"
+	grpcclient "api-gateway/internal/grpc/client"
 	"api-gateway/internal/routes"
+	"api-gateway/pkg/token"
 	"context"
 	"errors"
 	"log/slog"
@@ -34,7 +36,24 @@ func main() {
 
 	log.Debug("debug messages are enabled")
 
-	router := routes.NewRouter(log, cfg)
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	defer stopKeys()
+
+	// Ключи берём у сервиса авторизации; если он ещё не поднялся, догрузим при первом запросе
+	keys := token.NewJWKSCache(cfg.JWKSURL)
+	if err := keys.Refresh(keysCtx); err != nil {
+		log.Warn("failed to load JWKS on start", "error", err)
+	}
+	keys.Start(keysCtx, cfg.JWKSRefreshInterval, log)
+
+	authClient, err := grpcclient.NewAuthClient(cfg.AuthGRPC)
+	if err != nil {
+		log.Error("error with new auth grpc client", "error", err)
+		os.Exit(1)
+	}
+	defer authClient.Close()
+
+	router := routes.NewRouter(log, cfg, token.NewTokenManager(keys), authClient)
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
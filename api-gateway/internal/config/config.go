@@ -14,6 +14,13 @@ type Config struct {
 	Review string `env:"REVIEW" env-required:"true"`
 	Mentor string `env:"MENTOR" env-required:"true"`
 
+	// Ключи сервиса авторизации для проверки токенов на защищённых маршрутах
+	JWKSURL             string        `env:"JWKS_URL" env-default:"http://auth-server:8081/.well-known/jwks.json"`
+	JWKSRefreshInterval time.Duration `env:"JWKS_REFRESH_INTERVAL" env-default:"5m"`
+
+	// gRPC сервиса авторизации для проверки заголовка Authorization: ApiKey
+	AuthGRPC string `env:"AUTH_GRPC" env-default:"auth-server:50052"`
+
 	Env string `env:"ENV" env-required:"true"`
 
 	Timeout     time.Duration `env:"TIMEOUT" env-default:"4s"`
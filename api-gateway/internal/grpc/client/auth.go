@@ -0,0 +1,58 @@
+package grpcclient
+
+import (
+	pb "api-gateway/pkg/api/proto"
+	"api-gateway/pkg/token"
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type AuthClient struct {
+	client pb.AuthServiceClient
+	conn   *grpc.ClientConn
+}
+
+func NewAuthClient(addr string) (*AuthClient, error) {
+	creds := insecure.NewCredentials()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial auth service: %w", err)
+	}
+
+	return &AuthClient{
+		client: pb.NewAuthServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (a *AuthClient) Close() error {
+	return a.conn.Close()
+}
+
+// IntrospectAPIKey спрашивает у сервиса авторизации, действует ли ключ и какие у него права
+func (a *AuthClient) IntrospectAPIKey(ctx context.Context, key string) (*token.Claims, error) {
+	resp, err := a.client.IntrospectAPIKey(ctx, &pb.IntrospectAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, fmt.Errorf("IntrospectAPIKey RPC call failed: %w", err)
+	}
+
+	if !resp.Active {
+		return nil, token.ErrAPIKeyInactive
+	}
+
+	return token.APIKeyClaims(resp.UserId, resp.Role, resp.Scope, resp.ExpiresAt), nil
+}
+
+// IsSessionActive проверяет, не отозвал ли пользователь сессию, в которой выдан токен
+func (a *AuthClient) IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	resp, err := a.client.IsSessionActive(ctx, &pb.IsSessionActiveRequest{UserId: userID, SessionId: sessionID})
+	if err != nil {
+		return false, fmt.Errorf("IsSessionActive RPC call failed: %w", err)
+	}
+
+	return resp.Active, nil
+}
@@ -0,0 +1,131 @@
+package mwAuth
+
+import (
+	"api-gateway/pkg/token"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const UserKey contextKey = "user"
+
+// TokenParser проверяет подпись и срок access токена
+type TokenParser interface {
+	ParseToken(tokenStr string) (*token.Claims, error)
+}
+
+const apiKeyPrefix = "ApiKey "
+
+// AuthClient спрашивает сервис авторизации о персональных API ключах и отозванных сессиях
+type AuthClient interface {
+	IntrospectAPIKey(ctx context.Context, key string) (*token.Claims, error)
+	IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+// AuthMiddleware проверяет токен или API ключ на границе; сервисы за шлюзом проверяют их ещё раз
+func AuthMiddleware(tokenMn TokenParser, auth AuthClient, log *slog.Logger) func(http.Handler) http.Handler {
+	sessions := newSessionCache(auth, sessionCacheTTL)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+
+			if authHeader == "" {
+				log.Warn("Authorization header missing")
+				writeError(w, http.StatusUnauthorized, "token required")
+				return
+			}
+
+			if key, ok := strings.CutPrefix(authHeader, apiKeyPrefix); ok {
+				claims, err := auth.IntrospectAPIKey(r.Context(), key)
+				if errors.Is(err, token.ErrAPIKeyInactive) {
+					log.Warn("Invalid api key")
+					writeError(w, http.StatusUnauthorized, "invalid api key")
+					return
+				}
+				if err != nil {
+					log.Error("Api key introspection failed", "error", err)
+					writeError(w, http.StatusServiceUnavailable, "auth service unavailable")
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), UserKey, claims)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+			claims, err := tokenMn.ParseToken(tokenStr)
+			if err != nil {
+				if errors.Is(err, token.ErrTokenExpired) {
+					log.Warn("Token expired", "error", err)
+					writeError(w, http.StatusUnauthorized, "token expired")
+				} else {
+					log.Warn("Token validation failed", "error", err)
+					writeError(w, http.StatusUnauthorized, "invalid token")
+				}
+				return
+			}
+
+			if claims.TokenType != token.TypeAccess {
+				log.Warn("Invalid token type", "type", claims.TokenType)
+				writeError(w, http.StatusUnauthorized, "invalid token type")
+				return
+			}
+
+			// Подпись не знает о выходе пользователя: токен с сессией живёт, пока жива сессия
+			if claims.FamilyID != "" {
+				active, err := sessions.IsSessionActive(r.Context(), claims.UserID, claims.FamilyID)
+				if err != nil {
+					log.Error("Session check failed", "error", err)
+					writeError(w, http.StatusServiceUnavailable, "auth service unavailable")
+					return
+				}
+				if !active {
+					log.Warn("Session revoked", "user_id", claims.UserID)
+					writeError(w, http.StatusUnauthorized, "session revoked")
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), UserKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope пропускает запрос, только если в токене есть scope.
+// Ставится после AuthMiddleware, который кладёт claims в контекст
+func RequireScope(log *slog.Logger, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserKey).(*token.Claims)
+			if !ok || claims == nil {
+				log.Error("Claims missing, RequireScope used without AuthMiddleware")
+				writeError(w, http.StatusUnauthorized, "token required")
+				return
+			}
+
+			if !claims.HasScope(scope) {
+				log.Warn("Scope missing", "user_id", claims.UserID, "scope", scope, "path", r.URL.Path)
+				writeError(w, http.StatusForbidden, "insufficient scope")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Шлюз отвечает сам только ошибками, go-chi/render ради этого не нужен
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -2,7 +2,9 @@ package routes
 
 import (
 	"api-gateway/internal/config"
+	mwAuth "api-gateway/internal/middleware/auth"
 	mwLogger "api-gateway/internal/middleware/logger"
+	"api-gateway/pkg/token"
 	"log"
 	"log/slog"
 	"net/http"
@@ -28,7 +30,41 @@ func newProxy(target string) http.HandlerFunc {
 	return proxy.ServeHTTP
 }
 
-func NewRouter(log *slog.Logger, cfg *config.Config) http.Handler {
+// Route маршрут, который шлюз пропускает только с нужным scope в access токене
+type Route struct {
+	Method  string
+	Pattern string
+	Scope   string
+	Target  string
+}
+
+// Policy таблица защищённых маршрутов; остальные проксируются без проверки
+// и защищаются самими сервисами
+func Policy(cfg *config.Config) []Route {
+	return []Route{
+		{Method: http.MethodPost, Pattern: "/review/create", Scope: token.ScopeReviewWrite, Target: cfg.Review},
+		{Method: http.MethodPut, Pattern: "/review/update", Scope: token.ScopeReviewWrite, Target: cfg.Review},
+		{Method: http.MethodDelete, Pattern: "/review/delete/{id}", Scope: token.ScopeReviewWrite, Target: cfg.Review},
+
+		{Method: http.MethodPut, Pattern: "/mentors/me", Scope: token.ScopeMentorEditSelf, Target: cfg.Mentor},
+		{Method: http.MethodPut, Pattern: "/mentors/me/availability", Scope: token.ScopeMentorEditSelf, Target: cfg.Mentor},
+
+		{Method: http.MethodGet, Pattern: "/mentors/sessions", Scope: token.ScopeSessionBook, Target: cfg.Mentor},
+		{Method: http.MethodPost, Pattern: "/mentors/sessions", Scope: token.ScopeSessionBook, Target: cfg.Mentor},
+		{Method: http.MethodPost, Pattern: "/mentors/sessions/{id}/cancel", Scope: token.ScopeSessionBook, Target: cfg.Mentor},
+		{Method: http.MethodPost, Pattern: "/mentors/sessions/{id}/reschedule", Scope: token.ScopeSessionBook, Target: cfg.Mentor},
+
+		{Method: http.MethodPost, Pattern: "/auth/admin/unlock", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodGet, Pattern: "/auth/admin/users", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodPatch, Pattern: "/auth/admin/users/{id}/role", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodPost, Pattern: "/auth/admin/users/{id}/disable", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodPost, Pattern: "/auth/admin/users/{id}/enable", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodPost, Pattern: "/auth/admin/users/{id}/logout", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+		{Method: http.MethodGet, Pattern: "/auth/admin/audit", Scope: token.ScopeUserAdmin, Target: cfg.Auth},
+	}
+}
+
+func NewRouter(log *slog.Logger, cfg *config.Config, tokenMn mwAuth.TokenParser, authClient mwAuth.AuthClient) http.Handler {
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
@@ -36,24 +72,59 @@ func NewRouter(log *slog.Logger, cfg *config.Config) http.Handler {
 	router.Use(middleware.Recoverer)
 
 	auth := cfg.Auth
+	router.Get("/.well-known/jwks.json", newProxy(auth))
+
 	router.Route("/auth", func(r chi.Router) {
 		r.Post("/register", newProxy(auth))
 		r.Post("/login", newProxy(auth))
+		r.Post("/login/mfa", newProxy(auth))
+		r.Post("/magic", newProxy(auth))
+		r.Post("/magic/verify", newProxy(auth))
+		r.Get("/oidc/{provider}/start", newProxy(auth))
+		r.Get("/oidc/{provider}/callback", newProxy(auth))
+		r.Post("/2fa/enroll", newProxy(auth))
+		r.Post("/2fa/confirm", newProxy(auth))
 		r.Post("/refresh", newProxy(auth))
 		r.Post("/logout", newProxy(auth))
+		r.Post("/verify", newProxy(auth))
+		r.Post("/verify/resend", newProxy(auth))
+		r.Get("/profile", newProxy(auth))
+		r.Patch("/profile", newProxy(auth))
+		r.Get("/sessions", newProxy(auth))
+		r.Delete("/sessions", newProxy(auth))
+		r.Delete("/sessions/{id}", newProxy(auth))
+		r.Delete("/account", newProxy(auth))
+		r.Post("/account/export", newProxy(auth))
+		r.Get("/account/export/{id}", newProxy(auth))
+		r.Post("/api-keys", newProxy(auth))
+		r.Get("/api-keys", newProxy(auth))
+		r.Delete("/api-keys/{id}", newProxy(auth))
+		r.Post("/password/forgot", newProxy(auth))
+		r.Post("/password/reset", newProxy(auth))
+		r.Post("/password/change", newProxy(auth))
+		r.Post("/email/change", newProxy(auth))
+		r.Post("/email/confirm", newProxy(auth))
 	})
 
 	reviewService := cfg.Review
 	router.Route("/review", func(r chi.Router) {
-		r.Post("/create", newProxy(reviewService))
-		r.Put("/update", newProxy(reviewService))
-		r.Delete("/delete/{id}", newProxy(reviewService))
 		r.Get("/get", newProxy(reviewService))
 	})
 
+	router.Group(func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(tokenMn, authClient, log))
+		for _, route := range Policy(cfg) {
+			r.With(mwAuth.RequireScope(log, route.Scope)).Method(route.Method, route.Pattern, newProxy(route.Target))
+		}
+	})
+
 	mentorService := cfg.Mentor
 	router.Route("/mentors", func(r chi.Router) {
 		r.Get("/get", newProxy(mentorService))
+		r.Get("/{id}", newProxy(mentorService))
+		r.Get("/{id}/rating", newProxy(mentorService))
+		r.Get("/{id}/availability", newProxy(mentorService))
+		r.Get("/{id}/slots", newProxy(mentorService))
 	})
 
 	return router
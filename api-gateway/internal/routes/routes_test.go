@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"api-gateway/internal/config"
+	"api-gateway/pkg/token"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeParser выдаёт claims по строке токена, подпись проверяется в pkg/token
+type fakeParser map[string]*token.Claims
+
+func (p fakeParser) ParseToken(tokenStr string) (*token.Claims, error) {
+	claims, ok := p[tokenStr]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// fakeKeys отвечает на интроспекцию по строке ключа, как сервис авторизации
+type fakeKeys map[string]*token.Claims
+
+func (k fakeKeys) IntrospectAPIKey(_ context.Context, key string) (*token.Claims, error) {
+	if key == "unavailable" {
+		return nil, errors.New("connection refused")
+	}
+	claims, ok := k[key]
+	if !ok {
+		return nil, token.ErrAPIKeyInactive
+	}
+	return claims, nil
+}
+
+// IsSessionActive: сессия "revoked" отозвана, на "unavailable" сервис авторизации не отвечает
+func (k fakeKeys) IsSessionActive(_ context.Context, _ int64, sessionID string) (bool, error) {
+	switch sessionID {
+	case "unavailable":
+		return false, errors.New("connection refused")
+	case "revoked":
+		return false, nil
+	}
+	return true, nil
+}
+
+func TestPolicyCoversEveryProtectedRoute(t *testing.T) {
+	var hits []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	cfg := &config.Config{Auth: backend.URL, Review: backend.URL, Mentor: backend.URL}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, route := range Policy(cfg) {
+		parser := fakeParser{
+			"no-scope":    {UserID: 1, TokenType: token.TypeAccess},
+			"with-scope":  {UserID: 1, TokenType: token.TypeAccess, Scope: "other:scope " + route.Scope},
+			"refresh":     {UserID: 1, TokenType: "refresh", Scope: route.Scope},
+			"mfa-pending": {UserID: 1, TokenType: "mfa_pending"},
+			"session":     {UserID: 1, TokenType: token.TypeAccess, FamilyID: "family_1", Scope: route.Scope},
+			"revoked":     {UserID: 1, TokenType: token.TypeAccess, FamilyID: "revoked", Scope: route.Scope},
+			"unavailable": {UserID: 1, TokenType: token.TypeAccess, FamilyID: "unavailable", Scope: route.Scope},
+		}
+		keys := fakeKeys{
+			"key-no-scope":   token.APIKeyClaims(1, "user", "", 0),
+			"key-with-scope": token.APIKeyClaims(1, "user", route.Scope, 0),
+		}
+		router := NewRouter(log, cfg, parser, keys)
+		path := strings.ReplaceAll(route.Pattern, "{id}", "1")
+
+		cases := []struct {
+			name   string
+			auth   string
+			status int
+		}{
+			{name: "no token", status: http.StatusUnauthorized},
+			{name: "bad token", auth: "Bearer garbage", status: http.StatusUnauthorized},
+			{name: "refresh token", auth: "Bearer refresh", status: http.StatusUnauthorized},
+			{name: "mfa pending", auth: "Bearer mfa-pending", status: http.StatusUnauthorized},
+			{name: "no scope", auth: "Bearer no-scope", status: http.StatusForbidden},
+			{name: "with scope", auth: "Bearer with-scope", status: http.StatusOK},
+			{name: "active session", auth: "Bearer session", status: http.StatusOK},
+			{name: "revoked session", auth: "Bearer revoked", status: http.StatusUnauthorized},
+			{name: "session check down", auth: "Bearer unavailable", status: http.StatusServiceUnavailable},
+			{name: "inactive api key", auth: "ApiKey revoked", status: http.StatusUnauthorized},
+			{name: "auth service down", auth: "ApiKey unavailable", status: http.StatusServiceUnavailable},
+			{name: "api key no scope", auth: "ApiKey key-no-scope", status: http.StatusForbidden},
+			{name: "api key with scope", auth: "ApiKey key-with-scope", status: http.StatusOK},
+		}
+
+		for _, tc := range cases {
+			t.Run(route.Method+" "+route.Pattern+" "+tc.name, func(t *testing.T) {
+				hits = nil
+				req := httptest.NewRequest(route.Method, path, nil)
+				if tc.auth != "" {
+					req.Header.Set("Authorization", tc.auth)
+				}
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+
+				if rr.Code != tc.status {
+					t.Fatalf("expected %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+				}
+				// Запрос без нужного scope не должен доходить до сервиса
+				proxied := len(hits) == 1 && hits[0] == route.Method+" "+path
+				if proxied != (tc.status == http.StatusOK) {
+					t.Fatalf("unexpected backend hits: %v", hits)
+				}
+			})
+		}
+	}
+}
+
+func TestPolicyRoutesHaveScopeAndTarget(t *testing.T) {
+	cfg := &config.Config{Auth: "http://auth", Review: "http://review", Mentor: "http://mentor"}
+	for _, route := range Policy(cfg) {
+		if route.Scope == "" || route.Target == "" {
+			t.Errorf("%s %s: scope %q, target %q", route.Method, route.Pattern, route.Scope, route.Target)
+		}
+	}
+}
+
+func TestPublicRoutesStayOpen(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	cfg := &config.Config{Auth: backend.URL, Review: backend.URL, Mentor: backend.URL}
+	router := NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, fakeParser{}, fakeKeys{})
+
+	for _, r := range []struct{ method, path string }{
+		{http.MethodPost, "/auth/login"},
+		{http.MethodGet, "/auth/profile"},
+		{http.MethodGet, "/review/get"},
+		{http.MethodGet, "/mentors/get"},
+		{http.MethodGet, "/mentors/42"},
+		{http.MethodGet, "/mentors/42/rating"},
+		{http.MethodGet, "/mentors/42/availability"},
+		{http.MethodGet, "/mentors/42/slots"},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(r.method, r.path, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s %s: expected 200, got %d", r.method, r.path, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package token
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrAPIKeyInactive ключ неизвестен, отозван, истёк или его владелец отключён
+var ErrAPIKeyInactive = errors.New("api key is not active")
+
+// APIKeyClaims собирает claims из ответа интроспекции, чтобы обработчики
+// не различали запросы с ключом и с access токеном
+func APIKeyClaims(userID int64, role, scope string, expiresAt int64) *Claims {
+	claims := &Claims{
+		UserID:    userID,
+		Role:      role,
+		TokenType: TypeAccess,
+		Scope:     scope,
+	}
+	if expiresAt > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(expiresAt, 0))
+	}
+	return claims
+}
@@ -0,0 +1,23 @@
+package token
+
+import (
+	"slices"
+	"strings"
+)
+
+// Права из claim scope, которые выдаёт сервис авторизации
+const (
+	ScopeReviewWrite    = "review:write"
+	ScopeReviewModerate = "review:moderate"
+	ScopeMentorEditSelf = "mentor:edit_self"
+	ScopeUserAdmin      = "user:admin"
+	ScopeSessionBook    = "session:book"
+)
+
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
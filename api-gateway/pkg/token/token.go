@@ -0,0 +1,68 @@
+package token
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidSigningMethod = errors.New("invalid signing method")
+	ErrTokenExpired         = errors.New("token expired")
+)
+
+// Типы токенов, которые выдаёт сервис авторизации
+const (
+	TypeAccess     = "access"
+	TypeRefresh    = "refresh"
+	TypeMFAPending = "mfa_pending"
+)
+
+// KeySource отдаёт публичный ключ по kid из заголовка токена
+type KeySource interface {
+	Key(kid string) (*rsa.PublicKey, error)
+}
+
+type TokenManager struct {
+	keys KeySource
+}
+
+type Claims struct {
+	UserID    int64  `json:"user_id"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	FamilyID  string `json:"family_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func NewTokenManager(keys KeySource) *TokenManager {
+	return &TokenManager{keys: keys}
+}
+
+func (tm *TokenManager) ParseToken(tokenStr string) (*Claims, error) {
+
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, ErrInvalidSigningMethod
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return tm.keys.Key(kid)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if claims.ExpiresAt.Before(time.Now()) {
+			return nil, ErrTokenExpired
+		}
+		return claims, nil
+	}
+	return nil, fmt.Errorf("parse token: %w", err)
+}
@@ -5,25 +5,27 @@ import (
 	"fmt"
 	"log/slog"
 	"mentorlink/internal/config"
+	"mentorlink/internal/grpc/authservice"
 	grpcclient "mentorlink/internal/grpc/client"
-	"mentorlink/internal/handlers/login"
-	"mentorlink/internal/handlers/logout"
-	"mentorlink/internal/handlers/refresh"
-	"mentorlink/internal/handlers/register"
-	mwLogger "mentorlink/internal/middleware/logger"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"mentorlink/internal/kafka"
+	"mentorlink/internal/lib/audit"
 	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/mailer"
+	"mentorlink/internal/lib/oidc"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/passpolicy"
+	"mentorlink/internal/lib/verification"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/server"
 	"mentorlink/internal/storage/cache"
 	"mentorlink/internal/storage/db"
+	"mentorlink/internal/transport/http/router"
 	"mentorlink/pkg/token"
-	"net/http"
 	"os"
-
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
 )
 
 const (
@@ -49,17 +51,53 @@ func main() {
 
 	redisRepository := cache.NewRedisRepository(redisClient)
 
+	loginLimiter := cache.NewLoginLimiter(redisClient, cfg.Lockout)
+
 	storage, err := db.NewStorage(cfg.Config)
 	if err != nil {
 		log.Error("error creation storage", sl.Err(err))
 		os.Exit(1)
 	}
 
-	tokemMn, err := token.NewTokenmanagerRSA(cfg.PrivateKeyPath, cfg.PublicKeyPath)
+	hasher := passhash.New(cfg.Argon2)
+
+	policy, err := passpolicy.New(cfg.PasswordPolicy)
+	if err != nil {
+		log.Error("error with password policy", sl.Err(err))
+		os.Exit(1)
+	}
+	log.Info("password policy loaded", slog.Int("breached_hashes", policy.Breached()))
+
+	if err := bootstrapAdmin(log, storage, hasher, cfg.AdminEmail, cfg.AdminPassword); err != nil {
+		log.Error("failed to create bootstrap admin", sl.Err(err))
+		os.Exit(1)
+	}
+
+	var tokemMn *token.TokenManager
+	if cfg.KeysDir != "" {
+		tokemMn, err = token.NewTokenManagerFromDir(cfg.KeysDir, cfg.ActiveKID)
+	} else {
+		tokemMn, err = token.NewTokenmanagerRSA(cfg.PrivateKeyPath, cfg.PublicKeyPath)
+	}
 	if err != nil {
 		log.Error("error with token manager", sl.Err(err))
 		os.Exit(1)
 	}
+	log.Info("token signing key loaded", slog.String("kid", tokemMn.ActiveKID()))
+
+	mail, err := mailer.New(cfg.Mail, log)
+	if err != nil {
+		log.Error("error with mailer", sl.Err(err))
+		os.Exit(1)
+	}
+
+	verifier := verification.NewSender(tokemMn, mail, cfg.AppURL)
+
+	oidcProviders, err := oidc.NewRegistry(cfg.OIDC)
+	if err != nil {
+		log.Error("error with oidc config", sl.Err(err))
+		os.Exit(1)
+	}
 
 	client, err := grpcclient.NewMentorClient(fmt.Sprintf("mentor-server:%s", cfg.MentorServiceAddress))
 	if err != nil {
@@ -75,32 +113,58 @@ func main() {
 		}
 	}()
 
-	router := chi.NewRouter()
-
-	router.Use(middleware.RequestID)
-	router.Use(mwLogger.New(log))
-	router.Use(middleware.URLFormat)
+	producer, err := kafka.NewProducer(cfg.Kafka)
+	if err != nil {
+		log.Error("error with kafka producer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer producer.Close()
 
-	router.Post("/auth/register", register.Register(context.Background(), log, storage, client))
-	router.Post("/auth/login", login.Login(log, storage, tokemMn))
-	router.Post("/auth/logout", logout.Logout(log, redisRepository, tokemMn))
-	router.Post("/auth/refresh", refresh.RefreshTokens(log, redisRepository, tokemMn))
+	// Relay доставляет в сервис менторов события, записанные в outbox при регистрации и подтверждении email,
+	// а пользовательские события (удаление аккаунта, выгрузка данных) публикует в Kafka
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	go outbox.NewRelay(log, storage, client, producer, cfg.Outbox).Run(relayCtx)
 
-	log.Info("starting server", slog.String("adsress", cfg.Address))
+	// Части выгрузки данных от review и mentor
+	exportConsumer, err := kafka.NewExportConsumer(cfg.Kafka, redisRepository, log)
+	if err != nil {
+		log.Error("error with kafka consumer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer exportConsumer.Close()
+	go exportConsumer.Run(relayCtx)
+
+	handler := router.SetupRouter(log, router.Deps{
+		Storage:      storage,
+		TokenManager: tokemMn,
+		Redis:        redisRepository,
+		LoginLimiter: loginLimiter,
+		Mailer:       mail,
+		Hasher:       hasher,
+		Policy:       policy,
+		Verifier:     verifier,
+		Audit:        audit.New(storage, log),
+		OIDC:         oidcProviders,
+		OIDCStateTTL: cfg.OIDC.StateTTL,
+		AppURL:       cfg.AppURL,
+	})
+
+	authSrv := authservice.NewAuthService(log, storage, redisRepository, tokemMn)
+
+	srv, err := server.New(cfg, handler, authSrv)
+	if err != nil {
+		log.Error("failed to create server", sl.Err(err))
+		os.Exit(1)
+	}
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-	srv := &http.Server{
-		Addr:         cfg.Address,
-		Handler:      router,
-		ReadTimeout:  cfg.Timeout,
-		WriteTimeout: cfg.Timeout,
-		IdleTimeout:  cfg.IdleTimeout,
-	}
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := srv.Start(log); err != nil {
 			log.Error("failed to start server", sl.Err(err))
+			os.Exit(1)
 		}
 	}()
 
@@ -112,7 +176,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Stop(ctx); err != nil {
 		log.Error("failed to stop server", sl.Err(err))
 
 		return
@@ -122,6 +186,32 @@ func main() {
 
 }
 
+// bootstrapAdmin создаёт первого администратора: самостоятельно зарегистрироваться админом нельзя
+func bootstrapAdmin(log *slog.Logger, storage *db.Storage, hasher *passhash.Hasher, email, password string) error {
+	if email == "" {
+		return nil
+	}
+	if password == "" {
+		return fmt.Errorf("ADMIN_PASSWORD is required when ADMIN_EMAIL is set")
+	}
+
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("hash admin password: %w", err)
+	}
+
+	created, err := storage.EnsureAdmin(email, hash)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Info("bootstrap admin created", slog.String("email", email))
+	} else {
+		log.Debug("bootstrap admin already exists", slog.String("email", email))
+	}
+	return nil
+}
+
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
@@ -2,6 +2,12 @@ package config
 
 import (
 	"log"
+	"mentorlink/internal/kafka"
+	"mentorlink/internal/lib/mailer"
+	"mentorlink/internal/lib/oidc"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/passpolicy"
+	"mentorlink/internal/outbox"
 	"mentorlink/internal/storage/cache"
 	postgres "mentorlink/internal/storage/db"
 	"time"
@@ -12,9 +18,27 @@ import (
 type Config struct {
 	postgres.Config
 	cache.RedisConfig
+	Mail mailer.Config
+
+	Lockout cache.LockoutPolicy
+
+	Outbox outbox.Config
+
+	Kafka kafka.Config
+
+	OIDC oidc.Config
+
+	Argon2         passhash.Config
+	PasswordPolicy passpolicy.Config
 
 	Address string `env:"ADDRESS" env-required:"true"`
 
+	// gRPC сервер, через который review и шлюз проверяют API ключи
+	GRPCAddress string `env:"GRPC_ADDRESS" env-default:":50052"`
+
+	// Адрес фронтенда, на который ведут ссылки из писем
+	AppURL string `env:"APP_URL" env-default:"http://localhost:3001"`
+
 	MentorServiceAddress string `env:"MENTOR_SERVICE_ADDRESS" env-required:"true"`
 
 	Env string `env:"ENV" env-required:"true"`
@@ -25,6 +49,14 @@ type Config struct {
 	PrivateKeyPath string `env:"JWT_PRIVATE_KEY_PATH"`
 	PublicKeyPath  string `env:"JWT_PUBLIC_KEY_PATH"`
 
+	// Если задан каталог ключей, пара PRIVATE/PUBLIC_KEY_PATH не используется
+	KeysDir   string `env:"JWT_KEYS_DIR"`
+	ActiveKID string `env:"JWT_ACTIVE_KID"`
+
+	// Администратор, создаваемый при старте, если такого email ещё нет
+	AdminEmail    string `env:"ADMIN_EMAIL"`
+	AdminPassword string `env:"ADMIN_PASSWORD"`
+
 	Timeout     time.Duration `env:"TIMEOUT" env-default:"4s"`
 	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" env-default:"60s"`
 }
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Типы событий журнала аутентификации
+const (
+	AuthEventLogin          = "login"
+	AuthEventLoginMFA       = "login_mfa"
+	AuthEventLoginMagicLink = "login_magic_link"
+	AuthEventLoginOIDC      = "login_oidc"
+	AuthEventLogout         = "logout"
+	AuthEventRefresh        = "refresh"
+	AuthEventRegister       = "register"
+	AuthEventPasswordForgot = "password_forgot"
+	AuthEventPasswordReset  = "password_reset"
+	AuthEventPasswordChange = "password_change"
+	AuthEventEmailChange    = "email_change"
+	AuthEvent2FAEnable      = "2fa_enable"
+	AuthEventAccountDelete  = "account_delete"
+
+	// Действия администратора пишутся на пользователя, над которым они выполнены
+	AuthEventRoleChange     = "role_change"
+	AuthEventAccountDisable = "account_disable"
+	AuthEventAccountEnable  = "account_enable"
+	AuthEventForceLogout    = "force_logout"
+)
+
+// Результаты событий
+const (
+	OutcomeSuccess     = "success"
+	OutcomeFailure     = "failure"
+	OutcomeMFARequired = "mfa_required"
+)
+
+// AuthEvent запись журнала аутентификации. UserID = 0, если пользователь неизвестен,
+// например при входе с несуществующим email
+type AuthEvent struct {
+	ID        int64     `json:"id" db:"id"`
+	Type      string    `json:"type" db:"type"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	IP        string    `json:"ip" db:"ip"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	RequestID string    `json:"request_id" db:"request_id"`
+	Outcome   string    `json:"outcome" db:"outcome"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ExportServices сервисы, без частей которых выгрузка не готова
+var ExportServices = []string{"auth", "review", "mentor"}
+
+// DataExport выгрузка персональных данных, собираемая по частям от сервисов
+type DataExport struct {
+	ID        string
+	UserID    int64
+	CreatedAt time.Time
+	Parts     map[string]json.RawMessage
+}
+
+// Missing сервисы, которые ещё не прислали свою часть
+func (e *DataExport) Missing() []string {
+	var missing []string
+	for _, s := range ExportServices {
+		if _, ok := e.Parts[s]; !ok {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
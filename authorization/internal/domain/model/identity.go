@@ -0,0 +1,15 @@
+package model
+
+// Identity учётная запись пользователя у внешнего OIDC провайдера
+type Identity struct {
+	Provider string `db:"provider" json:"provider"`
+	Subject  string `db:"subject" json:"subject"` // claim sub, стабилен в пределах провайдера в отличие от email
+	Email    string `db:"email" json:"email"`
+}
+
+// OIDCState то, что нужно запомнить между редиректом к провайдеру и callback
+type OIDCState struct {
+	Provider     string
+	CodeVerifier string // PKCE
+	Nonce        string
+}
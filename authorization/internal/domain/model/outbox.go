@@ -0,0 +1,8 @@
+package model
+
+type OutboxMessage struct {
+	ID       int64  `db:"id"`
+	Kind     string `db:"kind"`
+	Payload  []byte `db:"payload"`
+	Attempts int    `db:"attempts"`
+}
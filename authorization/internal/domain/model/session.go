@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Session соответствует одному семейству refresh токенов (одному входу пользователя)
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
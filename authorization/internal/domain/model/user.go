@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 const (
 	RoleUser   = "user"
 	RoleMentor = "mentor"
@@ -11,6 +13,31 @@ type User struct {
 	Email    string `db:"email"`
 	Password string `db:"password"`
 	Role     string `db:"role"` // admin, mentor, user
+
+	VerifiedAt *time.Time `db:"verified_at"`
+	DisabledAt *time.Time `db:"disabled_at"` // отключённый администратором аккаунт не может войти
+
+	// Секрет TOTP появляется при подключении 2FA, включается после подтверждения кодом
+	TOTPSecret  string `db:"totp_secret"`
+	TOTPEnabled bool   `db:"totp_enabled"`
+
+	Profile
+}
+
+// Profile данные, которые пользователь редактирует сам
+type Profile struct {
+	DisplayName string `db:"display_name" json:"display_name"`
+	AvatarURL   string `db:"avatar_url" json:"avatar_url"`
+	Contact     string `db:"contact" json:"contact"`
+	Timezone    string `db:"timezone" json:"timezone"`
+}
+
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
 }
 
 type Mentor struct {
@@ -1,18 +1,112 @@
 package requests
 
+// Register длина и стойкость пароля проверяются политикой паролей, а не тегами
 type Register struct {
 	Email          string `json:"email" validate:"required,email"`
-	Password       string `json:"password" validate:"required,min=6"`
+	Password       string `json:"password" validate:"required"`
 	RepeatPassword string `json:"repeat_password" validate:"required,eqfield=Password"`
-	Role           string `json:"role" validate:"required,oneof=user mentor admin"`
+	Role           string `json:"role" validate:"required,oneof=user mentor"` // администратора назначает только администратор
 	Contact        string `json:"contact"`
 }
 
 type Login struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required,max=1024"`
 }
 
 type RFToken struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Token          string `json:"token" validate:"required"`
+	Password       string `json:"password" validate:"required"`
+	RepeatPassword string `json:"repeat_password" validate:"required,eqfield=Password"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password" validate:"max=1024"`
+	Password        string `json:"password" validate:"required"`
+	RepeatPassword  string `json:"repeat_password" validate:"required,eqfield=Password"`
+}
+
+type ChangeEmail struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"max=1024"`
+}
+
+type ConfirmEmailChange struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerification struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MFALogin struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"` // код из приложения или код восстановления
+}
+
+type TOTPCode struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type UnlockAccount struct {
+	Email string `json:"email" validate:"required,email"`
+	IP    string `json:"ip" validate:"omitempty,ip"`
+}
+
+// UpdateProfile частичное обновление: nil поле не меняется, пустая строка очищает его
+type UpdateProfile struct {
+	DisplayName *string `json:"display_name" validate:"omitnil,max=100"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitnil,max=2048,len=0|http_url"`
+	Contact     *string `json:"contact" validate:"omitnil,max=255"`
+	Timezone    *string `json:"timezone" validate:"omitnil,len=0|timezone"`
+}
+
+type ListUsers struct {
+	Query  string `validate:"max=255"`
+	Role   string `validate:"omitempty,oneof=user mentor admin"`
+	Limit  int    `validate:"min=1,max=100"`
+	Offset int    `validate:"min=0"`
+}
+
+type ListAuthEvents struct {
+	UserID int64  `validate:"min=0"`
+	Type   string `validate:"omitempty,max=50"`
+	Limit  int    `validate:"min=1,max=100"`
+	Offset int    `validate:"min=0"`
+}
+
+type ChangeRole struct {
+	Role string `json:"role" validate:"required,oneof=user mentor admin"`
+}
+
+// CreateAPIKey без scopes ключ получает все права роли, доступные ключам
+type CreateAPIKey struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"max=10,dive,required"`
+	ExpiresInDays *int     `json:"expires_in_days" validate:"omitnil,min=1,max=365"`
+}
+
+type MagicLink struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkVerify struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeleteAccount пароль подтверждает удаление; у аккаунтов без пароля (OIDC) не проверяется
+type DeleteAccount struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,215 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/lib/apikey"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	pb "mentorlink/pkg/api/proto"
+	"mentorlink/pkg/token"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBatchSize сколько пользователей можно запросить одним GetUsersByIDs
+const MaxBatchSize = 100
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Storage
+type Storage interface {
+	GetAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	TouchAPIKey(id int64) error
+	GetByID(id int64) (*model.User, error)
+	GetUsersByIDs(ids []int64) ([]model.User, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=SessionStore
+type SessionStore interface {
+	IsSessionActive(sessionID string) (bool, error)
+	SessionOwner(sessionID string) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenParser
+type TokenParser interface {
+	ParseToken(tokenStr string) (*token.Claims, error)
+}
+
+type AuthService struct {
+	pb.UnimplementedAuthServiceServer
+	storage  Storage
+	sessions SessionStore
+	tokens   TokenParser
+	log      *slog.Logger
+}
+
+func NewAuthService(log *slog.Logger, storage Storage, sessions SessionStore, tokens TokenParser) *AuthService {
+	return &AuthService{log: log, storage: storage, sessions: sessions, tokens: tokens}
+}
+
+var inactive = &pb.IntrospectResponse{Active: false}
+
+// IntrospectAPIKey проверяет ключ для review и шлюза. Неизвестный, отозванный или истёкший ключ,
+// как и ключ отключённого пользователя, не ошибка: ответ просто active=false
+func (s *AuthService) IntrospectAPIKey(ctx context.Context, req *pb.IntrospectAPIKeyRequest) (*pb.IntrospectResponse, error) {
+	const op = "grpc.authservice.IntrospectAPIKey"
+	log := s.log.With(slog.String("op", op))
+
+	if !apikey.LooksValid(req.GetKey()) {
+		return inactive, nil
+	}
+
+	key, err := s.storage.GetAPIKeyByHash(secret.Hash(req.GetKey()))
+	if errors.Is(err, db.ErrAPIKeyNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		log.Error("failed to get api key", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to introspect api key")
+	}
+	if !key.IsActive(time.Now()) {
+		return inactive, nil
+	}
+
+	user, err := s.storage.GetByID(key.UserID)
+	if errors.Is(err, db.ErrUserNotFound) {
+		return inactive, nil
+	}
+	if err != nil {
+		log.Error("failed to get key owner", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to introspect api key")
+	}
+	if user.IsDisabled() {
+		log.Warn("api key of disabled user", slog.Int64("user_id", user.ID), slog.String("prefix", key.Prefix))
+		return inactive, nil
+	}
+
+	// Отметка использования не должна ломать сам запрос
+	if err := s.storage.TouchAPIKey(key.ID); err != nil {
+		log.Warn("failed to touch api key", sl.Err(err))
+	}
+
+	resp := &pb.IntrospectResponse{
+		Active: true,
+		UserId: user.ID,
+		Role:   user.Role,
+		Scope:  apikey.EffectiveScope(key.Scope, user.Role),
+	}
+	if key.ExpiresAt != nil {
+		resp.ExpiresAt = key.ExpiresAt.Unix()
+	}
+	return resp, nil
+}
+
+// IntrospectToken повторяет проверки AuthMiddleware: подпись, тип access и живую сессию
+func (s *AuthService) IntrospectToken(ctx context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectResponse, error) {
+	const op = "grpc.authservice.IntrospectToken"
+	log := s.log.With(slog.String("op", op))
+
+	claims, err := s.tokens.ParseToken(req.GetToken())
+	if err != nil || claims.TokenType != token.TypeAccess {
+		return inactive, nil
+	}
+
+	if claims.FamilyID != "" {
+		active, err := s.sessions.IsSessionActive(claims.FamilyID)
+		if err != nil {
+			log.Error("failed to check session", sl.Err(err))
+			return nil, status.Error(codes.Internal, "failed to introspect token")
+		}
+		if !active {
+			return inactive, nil
+		}
+	}
+
+	resp := &pb.IntrospectResponse{
+		Active:    true,
+		UserId:    claims.UserID,
+		Role:      claims.Role,
+		Scope:     claims.Scope,
+		SessionId: claims.FamilyID,
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return resp, nil
+}
+
+func (s *AuthService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	const op = "grpc.authservice.GetUser"
+	log := s.log.With(slog.String("op", op))
+
+	user, err := s.storage.GetByID(req.GetUserId())
+	if errors.Is(err, db.ErrUserNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		log.Error("failed to get user", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+	return &pb.GetUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *AuthService) GetUsersByIDs(ctx context.Context, req *pb.GetUsersByIDsRequest) (*pb.GetUsersByIDsResponse, error) {
+	const op = "grpc.authservice.GetUsersByIDs"
+	log := s.log.With(slog.String("op", op))
+
+	ids := req.GetUserIds()
+	if len(ids) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user ids per request", MaxBatchSize)
+	}
+	if len(ids) == 0 {
+		return &pb.GetUsersByIDsResponse{}, nil
+	}
+
+	users, err := s.storage.GetUsersByIDs(ids)
+	if err != nil {
+		log.Error("failed to get users", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to get users")
+	}
+
+	resp := &pb.GetUsersByIDsResponse{Users: make([]*pb.User, 0, len(users))}
+	for i := range users {
+		resp.Users = append(resp.Users, toProtoUser(&users[i]))
+	}
+	return resp, nil
+}
+
+// IsSessionActive чужая сессия считается неактивной, чтобы по id нельзя было узнать о чужих входах
+func (s *AuthService) IsSessionActive(ctx context.Context, req *pb.IsSessionActiveRequest) (*pb.IsSessionActiveResponse, error) {
+	const op = "grpc.authservice.IsSessionActive"
+	log := s.log.With(slog.String("op", op))
+
+	if req.GetSessionId() == "" {
+		return &pb.IsSessionActiveResponse{Active: false}, nil
+	}
+
+	owner, err := s.sessions.SessionOwner(req.GetSessionId())
+	if errors.Is(err, cache.ErrSessionNotFound) {
+		return &pb.IsSessionActiveResponse{Active: false}, nil
+	}
+	if err != nil {
+		log.Error("failed to check session", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to check session")
+	}
+	return &pb.IsSessionActiveResponse{Active: owner == req.GetUserId()}, nil
+}
+
+func toProtoUser(u *model.User) *pb.User {
+	return &pb.User{
+		Id:          u.ID,
+		Email:       u.Email,
+		Role:        u.Role,
+		DisplayName: u.DisplayName,
+		AvatarUrl:   u.AvatarURL,
+		Contact:     u.Contact,
+		Timezone:    u.Timezone,
+		Verified:    u.IsVerified(),
+		Disabled:    u.IsDisabled(),
+	}
+}
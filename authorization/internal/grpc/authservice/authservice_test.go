@@ -0,0 +1,350 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/apikey"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	pb "mentorlink/pkg/api/proto"
+	"mentorlink/pkg/token"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntrospectAPIKey(t *testing.T) {
+	key, prefix, err := apikey.Generate()
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	stored := func(mod func(k *model.APIKey)) *model.APIKey {
+		k := &model.APIKey{ID: 3, UserID: 7, Prefix: prefix, KeyHash: secret.Hash(key), Scope: "mentor:edit_self review:write"}
+		if mod != nil {
+			mod(k)
+		}
+		return k
+	}
+	mentor := &model.User{ID: 7, Role: model.RoleMentor}
+
+	cases := []struct {
+		name      string
+		key       string
+		apiKey    *model.APIKey
+		keyErr    error
+		user      *model.User
+		userErr   error
+		touch     bool
+		expected  *pb.IntrospectResponse
+		errorCode codes.Code
+	}{
+		{
+			name:     "Active key",
+			key:      key,
+			apiKey:   stored(nil),
+			user:     mentor,
+			touch:    true,
+			expected: &pb.IntrospectResponse{Active: true, UserId: 7, Role: model.RoleMentor, Scope: "mentor:edit_self review:write"},
+		},
+		{
+			name:     "Expiry is reported",
+			key:      key,
+			apiKey:   stored(func(k *model.APIKey) { k.ExpiresAt = &future }),
+			user:     mentor,
+			touch:    true,
+			expected: &pb.IntrospectResponse{Active: true, UserId: 7, Role: model.RoleMentor, Scope: "mentor:edit_self review:write", ExpiresAt: future.Unix()},
+		},
+		{
+			name:     "Scope shrinks after demotion",
+			key:      key,
+			apiKey:   stored(nil),
+			user:     &model.User{ID: 7, Role: model.RoleUser},
+			touch:    true,
+			expected: &pb.IntrospectResponse{Active: true, UserId: 7, Role: model.RoleUser, Scope: "review:write"},
+		},
+		{
+			name:     "Malformed key is not looked up",
+			key:      "Bearer something",
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Unknown key",
+			key:      key,
+			keyErr:   db.ErrAPIKeyNotFound,
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Revoked key",
+			key:      key,
+			apiKey:   stored(func(k *model.APIKey) { k.RevokedAt = &past }),
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Expired key",
+			key:      key,
+			apiKey:   stored(func(k *model.APIKey) { k.ExpiresAt = &past }),
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Disabled owner",
+			key:      key,
+			apiKey:   stored(nil),
+			user:     &model.User{ID: 7, Role: model.RoleMentor, DisabledAt: &past},
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Deleted owner",
+			key:      key,
+			apiKey:   stored(nil),
+			userErr:  db.ErrUserNotFound,
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:      "Storage error",
+			key:       key,
+			keyErr:    errors.New("db error"),
+			errorCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := mocks.NewUserCreater(t)
+			if tc.apiKey != nil || tc.keyErr != nil {
+				storage.On("GetAPIKeyByHash", secret.Hash(key)).Return(tc.apiKey, tc.keyErr)
+			}
+			if tc.user != nil || tc.userErr != nil {
+				storage.On("GetByID", int64(7)).Return(tc.user, tc.userErr)
+			}
+			if tc.touch {
+				storage.On("TouchAPIKey", int64(3)).Return(errors.New("touch failures are ignored"))
+			}
+
+			srv := NewAuthService(slogdiscard.NewDiscardLogger(), storage, mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+			resp, err := srv.IntrospectAPIKey(context.Background(), &pb.IntrospectAPIKeyRequest{Key: tc.key})
+
+			if tc.errorCode != codes.OK {
+				require.Equal(t, tc.errorCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.Active, resp.Active)
+			require.Equal(t, tc.expected.UserId, resp.UserId)
+			require.Equal(t, tc.expected.Role, resp.Role)
+			require.Equal(t, tc.expected.Scope, resp.Scope)
+			require.Equal(t, tc.expected.ExpiresAt, resp.ExpiresAt)
+		})
+	}
+}
+
+func TestIntrospectToken(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Truncate(time.Second)
+	access := &token.Claims{
+		UserID:           7,
+		Role:             model.RoleMentor,
+		TokenType:        token.TypeAccess,
+		Scope:            "mentor:edit_self review:write",
+		FamilyID:         "family",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(exp)},
+	}
+
+	cases := []struct {
+		name          string
+		claims        *token.Claims
+		parseErr      error
+		checksSession bool
+		sessionActive bool
+		sessionErr    error
+		expected      *pb.IntrospectResponse
+		errorCode     codes.Code
+	}{
+		{
+			name:          "Active token",
+			claims:        access,
+			checksSession: true,
+			sessionActive: true,
+			expected: &pb.IntrospectResponse{
+				Active: true, UserId: 7, Role: model.RoleMentor, Scope: "mentor:edit_self review:write",
+				ExpiresAt: exp.Unix(), SessionId: "family",
+			},
+		},
+		{
+			name:     "Invalid signature",
+			parseErr: errors.New("invalid token"),
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "Refresh token",
+			claims:   &token.Claims{UserID: 7, TokenType: token.TypeRefresh, FamilyID: "family"},
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:     "MFA pending token",
+			claims:   &token.Claims{UserID: 7, TokenType: token.TypeMFAPending},
+			expected: &pb.IntrospectResponse{},
+		},
+		{
+			name:          "Revoked session",
+			claims:        access,
+			checksSession: true,
+			expected:      &pb.IntrospectResponse{},
+		},
+		{
+			name:          "Session store error",
+			claims:        access,
+			checksSession: true,
+			sessionErr:    errors.New("redis error"),
+			errorCode:     codes.Internal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := mocks.NewTokenMn(t)
+			tokens.On("ParseToken", "signed").Return(tc.claims, tc.parseErr)
+			sessions := mocks.NewRedisRepo(t)
+			if tc.checksSession {
+				sessions.On("IsSessionActive", "family").Return(tc.sessionActive, tc.sessionErr)
+			}
+
+			srv := NewAuthService(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), sessions, tokens)
+			resp, err := srv.IntrospectToken(context.Background(), &pb.IntrospectTokenRequest{Token: "signed"})
+
+			if tc.errorCode != codes.OK {
+				require.Equal(t, tc.errorCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.Active, resp.Active)
+			require.Equal(t, tc.expected.UserId, resp.UserId)
+			require.Equal(t, tc.expected.Role, resp.Role)
+			require.Equal(t, tc.expected.Scope, resp.Scope)
+			require.Equal(t, tc.expected.ExpiresAt, resp.ExpiresAt)
+			require.Equal(t, tc.expected.SessionId, resp.SessionId)
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	verified := time.Now()
+	user := &model.User{
+		ID: 7, Email: "m@example.com", Password: "hash", Role: model.RoleMentor, VerifiedAt: &verified,
+		TOTPSecret: "secret", Profile: model.Profile{DisplayName: "Mentor", Timezone: "Europe/Moscow"},
+	}
+
+	cases := []struct {
+		name      string
+		user      *model.User
+		mockErr   error
+		errorCode codes.Code
+	}{
+		{name: "Found", user: user},
+		{name: "Not found", mockErr: db.ErrUserNotFound, errorCode: codes.NotFound},
+		{name: "Storage error", mockErr: errors.New("db error"), errorCode: codes.Internal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := mocks.NewUserCreater(t)
+			storage.On("GetByID", int64(7)).Return(tc.user, tc.mockErr)
+
+			srv := NewAuthService(slogdiscard.NewDiscardLogger(), storage, mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+			resp, err := srv.GetUser(context.Background(), &pb.GetUserRequest{UserId: 7})
+
+			if tc.errorCode != codes.OK {
+				require.Equal(t, tc.errorCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(7), resp.User.Id)
+			require.Equal(t, "m@example.com", resp.User.Email)
+			require.Equal(t, model.RoleMentor, resp.User.Role)
+			require.Equal(t, "Mentor", resp.User.DisplayName)
+			require.Equal(t, "Europe/Moscow", resp.User.Timezone)
+			require.True(t, resp.User.Verified)
+			require.False(t, resp.User.Disabled)
+		})
+	}
+}
+
+func TestGetUsersByIDs(t *testing.T) {
+	t.Run("Missing ids are skipped", func(t *testing.T) {
+		storage := mocks.NewUserCreater(t)
+		storage.On("GetUsersByIDs", []int64{1, 2, 3}).Return([]model.User{{ID: 1}, {ID: 3}}, nil)
+
+		srv := NewAuthService(slogdiscard.NewDiscardLogger(), storage, mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+		resp, err := srv.GetUsersByIDs(context.Background(), &pb.GetUsersByIDsRequest{UserIds: []int64{1, 2, 3}})
+		require.NoError(t, err)
+		require.Len(t, resp.Users, 2)
+		require.Equal(t, int64(1), resp.Users[0].Id)
+		require.Equal(t, int64(3), resp.Users[1].Id)
+	})
+
+	t.Run("Empty request skips storage", func(t *testing.T) {
+		srv := NewAuthService(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+		resp, err := srv.GetUsersByIDs(context.Background(), &pb.GetUsersByIDsRequest{})
+		require.NoError(t, err)
+		require.Empty(t, resp.Users)
+	})
+
+	t.Run("Too many ids", func(t *testing.T) {
+		srv := NewAuthService(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+		_, err := srv.GetUsersByIDs(context.Background(), &pb.GetUsersByIDsRequest{UserIds: make([]int64, MaxBatchSize+1)})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		storage := mocks.NewUserCreater(t)
+		storage.On("GetUsersByIDs", []int64{1}).Return(nil, errors.New("db error"))
+
+		srv := NewAuthService(slogdiscard.NewDiscardLogger(), storage, mocks.NewRedisRepo(t), mocks.NewTokenMn(t))
+		_, err := srv.GetUsersByIDs(context.Background(), &pb.GetUsersByIDsRequest{UserIds: []int64{1}})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestIsSessionActive(t *testing.T) {
+	cases := []struct {
+		name      string
+		sessionID string
+		owner     int64
+		mockErr   error
+		expected  bool
+		errorCode codes.Code
+	}{
+		{name: "Own session", sessionID: "s1", owner: 7, expected: true},
+		{name: "Someone else's session", sessionID: "s1", owner: 8},
+		{name: "Expired session", sessionID: "s1", mockErr: cache.ErrSessionNotFound},
+		{name: "Empty session id", sessionID: ""},
+		{name: "Redis error", sessionID: "s1", mockErr: errors.New("redis error"), errorCode: codes.Internal},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessions := mocks.NewRedisRepo(t)
+			if tc.sessionID != "" {
+				sessions.On("SessionOwner", tc.sessionID).Return(tc.owner, tc.mockErr)
+			}
+
+			srv := NewAuthService(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), sessions, mocks.NewTokenMn(t))
+			resp, err := srv.IsSessionActive(context.Background(), &pb.IsSessionActiveRequest{UserId: 7, SessionId: tc.sessionID})
+
+			if tc.errorCode != codes.OK {
+				require.Equal(t, tc.errorCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resp.Active)
+		})
+	}
+}
@@ -34,8 +34,9 @@ func (m *MentorClient) Close() error {
 	return m.conn.Close()
 }
 
-func (m *MentorClient) NewMentor(ctx context.Context, mentorEmail, contact string) error {
+func (m *MentorClient) NewMentor(ctx context.Context, mentorID int64, mentorEmail, contact string) error {
 	req := &pb.MentorRequest{
+		MentorId:    mentorID,
 		MentorEmail: mentorEmail,
 		Contact:     contact,
 	}
@@ -51,3 +52,39 @@ func (m *MentorClient) NewMentor(ctx context.Context, mentorEmail, contact strin
 
 	return nil
 }
+
+func (m *MentorClient) ActivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error {
+	req := &pb.ActivateRequest{
+		MentorId:    mentorID,
+		MentorEmail: mentorEmail,
+	}
+
+	resp, err := m.client.ActivateMentor(ctx, req)
+	if err != nil {
+		return fmt.Errorf("ActivateMentor RPC call failed: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("server resonded with success=false, message=%s", resp.Message)
+	}
+
+	return nil
+}
+
+func (m *MentorClient) DeactivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error {
+	req := &pb.DeactivateRequest{
+		MentorId:    mentorID,
+		MentorEmail: mentorEmail,
+	}
+
+	resp, err := m.client.DeactivateMentor(ctx, req)
+	if err != nil {
+		return fmt.Errorf("DeactivateMentor RPC call failed: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("server resonded with success=false, message=%s", resp.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+package account
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/realip"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+var (
+	// ExportTTL сколько хранится собранная выгрузка
+	ExportTTL = 7 * 24 * time.Hour
+
+	// ExportInterval не чаще одной выгрузки за интервал: она опрашивает все сервисы
+	ExportInterval = time.Hour
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AccountStore
+type AccountStore interface {
+	GetByID(id int64) (*model.User, error)
+	DeleteUser(userID int64, outbox []model.OutboxMessage) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordVerifier
+type PasswordVerifier interface {
+	Verify(password, encoded string) (rehash bool, err error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=SessionRevoker
+type SessionRevoker interface {
+	RevokeAllSessions(userID int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AttemptLimiter
+type AttemptLimiter interface {
+	Check(email, ip string) (time.Duration, error)
+	RegisterFailure(email, ip string) (time.Duration, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+// Delete удаляет аккаунт. Данные в review и mentor удаляются асинхронно по событию
+// user.deleted, которое пишется в outbox в одной транзакции с удалением пользователя
+func Delete(log *slog.Logger, users AccountStore, hasher PasswordVerifier, sessions SessionRevoker, limiter AttemptLimiter, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.Delete"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.DeleteAccount
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Иначе можно остаться без администраторов; роль сначала снимает другой админ
+		if user.Role == model.RoleAdmin {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("admin account cannot be deleted"))
+			return
+		}
+
+		// Украденный access токен не должен позволять удалить аккаунт, а перебор пароля
+		// упирается в ту же блокировку, что и вход. У аккаунтов, созданных через OIDC, пароля нет
+		if user.Password != "" {
+			ip := realip.FromRequest(r)
+			retryAfter, err := limiter.Check(user.Email, ip)
+			if err != nil {
+				log.Error("failed to check password attempts", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("internal error"))
+				return
+			}
+			if retryAfter > 0 {
+				log.Warn("password check locked out", slog.Int64("user_id", user.ID), slog.String("ip", ip))
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "locked_out"})
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.Error("too many attempts"))
+				return
+			}
+
+			if _, err := hasher.Verify(req.Password, user.Password); err != nil {
+				if !errors.Is(err, passhash.ErrMismatch) {
+					log.Error("failed to verify password", sl.Err(err))
+				}
+				if _, err := limiter.RegisterFailure(user.Email, ip); err != nil {
+					log.Error("failed to register password failure", sl.Err(err))
+				}
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_password"})
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("invalid credentials"))
+				return
+			}
+		}
+
+		if err := users.DeleteUser(user.ID, []model.OutboxMessage{outbox.UserDeletedMessage(user)}); err != nil {
+			log.Error("failed to delete user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Журнал переживает удаление аккаунта: у auth_events нет внешнего ключа на users
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: user.ID, Outcome: model.OutcomeSuccess})
+
+		// Пользователя уже нет, оставшиеся сессии отвалятся при следующем refresh
+		if err := sessions.RevokeAllSessions(user.ID); err != nil {
+			log.Error("failed to revoke sessions of deleted user", sl.Err(err))
+		}
+
+		log.Info("account deleted", slog.Int64("user_id", user.ID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "account deleted"})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ExportUserStore
+type ExportUserStore interface {
+	GetByID(id int64) (*model.User, error)
+	ListIdentities(userID int64) ([]model.Identity, error)
+	ListAPIKeys(userID int64) ([]model.APIKey, error)
+	EnqueueOutbox(msgs []model.OutboxMessage) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ExportStore
+type ExportStore interface {
+	Throttle(key string, window time.Duration) (bool, error)
+	GetSessions(userID int64) ([]model.Session, error)
+	CreateExport(exportID string, userID int64, authPart []byte, ttl time.Duration) error
+	GetExport(exportID string) (*model.DataExport, error)
+}
+
+// authPart данные пользователя, которые хранит сервис авторизации
+type authPart struct {
+	ID               int64            `json:"id"`
+	Email            string           `json:"email"`
+	Role             string           `json:"role"`
+	Profile          model.Profile    `json:"profile"`
+	VerifiedAt       *time.Time       `json:"verified_at"`
+	TwoFactorEnabled bool             `json:"two_factor_enabled"`
+	Identities       []model.Identity `json:"identities"`
+	APIKeys          []model.APIKey   `json:"api_keys"`
+	Sessions         []model.Session  `json:"sessions"`
+}
+
+// RequestExport собирает свою часть выгрузки сразу, а review и mentor присылают
+// свои по событию user.export_requested. Готовность проверяется через GetExport
+func RequestExport(log *slog.Logger, users ExportUserStore, exports ExportStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.RequestExport"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		allowed, err := exports.Throttle("export:"+strconv.FormatInt(claims.UserID, 10), ExportInterval)
+		if err != nil {
+			log.Error("failed to check export throttle", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(ExportInterval.Seconds())))
+			render.Status(r, http.StatusTooManyRequests)
+			render.JSON(w, r, response.Error("too many requests"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		part, err := collectAuthPart(user, users, exports)
+		if err != nil {
+			log.Error("failed to collect user data", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		exportID, err := newExportID()
+		if err != nil {
+			log.Error("failed to generate export id", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		if err := exports.CreateExport(exportID, user.ID, part, ExportTTL); err != nil {
+			log.Error("failed to create export", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		if err := users.EnqueueOutbox([]model.OutboxMessage{outbox.ExportRequestedMessage(exportID, user)}); err != nil {
+			log.Error("failed to enqueue export request", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		log.Info("data export requested", slog.Int64("user_id", user.ID), slog.String("export_id", exportID))
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, map[string]any{
+			"export_id": exportID,
+			"status":    "pending",
+		})
+	}
+}
+
+func collectAuthPart(user *model.User, users ExportUserStore, exports ExportStore) ([]byte, error) {
+	identities, err := users.ListIdentities(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := users.ListAPIKeys(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := exports.GetSessions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(authPart{
+		ID:               user.ID,
+		Email:            user.Email,
+		Role:             user.Role,
+		Profile:          user.Profile,
+		VerifiedAt:       user.VerifiedAt,
+		TwoFactorEnabled: user.TOTPEnabled,
+		Identities:       identities,
+		APIKeys:          keys,
+		Sessions:         sessions,
+	})
+}
+
+func newExportID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GetExport отдаёт архив, когда все сервисы прислали свои части, иначе 202 со списком недостающих
+func GetExport(log *slog.Logger, exports ExportStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.GetExport"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		export, err := exports.GetExport(chi.URLParam(r, "id"))
+		if err != nil && !errors.Is(err, cache.ErrExportNotFound) {
+			log.Error("failed to get export", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		// Чужая выгрузка неотличима от несуществующей
+		if export == nil || export.UserID != claims.UserID {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("export not found"))
+			return
+		}
+
+		if missing := export.Missing(); len(missing) > 0 {
+			render.Status(r, http.StatusAccepted)
+			render.JSON(w, r, map[string]any{
+				"export_id": export.ID,
+				"status":    "pending",
+				"missing":   missing,
+			})
+			return
+		}
+
+		archive := map[string]any{
+			"export_id":  export.ID,
+			"user_id":    export.UserID,
+			"created_at": export.CreatedAt.UTC(),
+		}
+		for service, data := range export.Parts {
+			archive[service] = data
+		}
+
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="mentorlink-export-%s.json"`, export.ID))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, archive)
+	}
+}
@@ -0,0 +1,288 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testHasher = passhash.New(passhash.Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+func withClaims(req *http.Request, claims *token.Claims) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func TestDeleteHandler(t *testing.T) {
+	hash, err := testHasher.Hash("correctPassword")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name           string
+		user           *model.User
+		body           string
+		deleteError    error
+		revokeError    error
+		lockout        time.Duration
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Success",
+			user:           &model.User{ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: hash},
+			body:           `{"password":"correctPassword"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Account without password",
+			user:           &model.User{ID: 7, Email: "oidc@mail.com", Role: model.RoleUser},
+			body:           `{}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Sessions revoke error does not fail deletion",
+			user:           &model.User{ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: hash},
+			body:           `{"password":"correctPassword"}`,
+			revokeError:    errors.New("redis error"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Wrong password",
+			user:           &model.User{ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: hash},
+			body:           `{"password":"wrongPassword"}`,
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid credentials",
+		},
+		{
+			name:           "Locked out",
+			user:           &model.User{ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: hash},
+			body:           `{"password":"correctPassword"}`,
+			lockout:        90 * time.Second,
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many attempts",
+		},
+		{
+			name:           "Admin",
+			user:           &model.User{ID: 7, Email: "admin@mail.com", Role: model.RoleAdmin, Password: hash},
+			body:           `{"password":"correctPassword"}`,
+			expectedStatus: http.StatusForbidden,
+			respError:      "admin account cannot be deleted",
+		},
+		{
+			name:           "Delete error",
+			user:           &model.User{ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: hash},
+			body:           `{"password":"correctPassword"}`,
+			deleteError:    errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			limiter := mocks.NewAttemptLimiter(t)
+			auditor := mocks.NewAuditor(t)
+
+			users.On("GetByID", int64(7)).Return(tc.user, nil)
+			if tc.user.Password != "" && tc.user.Role != model.RoleAdmin {
+				limiter.On("Check", tc.user.Email, mock.AnythingOfType("string")).Return(tc.lockout, nil)
+			}
+			if tc.expectedStatus == http.StatusUnauthorized {
+				limiter.On("RegisterFailure", tc.user.Email, mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: 7, Outcome: model.OutcomeFailure, Reason: "invalid_password"}).Once()
+			}
+			if tc.lockout > 0 {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: 7, Outcome: model.OutcomeFailure, Reason: "locked_out"}).Once()
+			}
+			if tc.expectedStatus == http.StatusOK || tc.deleteError != nil {
+				// Событие для review и mentor пишется в той же транзакции, что и удаление
+				users.On("DeleteUser", int64(7), []model.OutboxMessage{outbox.UserDeletedMessage(tc.user)}).
+					Return(tc.deleteError)
+			}
+			if tc.expectedStatus == http.StatusOK {
+				redisMock.On("RevokeAllSessions", int64(7)).Return(tc.revokeError)
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventAccountDelete, UserID: 7, Outcome: model.OutcomeSuccess}).Once()
+			}
+
+			handler := Delete(slogdiscard.NewDiscardLogger(), users, testHasher, redisMock, limiter, auditor)
+			req := withClaims(httptest.NewRequest(http.MethodDelete, "/auth/account", bytes.NewBufferString(tc.body)),
+				&token.Claims{UserID: 7, Role: tc.user.Role})
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+			if tc.lockout > 0 {
+				require.Equal(t, "90", rr.Header().Get("Retry-After"))
+			}
+			if tc.expectedStatus != http.StatusOK && tc.deleteError == nil {
+				users.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRequestExport(t *testing.T) {
+	verified := time.Now().UTC().Truncate(time.Second)
+	user := &model.User{
+		ID: 7, Email: "mentor@mail.com", Role: model.RoleMentor, Password: "hash",
+		VerifiedAt: &verified, TOTPSecret: "JBSWY3DPEHPK3PXP",
+		Profile: model.Profile{DisplayName: "Mentor", Contact: "@mentor"},
+	}
+
+	users := mocks.NewUserCreater(t)
+	redisMock := mocks.NewRedisRepo(t)
+
+	redisMock.On("Throttle", "export:7", ExportInterval).Return(true, nil)
+	users.On("GetByID", int64(7)).Return(user, nil)
+	users.On("ListIdentities", int64(7)).Return([]model.Identity{{Provider: "corp", Subject: "sub-1", Email: "mentor@mail.com"}}, nil)
+	users.On("ListAPIKeys", int64(7)).Return([]model.APIKey{{ID: 1, Name: "ci", Prefix: "ml_abc", KeyHash: "secret-hash"}}, nil)
+	redisMock.On("GetSessions", int64(7)).Return([]model.Session{{ID: "s1", UserID: 7, IP: "10.0.0.1"}}, nil)
+
+	var exportID string
+	var part []byte
+	redisMock.On("CreateExport", mock.AnythingOfType("string"), int64(7), mock.AnythingOfType("[]uint8"), ExportTTL).
+		Run(func(args mock.Arguments) {
+			exportID = args.String(0)
+			part = args.Get(2).([]byte)
+		}).Return(nil)
+	users.On("EnqueueOutbox", mock.MatchedBy(func(msgs []model.OutboxMessage) bool {
+		return len(msgs) == 1 && msgs[0].Kind == outbox.KindExportRequested
+	})).Return(nil)
+
+	handler := RequestExport(slogdiscard.NewDiscardLogger(), users, redisMock)
+	req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/account/export", nil), &token.Claims{UserID: 7, Role: model.RoleMentor})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, exportID, resp["export_id"])
+	require.Len(t, exportID, 32)
+
+	// Событие несёт тот же id, по нему review и mentor присылают свои части
+	msgs := users.Calls[len(users.Calls)-1].Arguments.Get(0).([]model.OutboxMessage)
+	require.Equal(t, outbox.ExportRequestedMessage(exportID, user), msgs[0])
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(part, &data))
+	require.Equal(t, "mentor@mail.com", data["email"])
+	require.Len(t, data["identities"], 1)
+	require.Len(t, data["sessions"], 1)
+	// Хэши паролей, ключей и секрет TOTP в выгрузку не попадают
+	require.NotContains(t, string(part), "secret-hash")
+	require.NotContains(t, string(part), "JBSWY3DPEHPK3PXP")
+	require.NotContains(t, string(part), `"hash"`)
+}
+
+func TestRequestExportThrottled(t *testing.T) {
+	users := mocks.NewUserCreater(t)
+	redisMock := mocks.NewRedisRepo(t)
+	redisMock.On("Throttle", "export:7", ExportInterval).Return(false, nil)
+
+	handler := RequestExport(slogdiscard.NewDiscardLogger(), users, redisMock)
+	req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/account/export", nil), &token.Claims{UserID: 7})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "3600", rr.Header().Get("Retry-After"))
+	users.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestGetExport(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	parts := map[string]json.RawMessage{
+		"auth":   json.RawMessage(`{"email":"mentor@mail.com"}`),
+		"review": json.RawMessage(`{"reviews":[]}`),
+	}
+
+	cases := []struct {
+		name           string
+		export         *model.DataExport
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Pending",
+			export:         &model.DataExport{ID: "exp1", UserID: 7, CreatedAt: created, Parts: parts},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "Ready",
+			export: &model.DataExport{ID: "exp1", UserID: 7, CreatedAt: created, Parts: map[string]json.RawMessage{
+				"auth":   parts["auth"],
+				"review": parts["review"],
+				"mentor": json.RawMessage(`null`),
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Other user's export",
+			export:         &model.DataExport{ID: "exp1", UserID: 8, CreatedAt: created, Parts: parts},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Expired",
+			err:            cache.ErrExportNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Redis error",
+			err:            errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			redisMock := mocks.NewRedisRepo(t)
+			redisMock.On("GetExport", "exp1").Return(tc.export, tc.err)
+
+			r := chi.NewRouter()
+			r.Get("/auth/account/export/{id}", GetExport(slogdiscard.NewDiscardLogger(), redisMock))
+			req := withClaims(httptest.NewRequest(http.MethodGet, "/auth/account/export/exp1", nil), &token.Claims{UserID: 7})
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			switch tc.expectedStatus {
+			case http.StatusAccepted:
+				require.Equal(t, []any{"mentor"}, resp["missing"])
+			case http.StatusOK:
+				require.Contains(t, rr.Header().Get("Content-Disposition"), "mentorlink-export-exp1.json")
+				require.Equal(t, map[string]any{"email": "mentor@mail.com"}, resp["auth"])
+				require.Equal(t, map[string]any{"reviews": []any{}}, resp["review"])
+				require.Contains(t, resp, "mentor")
+			}
+		})
+	}
+}
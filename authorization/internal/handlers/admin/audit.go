@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/db"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AuthEventLister
+type AuthEventLister interface {
+	ListAuthEvents(f db.AuthEventFilter) ([]model.AuthEvent, int, error)
+}
+
+// ListAuthEvents журнал аутентификации: ?user_id=&type=&from=&to=&limit=&offset=,
+// from и to в RFC 3339, интервал [from, to)
+func ListAuthEvents(log *slog.Logger, events AuthEventLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.ListAuthEvents"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := r.URL.Query()
+		req := requests.ListAuthEvents{
+			Type:  q.Get("type"),
+			Limit: DefaultPageSize,
+		}
+		var err error
+		if v := q.Get("user_id"); v != "" {
+			if req.UserID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				req.UserID = -1
+			}
+		}
+		if v := q.Get("limit"); v != "" {
+			if req.Limit, err = strconv.Atoi(v); err != nil {
+				req.Limit = -1
+			}
+		}
+		if v := q.Get("offset"); v != "" {
+			if req.Offset, err = strconv.Atoi(v); err != nil {
+				req.Offset = -1
+			}
+		}
+
+		from, fromErr := parseTime(q.Get("from"))
+		to, toErr := parseTime(q.Get("to"))
+
+		if err := validate.IsValid(req); err != nil || fromErr != nil || toErr != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+		if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("from must be before to"))
+			return
+		}
+
+		list, total, err := events.ListAuthEvents(db.AuthEventFilter{
+			UserID: req.UserID,
+			Type:   req.Type,
+			From:   from,
+			To:     to,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+		if err != nil {
+			log.Error("failed to list auth events", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"events": list,
+			"total":  total,
+			"limit":  req.Limit,
+			"offset": req.Offset,
+		})
+	}
+}
+
+// parseTime пустая строка - без ограничения
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuthEventsHandler(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	found := []model.AuthEvent{
+		{ID: 2, Type: model.AuthEventLogin, UserID: 7, Outcome: model.OutcomeFailure, Reason: "invalid_password"},
+		{ID: 1, Type: model.AuthEventLogin, UserID: 7, Outcome: model.OutcomeSuccess},
+	}
+
+	cases := []struct {
+		name           string
+		query          string
+		filter         *db.AuthEventFilter
+		listError      error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Defaults",
+			query:          "",
+			filter:         &db.AuthEventFilter{Limit: DefaultPageSize},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "User and time range",
+			query:          "?user_id=7&type=login&from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z&limit=2&offset=4",
+			filter:         &db.AuthEventFilter{UserID: 7, Type: "login", From: from, To: to, Limit: 2, Offset: 4},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid time",
+			query:          "?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Empty range",
+			query:          "?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "from must be before to",
+		},
+		{
+			name:           "Invalid user id",
+			query:          "?user_id=abc",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Limit too large",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Storage error",
+			query:          "?user_id=7",
+			filter:         &db.AuthEventFilter{UserID: 7, Limit: DefaultPageSize},
+			listError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := mocks.NewUserCreater(t)
+			if tc.filter != nil {
+				storage.On("ListAuthEvents", *tc.filter).Return(found, 12, tc.listError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/admin/audit"+tc.query, nil)
+			rr := serve("/auth/admin/audit", ListAuthEvents(slogdiscard.NewDiscardLogger(), storage), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			requireError(t, rr, tc.respError)
+
+			if tc.expectedStatus == http.StatusOK {
+				var resp struct {
+					Events []model.AuthEvent `json:"events"`
+					Total  int               `json:"total"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, 12, resp.Total)
+				require.Len(t, resp.Events, 2)
+				require.Equal(t, "invalid_password", resp.Events[0].Reason)
+			}
+		})
+	}
+}
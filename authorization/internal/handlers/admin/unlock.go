@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"log/slog"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Unlocker
+type Unlocker interface {
+	Unlock(email, ip string) error
+}
+
+// Unlock снимает блокировку входа, наложенную после неудачных попыток.
+// Доступ только администраторам проверяет auth.RequireRole в роутере
+func Unlock(log *slog.Logger, unlocker Unlocker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.Unlock"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.UnlockAccount
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		if err := unlocker.Unlock(req.Email, req.IP); err != nil {
+			log.Error("failed to unlock account", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("account unlocked",
+			slog.String("email", req.Email),
+			slog.String("ip", req.IP),
+			slog.Int64("admin_id", claims.UserID),
+		)
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "unlocked"})
+	}
+}
@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		role           string
+		body           string
+		mockSetup      func(*mocks.AttemptLimiter)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			role: "admin",
+			body: `{"email": "locked@mail.com"}`,
+			mockSetup: func(l *mocks.AttemptLimiter) {
+				l.On("Unlock", "locked@mail.com", "").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Success with ip",
+			role: "admin",
+			body: `{"email": "locked@mail.com", "ip": "10.0.0.1"}`,
+			mockSetup: func(l *mocks.AttemptLimiter) {
+				l.On("Unlock", "locked@mail.com", "10.0.0.1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ip",
+			role:           "admin",
+			body:           `{"email": "locked@mail.com", "ip": "not-an-ip"}`,
+			mockSetup:      func(l *mocks.AttemptLimiter) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name: "Unlock error",
+			role: "admin",
+			body: `{"email": "locked@mail.com"}`,
+			mockSetup: func(l *mocks.AttemptLimiter) {
+				l.On("Unlock", "locked@mail.com", "").Return(errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := mocks.NewAttemptLimiter(t)
+			tc.mockSetup(limiter)
+
+			claims := &token.Claims{UserID: 99, Role: tc.role, TokenType: token.TypeAccess}
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/unlock", bytes.NewBufferString(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+			rr := httptest.NewRecorder()
+			Unlock(slogdiscard.NewDiscardLogger(), limiter).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package admin
+
+import (
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+const DefaultPageSize = 20
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserLister
+type UserLister interface {
+	ListUsers(f db.UserFilter) ([]model.User, int, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserManager
+type UserManager interface {
+	GetByID(id int64) (*model.User, error)
+	SetRole(userID int64, role string, outbox []model.OutboxMessage) error
+	SetDisabled(userID int64, disabled bool, outbox []model.OutboxMessage) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=SessionRevoker
+type SessionRevoker interface {
+	RevokeAllSessions(userID int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+// byAdmin причина события в журнале: кто из администраторов выполнил действие
+func byAdmin(adminID int64) string {
+	return "admin:" + strconv.FormatInt(adminID, 10)
+}
+
+type userView struct {
+	ID          int64      `json:"id"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	DisplayName string     `json:"display_name"`
+	TOTPEnabled bool       `json:"totp_enabled"`
+	VerifiedAt  *time.Time `json:"verified_at"`
+	DisabledAt  *time.Time `json:"disabled_at"`
+}
+
+func newUserView(u *model.User) userView {
+	return userView{
+		ID:          u.ID,
+		Email:       u.Email,
+		Role:        u.Role,
+		DisplayName: u.DisplayName,
+		TOTPEnabled: u.TOTPEnabled,
+		VerifiedAt:  u.VerifiedAt,
+		DisabledAt:  u.DisabledAt,
+	}
+}
+
+// ListUsers ищет пользователей по подстроке email или имени: ?q=&role=&limit=&offset=
+func ListUsers(log *slog.Logger, users UserLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.ListUsers"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := r.URL.Query()
+		req := requests.ListUsers{
+			Query: q.Get("q"),
+			Role:  q.Get("role"),
+			Limit: DefaultPageSize,
+		}
+		var err error
+		if v := q.Get("limit"); v != "" {
+			if req.Limit, err = strconv.Atoi(v); err != nil {
+				req.Limit = -1
+			}
+		}
+		if v := q.Get("offset"); v != "" {
+			if req.Offset, err = strconv.Atoi(v); err != nil {
+				req.Offset = -1
+			}
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		list, total, err := users.ListUsers(db.UserFilter{
+			Query:  req.Query,
+			Role:   req.Role,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+		if err != nil {
+			log.Error("failed to list users", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		views := make([]userView, 0, len(list))
+		for i := range list {
+			views = append(views, newUserView(&list[i]))
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"users":  views,
+			"total":  total,
+			"limit":  req.Limit,
+			"offset": req.Offset,
+		})
+	}
+}
+
+// ChangeRole назначает роль; изменения, касающиеся ментора, уходят в сервис менторов через outbox
+func ChangeRole(log *slog.Logger, users UserManager, sessions SessionRevoker, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.ChangeRole"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		admin, user, ok := targetUser(w, r, log, users)
+		if !ok {
+			return
+		}
+
+		var req requests.ChangeRole
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		if user.Role == req.Role {
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, newUserView(user))
+			return
+		}
+
+		var events []model.OutboxMessage
+		switch {
+		case req.Role == model.RoleMentor:
+			events = append(events, outbox.NewMentorMessage(user.ID, user.Email, user.Contact))
+			// Каталог показывает только подтверждённых и не отключённых менторов
+			if user.IsVerified() && !user.IsDisabled() {
+				events = append(events, outbox.ActivateMentorMessage(user.ID, user.Email))
+			}
+		case user.Role == model.RoleMentor:
+			events = append(events, outbox.DeactivateMentorMessage(user.ID, user.Email))
+		}
+
+		if err := users.SetRole(user.ID, req.Role, events); err != nil {
+			log.Error("failed to set role", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		// Роль зашита в токены, поэтому пользователь должен войти заново
+		if err := sessions.RevokeAllSessions(user.ID); err != nil {
+			log.Error("failed to revoke sessions after role change", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("user role changed",
+			slog.Int64("user_id", user.ID),
+			slog.String("from", user.Role),
+			slog.String("to", req.Role),
+			slog.Int64("admin_id", admin.UserID),
+		)
+		auditor.Record(r, model.AuthEvent{
+			Type:    model.AuthEventRoleChange,
+			UserID:  user.ID,
+			Outcome: model.OutcomeSuccess,
+			Reason:  byAdmin(admin.UserID) + " " + user.Role + "->" + req.Role,
+		})
+
+		user.Role = req.Role
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, newUserView(user))
+	}
+}
+
+// Disable отключает аккаунт и завершает все его сессии
+func Disable(log *slog.Logger, users UserManager, sessions SessionRevoker, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.Disable"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		admin, user, ok := targetUser(w, r, log, users)
+		if !ok {
+			return
+		}
+
+		var events []model.OutboxMessage
+		if user.Role == model.RoleMentor {
+			events = append(events, outbox.DeactivateMentorMessage(user.ID, user.Email))
+		}
+
+		if err := users.SetDisabled(user.ID, true, events); err != nil {
+			log.Error("failed to disable user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		if err := sessions.RevokeAllSessions(user.ID); err != nil {
+			log.Error("failed to revoke sessions of disabled user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("user disabled", slog.Int64("user_id", user.ID), slog.Int64("admin_id", admin.UserID))
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventAccountDisable, UserID: user.ID, Outcome: model.OutcomeSuccess, Reason: byAdmin(admin.UserID)})
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "disabled"})
+	}
+}
+
+// Enable возвращает доступ отключённому аккаунту
+func Enable(log *slog.Logger, users UserManager, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.Enable"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		admin, user, ok := targetUser(w, r, log, users)
+		if !ok {
+			return
+		}
+
+		var events []model.OutboxMessage
+		if user.Role == model.RoleMentor && user.IsVerified() {
+			events = append(events, outbox.ActivateMentorMessage(user.ID, user.Email))
+		}
+
+		if err := users.SetDisabled(user.ID, false, events); err != nil {
+			log.Error("failed to enable user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("user enabled", slog.Int64("user_id", user.ID), slog.Int64("admin_id", admin.UserID))
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventAccountEnable, UserID: user.ID, Outcome: model.OutcomeSuccess, Reason: byAdmin(admin.UserID)})
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "enabled"})
+	}
+}
+
+// ForceLogout завершает все сессии пользователя, аккаунт остаётся активным
+func ForceLogout(log *slog.Logger, sessions SessionRevoker, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.ForceLogout"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid user id"))
+			return
+		}
+
+		if err := sessions.RevokeAllSessions(userID); err != nil {
+			log.Error("failed to revoke sessions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("user logged out by admin", slog.Int64("user_id", userID), slog.Int64("admin_id", claims.UserID))
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventForceLogout, UserID: userID, Outcome: model.OutcomeSuccess, Reason: byAdmin(claims.UserID)})
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "logged_out"})
+	}
+}
+
+// targetUser загружает пользователя из {id}; администратор не может менять собственный аккаунт,
+// чтобы случайно не лишить себя доступа
+func targetUser(w http.ResponseWriter, r *http.Request, log *slog.Logger, users UserManager) (*token.Claims, *model.User, bool) {
+	claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+	if !ok || claims == nil {
+		log.Error("failed to get user claims")
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, response.Error("unauthorized"))
+		return nil, nil, false
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("invalid user id"))
+		return nil, nil, false
+	}
+
+	if userID == claims.UserID {
+		log.Warn("admin tried to modify own account", slog.Int64("user_id", userID))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("cannot modify own account"))
+		return nil, nil, false
+	}
+
+	user, err := users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return nil, nil, false
+		}
+		log.Error("failed to get user", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("server error"))
+		return nil, nil, false
+	}
+
+	return claims, user, true
+}
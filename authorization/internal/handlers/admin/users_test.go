@@ -0,0 +1,482 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var adminClaims = &token.Claims{UserID: 99, Role: model.RoleAdmin, TokenType: token.TypeAccess}
+
+func withClaims(r *http.Request, claims *token.Claims) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), auth.UserKey, claims))
+}
+
+func serve(pattern string, h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, withClaims(r, adminClaims))
+	})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func requireError(t *testing.T, rr *httptest.ResponseRecorder, respError string) {
+	t.Helper()
+	if respError == "" {
+		return
+	}
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, respError, resp.Error)
+}
+
+func TestListUsersHandler(t *testing.T) {
+	verifiedAt := time.Now()
+	found := []model.User{
+		{ID: 1, Email: "a@mail.com", Role: model.RoleUser, VerifiedAt: &verifiedAt},
+		{ID: 2, Email: "b@mail.com", Role: model.RoleMentor},
+	}
+
+	cases := []struct {
+		name           string
+		query          string
+		filter         *db.UserFilter
+		mockError      error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Defaults",
+			filter:         &db.UserFilter{Limit: DefaultPageSize},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Search with paging",
+			query:          "?q=mail&role=mentor&limit=5&offset=10",
+			filter:         &db.UserFilter{Query: "mail", Role: model.RoleMentor, Limit: 5, Offset: 10},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Limit too large",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Limit not a number",
+			query:          "?limit=ten",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Negative offset",
+			query:          "?offset=-1",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Unknown role",
+			query:          "?role=root",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Storage error",
+			filter:         &db.UserFilter{Limit: DefaultPageSize},
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			if tc.filter != nil {
+				users.On("ListUsers", *tc.filter).Return(found, 42, tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/admin/users"+tc.query, nil)
+			rr := serve("/auth/admin/users", ListUsers(slogdiscard.NewDiscardLogger(), users), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			requireError(t, rr, tc.respError)
+
+			if tc.expectedStatus == http.StatusOK {
+				var resp struct {
+					Users []userView `json:"users"`
+					Total int        `json:"total"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, 42, resp.Total)
+				require.Len(t, resp.Users, 2)
+				require.NotContains(t, rr.Body.String(), "password")
+			}
+		})
+	}
+}
+
+func TestChangeRoleHandler(t *testing.T) {
+	verifiedAt := time.Now()
+
+	cases := []struct {
+		name           string
+		id             string
+		body           string
+		user           *model.User
+		getError       error
+		events         []model.OutboxMessage
+		setError       error
+		revokeError    error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Promote verified user to mentor",
+			id:             "1",
+			body:           `{"role": "mentor"}`,
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser, VerifiedAt: &verifiedAt, Profile: model.Profile{Contact: "@u"}},
+			events:         []model.OutboxMessage{outbox.NewMentorMessage(1, "u@mail.com", "@u"), outbox.ActivateMentorMessage(1, "u@mail.com")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Promote unverified user to mentor stays pending",
+			id:             "1",
+			body:           `{"role": "mentor"}`,
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser},
+			events:         []model.OutboxMessage{outbox.NewMentorMessage(1, "u@mail.com", "")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Demote mentor deactivates",
+			id:             "1",
+			body:           `{"role": "user"}`,
+			user:           &model.User{ID: 1, Email: "m@mail.com", Role: model.RoleMentor, VerifiedAt: &verifiedAt},
+			events:         []model.OutboxMessage{outbox.DeactivateMentorMessage(1, "m@mail.com")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Promote user to admin",
+			id:             "1",
+			body:           `{"role": "admin"}`,
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Same role is a no-op",
+			id:             "1",
+			body:           `{"role": "user"}`,
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Own account",
+			id:             "99",
+			body:           `{"role": "user"}`,
+			expectedStatus: http.StatusBadRequest,
+			respError:      "cannot modify own account",
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			body:           `{"role": "user"}`,
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid user id",
+		},
+		{
+			name:           "Unknown role",
+			id:             "1",
+			body:           `{"role": "root"}`,
+			user:           &model.User{ID: 1, Role: model.RoleUser},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "User not found",
+			id:             "1",
+			body:           `{"role": "mentor"}`,
+			getError:       db.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			respError:      "user not found",
+		},
+		{
+			name:           "Set role error",
+			id:             "1",
+			body:           `{"role": "admin"}`,
+			user:           &model.User{ID: 1, Role: model.RoleUser},
+			setError:       errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+		{
+			name:           "Revoke sessions error",
+			id:             "1",
+			body:           `{"role": "admin"}`,
+			user:           &model.User{ID: 1, Role: model.RoleUser},
+			revokeError:    errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			sessions := mocks.NewRedisRepo(t)
+
+			auditor := mocks.NewAuditor(t)
+
+			if tc.user != nil || tc.getError != nil {
+				users.On("GetByID", int64(1)).Return(tc.user, tc.getError)
+			}
+			changes := tc.user != nil && tc.expectedStatus != http.StatusBadRequest && tc.name != "Same role is a no-op"
+			if changes {
+				var role struct{ Role string }
+				require.NoError(t, json.Unmarshal([]byte(tc.body), &role))
+				users.On("SetRole", int64(1), role.Role, tc.events).Return(tc.setError)
+				if tc.setError == nil {
+					sessions.On("RevokeAllSessions", int64(1)).Return(tc.revokeError)
+				}
+				if tc.expectedStatus == http.StatusOK {
+					auditor.On("Record", mock.Anything, model.AuthEvent{
+						Type: model.AuthEventRoleChange, UserID: 1, Outcome: model.OutcomeSuccess,
+						Reason: "admin:99 " + tc.user.Role + "->" + role.Role,
+					}).Once()
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/auth/admin/users/"+tc.id+"/role", bytes.NewBufferString(tc.body))
+			rr := serve("/auth/admin/users/{id}/role", ChangeRole(slogdiscard.NewDiscardLogger(), users, sessions, auditor), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			requireError(t, rr, tc.respError)
+		})
+	}
+}
+
+func TestDisableHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		id             string
+		user           *model.User
+		events         []model.OutboxMessage
+		setError       error
+		revokeError    error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Disable user",
+			id:             "1",
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Disable mentor hides from catalog",
+			id:             "1",
+			user:           &model.User{ID: 1, Email: "m@mail.com", Role: model.RoleMentor},
+			events:         []model.OutboxMessage{outbox.DeactivateMentorMessage(1, "m@mail.com")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Own account",
+			id:             "99",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "cannot modify own account",
+		},
+		{
+			name:           "Storage error",
+			id:             "1",
+			user:           &model.User{ID: 1, Role: model.RoleUser},
+			setError:       errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+		{
+			name:           "Revoke sessions error",
+			id:             "1",
+			user:           &model.User{ID: 1, Role: model.RoleUser},
+			revokeError:    errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			sessions := mocks.NewRedisRepo(t)
+
+			auditor := mocks.NewAuditor(t)
+
+			if tc.user != nil {
+				users.On("GetByID", int64(1)).Return(tc.user, nil)
+				users.On("SetDisabled", int64(1), true, tc.events).Return(tc.setError)
+				if tc.setError == nil {
+					sessions.On("RevokeAllSessions", int64(1)).Return(tc.revokeError)
+				}
+			}
+			if tc.expectedStatus == http.StatusOK {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventAccountDisable, UserID: 1, Outcome: model.OutcomeSuccess, Reason: "admin:99"}).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/users/"+tc.id+"/disable", nil)
+			rr := serve("/auth/admin/users/{id}/disable", Disable(slogdiscard.NewDiscardLogger(), users, sessions, auditor), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			requireError(t, rr, tc.respError)
+		})
+	}
+}
+
+func TestEnableHandler(t *testing.T) {
+	verifiedAt := time.Now()
+
+	cases := []struct {
+		name           string
+		user           *model.User
+		events         []model.OutboxMessage
+		expectedStatus int
+	}{
+		{
+			name:           "Enable user",
+			user:           &model.User{ID: 1, Email: "u@mail.com", Role: model.RoleUser, DisabledAt: &verifiedAt},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Enable verified mentor reactivates",
+			user:           &model.User{ID: 1, Email: "m@mail.com", Role: model.RoleMentor, VerifiedAt: &verifiedAt, DisabledAt: &verifiedAt},
+			events:         []model.OutboxMessage{outbox.ActivateMentorMessage(1, "m@mail.com")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Enable unverified mentor stays pending",
+			user:           &model.User{ID: 1, Email: "m@mail.com", Role: model.RoleMentor, DisabledAt: &verifiedAt},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			users.On("GetByID", int64(1)).Return(tc.user, nil)
+			users.On("SetDisabled", int64(1), false, tc.events).Return(nil)
+			auditor := mocks.NewAuditor(t)
+			auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventAccountEnable, UserID: 1, Outcome: model.OutcomeSuccess, Reason: "admin:99"}).Once()
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/users/1/enable", nil)
+			rr := serve("/auth/admin/users/{id}/enable", Enable(slogdiscard.NewDiscardLogger(), users, auditor), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestForceLogoutHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		id             string
+		revokeError    error
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Success",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid user id",
+		},
+		{
+			name:           "Revoke error",
+			id:             "1",
+			revokeError:    errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessions := mocks.NewRedisRepo(t)
+			auditor := mocks.NewAuditor(t)
+			if tc.id == "1" {
+				sessions.On("RevokeAllSessions", int64(1)).Return(tc.revokeError)
+			}
+			if tc.expectedStatus == http.StatusOK {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventForceLogout, UserID: 1, Outcome: model.OutcomeSuccess, Reason: "admin:99"}).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/users/"+tc.id+"/logout", nil)
+			rr := serve("/auth/admin/users/{id}/logout", ForceLogout(slogdiscard.NewDiscardLogger(), sessions, auditor), req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			requireError(t, rr, tc.respError)
+		})
+	}
+}
+
+func TestRequireRoleGuardsAdminRoutes(t *testing.T) {
+	cases := []struct {
+		name           string
+		claims         *token.Claims
+		expectedStatus int
+	}{
+		{name: "Admin", claims: adminClaims, expectedStatus: http.StatusOK},
+		{name: "Mentor", claims: &token.Claims{UserID: 1, Role: model.RoleMentor}, expectedStatus: http.StatusForbidden},
+		{name: "User", claims: &token.Claims{UserID: 1, Role: model.RoleUser}, expectedStatus: http.StatusForbidden},
+		{name: "No claims", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessions := mocks.NewRedisRepo(t)
+			auditor := mocks.NewAuditor(t)
+			if tc.expectedStatus == http.StatusOK {
+				sessions.On("RevokeAllSessions", int64(1)).Return(nil)
+				auditor.On("Record", mock.Anything, mock.AnythingOfType("model.AuthEvent")).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tc.claims != nil {
+						r = withClaims(r, tc.claims)
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			router.Use(auth.RequireRole(slogdiscard.NewDiscardLogger(), model.RoleAdmin))
+			router.Post("/auth/admin/users/{id}/logout", ForceLogout(slogdiscard.NewDiscardLogger(), sessions, auditor))
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/admin/users/1/logout", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
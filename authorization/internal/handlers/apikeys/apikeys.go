@@ -0,0 +1,202 @@
+package apikeys
+
+import (
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/apikey"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+// MaxKeysPerUser сколько действующих ключей может быть у одного пользователя
+const MaxKeysPerUser = 10
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=APIKeyStore
+type APIKeyStore interface {
+	CreateAPIKey(k *model.APIKey, limit int) error
+	ListAPIKeys(userID int64) ([]model.APIKey, error)
+	RevokeAPIKey(userID, id int64) error
+}
+
+func Create(log *slog.Logger, store APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikeys.Create"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.CreateAPIKey
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		allowed := apikey.AllowedScopes(claims.Role)
+		scopes := req.Scopes
+		if len(scopes) == 0 {
+			scopes = allowed
+		}
+		for _, s := range scopes {
+			if !slices.Contains(allowed, s) {
+				log.Warn("scope not allowed", slog.String("scope", s), slog.String("role", claims.Role))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("scope not allowed"))
+				return
+			}
+		}
+		slices.Sort(scopes)
+		scopes = slices.Compact(scopes)
+
+		key, prefix, err := apikey.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		k := &model.APIKey{
+			UserID:  claims.UserID,
+			Name:    req.Name,
+			Prefix:  prefix,
+			KeyHash: secret.Hash(key),
+			Scope:   strings.Join(scopes, " "),
+		}
+		if req.ExpiresInDays != nil {
+			expiresAt := time.Now().Add(time.Duration(*req.ExpiresInDays) * 24 * time.Hour)
+			k.ExpiresAt = &expiresAt
+		}
+
+		err = store.CreateAPIKey(k, MaxKeysPerUser)
+		if errors.Is(err, db.ErrAPIKeyLimit) {
+			log.Warn("api key limit reached", slog.Int64("user_id", claims.UserID))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("api key limit reached"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to create api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("api key created", slog.Int64("user_id", claims.UserID), slog.String("prefix", prefix))
+
+		// Сам ключ возвращается только здесь, дальше доступен лишь префикс
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]any{
+			"key":     key,
+			"api_key": k,
+		})
+	}
+}
+
+func List(log *slog.Logger, store APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikeys.List"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		keys, err := store.ListAPIKeys(claims.UserID)
+		if err != nil {
+			log.Error("failed to list api keys", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"api_keys": keys,
+		})
+	}
+}
+
+func Revoke(log *slog.Logger, store APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikeys.Revoke"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid api key id"))
+			return
+		}
+
+		err = store.RevokeAPIKey(claims.UserID, id)
+		if errors.Is(err, db.ErrAPIKeyNotFound) {
+			log.Warn("api key not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("api key not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke api key", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"status": "api key revoked",
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package apikeys
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/apikey"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func withClaims(req *http.Request, claims *token.Claims) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func TestCreateHandler(t *testing.T) {
+	mentor := &token.Claims{UserID: 7, Role: "mentor"}
+	admin := &token.Claims{UserID: 1, Role: "admin"}
+
+	cases := []struct {
+		name           string
+		claims         *token.Claims
+		body           string
+		mockError      error
+		callsStore     bool
+		expectedStatus int
+		expectedScope  string
+		expectsExpiry  bool
+	}{
+		{
+			name:           "Defaults to role scopes",
+			claims:         mentor,
+			body:           `{"name":"ci"}`,
+			callsStore:     true,
+			expectedStatus: http.StatusCreated,
+			expectedScope:  "mentor:edit_self review:write session:book",
+		},
+		{
+			name:           "Narrowed scope with expiry",
+			claims:         mentor,
+			body:           `{"name":"bot","scopes":["review:write","review:write"],"expires_in_days":30}`,
+			callsStore:     true,
+			expectedStatus: http.StatusCreated,
+			expectedScope:  "review:write",
+			expectsExpiry:  true,
+		},
+		{
+			name:           "Admin keys never get user admin",
+			claims:         admin,
+			body:           `{"name":"moderation"}`,
+			callsStore:     true,
+			expectedStatus: http.StatusCreated,
+			expectedScope:  "review:moderate review:write",
+		},
+		{
+			name:           "Scope outside role",
+			claims:         mentor,
+			body:           `{"name":"bot","scopes":["review:moderate"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "User admin scope rejected",
+			claims:         admin,
+			body:           `{"name":"bot","scopes":["user:admin"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing name",
+			claims:         mentor,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Expiry too long",
+			claims:         mentor,
+			body:           `{"name":"bot","expires_in_days":1000}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Limit reached",
+			claims:         mentor,
+			body:           `{"name":"bot"}`,
+			mockError:      db.ErrAPIKeyLimit,
+			callsStore:     true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Store error",
+			claims:         mentor,
+			body:           `{"name":"bot"}`,
+			mockError:      errors.New("db error"),
+			callsStore:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "No claims",
+			body:           `{"name":"bot"}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mocks.NewUserCreater(t)
+			var saved *model.APIKey
+			if tc.callsStore {
+				store.On("CreateAPIKey", mock.AnythingOfType("*model.APIKey"), MaxKeysPerUser).
+					Run(func(args mock.Arguments) {
+						saved = args.Get(0).(*model.APIKey)
+						saved.ID = 3
+					}).
+					Return(tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/api-keys", bytes.NewBufferString(tc.body))
+			if tc.claims != nil {
+				req = withClaims(req, tc.claims)
+			}
+			rr := httptest.NewRecorder()
+			Create(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			if tc.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var resp struct {
+				Key    string         `json:"key"`
+				APIKey map[string]any `json:"api_key"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			// Наружу уходит ключ, в базу только его хэш
+			require.True(t, apikey.LooksValid(resp.Key), resp.Key)
+			require.Equal(t, secret.Hash(resp.Key), saved.KeyHash)
+			require.Contains(t, resp.Key, saved.Prefix)
+			require.NotContains(t, rr.Body.String(), saved.KeyHash)
+			require.Equal(t, tc.claims.UserID, saved.UserID)
+			require.Equal(t, tc.expectedScope, saved.Scope)
+			require.Equal(t, saved.Prefix, resp.APIKey["prefix"])
+			require.Equal(t, tc.expectsExpiry, saved.ExpiresAt != nil)
+			if tc.expectsExpiry {
+				require.WithinDuration(t, time.Now().Add(30*24*time.Hour), *saved.ExpiresAt, time.Minute)
+			}
+		})
+	}
+}
+
+func TestListHandler(t *testing.T) {
+	claims := &token.Claims{UserID: 7, Role: "user"}
+	keys := []model.APIKey{
+		{ID: 1, UserID: 7, Name: "ci", Prefix: "ml_aaaaaaaa", KeyHash: "secret-hash", Scope: "review:write"},
+	}
+
+	store := mocks.NewUserCreater(t)
+	store.On("ListAPIKeys", int64(7)).Return(keys, nil)
+
+	req := withClaims(httptest.NewRequest(http.MethodGet, "/auth/api-keys", nil), claims)
+	rr := httptest.NewRecorder()
+	List(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "ml_aaaaaaaa")
+	require.NotContains(t, rr.Body.String(), "secret-hash")
+}
+
+func TestRevokeHandler(t *testing.T) {
+	claims := &token.Claims{UserID: 7, Role: "user"}
+
+	cases := []struct {
+		name           string
+		id             string
+		mockError      error
+		callsStore     bool
+		expectedStatus int
+	}{
+		{name: "Success", id: "3", callsStore: true, expectedStatus: http.StatusOK},
+		{name: "Not found", id: "3", mockError: db.ErrAPIKeyNotFound, callsStore: true, expectedStatus: http.StatusNotFound},
+		{name: "Store error", id: "3", mockError: errors.New("db error"), callsStore: true, expectedStatus: http.StatusInternalServerError},
+		{name: "Invalid id", id: "abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mocks.NewUserCreater(t)
+			if tc.callsStore {
+				store.On("RevokeAPIKey", int64(7), int64(3)).Return(tc.mockError)
+			}
+
+			r := chi.NewRouter()
+			r.Delete("/auth/api-keys/{id}", Revoke(slogdiscard.NewDiscardLogger(), store))
+
+			req := withClaims(httptest.NewRequest(http.MethodDelete, "/auth/api-keys/"+tc.id, nil), claims)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/realip"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+var EmailChangeTTL = time.Hour
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserStore
+type UserStore interface {
+	GetByID(id int64) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=EmailChanger
+type EmailChanger interface {
+	GetByID(id int64) (*model.User, error)
+	ChangeEmail(userID int64, newEmail string, outbox []model.OutboxMessage) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordVerifier
+type PasswordVerifier interface {
+	Verify(password, encoded string) (rehash bool, err error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AttemptLimiter
+type AttemptLimiter interface {
+	Check(email, ip string) (time.Duration, error)
+	RegisterFailure(email, ip string) (time.Duration, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ChangeRepo
+type ChangeRepo interface {
+	SaveEmailChange(tokenHash string, userID int64, newEmail string, ttl time.Duration) error
+	ConsumeEmailChange(tokenHash string) (int64, string, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Mailer
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+// Change отправляет ссылку подтверждения на новый адрес; email меняется только после перехода по ней
+func Change(log *slog.Logger, users UserStore, hasher PasswordVerifier, changes ChangeRepo, mailer Mailer, limiter AttemptLimiter, auditor Auditor, appURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.email.Change"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.ChangeEmail
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Аккаунты, созданные через OIDC, пароля не имеют, для них достаточно активной сессии.
+		// Перебор пароля здесь упирается в ту же блокировку, что и перебор через вход
+		if user.Password != "" {
+			ip := realip.FromRequest(r)
+			retryAfter, err := limiter.Check(user.Email, ip)
+			if err != nil {
+				log.Error("failed to check password attempts", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("internal error"))
+				return
+			}
+			if retryAfter > 0 {
+				log.Warn("password check locked out", slog.Int64("user_id", user.ID), slog.String("ip", ip))
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventEmailChange, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "locked_out"})
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, response.Error("too many attempts"))
+				return
+			}
+
+			if _, err := hasher.Verify(req.Password, user.Password); err != nil {
+				if !errors.Is(err, passhash.ErrMismatch) {
+					log.Error("failed to verify password", sl.Err(err))
+				}
+				if _, err := limiter.RegisterFailure(user.Email, ip); err != nil {
+					log.Error("failed to register password failure", sl.Err(err))
+				}
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventEmailChange, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_password"})
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("invalid credentials"))
+				return
+			}
+		}
+
+		if strings.EqualFold(req.Email, user.Email) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("email is the same as current"))
+			return
+		}
+
+		_, err = users.GetByEmail(req.Email)
+		if err == nil {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("email already taken"))
+			return
+		}
+		if !errors.Is(err, db.ErrUserNotFound) {
+			log.Error("failed to check email", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		confirmToken := token.NewID()
+		if err := changes.SaveEmailChange(secret.Hash(confirmToken), user.ID, req.Email, EmailChangeTTL); err != nil {
+			log.Error("failed to save email change", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		link := fmt.Sprintf("%s/confirm-email?token=%s", appURL, url.QueryEscape(confirmToken))
+		body := fmt.Sprintf(
+			"Чтобы подтвердить новый email для входа в MentorLink, перейдите по ссылке:\n%s\n\nСсылка действует %d минут. Если вы не меняли email, просто проигнорируйте это письмо.",
+			link, int(EmailChangeTTL.Minutes()),
+		)
+		if err := mailer.Send(req.Email, "MentorLink: подтверждение email", body); err != nil {
+			log.Error("failed to send confirmation email", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to send email"))
+			return
+		}
+
+		log.Info("email change requested", slog.Int64("user_id", user.ID))
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, map[string]any{"status": "confirmation sent"})
+	}
+}
+
+// Confirm применяет смену email по токену из письма. Событие user.email_changed пишется в outbox
+// в той же транзакции: review и mentor переносят на новый адрес свои записи о менторе
+func Confirm(log *slog.Logger, users EmailChanger, changes ChangeRepo, mailer Mailer, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.email.Confirm"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.ConfirmEmailChange
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		userID, newEmail, err := changes.ConsumeEmailChange(secret.Hash(req.Token))
+		if err != nil {
+			if errors.Is(err, cache.ErrEmailChangeNotFound) {
+				log.Warn("email change token is invalid or expired")
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid or expired token"))
+				return
+			}
+			log.Error("failed to consume email change", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		user, err := users.GetByID(userID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			// Аккаунт удалён, пока письмо шло
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired token"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		err = users.ChangeEmail(user.ID, newEmail, []model.OutboxMessage{outbox.EmailChangedMessage(user, newEmail)})
+		if errors.Is(err, db.ErrEmailTaken) {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("email already taken"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to change email", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventEmailChange, UserID: user.ID, Outcome: model.OutcomeSuccess})
+
+		// Уведомление на старый адрес, чтобы владелец заметил чужую смену
+		body := fmt.Sprintf(
+			"Email для входа в MentorLink изменён на %s. Если это были не вы, срочно обратитесь в поддержку.",
+			newEmail,
+		)
+		if err := mailer.Send(user.Email, "MentorLink: email изменён", body); err != nil {
+			log.Error("failed to notify old email", sl.Err(err))
+		}
+
+		log.Info("email changed", slog.Int64("user_id", user.ID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "email updated"})
+	}
+}
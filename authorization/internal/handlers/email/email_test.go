@@ -0,0 +1,279 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testHasher = passhash.New(passhash.Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+func withClaims(req *http.Request, claims *token.Claims) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func TestChangeHandler(t *testing.T) {
+	hash, err := testHasher.Hash("correctPassword")
+	require.NoError(t, err)
+	user := &model.User{ID: 7, Email: "old@mail.com", Role: model.RoleMentor, Password: hash}
+
+	cases := []struct {
+		name           string
+		user           *model.User
+		body           string
+		mockSetup      func(*mocks.UserCreater, *mocks.RedisRepo, *mocks.Mailer)
+		lockout        time.Duration
+		expectedStatus int
+		respError      string
+		event          *model.AuthEvent
+	}{
+		{
+			name: "Success",
+			user: user,
+			body: `{"email": "new@mail.com", "password": "correctPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "new@mail.com").Return(nil, db.ErrUserNotFound)
+				r.On("SaveEmailChange", mock.AnythingOfType("string"), int64(7), "new@mail.com", EmailChangeTTL).Return(nil)
+				m.On("Send", "new@mail.com", mock.AnythingOfType("string"), mock.MatchedBy(func(body string) bool {
+					return strings.Contains(body, "http://app.test/confirm-email?token=")
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "Account without password",
+			user: &model.User{ID: 7, Email: "old@mail.com", Role: model.RoleUser},
+			body: `{"email": "new@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "new@mail.com").Return(nil, db.ErrUserNotFound)
+				r.On("SaveEmailChange", mock.AnythingOfType("string"), int64(7), "new@mail.com", EmailChangeTTL).Return(nil)
+				m.On("Send", "new@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Wrong password",
+			user:           user,
+			body:           `{"email": "new@mail.com", "password": "wrongPassword"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid credentials",
+			event:          &model.AuthEvent{Type: model.AuthEventEmailChange, UserID: 7, Outcome: model.OutcomeFailure, Reason: "invalid_password"},
+		},
+		{
+			name:           "Locked out",
+			user:           user,
+			body:           `{"email": "new@mail.com", "password": "correctPassword"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {},
+			lockout:        1500 * time.Millisecond,
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many attempts",
+			event:          &model.AuthEvent{Type: model.AuthEventEmailChange, UserID: 7, Outcome: model.OutcomeFailure, Reason: "locked_out"},
+		},
+		{
+			name:           "Same email",
+			user:           user,
+			body:           `{"email": "OLD@mail.com", "password": "correctPassword"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "email is the same as current",
+		},
+		{
+			name: "Email taken",
+			user: user,
+			body: `{"email": "new@mail.com", "password": "correctPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "new@mail.com").Return(&model.User{ID: 8, Email: "new@mail.com"}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+			respError:      "email already taken",
+		},
+		{
+			name: "Send mail error",
+			user: user,
+			body: `{"email": "new@mail.com", "password": "correctPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "new@mail.com").Return(nil, db.ErrUserNotFound)
+				r.On("SaveEmailChange", mock.AnythingOfType("string"), int64(7), "new@mail.com", EmailChangeTTL).Return(nil)
+				m.On("Send", "new@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(errors.New("smtp error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "failed to send email",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			mailer := mocks.NewMailer(t)
+			limiter := mocks.NewAttemptLimiter(t)
+			auditor := mocks.NewAuditor(t)
+
+			users.On("GetByID", int64(7)).Return(tc.user, nil)
+			tc.mockSetup(users, redisMock, mailer)
+			if tc.user.Password != "" {
+				limiter.On("Check", "old@mail.com", mock.AnythingOfType("string")).Return(tc.lockout, nil)
+			}
+			if tc.respError == "invalid credentials" {
+				limiter.On("RegisterFailure", "old@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+			}
+			// Перебор пароля с чужой сессией должен оставлять след в журнале
+			if tc.event != nil {
+				auditor.On("Record", mock.Anything, *tc.event).Once()
+			}
+
+			handler := Change(slogdiscard.NewDiscardLogger(), users, testHasher, redisMock, mailer, limiter, auditor, "http://app.test")
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/email/change", bytes.NewBufferString(tc.body)),
+				&token.Claims{UserID: 7, Role: tc.user.Role})
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+			if tc.lockout > 0 {
+				// Остаток блокировки округляется вверх до секунд
+				require.Equal(t, "2", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestChangeStoresHashOfSentToken(t *testing.T) {
+	users := mocks.NewUserCreater(t)
+	redisMock := mocks.NewRedisRepo(t)
+	mailer := mocks.NewMailer(t)
+
+	var storedHash, sentBody string
+	users.On("GetByID", int64(7)).Return(&model.User{ID: 7, Email: "old@mail.com"}, nil)
+	users.On("GetByEmail", "new@mail.com").Return(nil, db.ErrUserNotFound)
+	redisMock.On("SaveEmailChange", mock.AnythingOfType("string"), int64(7), "new@mail.com", EmailChangeTTL).
+		Run(func(args mock.Arguments) { storedHash = args.String(0) }).Return(nil)
+	mailer.On("Send", "new@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { sentBody = args.String(2) }).Return(nil)
+
+	handler := Change(slogdiscard.NewDiscardLogger(), users, testHasher, redisMock, mailer, mocks.NewAttemptLimiter(t), mocks.NewAuditor(t), "http://app.test")
+	req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/email/change", bytes.NewBufferString(`{"email": "new@mail.com"}`)),
+		&token.Claims{UserID: 7})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	_, rest, found := strings.Cut(sentBody, "token=")
+	require.True(t, found)
+	sentToken := strings.Fields(rest)[0]
+	require.NotEqual(t, sentToken, storedHash)
+	require.Equal(t, secret.Hash(sentToken), storedHash)
+}
+
+func TestConfirmHandler(t *testing.T) {
+	tokenHash := secret.Hash("confirm-token")
+	user := &model.User{ID: 7, Email: "old@mail.com", Role: model.RoleMentor}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater, *mocks.RedisRepo, *mocks.Mailer)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			body: `{"token": "confirm-token"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				r.On("ConsumeEmailChange", tokenHash).Return(int64(7), "new@mail.com", nil)
+				u.On("GetByID", int64(7)).Return(user, nil)
+				// Событие несёт старый адрес: по нему review и mentor находят записи ментора
+				u.On("ChangeEmail", int64(7), "new@mail.com", []model.OutboxMessage{outbox.EmailChangedMessage(user, "new@mail.com")}).Return(nil)
+				m.On("Send", "old@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Notify error does not fail change",
+			body: `{"token": "confirm-token"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				r.On("ConsumeEmailChange", tokenHash).Return(int64(7), "new@mail.com", nil)
+				u.On("GetByID", int64(7)).Return(user, nil)
+				u.On("ChangeEmail", int64(7), "new@mail.com", mock.AnythingOfType("[]model.OutboxMessage")).Return(nil)
+				m.On("Send", "old@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(errors.New("smtp error"))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Token already used or expired",
+			body: `{"token": "confirm-token"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				r.On("ConsumeEmailChange", tokenHash).Return(int64(0), "", cache.ErrEmailChangeNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid or expired token",
+		},
+		{
+			name: "Email taken meanwhile",
+			body: `{"token": "confirm-token"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				r.On("ConsumeEmailChange", tokenHash).Return(int64(7), "new@mail.com", nil)
+				u.On("GetByID", int64(7)).Return(user, nil)
+				u.On("ChangeEmail", int64(7), "new@mail.com", mock.AnythingOfType("[]model.OutboxMessage")).Return(db.ErrEmailTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			respError:      "email already taken",
+		},
+		{
+			name:           "Missing token",
+			body:           `{}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			mailer := mocks.NewMailer(t)
+			tc.mockSetup(users, redisMock, mailer)
+
+			auditor := mocks.NewAuditor(t)
+			if tc.expectedStatus == http.StatusOK {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventEmailChange, UserID: 7, Outcome: model.OutcomeSuccess}).Once()
+			}
+
+			handler := Confirm(slogdiscard.NewDiscardLogger(), users, redisMock, mailer, auditor)
+			req := httptest.NewRequest(http.MethodPost, "/auth/email/confirm", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
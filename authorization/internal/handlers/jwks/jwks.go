@@ -0,0 +1,22 @@
+package jwks
+
+import (
+	"mentorlink/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=KeySet
+type KeySet interface {
+	JWKS() token.JWKS
+}
+
+// Get отдаёт публичные ключи в формате JWKS; сервисы кэшируют ответ и периодически обновляют
+func Get(keys KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, keys.JWKS())
+	}
+}
@@ -0,0 +1,94 @@
+package jwks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, dir, name string, public bool) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600))
+}
+
+func TestJWKSHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2025-01.pub.pem", true)
+	writeKey(t, dir, "2025-06.pem", false)
+
+	tm, err := token.NewTokenManagerFromDir(dir, "2025-06")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rr := httptest.NewRecorder()
+	Get(tm).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Header().Get("Cache-Control"), "max-age")
+
+	var set token.JWKS
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+
+	kids := []string{set.Keys[0].Kid, set.Keys[1].Kid}
+	require.ElementsMatch(t, []string{"2025-01", "2025-06"}, kids)
+	for _, k := range set.Keys {
+		require.Equal(t, "RSA", k.Kty)
+		require.Equal(t, "RS256", k.Alg)
+		require.NotEmpty(t, k.N)
+		require.Equal(t, "AQAB", k.E)
+	}
+}
+
+func TestTokensCarryActiveKID(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "old.pem", false)
+	writeKey(t, dir, "new.pem", false)
+
+	oldTM, err := token.NewTokenManagerFromDir(dir, "old")
+	require.NoError(t, err)
+	newTM, err := token.NewTokenManagerFromDir(dir, "new")
+	require.NoError(t, err)
+
+	signed, _, err := oldTM.GenerateToken(1, "user", time.Minute, token.TypeAccess, "")
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(signed, &token.Claims{})
+	require.NoError(t, err)
+	require.Equal(t, "old", parsed.Header["kid"])
+
+	// После ротации токены, подписанные старым ключом, продолжают проверяться
+	claims, err := newTM.ParseToken(signed)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), claims.UserID)
+}
+
+func TestUnknownActiveKID(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "only.pub.pem", true)
+
+	_, err := token.NewTokenManagerFromDir(dir, "only")
+	require.ErrorIs(t, err, token.ErrNoActiveKey)
+}
@@ -3,39 +3,68 @@ package login
 import (
 	"errors"
 	"log/slog"
+	"math"
 	"mentorlink/internal/domain/model"
 	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/realip"
 	"mentorlink/internal/lib/validate"
 	"mentorlink/internal/storage/db"
 	"mentorlink/pkg/token"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	AccessTokenTTL  int64 = 1800
 	RefreshTokenTTL int64 = 604800
+
+	MFATokenTTL = 5 * time.Minute
 )
 
 //go:generate go run github.com/vektra/mockery/v2@latest --name=Auth
 type Auth interface {
-	CreateUser(u *model.User) error
 	GetByEmail(email string) (*model.User, error)
+	UpdatePassword(userID int64, passwordHash string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordHasher
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (rehash bool, err error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@latest --name=TokenMn
 type TokenMn interface {
-	GenerateToken(userID int64, role string, ttl time.Duration, tokenType string) (string, error)
+	GenerateToken(userID int64, role string, ttl time.Duration, tokenType, familyID string) (string, string, error)
 	ParseToken(tokenStr string) (*token.Claims, error)
 }
 
-func Login(log *slog.Logger, auth Auth, tokenMn TokenMn) http.HandlerFunc {
+//go:generate go run github.com/vektra/mockery/v2@latest --name=RedisRepo
+type RedisRepo interface {
+	StartFamily(familyID, tokenID string, exp int64) error
+	CreateSession(s *model.Session, exp int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AttemptLimiter
+type AttemptLimiter interface {
+	Check(email, ip string) (time.Duration, error)
+	RegisterFailure(email, ip string) (time.Duration, error)
+	Reset(email string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+func Login(log *slog.Logger, auth Auth, hasher PasswordHasher, tokenMn TokenMn, redisRepo RedisRepo, limiter AttemptLimiter, auditor Auditor) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.login.Login"
 		log := log.With(
@@ -59,10 +88,29 @@ func Login(log *slog.Logger, auth Auth, tokenMn TokenMn) http.HandlerFunc {
 			return
 		}
 
+		ip := realip.FromRequest(r)
+
+		retryAfter, err := limiter.Check(req.Email, ip)
+		if err != nil {
+			log.Error("failed to check login attempts", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if retryAfter > 0 {
+			log.Warn("login locked out", slog.String("email", req.Email), slog.String("ip", ip))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, Outcome: model.OutcomeFailure, Reason: "locked_out"})
+			tooManyAttempts(w, r, retryAfter)
+			return
+		}
+
 		user, err := auth.GetByEmail(req.Email)
 		if err != nil {
 			if errors.Is(err, db.ErrUserNotFound) {
 				log.Warn("user not found", slog.String("email", req.Email))
+				// Несуществующие email считаем так же, чтобы перебор не отличался от обычного
+				registerFailure(log, limiter, req.Email, ip)
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, Outcome: model.OutcomeFailure, Reason: "unknown_user"})
 				render.Status(r, http.StatusUnauthorized)
 				render.JSON(w, r, response.Error("status invalid credentials"))
 				return
@@ -73,56 +121,188 @@ func Login(log *slog.Logger, auth Auth, tokenMn TokenMn) http.HandlerFunc {
 			return
 		}
 
-		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+		rehash, err := hasher.Verify(req.Password, user.Password)
 		if err != nil {
+			if !errors.Is(err, passhash.ErrMismatch) {
+				// Например, пустой пароль у аккаунта, созданного через OIDC
+				log.Warn("unusable password hash", slog.Int64("user_id", user.ID), sl.Err(err))
+			}
 			log.Warn("invalid password", slog.String("email", req.Email))
+			registerFailure(log, limiter, req.Email, ip)
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_password"})
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, response.Error("invalid credentials"))
 			return
 		}
 
-		access, err := tokenMn.GenerateToken(
-			user.ID,
-			user.Role,
-			time.Duration(AccessTokenTTL)*time.Second,
-			"access",
-		)
-		if err != nil {
-			log.Error("failed to generate access token", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to generate access token"))
+		// Пароль известен только сейчас, поэтому устаревший хэш пересчитываем при входе
+		if rehash {
+			upgradePasswordHash(log, auth, hasher, user.ID, req.Password)
+		}
+
+		if !user.IsVerified() {
+			log.Warn("email not verified", slog.String("email", req.Email))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "email_not_verified"})
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("email not verified"))
 			return
 		}
 
-		refresh, err := tokenMn.GenerateToken(
-			user.ID,
-			user.Role,
-			time.Duration(RefreshTokenTTL)*time.Second,
-			"refresh",
-		)
-		if err != nil {
-			log.Error("failed to generate refresh token", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to genrate refresh token"))
+		if user.IsDisabled() {
+			log.Warn("account disabled", slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "account_disabled"})
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("account disabled"))
 			return
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:     "refresh_token",
-			Value:    refresh,
-			Path:     "/",
-			MaxAge:   int(RefreshTokenTTL),
-			HttpOnly: true,
-			Secure:   false,
-			SameSite: http.SameSiteDefaultMode,
-		})
-
-		render.Status(r, http.StatusOK)
-		render.JSON(w, r, map[string]any{
-			"access_token":  access,
-			"refresh_token": refresh,
-			"role":          user.Role,
-		})
+		// С включённой 2FA пароль даёт только короткий mfa_pending токен
+		if user.TOTPEnabled {
+			if requireMFA(w, r, log, user, tokenMn) {
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, UserID: user.ID, Outcome: model.OutcomeMFARequired})
+			}
+			return
+		}
 
+		// Счётчик сбрасываем только после полного входа, иначе пароль обнулял бы перебор кодов 2FA
+		if err := limiter.Reset(req.Email); err != nil {
+			log.Error("failed to reset login attempts", sl.Err(err))
+		}
+
+		if issueSession(w, r, log, user, tokenMn, redisRepo) {
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogin, UserID: user.ID, Outcome: model.OutcomeSuccess})
+		}
+	}
+}
+
+// upgradePasswordHash переводит хэш на текущий алгоритм; при ошибке пользователь войдёт со старым
+func upgradePasswordHash(log *slog.Logger, auth Auth, hasher PasswordHasher, userID int64, password string) {
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		log.Error("failed to rehash password", sl.Err(err))
+		return
+	}
+	if err := auth.UpdatePassword(userID, hash); err != nil {
+		log.Error("failed to store rehashed password", sl.Err(err))
+		return
+	}
+	log.Info("password hash upgraded", slog.Int64("user_id", userID))
+}
+
+// registerFailure учитывает неудачу; ошибка счётчика не должна менять ответ клиенту
+func registerFailure(log *slog.Logger, limiter AttemptLimiter, email, ip string) {
+	lockout, err := limiter.RegisterFailure(email, ip)
+	if err != nil {
+		log.Error("failed to register login failure", sl.Err(err))
+		return
+	}
+	if lockout > 0 {
+		log.Warn("login locked",
+			slog.String("event", "login_lockout"),
+			slog.String("email", email),
+			slog.String("ip", ip),
+			slog.Duration("lockout", lockout),
+		)
+	}
+}
+
+func tooManyAttempts(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	render.Status(r, http.StatusTooManyRequests)
+	render.JSON(w, r, response.Error("too many login attempts"))
+}
+
+// requireMFA выдаёт mfa_pending токен, который обменивается на сессию через /auth/login/mfa.
+// false, если токен выдать не удалось и клиенту уже отдана ошибка
+func requireMFA(w http.ResponseWriter, r *http.Request, log *slog.Logger, user *model.User, tokenMn TokenMn) bool {
+	mfaToken, _, err := tokenMn.GenerateToken(user.ID, user.Role, MFATokenTTL, token.TypeMFAPending, "")
+	if err != nil {
+		log.Error("failed to generate mfa token", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return false
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]any{
+		"mfa_required": true,
+		"mfa_token":    mfaToken,
+	})
+	return true
+}
+
+// issueSession открывает новое семейство refresh токенов и отдаёт пару токенов клиенту.
+// false, если сессию открыть не удалось и клиенту уже отдана ошибка
+func issueSession(w http.ResponseWriter, r *http.Request, log *slog.Logger, user *model.User, tokenMn TokenMn, redisRepo RedisRepo) bool {
+	familyID := token.NewID()
+
+	access, _, err := tokenMn.GenerateToken(
+		user.ID,
+		user.Role,
+		time.Duration(AccessTokenTTL)*time.Second,
+		token.TypeAccess,
+		familyID,
+	)
+	if err != nil {
+		log.Error("failed to generate access token", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed to generate access token"))
+		return false
+	}
+
+	refresh, refreshID, err := tokenMn.GenerateToken(
+		user.ID,
+		user.Role,
+		time.Duration(RefreshTokenTTL)*time.Second,
+		token.TypeRefresh,
+		familyID,
+	)
+	if err != nil {
+		log.Error("failed to generate refresh token", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed to genrate refresh token"))
+		return false
 	}
+
+	exp := time.Now().Add(time.Duration(RefreshTokenTTL) * time.Second).Unix()
+	if err := redisRepo.StartFamily(familyID, refreshID, exp); err != nil {
+		log.Error("failed to start token family", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return false
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		ID:         familyID,
+		UserID:     user.ID,
+		UserAgent:  r.UserAgent(),
+		IP:         realip.FromRequest(r),
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if err := redisRepo.CreateSession(session, exp); err != nil {
+		log.Error("failed to create session", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return false
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refresh,
+		Path:     "/",
+		MaxAge:   int(RefreshTokenTTL),
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteDefaultMode,
+	})
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, map[string]any{
+		"access_token":  access,
+		"refresh_token": refresh,
+		"role":          user.Role,
+	})
+	return true
 }
@@ -10,18 +10,26 @@ import (
 
 	"mentorlink/internal/handlers/mocks"
 	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/passhash"
 	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Дешёвые параметры Argon2id, чтобы тесты не тратили 64 МБ на каждую проверку
+var testHasher = passhash.New(passhash.Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+
 func TestLoginHandler(t *testing.T) {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctPassword"), bcrypt.DefaultCost)
+	hashedPassword, _ := testHasher.Hash("correctPassword")
+	verifiedAt := time.Now().Add(-time.Hour)
 	cases := []struct {
 		name           string
 		email          string
@@ -29,8 +37,14 @@ func TestLoginHandler(t *testing.T) {
 		mockUser       *model.User
 		mockError      error
 		tokenError     error
+		familyError    error
+		sessionError   error
+		lockedFor      time.Duration
+		checkError     error
 		expectedStatus int
 		respError      string
+		auditOutcome   string
+		auditReason    string
 	}{
 		{
 			name:     "Success",
@@ -39,10 +53,13 @@ func TestLoginHandler(t *testing.T) {
 			mockUser: &model.User{
 				ID:       1,
 				Email:    "valid@mail.com",
-				Password: string(hashedPassword),
+				Password: hashedPassword,
 				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
 			},
 			expectedStatus: http.StatusOK,
+			auditOutcome:   model.OutcomeSuccess,
 		},
 		{
 			name:           "User not found",
@@ -52,6 +69,8 @@ func TestLoginHandler(t *testing.T) {
 			mockError:      db.ErrUserNotFound,
 			expectedStatus: http.StatusUnauthorized,
 			respError:      "status invalid credentials",
+			auditOutcome:   model.OutcomeFailure,
+			auditReason:    "unknown_user",
 		},
 		{
 			name:     "Wrong password",
@@ -59,11 +78,66 @@ func TestLoginHandler(t *testing.T) {
 			password: "wrongPassword",
 			mockUser: &model.User{
 				Email:    "valid@mail.com",
-				Password: string(hashedPassword),
+				Password: hashedPassword,
 				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
 			},
 			expectedStatus: http.StatusUnauthorized,
 			respError:      "invalid credentials",
+			auditOutcome:   model.OutcomeFailure,
+			auditReason:    "invalid_password",
+		},
+		{
+			name:     "Email not verified",
+			email:    "valid@mail.com",
+			password: "correctPassword",
+			mockUser: &model.User{
+				ID:       1,
+				Email:    "valid@mail.com",
+				Password: hashedPassword,
+				Role:     "user",
+			},
+			expectedStatus: http.StatusForbidden,
+			respError:      "email not verified",
+			auditOutcome:   model.OutcomeFailure,
+			auditReason:    "email_not_verified",
+		},
+		{
+			name:     "Account disabled",
+			email:    "valid@mail.com",
+			password: "correctPassword",
+			mockUser: &model.User{
+				ID:       1,
+				Email:    "valid@mail.com",
+				Password: hashedPassword,
+				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
+				DisabledAt: &verifiedAt,
+			},
+			expectedStatus: http.StatusForbidden,
+			respError:      "account disabled",
+			auditOutcome:   model.OutcomeFailure,
+			auditReason:    "account_disabled",
+		},
+		{
+			name:           "Locked out",
+			email:          "valid@mail.com",
+			password:       "correctPassword",
+			lockedFor:      90*time.Second + 300*time.Millisecond,
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many login attempts",
+			auditOutcome:   model.OutcomeFailure,
+			auditReason:    "locked_out",
+		},
+		{
+			name:           "Lockout check error",
+			email:          "valid@mail.com",
+			password:       "correctPassword",
+			checkError:     errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
 		},
 		{
 			name:     "Token generate error",
@@ -72,13 +146,47 @@ func TestLoginHandler(t *testing.T) {
 			mockUser: &model.User{
 				ID:       1,
 				Email:    "valid@mail.com",
-				Password: string(hashedPassword),
+				Password: hashedPassword,
 				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
 			},
 			tokenError:     errors.New("token error"),
 			expectedStatus: http.StatusInternalServerError,
 			respError:      "failed to generate access token",
 		},
+		{
+			name:     "Start family error",
+			email:    "valid@mail.com",
+			password: "correctPassword",
+			mockUser: &model.User{
+				ID:       1,
+				Email:    "valid@mail.com",
+				Password: hashedPassword,
+				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
+			},
+			familyError:    errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name:     "Create session error",
+			email:    "valid@mail.com",
+			password: "correctPassword",
+			mockUser: &model.User{
+				ID:       1,
+				Email:    "valid@mail.com",
+				Password: hashedPassword,
+				Role:     "user",
+
+				VerifiedAt: &verifiedAt,
+			},
+			sessionError:   errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
 	}
 
 	for _, tc := range cases {
@@ -86,19 +194,52 @@ func TestLoginHandler(t *testing.T) {
 
 			tokenMn := mocks.NewTokenMn(t)
 			authMock := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
 
-			authMock.On("GetByEmail", tc.email).Return(tc.mockUser, tc.mockError)
+			limiter := mocks.NewAttemptLimiter(t)
+			auditor := mocks.NewAuditor(t)
 
-			if tc.expectedStatus == http.StatusOK || tc.tokenError != nil {
+			// Неожиданное событие уронит тест: при ошибках сервера в журнал ничего не пишется
+			if tc.auditOutcome != "" {
+				auditor.On("Record", mock.Anything, model.AuthEvent{
+					Type:    model.AuthEventLogin,
+					UserID:  expectedAuditUserID(tc.mockUser, tc.auditReason),
+					Outcome: tc.auditOutcome,
+					Reason:  tc.auditReason,
+				}).Once()
+			}
+
+			limiter.On("Check", tc.email, "10.0.0.1").Return(tc.lockedFor, tc.checkError)
+			if tc.lockedFor == 0 && tc.checkError == nil {
+				authMock.On("GetByEmail", tc.email).Return(tc.mockUser, tc.mockError)
+			}
+
+			switch {
+			case tc.expectedStatus == http.StatusUnauthorized:
+				limiter.On("RegisterFailure", tc.email, "10.0.0.1").Return(time.Duration(0), nil)
+			case tc.expectedStatus == http.StatusOK || tc.tokenError != nil || tc.familyError != nil || tc.sessionError != nil:
+				limiter.On("Reset", tc.email).Return(nil)
+			}
+
+			if tc.expectedStatus == http.StatusOK || tc.tokenError != nil || tc.familyError != nil || tc.sessionError != nil {
 				if tc.mockUser != nil {
 					tokenMn.On("GenerateToken", tc.mockUser.ID, tc.mockUser.Role,
-						time.Duration(AccessTokenTTL)*time.Second, "access").
-						Return("access_token", tc.tokenError)
+						time.Duration(AccessTokenTTL)*time.Second, "access", mock.AnythingOfType("string")).
+						Return("access_token", "access_jti", tc.tokenError)
 
 					if tc.tokenError == nil {
 						tokenMn.On("GenerateToken", tc.mockUser.ID, tc.mockUser.Role,
-							time.Duration(RefreshTokenTTL)*time.Second, "refresh").
-							Return("refresh_token", nil)
+							time.Duration(RefreshTokenTTL)*time.Second, "refresh", mock.AnythingOfType("string")).
+							Return("refresh_token", "refresh_jti", nil)
+
+						redisMock.On("StartFamily", mock.AnythingOfType("string"), "refresh_jti", mock.AnythingOfType("int64")).
+							Return(tc.familyError)
+
+						if tc.familyError == nil {
+							redisMock.On("CreateSession", mock.MatchedBy(func(s *model.Session) bool {
+								return s.UserID == tc.mockUser.ID && s.UserAgent == "test-agent" && s.IP == "10.0.0.1"
+							}), mock.AnythingOfType("int64")).Return(tc.sessionError)
+						}
 					}
 				}
 			}
@@ -106,7 +247,11 @@ func TestLoginHandler(t *testing.T) {
 			handler := Login(
 				slogdiscard.NewDiscardLogger(),
 				authMock,
+				testHasher,
 				tokenMn,
+				redisMock,
+				limiter,
+				auditor,
 			)
 
 			body := fmt.Sprintf(
@@ -120,12 +265,18 @@ func TestLoginHandler(t *testing.T) {
 				bytes.NewBufferString(body),
 			)
 			require.NoError(t, err)
+			req.Header.Set("User-Agent", "test-agent")
+			req.RemoteAddr = "10.0.0.1:5555"
 
 			rr := httptest.NewRecorder()
 			handler.ServeHTTP(rr, req)
 
 			require.Equal(t, tc.expectedStatus, rr.Code)
 
+			if tc.lockedFor > 0 {
+				require.Equal(t, "91", rr.Header().Get("Retry-After"))
+			}
+
 			if tc.respError != "" {
 				var resp response.Response
 				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
@@ -142,7 +293,212 @@ func TestLoginHandler(t *testing.T) {
 
 			authMock.AssertExpectations(t)
 			tokenMn.AssertExpectations(t)
+			redisMock.AssertExpectations(t)
+			limiter.AssertExpectations(t)
 
 		})
 	}
 }
+
+// allowAudit журнал, который принимает любые события, для тестов не о нём
+func allowAudit(t *testing.T) *mocks.Auditor {
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, mock.Anything).Maybe()
+	return auditor
+}
+
+// expectedAuditUserID пользователь в событии известен после поиска по email
+func expectedAuditUserID(u *model.User, reason string) int64 {
+	if u == nil || reason == "locked_out" {
+		return 0
+	}
+	return u.ID
+}
+
+func TestLoginWithTOTPReturnsMFAToken(t *testing.T) {
+	hashedPassword, _ := testHasher.Hash("correctPassword")
+	verifiedAt := time.Now()
+	user := &model.User{
+		ID:          1,
+		Email:       "valid@mail.com",
+		Password:    hashedPassword,
+		Role:        "user",
+		VerifiedAt:  &verifiedAt,
+		TOTPSecret:  "JBSWY3DPEHPK3PXP",
+		TOTPEnabled: true,
+	}
+
+	tokenMn := mocks.NewTokenMn(t)
+	authMock := mocks.NewUserCreater(t)
+	redisMock := mocks.NewRedisRepo(t)
+	limiter := mocks.NewAttemptLimiter(t)
+
+	limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+	authMock.On("GetByEmail", "valid@mail.com").Return(user, nil)
+	tokenMn.On("GenerateToken", int64(1), "user", MFATokenTTL, token.TypeMFAPending, "").
+		Return("mfa_token", "mfa_jti", nil)
+
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventLogin, UserID: 1, Outcome: model.OutcomeMFARequired}).Once()
+
+	handler := Login(slogdiscard.NewDiscardLogger(), authMock, testHasher, tokenMn, redisMock, limiter, auditor)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login",
+		bytes.NewBufferString(`{"email": "valid@mail.com", "password": "correctPassword"}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, true, resp["mfa_required"])
+	require.Equal(t, "mfa_token", resp["mfa_token"])
+	require.NotContains(t, resp, "access_token")
+	require.NotContains(t, resp, "refresh_token")
+	require.Empty(t, rr.Result().Cookies())
+
+	// Ни семейство токенов, ни сессия не создаются до ввода кода
+	redisMock.AssertNotCalled(t, "StartFamily", mock.Anything, mock.Anything, mock.Anything)
+	redisMock.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+	// Пока код 2FA не введён, счётчик неудач не сбрасывается
+	limiter.AssertNotCalled(t, "Reset", mock.Anything)
+}
+
+func TestLoginLockoutAfterRepeatedFailures(t *testing.T) {
+	hashedPassword, _ := testHasher.Hash("correctPassword")
+	verifiedAt := time.Now()
+	user := &model.User{ID: 1, Email: "valid@mail.com", Password: hashedPassword, Role: "user", VerifiedAt: &verifiedAt}
+
+	authMock := mocks.NewUserCreater(t)
+	tokenMn := mocks.NewTokenMn(t)
+	redisMock := mocks.NewRedisRepo(t)
+	limiter := mocks.NewAttemptLimiter(t)
+
+	// Лимитер в памяти по той же схеме: после трёх неудач блокировка на минуту
+	failures := 0
+	locked := time.Duration(0)
+	limiter.On("Check", "valid@mail.com", "10.0.0.1").Return(func(string, string) time.Duration { return locked }, nil)
+	limiter.On("RegisterFailure", "valid@mail.com", "10.0.0.1").Return(func(string, string) time.Duration {
+		failures++
+		if failures >= 3 {
+			locked = time.Minute
+		}
+		return locked
+	}, nil)
+	limiter.On("Reset", "valid@mail.com").Run(func(mock.Arguments) {
+		failures = 0
+		locked = 0
+	}).Return(nil)
+
+	authMock.On("GetByEmail", "valid@mail.com").Return(user, nil)
+	tokenMn.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("token", "jti", nil)
+	redisMock.On("StartFamily", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	redisMock.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+
+	handler := Login(slogdiscard.NewDiscardLogger(), authMock, testHasher, tokenMn, redisMock, limiter, allowAudit(t))
+	attempt := func(password string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"email": "valid@mail.com", "password": "%s"}`, password)
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(body))
+		req.RemoteAddr = "10.0.0.1:5555"
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Успешный вход обнуляет счётчик
+	require.Equal(t, http.StatusUnauthorized, attempt("wrongPassword").Code)
+	require.Equal(t, http.StatusUnauthorized, attempt("wrongPassword").Code)
+	require.Equal(t, http.StatusOK, attempt("correctPassword").Code)
+	require.Equal(t, 0, failures)
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusUnauthorized, attempt("wrongPassword").Code)
+	}
+
+	// Во время блокировки не помогает даже верный пароль, и хэш не проверяется
+	rr := attempt("correctPassword")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "60", rr.Header().Get("Retry-After"))
+	authMock.AssertNumberOfCalls(t, "GetByEmail", 6)
+}
+
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("correctPassword"), bcrypt.MinCost)
+	verifiedAt := time.Now()
+
+	for _, updateErr := range []error{nil, errors.New("db error")} {
+		user := &model.User{ID: 1, Email: "valid@mail.com", Password: string(legacy), Role: "user", VerifiedAt: &verifiedAt}
+
+		authMock := mocks.NewUserCreater(t)
+		tokenMn := mocks.NewTokenMn(t)
+		redisMock := mocks.NewRedisRepo(t)
+		limiter := mocks.NewAttemptLimiter(t)
+
+		limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+		limiter.On("Reset", "valid@mail.com").Return(nil)
+		authMock.On("GetByEmail", "valid@mail.com").Return(user, nil)
+		authMock.On("UpdatePassword", int64(1), mock.MatchedBy(func(hash string) bool {
+			rehash, err := testHasher.Verify("correctPassword", hash)
+			return strings.HasPrefix(hash, "$argon2id$") && err == nil && !rehash
+		})).Return(updateErr).Once()
+		tokenMn.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return("token", "jti", nil)
+		redisMock.On("StartFamily", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		redisMock.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+
+		handler := Login(slogdiscard.NewDiscardLogger(), authMock, testHasher, tokenMn, redisMock, limiter, allowAudit(t))
+		req := httptest.NewRequest(http.MethodPost, "/auth/login",
+			bytes.NewBufferString(`{"email": "valid@mail.com", "password": "correctPassword"}`))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// Ошибка сохранения нового хэша не мешает входу
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+}
+
+func TestLoginCurrentHashIsNotRewritten(t *testing.T) {
+	hashedPassword, _ := testHasher.Hash("correctPassword")
+	verifiedAt := time.Now()
+	user := &model.User{ID: 1, Email: "valid@mail.com", Password: hashedPassword, Role: "user", VerifiedAt: &verifiedAt, TOTPEnabled: true}
+
+	authMock := mocks.NewUserCreater(t)
+	tokenMn := mocks.NewTokenMn(t)
+	limiter := mocks.NewAttemptLimiter(t)
+
+	limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+	authMock.On("GetByEmail", "valid@mail.com").Return(user, nil)
+	tokenMn.On("GenerateToken", int64(1), "user", MFATokenTTL, token.TypeMFAPending, "").Return("mfa_token", "", nil)
+
+	handler := Login(slogdiscard.NewDiscardLogger(), authMock, testHasher, tokenMn, mocks.NewRedisRepo(t), limiter, allowAudit(t))
+	req := httptest.NewRequest(http.MethodPost, "/auth/login",
+		bytes.NewBufferString(`{"email": "valid@mail.com", "password": "correctPassword"}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	authMock.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestLoginAccountWithoutPassword(t *testing.T) {
+	// Аккаунт создан через OIDC и пароля не имеет
+	verifiedAt := time.Now()
+	user := &model.User{ID: 1, Email: "valid@mail.com", Role: "user", VerifiedAt: &verifiedAt}
+
+	authMock := mocks.NewUserCreater(t)
+	limiter := mocks.NewAttemptLimiter(t)
+
+	limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+	limiter.On("RegisterFailure", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+	authMock.On("GetByEmail", "valid@mail.com").Return(user, nil)
+
+	handler := Login(slogdiscard.NewDiscardLogger(), authMock, testHasher, mocks.NewTokenMn(t), mocks.NewRedisRepo(t), limiter, allowAudit(t))
+	req := httptest.NewRequest(http.MethodPost, "/auth/login",
+		bytes.NewBufferString(`{"email": "valid@mail.com", "password": "anything"}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
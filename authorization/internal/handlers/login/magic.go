@@ -0,0 +1,222 @@
+package login
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/realip"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+var (
+	MagicLinkTTL = 15 * time.Minute
+
+	// Одно письмо на email в минуту и не больше MagicLinkIPLimit запросов с IP за окно
+	MagicLinkEmailInterval       = time.Minute
+	MagicLinkIPLimit       int64 = 10
+	MagicLinkIPWindow            = 15 * time.Minute
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=MagicUserStore
+type MagicUserStore interface {
+	GetByEmail(email string) (*model.User, error)
+	GetByID(id int64) (*model.User, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=MagicLimiter
+type MagicLimiter interface {
+	Throttle(key string, window time.Duration) (bool, error)
+	Allow(key string, limit int64, window time.Duration) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=MagicRedisRepo
+type MagicRedisRepo interface {
+	RedisRepo
+	AddToBlackListOnce(token string, exp int64) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Mailer
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// MagicRequest отправляет ссылку для входа без пароля.
+// Ответ одинаков для любых email, чтобы по нему нельзя было проверить регистрацию
+func MagicRequest(log *slog.Logger, users MagicUserStore, limiter MagicLimiter, tokenMn TokenMn, mailer Mailer, appURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.login.MagicRequest"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.MagicLink
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		ip := realip.FromRequest(r)
+		allowed, err := limiter.Allow("magic_link:ip:"+ip, MagicLinkIPLimit, MagicLinkIPWindow)
+		if err == nil && allowed {
+			allowed, err = limiter.Throttle("magic_link:email:"+req.Email, MagicLinkEmailInterval)
+		}
+		if err != nil {
+			log.Error("failed to check magic link throttle", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if !allowed {
+			log.Warn("magic link throttled", slog.String("email", req.Email), slog.String("ip", ip))
+			w.Header().Set("Retry-After", strconv.Itoa(int(MagicLinkEmailInterval.Seconds())))
+			render.Status(r, http.StatusTooManyRequests)
+			render.JSON(w, r, response.Error("too many requests"))
+			return
+		}
+
+		user, err := users.GetByEmail(req.Email)
+		if err != nil && !errors.Is(err, db.ErrUserNotFound) {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Неподтверждённый email сначала подтверждается обычным письмом, отключённый аккаунт не входит
+		if user == nil || !user.IsVerified() || user.IsDisabled() {
+			log.Info("magic link not sent", slog.Bool("known", user != nil))
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, map[string]any{"status": "magic link sent"})
+			return
+		}
+
+		magic, _, err := tokenMn.GenerateToken(user.ID, user.Role, MagicLinkTTL, token.TypeMagicLink, "")
+		if err != nil {
+			log.Error("failed to generate magic link token", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		link := fmt.Sprintf("%s/magic-login?token=%s", appURL, url.QueryEscape(magic))
+		body := fmt.Sprintf(
+			"Чтобы войти в MentorLink, перейдите по ссылке:\n%s\n\nСсылка одноразовая и действует %d минут. Если вы не запрашивали вход, просто проигнорируйте это письмо.",
+			link, int(MagicLinkTTL.Minutes()),
+		)
+		// Ошибка почты не должна отличать зарегистрированный email от незнакомого
+		if err := mailer.Send(user.Email, "MentorLink: вход по ссылке", body); err != nil {
+			log.Error("failed to send magic link", sl.Err(err), slog.Int64("user_id", user.ID))
+		} else {
+			log.Info("magic link sent", slog.Int64("user_id", user.ID))
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "magic link sent"})
+	}
+}
+
+// MagicVerify обменивает токен из ссылки на обычную пару токенов; повторно ссылка не сработает
+func MagicVerify(log *slog.Logger, users MagicUserStore, tokenMn TokenMn, redisRepo MagicRedisRepo, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.login.MagicVerify"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.MagicLinkVerify
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		claims, err := tokenMn.ParseToken(req.Token)
+		if err != nil || claims.TokenType != token.TypeMagicLink {
+			log.Warn("invalid magic link token")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired token"))
+			return
+		}
+
+		first, err := redisRepo.AddToBlackListOnce(req.Token, claims.ExpiresAt.Unix())
+		if err != nil {
+			log.Error("failed to blacklist magic link", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if !first {
+			log.Warn("magic link reused", slog.Int64("user_id", claims.UserID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMagicLink, UserID: claims.UserID, Outcome: model.OutcomeFailure, Reason: "token_reused"})
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired token"))
+			return
+		}
+
+		// Роль и статус берём из базы: за время жизни ссылки они могли измениться
+		user, err := users.GetByID(claims.UserID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired token"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		if user.IsDisabled() {
+			log.Warn("account disabled", slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMagicLink, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "account_disabled"})
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("account disabled"))
+			return
+		}
+
+		// Ссылка заменяет пароль, но не второй фактор
+		if user.TOTPEnabled {
+			if requireMFA(w, r, log, user, tokenMn) {
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMagicLink, UserID: user.ID, Outcome: model.OutcomeMFARequired})
+			}
+			return
+		}
+
+		if issueSession(w, r, log, user, tokenMn, redisRepo) {
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMagicLink, UserID: user.ID, Outcome: model.OutcomeSuccess})
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package login
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func isIPKey(key string) bool { return strings.HasPrefix(key, "magic_link:ip:") }
+
+func postJSON(t *testing.T, h http.HandlerFunc, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b)))
+	return rr
+}
+
+func TestMagicRequestSendsLink(t *testing.T) {
+	verified := time.Now()
+	users := mocks.NewUserCreater(t)
+	limiter := mocks.NewRedisRepo(t)
+	tokenMn := mocks.NewTokenMn(t)
+	mailer := mocks.NewMailer(t)
+
+	limiter.On("Allow", mock.MatchedBy(isIPKey), MagicLinkIPLimit, MagicLinkIPWindow).Return(true, nil)
+	limiter.On("Throttle", "magic_link:email:user@mail.com", MagicLinkEmailInterval).Return(true, nil)
+	users.On("GetByEmail", "user@mail.com").Return(&model.User{ID: 3, Email: "user@mail.com", Role: model.RoleUser, VerifiedAt: &verified}, nil)
+	tokenMn.On("GenerateToken", int64(3), model.RoleUser, MagicLinkTTL, token.TypeMagicLink, "").Return("magic.token", "", nil)
+	mailer.On("Send", "user@mail.com", mock.AnythingOfType("string"), mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "http://app.local/magic-login?token=magic.token")
+	})).Return(nil)
+
+	rr := postJSON(t, MagicRequest(slogdiscard.NewDiscardLogger(), users, limiter, tokenMn, mailer, "http://app.local"),
+		map[string]string{"email": "user@mail.com"})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+// Сбой почты не выдаёт, что email зарегистрирован: ответ тот же, что для незнакомого адреса
+func TestMagicRequestMailFailure(t *testing.T) {
+	verified := time.Now()
+	users := mocks.NewUserCreater(t)
+	limiter := mocks.NewRedisRepo(t)
+	tokenMn := mocks.NewTokenMn(t)
+	mailer := mocks.NewMailer(t)
+
+	limiter.On("Allow", mock.MatchedBy(isIPKey), MagicLinkIPLimit, MagicLinkIPWindow).Return(true, nil)
+	limiter.On("Throttle", "magic_link:email:user@mail.com", MagicLinkEmailInterval).Return(true, nil)
+	users.On("GetByEmail", "user@mail.com").Return(&model.User{ID: 3, Email: "user@mail.com", Role: model.RoleUser, VerifiedAt: &verified}, nil)
+	tokenMn.On("GenerateToken", int64(3), model.RoleUser, MagicLinkTTL, token.TypeMagicLink, "").Return("magic.token", "", nil)
+	mailer.On("Send", "user@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(errors.New("smtp error"))
+
+	rr := postJSON(t, MagicRequest(slogdiscard.NewDiscardLogger(), users, limiter, tokenMn, mailer, "http://app.local"),
+		map[string]string{"email": "user@mail.com"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"status": "magic link sent"}`, rr.Body.String())
+}
+
+func TestMagicRequestHidesAccountState(t *testing.T) {
+	verified := time.Now()
+	disabled := time.Now()
+	cases := []struct {
+		name string
+		user *model.User
+		err  error
+	}{
+		{name: "unknown email", err: db.ErrUserNotFound},
+		{name: "unverified", user: &model.User{ID: 3, Email: "user@mail.com"}},
+		{name: "disabled", user: &model.User{ID: 3, Email: "user@mail.com", VerifiedAt: &verified, DisabledAt: &disabled}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			limiter := mocks.NewRedisRepo(t)
+			tokenMn := mocks.NewTokenMn(t)
+			mailer := mocks.NewMailer(t)
+
+			limiter.On("Allow", mock.MatchedBy(isIPKey), MagicLinkIPLimit, MagicLinkIPWindow).Return(true, nil)
+			limiter.On("Throttle", "magic_link:email:user@mail.com", MagicLinkEmailInterval).Return(true, nil)
+			users.On("GetByEmail", "user@mail.com").Return(tc.user, tc.err)
+
+			rr := postJSON(t, MagicRequest(slogdiscard.NewDiscardLogger(), users, limiter, tokenMn, mailer, "http://app.local"),
+				map[string]string{"email": "user@mail.com"})
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Contains(t, rr.Body.String(), "magic link sent")
+			mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMagicRequestThrottled(t *testing.T) {
+	cases := []struct {
+		name      string
+		ipAllowed bool
+	}{
+		{name: "per ip", ipAllowed: false},
+		{name: "per email", ipAllowed: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			limiter := mocks.NewRedisRepo(t)
+
+			limiter.On("Allow", mock.MatchedBy(isIPKey), MagicLinkIPLimit, MagicLinkIPWindow).Return(tc.ipAllowed, nil)
+			if tc.ipAllowed {
+				limiter.On("Throttle", "magic_link:email:user@mail.com", MagicLinkEmailInterval).Return(false, nil)
+			}
+
+			rr := postJSON(t, MagicRequest(slogdiscard.NewDiscardLogger(), users, limiter, mocks.NewTokenMn(t), mocks.NewMailer(t), "http://app.local"),
+				map[string]string{"email": "user@mail.com"})
+			require.Equal(t, http.StatusTooManyRequests, rr.Code)
+			require.NotEmpty(t, rr.Header().Get("Retry-After"))
+			users.AssertNotCalled(t, "GetByEmail", mock.Anything)
+		})
+	}
+}
+
+func TestMagicRequestInvalidEmail(t *testing.T) {
+	rr := postJSON(t, MagicRequest(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), mocks.NewRedisRepo(t), mocks.NewTokenMn(t), mocks.NewMailer(t), "http://app.local"),
+		map[string]string{"email": "not-an-email"})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func magicClaims(userID int64, tokenType string) *token.Claims {
+	return &token.Claims{
+		UserID:           userID,
+		Role:             model.RoleUser,
+		TokenType:        tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(MagicLinkTTL))},
+	}
+}
+
+func TestMagicVerifyIssuesSession(t *testing.T) {
+	verified := time.Now()
+	users := mocks.NewUserCreater(t)
+	tokenMn := mocks.NewTokenMn(t)
+	redis := mocks.NewRedisRepo(t)
+
+	tokenMn.On("ParseToken", "magic.token").Return(magicClaims(3, token.TypeMagicLink), nil)
+	redis.On("AddToBlackListOnce", "magic.token", mock.AnythingOfType("int64")).Return(true, nil)
+	// Роль берётся из базы, а не из ссылки
+	users.On("GetByID", int64(3)).Return(&model.User{ID: 3, Role: model.RoleMentor, VerifiedAt: &verified}, nil)
+	tokenMn.On("GenerateToken", int64(3), model.RoleMentor, time.Duration(AccessTokenTTL)*time.Second, token.TypeAccess, mock.AnythingOfType("string")).
+		Return("access", "access_jti", nil)
+	tokenMn.On("GenerateToken", int64(3), model.RoleMentor, time.Duration(RefreshTokenTTL)*time.Second, token.TypeRefresh, mock.AnythingOfType("string")).
+		Return("refresh", "refresh_jti", nil)
+	redis.On("StartFamily", mock.AnythingOfType("string"), "refresh_jti", mock.AnythingOfType("int64")).Return(nil)
+	redis.On("CreateSession", mock.AnythingOfType("*model.Session"), mock.AnythingOfType("int64")).Return(nil)
+
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventLoginMagicLink, UserID: 3, Outcome: model.OutcomeSuccess}).Once()
+
+	rr := postJSON(t, MagicVerify(slogdiscard.NewDiscardLogger(), users, tokenMn, redis, auditor), map[string]string{"token": "magic.token"})
+	requireTokens(t, rr)
+}
+
+func TestMagicVerifyRequiresMFA(t *testing.T) {
+	verified := time.Now()
+	users := mocks.NewUserCreater(t)
+	tokenMn := mocks.NewTokenMn(t)
+	redis := mocks.NewRedisRepo(t)
+
+	tokenMn.On("ParseToken", "magic.token").Return(magicClaims(3, token.TypeMagicLink), nil)
+	redis.On("AddToBlackListOnce", "magic.token", mock.AnythingOfType("int64")).Return(true, nil)
+	users.On("GetByID", int64(3)).Return(&model.User{ID: 3, Role: model.RoleUser, VerifiedAt: &verified, TOTPEnabled: true}, nil)
+	tokenMn.On("GenerateToken", int64(3), model.RoleUser, MFATokenTTL, token.TypeMFAPending, "").Return("mfa", "", nil)
+
+	rr := postJSON(t, MagicVerify(slogdiscard.NewDiscardLogger(), users, tokenMn, redis, allowAudit(t)), map[string]string{"token": "magic.token"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"mfa_required":true`)
+	redis.AssertNotCalled(t, "StartFamily", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMagicVerifyRejected(t *testing.T) {
+	disabled := time.Now()
+	cases := []struct {
+		name           string
+		claims         *token.Claims
+		parseErr       error
+		first          bool
+		user           *model.User
+		userErr        error
+		expectedStatus int
+	}{
+		{name: "invalid token", parseErr: errors.New("bad signature"), expectedStatus: http.StatusBadRequest},
+		{name: "wrong token type", claims: magicClaims(3, token.TypeAccess), expectedStatus: http.StatusBadRequest},
+		{name: "already used", claims: magicClaims(3, token.TypeMagicLink), first: false, expectedStatus: http.StatusBadRequest},
+		{name: "user deleted", claims: magicClaims(3, token.TypeMagicLink), first: true, userErr: db.ErrUserNotFound, expectedStatus: http.StatusBadRequest},
+		{name: "user disabled", claims: magicClaims(3, token.TypeMagicLink), first: true, user: &model.User{ID: 3, DisabledAt: &disabled}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			tokenMn := mocks.NewTokenMn(t)
+			redis := mocks.NewRedisRepo(t)
+
+			tokenMn.On("ParseToken", "magic.token").Return(tc.claims, tc.parseErr)
+			if tc.claims != nil && tc.claims.TokenType == token.TypeMagicLink {
+				redis.On("AddToBlackListOnce", "magic.token", mock.AnythingOfType("int64")).Return(tc.first, nil)
+			}
+			if tc.first {
+				users.On("GetByID", int64(3)).Return(tc.user, tc.userErr)
+			}
+
+			rr := postJSON(t, MagicVerify(slogdiscard.NewDiscardLogger(), users, tokenMn, redis, allowAudit(t)), map[string]string{"token": "magic.token"})
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			tokenMn.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
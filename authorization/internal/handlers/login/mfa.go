@@ -0,0 +1,163 @@
+package login
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/realip"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/totp"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=MFAStore
+type MFAStore interface {
+	GetByID(id int64) (*model.User, error)
+	UseRecoveryCode(userID int64, codeHash string) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=MFARedisRepo
+type MFARedisRepo interface {
+	RedisRepo
+	Throttle(key string, window time.Duration) (bool, error)
+}
+
+// CompleteMFA обменивает mfa_pending токен и код 2FA на пару access/refresh токенов
+func CompleteMFA(log *slog.Logger, users MFAStore, tokenMn TokenMn, redisRepo MFARedisRepo, limiter AttemptLimiter, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.login.CompleteMFA"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.MFALogin
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		claims, err := tokenMn.ParseToken(req.MFAToken)
+		if err != nil || claims.TokenType != token.TypeMFAPending {
+			log.Warn("invalid mfa token")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				log.Warn("user from mfa token not found", slog.Int64("user_id", claims.UserID))
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, response.Error("invalid token"))
+				return
+			}
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		if !user.TOTPEnabled {
+			log.Warn("mfa token for user without 2fa", slog.Int64("user_id", user.ID))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token"))
+			return
+		}
+
+		// Аккаунт могли отключить, пока пользователь вводил код
+		if user.IsDisabled() {
+			log.Warn("account disabled", slog.Int64("user_id", user.ID))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("account disabled"))
+			return
+		}
+
+		// Код 2FA перебирается так же, как пароль, поэтому считаем попытки тем же лимитером
+		ip := realip.FromRequest(r)
+		retryAfter, err := limiter.Check(user.Email, ip)
+		if err != nil {
+			log.Error("failed to check login attempts", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if retryAfter > 0 {
+			log.Warn("mfa locked out", slog.Int64("user_id", user.ID), slog.String("ip", ip))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMFA, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "locked_out"})
+			tooManyAttempts(w, r, retryAfter)
+			return
+		}
+
+		ok, err := checkSecondFactor(user, strings.TrimSpace(req.Code), users, redisRepo)
+		if err != nil {
+			log.Error("failed to check second factor", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if !ok {
+			log.Warn("invalid 2fa code", slog.Int64("user_id", user.ID))
+			registerFailure(log, limiter, user.Email, ip)
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMFA, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_code"})
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid code"))
+			return
+		}
+
+		if err := limiter.Reset(user.Email); err != nil {
+			log.Error("failed to reset login attempts", sl.Err(err))
+		}
+
+		if issueSession(w, r, log, user, tokenMn, redisRepo) {
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginMFA, UserID: user.ID, Outcome: model.OutcomeSuccess})
+		}
+	}
+}
+
+// checkSecondFactor принимает TOTP код (один раз за окно действия) или неиспользованный код восстановления
+func checkSecondFactor(user *model.User, code string, users MFAStore, redisRepo MFARedisRepo) (bool, error) {
+	if isTOTPCode(code) {
+		if !totp.Validate(code, user.TOTPSecret, time.Now()) {
+			return false, nil
+		}
+		// Один и тот же код нельзя предъявить повторно, пока он ещё валиден
+		return redisRepo.Throttle(fmt.Sprintf("totp_used:%d:%s", user.ID, code), 3*totp.Period)
+	}
+
+	return users.UseRecoveryCode(user.ID, secret.Hash(totp.NormalizeRecoveryCode(code)))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,227 @@
+package login
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/totp"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func TestCompleteMFAHandler(t *testing.T) {
+	validCode, err := totp.Code(testTOTPSecret, time.Now())
+	require.NoError(t, err)
+	wrongCode := "000000"
+	if wrongCode == validCode {
+		wrongCode = "111111"
+	}
+
+	mfaClaims := &token.Claims{UserID: 1, Role: "user", TokenType: token.TypeMFAPending}
+	user := &model.User{ID: 1, Email: "valid@mail.com", Role: "user", TOTPSecret: testTOTPSecret, TOTPEnabled: true}
+
+	expectSession := func(tm *mocks.TokenMn, r *mocks.RedisRepo) {
+		tm.On("GenerateToken", int64(1), "user", time.Duration(AccessTokenTTL)*time.Second, token.TypeAccess, mock.AnythingOfType("string")).
+			Return("access_token", "access_jti", nil)
+		tm.On("GenerateToken", int64(1), "user", time.Duration(RefreshTokenTTL)*time.Second, token.TypeRefresh, mock.AnythingOfType("string")).
+			Return("refresh_token", "refresh_jti", nil)
+		r.On("StartFamily", mock.AnythingOfType("string"), "refresh_jti", mock.AnythingOfType("int64")).Return(nil)
+		r.On("CreateSession", mock.AnythingOfType("*model.Session"), mock.AnythingOfType("int64")).Return(nil)
+	}
+
+	cases := []struct {
+		name           string
+		code           string
+		mockSetup      func(*mocks.TokenMn, *mocks.UserCreater, *mocks.RedisRepo)
+		lockedFor      time.Duration
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Valid TOTP code",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+				r.On("Throttle", "totp_used:1:"+validCode, 3*totp.Period).Return(true, nil)
+				expectSession(tm, r)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Replayed TOTP code",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+				r.On("Throttle", "totp_used:1:"+validCode, 3*totp.Period).Return(false, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid code",
+		},
+		{
+			name: "Wrong TOTP code",
+			code: wrongCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid code",
+		},
+		{
+			name: "Valid recovery code",
+			code: "ABCDE-12345",
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+				u.On("UseRecoveryCode", int64(1), secret.Hash("abcde-12345")).Return(true, nil)
+				expectSession(tm, r)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Used recovery code",
+			code: "abcde-12345",
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+				u.On("UseRecoveryCode", int64(1), secret.Hash("abcde-12345")).Return(false, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid code",
+		},
+		{
+			name: "Recovery code check error",
+			code: "abcde-12345",
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+				u.On("UseRecoveryCode", int64(1), secret.Hash("abcde-12345")).Return(false, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name: "Locked out",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(user, nil)
+			},
+			lockedFor:      time.Minute,
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many login attempts",
+		},
+		{
+			name: "Access token instead of mfa token",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(&token.Claims{UserID: 1, TokenType: token.TypeAccess}, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+		{
+			name: "Expired mfa token",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(nil, token.ErrTokenExpired)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+		{
+			name: "User not found",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(nil, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+		{
+			name: "2FA disabled meanwhile",
+			code: validCode,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater, r *mocks.RedisRepo) {
+				tm.On("ParseToken", "mfa_token").Return(mfaClaims, nil)
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, Role: "user"}, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid token",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenMn := mocks.NewTokenMn(t)
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			limiter := mocks.NewAttemptLimiter(t)
+			tc.mockSetup(tokenMn, users, redisMock)
+
+			// Лимитер проверяется только когда пользователь из токена найден и 2FA включена
+			if tc.respError != "invalid token" {
+				limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(tc.lockedFor, nil)
+			}
+			switch {
+			case tc.expectedStatus == http.StatusOK:
+				limiter.On("Reset", "valid@mail.com").Return(nil)
+			case tc.respError == "invalid code":
+				limiter.On("RegisterFailure", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+			}
+
+			auditor := mocks.NewAuditor(t)
+			expectEvent := func(outcome, reason string) {
+				auditor.On("Record", mock.Anything, mock.MatchedBy(func(e model.AuthEvent) bool {
+					return e.Type == model.AuthEventLoginMFA && e.Outcome == outcome && e.Reason == reason
+				})).Once()
+			}
+			switch {
+			case tc.expectedStatus == http.StatusOK:
+				expectEvent(model.OutcomeSuccess, "")
+			case tc.respError == "invalid code":
+				expectEvent(model.OutcomeFailure, "invalid_code")
+			case tc.lockedFor > 0:
+				expectEvent(model.OutcomeFailure, "locked_out")
+			}
+
+			handler := CompleteMFA(slogdiscard.NewDiscardLogger(), users, tokenMn, redisMock, limiter, auditor)
+
+			body, _ := json.Marshal(map[string]string{"mfa_token": "mfa_token", "code": tc.code})
+			req := httptest.NewRequest(http.MethodPost, "/auth/login/mfa", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+
+			if tc.expectedStatus == http.StatusOK {
+				var resp map[string]interface{}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, "access_token", resp["access_token"])
+				require.Equal(t, "refresh_token", resp["refresh_token"])
+			}
+		})
+	}
+}
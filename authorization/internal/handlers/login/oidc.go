@@ -0,0 +1,247 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/oidc"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"golang.org/x/oauth2"
+)
+
+// maxDisplayName размер колонки users.display_name
+const maxDisplayName = 100
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=OIDCProviders
+type OIDCProviders interface {
+	Provider(ctx context.Context, name string) (*oidc.Provider, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=IdentityStore
+type IdentityStore interface {
+	GetByIdentity(provider, subject string) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	LinkIdentity(userID int64, identity model.Identity) error
+	CreateUserWithIdentity(u *model.User, identity model.Identity) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=OIDCRedisRepo
+type OIDCRedisRepo interface {
+	RedisRepo
+	SaveOIDCState(state string, s model.OIDCState, ttl time.Duration) error
+	ConsumeOIDCState(state string) (*model.OIDCState, error)
+}
+
+// OIDCStart отправляет пользователя на страницу входа провайдера
+func OIDCStart(log *slog.Logger, providers OIDCProviders, states OIDCRedisRepo, stateTTL time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.login.OIDCStart"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		provider, ok := lookupProvider(w, r, log, providers, chi.URLParam(r, "provider"))
+		if !ok {
+			return
+		}
+
+		state := token.NewID()
+		st := model.OIDCState{
+			Provider:     provider.Name,
+			CodeVerifier: oauth2.GenerateVerifier(),
+			Nonce:        token.NewID(),
+		}
+		if err := states.SaveOIDCState(state, st, stateTTL); err != nil {
+			log.Error("failed to save oidc state", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		http.Redirect(w, r, provider.AuthCodeURL(state, st.CodeVerifier, st.Nonce), http.StatusFound)
+	}
+}
+
+// OIDCCallback принимает code от провайдера и выдаёт ту же пару токенов, что и вход по паролю
+func OIDCCallback(log *slog.Logger, providers OIDCProviders, users IdentityStore, tokenMn TokenMn, redisRepo OIDCRedisRepo, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.login.OIDCCallback"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "provider")
+		q := r.URL.Query()
+
+		if e := q.Get("error"); e != "" {
+			log.Warn("provider returned error", slog.String("provider", name), slog.String("error", e))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("authorization denied"))
+			return
+		}
+
+		state, code := q.Get("state"), q.Get("code")
+		if state == "" || code == "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		st, err := redisRepo.ConsumeOIDCState(state)
+		if errors.Is(err, cache.ErrOIDCStateNotFound) || (err == nil && st.Provider != name) {
+			log.Warn("unknown oidc state", slog.String("provider", name))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired state"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get oidc state", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		provider, ok := lookupProvider(w, r, log, providers, name)
+		if !ok {
+			return
+		}
+
+		claims, err := provider.Exchange(r.Context(), code, st.CodeVerifier, st.Nonce)
+		if err != nil {
+			log.Warn("oidc exchange failed", slog.String("provider", name), sl.Err(err))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginOIDC, Outcome: model.OutcomeFailure, Reason: "exchange_failed"})
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("oidc authentication failed"))
+			return
+		}
+
+		user, ok := resolveIdentity(w, r, log, users, name, claims)
+		if !ok {
+			return
+		}
+
+		if user.IsDisabled() {
+			log.Warn("account disabled", slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginOIDC, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "account_disabled"})
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, response.Error("account disabled"))
+			return
+		}
+
+		// Провайдер подтверждает только первый фактор, включённая 2FA по-прежнему требуется
+		if user.TOTPEnabled {
+			if requireMFA(w, r, log, user, tokenMn) {
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginOIDC, UserID: user.ID, Outcome: model.OutcomeMFARequired})
+			}
+			return
+		}
+
+		if issueSession(w, r, log, user, tokenMn, redisRepo) {
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventLoginOIDC, UserID: user.ID, Outcome: model.OutcomeSuccess})
+		}
+	}
+}
+
+func lookupProvider(w http.ResponseWriter, r *http.Request, log *slog.Logger, providers OIDCProviders, name string) (*oidc.Provider, bool) {
+	provider, err := providers.Provider(r.Context(), name)
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("unknown provider"))
+		return nil, false
+	}
+	if err != nil {
+		log.Error("oidc provider unavailable", slog.String("provider", name), sl.Err(err))
+		render.Status(r, http.StatusBadGateway)
+		render.JSON(w, r, response.Error("provider unavailable"))
+		return nil, false
+	}
+	return provider, true
+}
+
+// resolveIdentity находит пользователя по sub, привязывает провайдера к аккаунту с тем же email
+// или создаёт новый аккаунт. Привязка по email требует, чтобы email подтвердили обе стороны:
+// иначе заранее зарегистрированный чужой аккаунт получил бы вход жертвы
+func resolveIdentity(w http.ResponseWriter, r *http.Request, log *slog.Logger, users IdentityStore, provider string, claims *oidc.Claims) (*model.User, bool) {
+	identity := model.Identity{Provider: provider, Subject: claims.Subject, Email: claims.Email}
+
+	user, err := users.GetByIdentity(provider, claims.Subject)
+	if err == nil {
+		return user, true
+	}
+	if !errors.Is(err, db.ErrUserNotFound) {
+		log.Error("failed to get user by identity", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return nil, false
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		log.Warn("oidc identity without verified email", slog.String("provider", provider))
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, response.Error("verified email required"))
+		return nil, false
+	}
+
+	user, err = users.GetByEmail(claims.Email)
+	switch {
+	case err == nil:
+		if !user.IsVerified() {
+			log.Warn("refusing to link unverified account", slog.Int64("user_id", user.ID), slog.String("provider", provider))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("account with this email is not verified"))
+			return nil, false
+		}
+		if err := users.LinkIdentity(user.ID, identity); err != nil {
+			log.Error("failed to link identity", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return nil, false
+		}
+		log.Info("oidc identity linked", slog.Int64("user_id", user.ID), slog.String("provider", provider))
+		return user, true
+
+	case errors.Is(err, db.ErrUserNotFound):
+		now := time.Now()
+		user = &model.User{
+			Email:      claims.Email,
+			Role:       model.RoleUser,
+			VerifiedAt: &now,
+			Profile:    model.Profile{DisplayName: truncate(claims.Name, maxDisplayName)},
+		}
+		if err := users.CreateUserWithIdentity(user, identity); err != nil {
+			log.Error("failed to create oidc user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return nil, false
+		}
+		log.Info("user registered via oidc", slog.Int64("user_id", user.ID), slog.String("provider", provider))
+		return user, true
+
+	default:
+		log.Error("failed to get user", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return nil, false
+	}
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,434 @@
+package login
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/oidc"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID     = "mentorlink"
+	testClientSecret = "secret"
+)
+
+// fakeIssuer минимальный OIDC провайдер: discovery, JWKS и token endpoint с проверкой PKCE
+type fakeIssuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]url.Values // code -> параметры запроса авторизации
+
+	// Что провайдер знает о пользователе
+	subject       string
+	email         string
+	emailVerified bool
+	nonce         string // если задан, подменяет nonce из запроса
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeIssuer{key: key, grants: map[string]url.Values{}, subject: "sub-1", email: "oidc@mail.com", emailVerified: true}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                f.srv.URL,
+			"authorization_endpoint":                f.srv.URL + "/authorize",
+			"token_endpoint":                        f.srv.URL + "/token",
+			"jwks_uri":                              f.srv.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", f.token)
+
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+// authorize то, что провайдер делает после входа пользователя: выдаёт code под параметры запроса
+func (f *fakeIssuer) authorize(t *testing.T, location string) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, f.srv.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, testClientID, q.Get("client_id"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.NotEmpty(t, q.Get("code_challenge"))
+	require.NotEmpty(t, q.Get("nonce"))
+
+	code = token.NewID()
+	f.mu.Lock()
+	f.grants[code] = q
+	f.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (f *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	f.mu.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.Get("code_challenge") ||
+		r.PostForm.Get("redirect_uri") != grant.Get("redirect_uri") {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := grant.Get("nonce")
+	if f.nonce != "" {
+		nonce = f.nonce
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            f.srv.URL,
+		"sub":            f.subject,
+		"aud":            testClientID,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          f.email,
+		"email_verified": f.emailVerified,
+		"name":           "OIDC User",
+	})
+	idToken.Header["kid"] = "k1"
+	signed, err := idToken.SignedString(f.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "provider-access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type oidcEnv struct {
+	issuer   *fakeIssuer
+	router   http.Handler
+	users    *mocks.UserCreater
+	tokenMn  *mocks.TokenMn
+	redis    *mocks.RedisRepo
+	states   map[string]model.OIDCState
+	statesMu sync.Mutex
+}
+
+func newOIDCEnv(t *testing.T) *oidcEnv {
+	t.Helper()
+	env := &oidcEnv{
+		issuer:  newFakeIssuer(t),
+		users:   mocks.NewUserCreater(t),
+		tokenMn: mocks.NewTokenMn(t),
+		redis:   mocks.NewRedisRepo(t),
+		states:  map[string]model.OIDCState{},
+	}
+
+	registry, err := oidc.NewRegistry(oidc.Config{
+		Providers: oidc.Providers{{
+			Name:         "corp",
+			Issuer:       env.issuer.srv.URL,
+			ClientID:     testClientID,
+			ClientSecret: testClientSecret,
+		}},
+		RedirectBaseURL: "http://gateway.local",
+	})
+	require.NoError(t, err)
+
+	env.redis.On("SaveOIDCState", mock.AnythingOfType("string"), mock.AnythingOfType("model.OIDCState"), 10*time.Minute).
+		Run(func(args mock.Arguments) {
+			env.statesMu.Lock()
+			env.states[args.String(0)] = args.Get(1).(model.OIDCState)
+			env.statesMu.Unlock()
+		}).Return(nil).Maybe()
+	env.redis.On("ConsumeOIDCState", mock.AnythingOfType("string")).
+		Return(func(state string) (*model.OIDCState, error) {
+			env.statesMu.Lock()
+			defer env.statesMu.Unlock()
+			st, ok := env.states[state]
+			if !ok {
+				return nil, cache.ErrOIDCStateNotFound
+			}
+			delete(env.states, state)
+			return &st, nil
+		}).Maybe()
+
+	log := slogdiscard.NewDiscardLogger()
+	r := chi.NewRouter()
+	r.Get("/auth/oidc/{provider}/start", OIDCStart(log, registry, env.redis, 10*time.Minute))
+	r.Get("/auth/oidc/{provider}/callback", OIDCCallback(log, registry, env.users, env.tokenMn, env.redis, allowAudit(t)))
+	env.router = r
+	return env
+}
+
+func (e *oidcEnv) start(t *testing.T, provider string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	e.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/"+provider+"/start", nil))
+	return rr
+}
+
+func (e *oidcEnv) callback(t *testing.T, provider, code, state string) *httptest.ResponseRecorder {
+	t.Helper()
+	q := url.Values{"code": {code}, "state": {state}}
+	rr := httptest.NewRecorder()
+	e.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/"+provider+"/callback?"+q.Encode(), nil))
+	return rr
+}
+
+// login проходит весь путь: start -> провайдер -> callback
+func (e *oidcEnv) login(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := e.start(t, "corp")
+	require.Equal(t, http.StatusFound, rr.Code)
+
+	location := rr.Header().Get("Location")
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	require.Equal(t, "http://gateway.local/auth/oidc/corp/callback", u.Query().Get("redirect_uri"))
+
+	code, state := e.issuer.authorize(t, location)
+	return e.callback(t, "corp", code, state)
+}
+
+func (e *oidcEnv) expectSession(userID int64, role string) {
+	e.tokenMn.On("GenerateToken", userID, role, time.Duration(AccessTokenTTL)*time.Second, token.TypeAccess, mock.AnythingOfType("string")).
+		Return("access", "access_jti", nil).Once()
+	e.tokenMn.On("GenerateToken", userID, role, time.Duration(RefreshTokenTTL)*time.Second, token.TypeRefresh, mock.AnythingOfType("string")).
+		Return("refresh", "refresh_jti", nil).Once()
+	e.redis.On("StartFamily", mock.AnythingOfType("string"), "refresh_jti", mock.AnythingOfType("int64")).Return(nil).Once()
+	e.redis.On("CreateSession", mock.MatchedBy(func(s *model.Session) bool { return s.UserID == userID }), mock.AnythingOfType("int64")).
+		Return(nil).Once()
+}
+
+func requireTokens(t *testing.T, rr *httptest.ResponseRecorder) {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "access", resp["access_token"])
+	require.Equal(t, "refresh", resp["refresh_token"])
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	env := newOIDCEnv(t)
+	identity := model.Identity{Provider: "corp", Subject: "sub-1", Email: "oidc@mail.com"}
+
+	env.users.On("GetByIdentity", "corp", "sub-1").Return(nil, db.ErrUserNotFound)
+	env.users.On("GetByEmail", "oidc@mail.com").Return(nil, db.ErrUserNotFound)
+	env.users.On("CreateUserWithIdentity", mock.MatchedBy(func(u *model.User) bool {
+		return u.Email == "oidc@mail.com" && u.Role == model.RoleUser && u.IsVerified() && u.DisplayName == "OIDC User"
+	}), identity).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.User).ID = 42
+	}).Return(nil)
+	env.expectSession(42, model.RoleUser)
+
+	requireTokens(t, env.login(t))
+}
+
+func TestOIDCLoginKnownIdentity(t *testing.T) {
+	env := newOIDCEnv(t)
+	verified := time.Now()
+	env.users.On("GetByIdentity", "corp", "sub-1").Return(&model.User{ID: 7, Role: model.RoleMentor, VerifiedAt: &verified}, nil)
+	env.expectSession(7, model.RoleMentor)
+
+	requireTokens(t, env.login(t))
+	env.users.AssertNotCalled(t, "GetByEmail", mock.Anything)
+}
+
+func TestOIDCLoginLinksVerifiedAccount(t *testing.T) {
+	env := newOIDCEnv(t)
+	verified := time.Now()
+	env.users.On("GetByIdentity", "corp", "sub-1").Return(nil, db.ErrUserNotFound)
+	env.users.On("GetByEmail", "oidc@mail.com").Return(&model.User{ID: 7, Role: model.RoleUser, VerifiedAt: &verified}, nil)
+	env.users.On("LinkIdentity", int64(7), model.Identity{Provider: "corp", Subject: "sub-1", Email: "oidc@mail.com"}).Return(nil)
+	env.expectSession(7, model.RoleUser)
+
+	requireTokens(t, env.login(t))
+}
+
+func TestOIDCLoginRejected(t *testing.T) {
+	verified := time.Now()
+
+	cases := []struct {
+		name           string
+		setup          func(env *oidcEnv)
+		expectedStatus int
+	}{
+		{
+			name: "Unverified local account is not linked",
+			setup: func(env *oidcEnv) {
+				env.users.On("GetByIdentity", "corp", "sub-1").Return(nil, db.ErrUserNotFound)
+				env.users.On("GetByEmail", "oidc@mail.com").Return(&model.User{ID: 7, Role: model.RoleUser}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Provider did not verify email",
+			setup: func(env *oidcEnv) {
+				env.issuer.emailVerified = false
+				env.users.On("GetByIdentity", "corp", "sub-1").Return(nil, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Disabled account",
+			setup: func(env *oidcEnv) {
+				env.users.On("GetByIdentity", "corp", "sub-1").
+					Return(&model.User{ID: 7, Role: model.RoleUser, VerifiedAt: &verified, DisabledAt: &verified}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Nonce mismatch",
+			setup: func(env *oidcEnv) {
+				env.issuer.nonce = "replayed"
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newOIDCEnv(t)
+			tc.setup(env)
+
+			rr := env.login(t)
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+			env.tokenMn.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOIDCLoginRequiresMFA(t *testing.T) {
+	env := newOIDCEnv(t)
+	verified := time.Now()
+	env.users.On("GetByIdentity", "corp", "sub-1").
+		Return(&model.User{ID: 7, Role: model.RoleUser, VerifiedAt: &verified, TOTPEnabled: true}, nil)
+	env.tokenMn.On("GenerateToken", int64(7), model.RoleUser, MFATokenTTL, token.TypeMFAPending, "").Return("mfa", "", nil)
+
+	rr := env.login(t)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"mfa_required":true`)
+	env.redis.AssertNotCalled(t, "StartFamily", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallbackChecksPKCE(t *testing.T) {
+	env := newOIDCEnv(t)
+
+	rr := env.start(t, "corp")
+	code, state := env.issuer.authorize(t, rr.Header().Get("Location"))
+
+	// Перехваченный code без verifier из нашего state бесполезен
+	env.statesMu.Lock()
+	st := env.states[state]
+	st.CodeVerifier = "attacker-verifier-attacker-verifier-attacker"
+	env.states[state] = st
+	env.statesMu.Unlock()
+
+	rr = env.callback(t, "corp", code, state)
+	require.Equal(t, http.StatusUnauthorized, rr.Code, rr.Body.String())
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	env := newOIDCEnv(t)
+	verified := time.Now()
+	env.users.On("GetByIdentity", "corp", "sub-1").Return(&model.User{ID: 7, Role: model.RoleUser, VerifiedAt: &verified}, nil)
+	env.expectSession(7, model.RoleUser)
+
+	rr := env.start(t, "corp")
+	code, state := env.issuer.authorize(t, rr.Header().Get("Location"))
+	requireTokens(t, env.callback(t, "corp", code, state))
+
+	// Повтор того же state
+	rr = env.callback(t, "corp", code, state)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Неизвестный state
+	rr = env.callback(t, "corp", code, "forged")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Нет code
+	rr = env.callback(t, "corp", "", state)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestOIDCCallbackProviderMismatch(t *testing.T) {
+	env := newOIDCEnv(t)
+	env.states["state"] = model.OIDCState{Provider: "other", CodeVerifier: "v", Nonce: "n"}
+
+	rr := env.callback(t, "corp", "code", "state")
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	env := newOIDCEnv(t)
+	rr := env.start(t, "nope")
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestOIDCProviderDenied(t *testing.T) {
+	env := newOIDCEnv(t)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/corp/callback?error=access_denied&state=x", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
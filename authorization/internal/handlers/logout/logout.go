@@ -1,11 +1,14 @@
 package logout
 
 import (
+	"errors"
 	"log/slog"
+	"mentorlink/internal/domain/model"
 	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/lib/logger/sl"
 	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/cache"
 	"mentorlink/pkg/token"
 	"net/http"
 	"time"
@@ -18,14 +21,20 @@ import (
 type RedisRepo interface {
 	AddToBlackList(token string, exp int64) error
 	IsBlackListed(token string) (bool, error)
+	RevokeSession(userID int64, sessionID string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
 }
 
 type TokenMn interface {
-	GenerateToken(userID int64, role string, ttl time.Duration, tokenType string) (string, error)
+	GenerateToken(userID int64, role string, ttl time.Duration, tokenType, familyID string) (string, string, error)
 	ParseToken(tokenStr string) (*token.Claims, error)
 }
 
-func Logout(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.HandlerFunc {
+func Logout(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn, auditor Auditor) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.logout.Logout"
 		log := log.With(
@@ -70,7 +79,7 @@ func Logout(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.Handler
 			return
 		}
 
-		if claims.TokenType != "refresh" {
+		if claims.TokenType != token.TypeRefresh {
 			log.Error("invalid token type", slog.String("type", claims.TokenType))
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, response.Error("invalid token type"))
@@ -84,6 +93,18 @@ func Logout(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.Handler
 			return
 		}
 
+		if claims.FamilyID != "" {
+			err := redisRepo.RevokeSession(claims.UserID, claims.FamilyID)
+			if err != nil && !errors.Is(err, cache.ErrSessionNotFound) {
+				log.Error("failed to revoke session", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("server error"))
+				return
+			}
+		}
+
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventLogout, UserID: claims.UserID, Outcome: model.OutcomeSuccess})
+
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, map[string]any{
 			"status": "logged_out",
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"mentorlink/internal/domain/model"
 	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/handlers/mocks"
 	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/cache"
 	"mentorlink/pkg/token"
 	"net/http"
 	"net/http/httptest"
@@ -23,6 +25,15 @@ func TestLogoutHandler(t *testing.T) {
 	expiredRefreshToken := "expired.refresh.token"
 	invalidTypeToken := "invalid.type.token"
 	blacklistedToken := "blacklisted.token"
+	sessionToken := "session.refresh.token"
+	sessionClaims := &token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		TokenType: "refresh",
+		UserID:    7,
+		FamilyID:  "family_1",
+	}
 
 	cases := []struct {
 		name           string
@@ -145,6 +156,55 @@ func TestLogoutHandler(t *testing.T) {
 				"status": "ERROR",
 			},
 		},
+		{
+			name: "Success Revokes Session",
+			request: requests.RFToken{
+				RefreshToken: sessionToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", sessionToken).Return(false, nil)
+				tm.On("ParseToken", sessionToken).Return(sessionClaims, nil)
+				r.On("AddToBlackList", sessionToken, anyPositiveInt64()).Return(nil)
+				r.On("RevokeSession", int64(7), "family_1").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: map[string]interface{}{
+				"status": "logged_out",
+			},
+		},
+		{
+			name: "Session Already Gone",
+			request: requests.RFToken{
+				RefreshToken: sessionToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", sessionToken).Return(false, nil)
+				tm.On("ParseToken", sessionToken).Return(sessionClaims, nil)
+				r.On("AddToBlackList", sessionToken, anyPositiveInt64()).Return(nil)
+				r.On("RevokeSession", int64(7), "family_1").Return(cache.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResp: map[string]interface{}{
+				"status": "logged_out",
+			},
+		},
+		{
+			name: "Revoke Session Error",
+			request: requests.RFToken{
+				RefreshToken: sessionToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", sessionToken).Return(false, nil)
+				tm.On("ParseToken", sessionToken).Return(sessionClaims, nil)
+				r.On("AddToBlackList", sessionToken, anyPositiveInt64()).Return(nil)
+				r.On("RevokeSession", int64(7), "family_1").Return(errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: map[string]interface{}{
+				"error":  "server error",
+				"status": "ERROR",
+			},
+		},
 		{
 			name: "Redis Add Error",
 			request: requests.RFToken{
@@ -177,10 +237,18 @@ func TestLogoutHandler(t *testing.T) {
 			tokenMock := mocks.NewTokenMn(t)
 			tc.mockSetup(redisMock, tokenMock)
 
+			auditor := mocks.NewAuditor(t)
+			if tc.expectedStatus == http.StatusOK {
+				auditor.On("Record", mock.Anything, mock.MatchedBy(func(e model.AuthEvent) bool {
+					return e.Type == model.AuthEventLogout && e.Outcome == model.OutcomeSuccess
+				})).Once()
+			}
+
 			handler := Logout(
 				slogdiscard.NewDiscardLogger(),
 				redisMock,
 				tokenMock,
+				auditor,
 			)
 
 			body, _ := json.Marshal(tc.request)
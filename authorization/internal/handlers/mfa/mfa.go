@@ -0,0 +1,179 @@
+package mfa
+
+import (
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/totp"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+const Issuer = "MentorLink"
+
+var RecoveryCodesCount = 10
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserStore
+type UserStore interface {
+	GetByID(id int64) (*model.User, error)
+	SaveTOTPEnrollment(userID int64, secret string, recoveryCodeHashes []string) error
+	EnableTOTP(userID int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+// Enroll выдаёт новый секрет TOTP и коды восстановления; 2FA включится после Confirm
+func Enroll(log *slog.Logger, users UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mfa.Enroll"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		if user.TOTPEnabled {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("2fa already enabled"))
+			return
+		}
+
+		totpSecret, err := totp.GenerateSecret()
+		if err != nil {
+			log.Error("failed to generate totp secret", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		codes, err := totp.GenerateRecoveryCodes(RecoveryCodesCount)
+		if err != nil {
+			log.Error("failed to generate recovery codes", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		// Коды восстановления показываем один раз, в базе только хэши
+		hashes := make([]string, 0, len(codes))
+		for _, c := range codes {
+			hashes = append(hashes, secret.Hash(c))
+		}
+
+		if err := users.SaveTOTPEnrollment(user.ID, totpSecret, hashes); err != nil {
+			log.Error("failed to save totp enrollment", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"otpauth_uri":    totp.URI(Issuer, user.Email, totpSecret),
+			"secret":         totpSecret,
+			"recovery_codes": codes,
+		})
+	}
+}
+
+// Confirm включает 2FA, если пользователь ввёл верный код из приложения
+func Confirm(log *slog.Logger, users UserStore, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.mfa.Confirm"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.TOTPCode
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		if user.TOTPEnabled {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, response.Error("2fa already enabled"))
+			return
+		}
+
+		if user.TOTPSecret == "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("2fa enrollment not started"))
+			return
+		}
+
+		if !totp.Validate(req.Code, user.TOTPSecret, time.Now()) {
+			log.Warn("invalid totp code on confirm", slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEvent2FAEnable, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_code"})
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid code"))
+			return
+		}
+
+		if err := users.EnableTOTP(user.ID); err != nil {
+			log.Error("failed to enable totp", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("2fa enabled", slog.Int64("user_id", user.ID))
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEvent2FAEnable, UserID: user.ID, Outcome: model.OutcomeSuccess})
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "2fa enabled"})
+	}
+}
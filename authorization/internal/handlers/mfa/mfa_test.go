@@ -0,0 +1,206 @@
+package mfa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/totp"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func withClaims(req *http.Request) *http.Request {
+	claims := &token.Claims{UserID: 1, Role: "user", TokenType: token.TypeAccess}
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func TestEnrollHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		users := mocks.NewUserCreater(t)
+		users.On("GetByID", int64(1)).Return(&model.User{ID: 1, Email: "valid@mail.com"}, nil)
+
+		var storedSecret string
+		var storedHashes []string
+		users.On("SaveTOTPEnrollment", int64(1), mock.AnythingOfType("string"), mock.AnythingOfType("[]string")).
+			Run(func(args mock.Arguments) {
+				storedSecret = args.String(1)
+				storedHashes = args.Get(2).([]string)
+			}).Return(nil)
+
+		req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil))
+		rr := httptest.NewRecorder()
+		Enroll(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			URI           string   `json:"otpauth_uri"`
+			Secret        string   `json:"secret"`
+			RecoveryCodes []string `json:"recovery_codes"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Equal(t, storedSecret, resp.Secret)
+		uri, err := url.Parse(resp.URI)
+		require.NoError(t, err)
+		require.Equal(t, "otpauth", uri.Scheme)
+		require.Equal(t, "totp", uri.Host)
+		require.Equal(t, "/MentorLink:valid@mail.com", uri.Path)
+		require.Equal(t, resp.Secret, uri.Query().Get("secret"))
+		require.Equal(t, Issuer, uri.Query().Get("issuer"))
+		require.Len(t, resp.RecoveryCodes, RecoveryCodesCount)
+
+		// В базу уходят только хэши кодов восстановления
+		require.Len(t, storedHashes, RecoveryCodesCount)
+		for i, c := range resp.RecoveryCodes {
+			require.Equal(t, secret.Hash(c), storedHashes[i])
+		}
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		users := mocks.NewUserCreater(t)
+		users.On("GetByID", int64(1)).Return(&model.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+
+		req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil))
+		rr := httptest.NewRecorder()
+		Enroll(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Save error", func(t *testing.T) {
+		users := mocks.NewUserCreater(t)
+		users.On("GetByID", int64(1)).Return(&model.User{ID: 1, Email: "valid@mail.com"}, nil)
+		users.On("SaveTOTPEnrollment", int64(1), mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+		req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil))
+		rr := httptest.NewRecorder()
+		Enroll(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("No claims", func(t *testing.T) {
+		users := mocks.NewUserCreater(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/2fa/enroll", nil)
+		rr := httptest.NewRecorder()
+		Enroll(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestConfirmHandler(t *testing.T) {
+	validCode, err := totp.Code(testTOTPSecret, time.Now())
+	require.NoError(t, err)
+	wrongCode := "000000"
+	if wrongCode == validCode {
+		wrongCode = "111111"
+	}
+
+	pending := &model.User{ID: 1, TOTPSecret: testTOTPSecret}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			body: `{"code": "` + validCode + `"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(pending, nil)
+				u.On("EnableTOTP", int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Wrong code",
+			body: `{"code": "` + wrongCode + `"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(pending, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid code",
+		},
+		{
+			name: "Not enrolled",
+			body: `{"code": "` + validCode + `"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "2fa enrollment not started",
+		},
+		{
+			name: "Already enabled",
+			body: `{"code": "` + validCode + `"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, TOTPSecret: testTOTPSecret, TOTPEnabled: true}, nil)
+			},
+			expectedStatus: http.StatusConflict,
+			respError:      "2fa already enabled",
+		},
+		{
+			name:           "Malformed code",
+			body:           `{"code": "12ab"}`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name: "Enable error",
+			body: `{"code": "` + validCode + `"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(pending, nil)
+				u.On("EnableTOTP", int64(1)).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "server error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			tc.mockSetup(users)
+
+			req := withClaims(httptest.NewRequest(http.MethodPost, "/auth/2fa/confirm", bytes.NewBufferString(tc.body)))
+			rr := httptest.NewRecorder()
+			auditor := mocks.NewAuditor(t)
+			switch {
+			case tc.expectedStatus == http.StatusOK:
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEvent2FAEnable, UserID: 1, Outcome: model.OutcomeSuccess}).Once()
+			case tc.respError == "invalid code":
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEvent2FAEnable, UserID: 1, Outcome: model.OutcomeFailure, Reason: "invalid_code"}).Once()
+			}
+			Confirm(slogdiscard.NewDiscardLogger(), users, auditor).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
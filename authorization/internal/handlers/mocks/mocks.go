@@ -5,10 +5,12 @@ package mocks
 import (
 	model "mentorlink/internal/domain/model"
 
+	"context"
 	mock "github.com/stretchr/testify/mock"
-	"time"
+	"mentorlink/internal/storage/db"
 	"mentorlink/pkg/token"
-	"context"
+	"net/http"
+	"time"
 )
 
 // UserCreater is an autogenerated mock type for the UserCreater type
@@ -16,17 +18,47 @@ type UserCreater struct {
 	mock.Mock
 }
 
-// CreateUser provides a mock function with given fields: u
-func (_m *UserCreater) CreateUser(u *model.User) error {
-	ret := _m.Called(u)
+// GetByEmail provides a mock function with given fields: email
+func (_m *UserCreater) GetByEmail(email string) (*model.User, error) {
+	ret := _m.Called(email)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateUser")
+		panic("no return value specified for GetByEmail")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.User, error)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePassword provides a mock function with given fields: userID, passwordHash
+func (_m *UserCreater) UpdatePassword(userID int64, passwordHash string) error {
+	ret := _m.Called(userID, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.User) error); ok {
-		r0 = rf(u)
+	if rf, ok := ret.Get(0).(func(int64, string) error); ok {
+		r0 = rf(userID, passwordHash)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -34,29 +66,268 @@ func (_m *UserCreater) CreateUser(u *model.User) error {
 	return r0
 }
 
-// GetByEmail provides a mock function with given fields: email
-func (_m *UserCreater) GetByEmail(email string) (*model.User, error) {
-	ret := _m.Called(email)
+// GetByID provides a mock function with given fields: id
+func (_m *UserCreater) GetByID(id int64) (*model.User, error) {
+	ret := _m.Called(id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByEmail")
+		panic("no return value specified for GetByID")
 	}
 
 	var r0 *model.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*model.User, error)); ok {
-		return rf(email)
+	if rf, ok := ret.Get(0).(func(int64) (*model.User, error)); ok {
+		return rf(id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(int64) *model.User); ok {
+		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.User)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveTOTPEnrollment provides a mock function with given fields: userID, secret, recoveryCodeHashes
+func (_m *UserCreater) SaveTOTPEnrollment(userID int64, secret string, recoveryCodeHashes []string) error {
+	ret := _m.Called(userID, secret, recoveryCodeHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveTOTPEnrollment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string, []string) error); ok {
+		r0 = rf(userID, secret, recoveryCodeHashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableTOTP provides a mock function with given fields: userID
+func (_m *UserCreater) EnableTOTP(userID int64) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UseRecoveryCode provides a mock function with given fields: userID, codeHash
+func (_m *UserCreater) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	ret := _m.Called(userID, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string) (bool, error)); ok {
+		return rf(userID, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string) bool); ok {
+		r0 = rf(userID, codeHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(userID, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProfile provides a mock function with given fields: userID, p
+func (_m *UserCreater) UpdateProfile(userID int64, p model.Profile) error {
+	ret := _m.Called(userID, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, model.Profile) error); ok {
+		r0 = rf(userID, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUser provides a mock function with given fields: u, outbox
+func (_m *UserCreater) CreateUser(u *model.User, outbox func(*model.User) []model.OutboxMessage) error {
+	ret := _m.Called(u, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User, func(*model.User) []model.OutboxMessage) error); ok {
+		r0 = rf(u, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkVerified provides a mock function with given fields: userID, outbox
+func (_m *UserCreater) MarkVerified(userID int64, outbox []model.OutboxMessage) error {
+	ret := _m.Called(userID, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkVerified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []model.OutboxMessage) error); ok {
+		r0 = rf(userID, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListUsers provides a mock function with given fields: f
+func (_m *UserCreater) ListUsers(f db.UserFilter) ([]model.User, int, error) {
+	ret := _m.Called(f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []model.User
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(db.UserFilter) ([]model.User, int, error)); ok {
+		return rf(f)
+	}
+	if rf, ok := ret.Get(0).(func(db.UserFilter) []model.User); ok {
+		r0 = rf(f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.UserFilter) int); ok {
+		r1 = rf(f)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(db.UserFilter) error); ok {
+		r2 = rf(f)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetRole provides a mock function with given fields: userID, role, outbox
+func (_m *UserCreater) SetRole(userID int64, role string, outbox []model.OutboxMessage) error {
+	ret := _m.Called(userID, role, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string, []model.OutboxMessage) error); ok {
+		r0 = rf(userID, role, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetDisabled provides a mock function with given fields: userID, disabled, outbox
+func (_m *UserCreater) SetDisabled(userID int64, disabled bool, outbox []model.OutboxMessage) error {
+	ret := _m.Called(userID, disabled, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDisabled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, bool, []model.OutboxMessage) error); ok {
+		r0 = rf(userID, disabled, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAPIKey provides a mock function with given fields: k, limit
+func (_m *UserCreater) CreateAPIKey(k *model.APIKey, limit int) error {
+	ret := _m.Called(k, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.APIKey, int) error); ok {
+		r0 = rf(k, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAPIKeyByHash provides a mock function with given fields: keyHash
+func (_m *UserCreater) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	ret := _m.Called(keyHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByHash")
+	}
+
+	var r0 *model.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.APIKey, error)); ok {
+		return rf(keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.APIKey); ok {
+		r0 = rf(keyHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIKey)
+		}
+	}
+
 	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+		r1 = rf(keyHash)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -64,37 +335,821 @@ func (_m *UserCreater) GetByEmail(email string) (*model.User, error) {
 	return r0, r1
 }
 
-// NewUserCreater creates a new instance of UserCreater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewUserCreater(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *UserCreater {
-	mock := &UserCreater{}
-	mock.Mock.Test(t)
+// ListAPIKeys provides a mock function with given fields: userID
+func (_m *UserCreater) ListAPIKeys(userID int64) ([]model.APIKey, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []model.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]model.APIKey, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []model.APIKey); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: userID, id
+func (_m *UserCreater) RevokeAPIKey(userID int64, id int64) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchAPIKey provides a mock function with given fields: id
+func (_m *UserCreater) TouchAPIKey(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUsersByIDs provides a mock function with given fields: ids
+func (_m *UserCreater) GetUsersByIDs(ids []int64) ([]model.User, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
+	var r0 []model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]int64) ([]model.User, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]int64) []model.User); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]int64) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUserWithIdentity provides a mock function with given fields: u, identity
+func (_m *UserCreater) CreateUserWithIdentity(u *model.User, identity model.Identity) error {
+	ret := _m.Called(u, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUserWithIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User, model.Identity) error); ok {
+		r0 = rf(u, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByIdentity provides a mock function with given fields: provider, subject
+func (_m *UserCreater) GetByIdentity(provider string, subject string) (*model.User, error) {
+	ret := _m.Called(provider, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdentity")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*model.User, error)); ok {
+		return rf(provider, subject)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *model.User); ok {
+		r0 = rf(provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkIdentity provides a mock function with given fields: userID, identity
+func (_m *UserCreater) LinkIdentity(userID int64, identity model.Identity) error {
+	ret := _m.Called(userID, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, model.Identity) error); ok {
+		r0 = rf(userID, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUser provides a mock function with given fields: userID, outbox
+func (_m *UserCreater) DeleteUser(userID int64, outbox []model.OutboxMessage) error {
+	ret := _m.Called(userID, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []model.OutboxMessage) error); ok {
+		r0 = rf(userID, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListIdentities provides a mock function with given fields: userID
+func (_m *UserCreater) ListIdentities(userID int64) ([]model.Identity, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIdentities")
+	}
+
+	var r0 []model.Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]model.Identity, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []model.Identity); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Identity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnqueueOutbox provides a mock function with given fields: msgs
+func (_m *UserCreater) EnqueueOutbox(msgs []model.OutboxMessage) error {
+	ret := _m.Called(msgs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnqueueOutbox")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]model.OutboxMessage) error); ok {
+		r0 = rf(msgs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListAuthEvents provides a mock function with given fields: f
+func (_m *UserCreater) ListAuthEvents(f db.AuthEventFilter) ([]model.AuthEvent, int, error) {
+	ret := _m.Called(f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAuthEvents")
+	}
+
+	var r0 []model.AuthEvent
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(db.AuthEventFilter) ([]model.AuthEvent, int, error)); ok {
+		return rf(f)
+	}
+	if rf, ok := ret.Get(0).(func(db.AuthEventFilter) []model.AuthEvent); ok {
+		r0 = rf(f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AuthEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(db.AuthEventFilter) int); ok {
+		r1 = rf(f)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(db.AuthEventFilter) error); ok {
+		r2 = rf(f)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ChangeEmail provides a mock function with given fields: userID, newEmail, outbox
+func (_m *UserCreater) ChangeEmail(userID int64, newEmail string, outbox []model.OutboxMessage) error {
+	ret := _m.Called(userID, newEmail, outbox)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string, []model.OutboxMessage) error); ok {
+		r0 = rf(userID, newEmail, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserCreater creates a new instance of UserCreater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserCreater(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserCreater {
+	mock := &UserCreater{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// RedisRepo is an autogenerated mock type for the RedisRepo type
+type RedisRepo struct {
+	mock.Mock
+}
+
+// AddToBlackList provides a mock function with given fields: token, exp
+func (_m *RedisRepo) AddToBlackList(token string, exp int64) error {
+	ret := _m.Called(token, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddToBlackList")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(token, exp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IsBlackListed provides a mock function with given fields: token
+func (_m *RedisRepo) IsBlackListed(token string) (bool, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsBlackListed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeFamily provides a mock function with given fields: familyID
+func (_m *RedisRepo) RevokeFamily(familyID string) error {
+	ret := _m.Called(familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateFamily provides a mock function with given fields: familyID, oldTokenID, newTokenID, exp
+func (_m *RedisRepo) RotateFamily(familyID string, oldTokenID string, newTokenID string, exp int64) error {
+	ret := _m.Called(familyID, oldTokenID, newTokenID, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, int64) error); ok {
+		r0 = rf(familyID, oldTokenID, newTokenID, exp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartFamily provides a mock function with given fields: familyID, tokenID, exp
+func (_m *RedisRepo) StartFamily(familyID string, tokenID string, exp int64) error {
+	ret := _m.Called(familyID, tokenID, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, int64) error); ok {
+		r0 = rf(familyID, tokenID, exp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: s, exp
+func (_m *RedisRepo) CreateSession(s *model.Session, exp int64) error {
+	ret := _m.Called(s, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Session, int64) error); ok {
+		r0 = rf(s, exp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IsSessionActive provides a mock function with given fields: sessionID
+func (_m *RedisRepo) IsSessionActive(sessionID string) (bool, error) {
+	ret := _m.Called(sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsSessionActive")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(sessionID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: userID, sessionID
+func (_m *RedisRepo) RevokeSession(userID int64, sessionID string) error {
+	ret := _m.Called(userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string) error); ok {
+		r0 = rf(userID, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchSession provides a mock function with given fields: sessionID, exp
+func (_m *RedisRepo) TouchSession(sessionID string, exp int64) error {
+	ret := _m.Called(sessionID, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(sessionID, exp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumeResetToken provides a mock function with given fields: tokenHash
+func (_m *RedisRepo) ConsumeResetToken(tokenHash string) (int64, error) {
+	ret := _m.Called(tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeResetToken")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(tokenHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAllSessions provides a mock function with given fields: userID
+func (_m *RedisRepo) RevokeAllSessions(userID int64) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveResetToken provides a mock function with given fields: tokenHash, userID, ttl
+func (_m *RedisRepo) SaveResetToken(tokenHash string, userID int64, ttl time.Duration) error {
+	ret := _m.Called(tokenHash, userID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveResetToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64, time.Duration) error); ok {
+		r0 = rf(tokenHash, userID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Throttle provides a mock function with given fields: key, window
+func (_m *RedisRepo) Throttle(key string, window time.Duration) (bool, error) {
+	ret := _m.Called(key, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Throttle")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Duration) (bool, error)); ok {
+		return rf(key, window)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Duration) bool); ok {
+		r0 = rf(key, window)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Duration) error); ok {
+		r1 = rf(key, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionOwner provides a mock function with given fields: sessionID
+func (_m *RedisRepo) SessionOwner(sessionID string) (int64, error) {
+	ret := _m.Called(sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SessionOwner")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(sessionID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsumeOIDCState provides a mock function with given fields: state
+func (_m *RedisRepo) ConsumeOIDCState(state string) (*model.OIDCState, error) {
+	ret := _m.Called(state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeOIDCState")
+	}
+
+	var r0 *model.OIDCState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.OIDCState, error)); ok {
+		return rf(state)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.OIDCState); ok {
+		r0 = rf(state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OIDCState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(state)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveOIDCState provides a mock function with given fields: state, s, ttl
+func (_m *RedisRepo) SaveOIDCState(state string, s model.OIDCState, ttl time.Duration) error {
+	ret := _m.Called(state, s, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOIDCState")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, model.OIDCState, time.Duration) error); ok {
+		r0 = rf(state, s, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddToBlackListOnce provides a mock function with given fields: token, exp
+func (_m *RedisRepo) AddToBlackListOnce(token string, exp int64) (bool, error) {
+	ret := _m.Called(token, exp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddToBlackListOnce")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (bool, error)); ok {
+		return rf(token, exp)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) bool); ok {
+		r0 = rf(token, exp)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(token, exp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Allow provides a mock function with given fields: key, limit, window
+func (_m *RedisRepo) Allow(key string, limit int64, window time.Duration) (bool, error) {
+	ret := _m.Called(key, limit, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Allow")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, time.Duration) (bool, error)); ok {
+		return rf(key, limit, window)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, time.Duration) bool); ok {
+		r0 = rf(key, limit, window)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, time.Duration) error); ok {
+		r1 = rf(key, limit, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateExport provides a mock function with given fields: exportID, userID, authPart, ttl
+func (_m *RedisRepo) CreateExport(exportID string, userID int64, authPart []byte, ttl time.Duration) error {
+	ret := _m.Called(exportID, userID, authPart, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateExport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64, []byte, time.Duration) error); ok {
+		r0 = rf(exportID, userID, authPart, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetExport provides a mock function with given fields: exportID
+func (_m *RedisRepo) GetExport(exportID string) (*model.DataExport, error) {
+	ret := _m.Called(exportID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExport")
+	}
+
+	var r0 *model.DataExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.DataExport, error)); ok {
+		return rf(exportID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.DataExport); ok {
+		r0 = rf(exportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DataExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(exportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessions provides a mock function with given fields: userID
+func (_m *RedisRepo) GetSessions(userID int64) ([]model.Session, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessions")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]model.Session, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []model.Session); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
 
-	return mock
+	return r0, r1
 }
 
+// RevokeOtherSessions provides a mock function with given fields: userID, keepID
+func (_m *RedisRepo) RevokeOtherSessions(userID int64, keepID string) error {
+	ret := _m.Called(userID, keepID)
 
-// RedisRepo is an autogenerated mock type for the RedisRepo type
-type RedisRepo struct {
-	mock.Mock
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOtherSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string) error); ok {
+		r0 = rf(userID, keepID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// AddToBlackList provides a mock function with given fields: token, exp
-func (_m *RedisRepo) AddToBlackList(token string, exp int64) error {
-	ret := _m.Called(token, exp)
+// SaveEmailChange provides a mock function with given fields: tokenHash, userID, newEmail, ttl
+func (_m *RedisRepo) SaveEmailChange(tokenHash string, userID int64, newEmail string, ttl time.Duration) error {
+	ret := _m.Called(tokenHash, userID, newEmail, ttl)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AddToBlackList")
+		panic("no return value specified for SaveEmailChange")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
-		r0 = rf(token, exp)
+	if rf, ok := ret.Get(0).(func(string, int64, string, time.Duration) error); ok {
+		r0 = rf(tokenHash, userID, newEmail, ttl)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -102,32 +1157,39 @@ func (_m *RedisRepo) AddToBlackList(token string, exp int64) error {
 	return r0
 }
 
-// IsBlackListed provides a mock function with given fields: token
-func (_m *RedisRepo) IsBlackListed(token string) (bool, error) {
-	ret := _m.Called(token)
+// ConsumeEmailChange provides a mock function with given fields: tokenHash
+func (_m *RedisRepo) ConsumeEmailChange(tokenHash string) (int64, string, error) {
+	ret := _m.Called(tokenHash)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IsBlackListed")
+		panic("no return value specified for ConsumeEmailChange")
 	}
 
-	var r0 bool
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
-		return rf(token)
+	var r0 int64
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (int64, string, error)); ok {
+		return rf(tokenHash)
 	}
-	if rf, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = rf(token)
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(tokenHash)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(token)
+	if rf, ok := ret.Get(1).(func(string) string); ok {
+		r1 = rf(tokenHash)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(tokenHash)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // NewRedisRepo creates a new instance of RedisRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -144,38 +1206,44 @@ func NewRedisRepo(t interface {
 	return mock
 }
 
-
 // TokenMn is an autogenerated mock type for the TokenMn type
 type TokenMn struct {
 	mock.Mock
 }
 
-// GenerateToken provides a mock function with given fields: userID, role, ttl, tokenType
-func (_m *TokenMn) GenerateToken(userID int64, role string, ttl time.Duration, tokenType string) (string, error) {
-	ret := _m.Called(userID, role, ttl, tokenType)
+// GenerateToken provides a mock function with given fields: userID, role, ttl, tokenType, familyID
+func (_m *TokenMn) GenerateToken(userID int64, role string, ttl time.Duration, tokenType string, familyID string) (string, string, error) {
+	ret := _m.Called(userID, role, ttl, tokenType, familyID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateToken")
 	}
 
 	var r0 string
-	var r1 error
-	if rf, ok := ret.Get(0).(func(int64, string, time.Duration, string) (string, error)); ok {
-		return rf(userID, role, ttl, tokenType)
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(int64, string, time.Duration, string, string) (string, string, error)); ok {
+		return rf(userID, role, ttl, tokenType, familyID)
 	}
-	if rf, ok := ret.Get(0).(func(int64, string, time.Duration, string) string); ok {
-		r0 = rf(userID, role, ttl, tokenType)
+	if rf, ok := ret.Get(0).(func(int64, string, time.Duration, string, string) string); ok {
+		r0 = rf(userID, role, ttl, tokenType, familyID)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(int64, string, time.Duration, string) error); ok {
-		r1 = rf(userID, role, ttl, tokenType)
+	if rf, ok := ret.Get(1).(func(int64, string, time.Duration, string, string) string); ok {
+		r1 = rf(userID, role, ttl, tokenType, familyID)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(int64, string, time.Duration, string, string) error); ok {
+		r2 = rf(userID, role, ttl, tokenType, familyID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // ParseToken provides a mock function with given fields: tokenStr
@@ -245,6 +1313,24 @@ func (_m *NewMentor) NewMentor(ctx context.Context, mentorEmail string, contact
 	return r0
 }
 
+// ActivateMentor provides a mock function with given fields: ctx, mentorEmail
+func (_m *NewMentor) ActivateMentor(ctx context.Context, mentorEmail string) error {
+	ret := _m.Called(ctx, mentorEmail)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActivateMentor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, mentorEmail)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewNewMentor creates a new instance of NewMentor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewNewMentor(t interface {
@@ -258,3 +1344,297 @@ func NewNewMentor(t interface {
 
 	return mock
 }
+
+// SessionStore is an autogenerated mock type for the SessionStore type
+type SessionStore struct {
+	mock.Mock
+}
+
+// GetSessions provides a mock function with given fields: userID
+func (_m *SessionStore) GetSessions(userID int64) ([]model.Session, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessions")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]model.Session, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []model.Session); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAllSessions provides a mock function with given fields: userID
+func (_m *SessionStore) RevokeAllSessions(userID int64) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: userID, sessionID
+func (_m *SessionStore) RevokeSession(userID int64, sessionID string) error {
+	ret := _m.Called(userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, string) error); ok {
+		r0 = rf(userID, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSessionStore creates a new instance of SessionStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionStore {
+	mock := &SessionStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// Mailer is an autogenerated mock type for the Mailer type
+type Mailer struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: to, subject, body
+func (_m *Mailer) Send(to string, subject string, body string) error {
+	ret := _m.Called(to, subject, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(to, subject, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMailer creates a new instance of Mailer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMailer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Mailer {
+	mock := &Mailer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// VerificationSender is an autogenerated mock type for the VerificationSender type
+type VerificationSender struct {
+	mock.Mock
+}
+
+// SendVerification provides a mock function with given fields: u
+func (_m *VerificationSender) SendVerification(u *model.User) error {
+	ret := _m.Called(u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User) error); ok {
+		r0 = rf(u)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewVerificationSender creates a new instance of VerificationSender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewVerificationSender(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *VerificationSender {
+	mock := &VerificationSender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// AttemptLimiter is an autogenerated mock type for the AttemptLimiter type
+type AttemptLimiter struct {
+	mock.Mock
+}
+
+// Check provides a mock function with given fields: email, ip
+func (_m *AttemptLimiter) Check(email string, ip string) (time.Duration, error) {
+	ret := _m.Called(email, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Check")
+	}
+
+	var r0 time.Duration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (time.Duration, error)); ok {
+		return rf(email, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) time.Duration); ok {
+		r0 = rf(email, ip)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(email, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterFailure provides a mock function with given fields: email, ip
+func (_m *AttemptLimiter) RegisterFailure(email string, ip string) (time.Duration, error) {
+	ret := _m.Called(email, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterFailure")
+	}
+
+	var r0 time.Duration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (time.Duration, error)); ok {
+		return rf(email, ip)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) time.Duration); ok {
+		r0 = rf(email, ip)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(email, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reset provides a mock function with given fields: email
+func (_m *AttemptLimiter) Reset(email string) error {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unlock provides a mock function with given fields: email, ip
+func (_m *AttemptLimiter) Unlock(email string, ip string) error {
+	ret := _m.Called(email, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unlock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(email, ip)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAttemptLimiter creates a new instance of AttemptLimiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAttemptLimiter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AttemptLimiter {
+	mock := &AttemptLimiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// Auditor is an autogenerated mock type for the Auditor type
+type Auditor struct {
+	mock.Mock
+}
+
+// Record provides a mock function with given fields: r, e
+func (_m *Auditor) Record(r *http.Request, e model.AuthEvent) {
+	_m.Called(r, e)
+}
+
+// NewAuditor creates a new instance of Auditor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Auditor {
+	mock := &Auditor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,352 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/realip"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+var ResetTokenTTL = 30 * time.Minute
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserStore
+type UserStore interface {
+	GetByEmail(email string) (*model.User, error)
+	UpdatePassword(userID int64, passwordHash string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=RedisRepo
+type RedisRepo interface {
+	SaveResetToken(tokenHash string, userID int64, ttl time.Duration) error
+	ConsumeResetToken(tokenHash string) (int64, error)
+	RevokeAllSessions(userID int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordHasher
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordPolicy
+type PasswordPolicy interface {
+	Check(password string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Mailer
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+func Forgot(log *slog.Logger, users UserStore, redisRepo RedisRepo, mailer Mailer, auditor Auditor, appURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.password.Forgot"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.ForgotPassword
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		user, err := users.GetByEmail(req.Email)
+		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				// Не раскрываем, зарегистрирован ли email
+				log.Info("password reset requested for unknown email")
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordForgot, Outcome: model.OutcomeFailure, Reason: "unknown_user"})
+				render.Status(r, http.StatusOK)
+				render.JSON(w, r, map[string]any{"status": "reset link sent"})
+				return
+			}
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		resetToken := token.NewID()
+		if err := redisRepo.SaveResetToken(secret.Hash(resetToken), user.ID, ResetTokenTTL); err != nil {
+			log.Error("failed to save reset token", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		link := fmt.Sprintf("%s/reset-password?token=%s", appURL, url.QueryEscape(resetToken))
+		body := fmt.Sprintf(
+			"Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\nСсылка действует %d минут. Если вы не запрашивали сброс, просто проигнорируйте это письмо.",
+			link, int(ResetTokenTTL.Minutes()),
+		)
+		// Ошибка почты не должна отличать зарегистрированный email от незнакомого
+		if err := mailer.Send(user.Email, "MentorLink: сброс пароля", body); err != nil {
+			log.Error("failed to send reset email", sl.Err(err), slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordForgot, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "mail_failed"})
+		} else {
+			log.Info("password reset link sent", slog.Int64("user_id", user.ID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordForgot, UserID: user.ID, Outcome: model.OutcomeSuccess})
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "reset link sent"})
+	}
+}
+
+func Reset(log *slog.Logger, users UserStore, redisRepo RedisRepo, hasher PasswordHasher, policy PasswordPolicy, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.password.Reset"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.ResetPassword
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		// Проверяем до погашения токена, чтобы со слабым паролем можно было повторить попытку
+		if err := policy.Check(req.Password); err != nil {
+			log.Warn("password rejected by policy", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		userID, err := redisRepo.ConsumeResetToken(secret.Hash(req.Token))
+		if err != nil {
+			if errors.Is(err, cache.ErrResetTokenNotFound) {
+				log.Warn("reset token is invalid or expired")
+				auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordReset, Outcome: model.OutcomeFailure, Reason: "invalid_token"})
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid or expired token"))
+				return
+			}
+			log.Error("failed to consume reset token", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		hash, err := hasher.Hash(req.Password)
+		if err != nil {
+			log.Error("failed to hash password", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to process password"))
+			return
+		}
+
+		if err := users.UpdatePassword(userID, hash); err != nil {
+			log.Error("failed to update password", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Пароль уже сменён, даже если отзыв сессий ниже не удастся
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordReset, UserID: userID, Outcome: model.OutcomeSuccess})
+
+		// Все выданные refresh токены становятся недействительными
+		if err := redisRepo.RevokeAllSessions(userID); err != nil {
+			log.Error("failed to revoke sessions after password reset", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		log.Info("password reset", slog.Int64("user_id", userID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "password updated"})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=ChangeStore
+type ChangeStore interface {
+	GetByID(id int64) (*model.User, error)
+	UpdatePassword(userID int64, passwordHash string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=SessionRevoker
+type SessionRevoker interface {
+	RevokeOtherSessions(userID int64, keepID string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordManager
+type PasswordManager interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (rehash bool, err error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AttemptLimiter
+type AttemptLimiter interface {
+	Check(email, ip string) (time.Duration, error)
+	RegisterFailure(email, ip string) (time.Duration, error)
+}
+
+// Change меняет пароль по текущему паролю; все сессии, кроме текущей, отзываются
+func Change(log *slog.Logger, users ChangeStore, sessions SessionRevoker, hasher PasswordManager, policy PasswordPolicy, limiter AttemptLimiter, auditor Auditor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.password.Change"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.ChangePassword
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if errors.Is(err, db.ErrUserNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("user not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// У аккаунтов, созданных через OIDC, текущего пароля нет; задать его можно через сброс
+		if user.Password == "" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("password is not set, use password reset"))
+			return
+		}
+
+		// Перебор текущего пароля упирается в ту же блокировку, что и перебор через вход
+		ip := realip.FromRequest(r)
+		retryAfter, err := limiter.Check(user.Email, ip)
+		if err != nil {
+			log.Error("failed to check password attempts", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if retryAfter > 0 {
+			log.Warn("password check locked out", slog.Int64("user_id", user.ID), slog.String("ip", ip))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "locked_out"})
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			render.Status(r, http.StatusTooManyRequests)
+			render.JSON(w, r, response.Error("too many attempts"))
+			return
+		}
+
+		if _, err := hasher.Verify(req.CurrentPassword, user.Password); err != nil {
+			if !errors.Is(err, passhash.ErrMismatch) {
+				log.Error("failed to verify password", sl.Err(err))
+			}
+			if _, err := limiter.RegisterFailure(user.Email, ip); err != nil {
+				log.Error("failed to register password failure", sl.Err(err))
+			}
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: user.ID, Outcome: model.OutcomeFailure, Reason: "invalid_password"})
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid credentials"))
+			return
+		}
+
+		if err := policy.Check(req.Password); err != nil {
+			log.Warn("password rejected by policy", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		hash, err := hasher.Hash(req.Password)
+		if err != nil {
+			log.Error("failed to hash password", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to process password"))
+			return
+		}
+
+		if err := users.UpdatePassword(user.ID, hash); err != nil {
+			log.Error("failed to update password", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: user.ID, Outcome: model.OutcomeSuccess})
+
+		// Текущая сессия остаётся, остальные устройства придётся залогинить заново
+		if err := sessions.RevokeOtherSessions(user.ID, claims.FamilyID); err != nil {
+			log.Error("failed to revoke sessions after password change", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		log.Info("password changed", slog.Int64("user_id", user.ID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "password updated"})
+	}
+}
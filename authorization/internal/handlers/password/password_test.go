@@ -0,0 +1,388 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/passpolicy"
+	"mentorlink/internal/lib/secret"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForgotHandler(t *testing.T) {
+	user := &model.User{ID: 1, Email: "valid@mail.com", Role: "user"}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater, *mocks.RedisRepo, *mocks.Mailer)
+		event          *model.AuthEvent
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "valid@mail.com").Return(user, nil)
+				r.On("SaveResetToken", mock.AnythingOfType("string"), int64(1), ResetTokenTTL).Return(nil)
+				m.On("Send", "valid@mail.com", mock.AnythingOfType("string"), mock.MatchedBy(func(body string) bool {
+					return strings.Contains(body, "http://app.test/reset-password?token=")
+				})).Return(nil)
+			},
+			event:          &model.AuthEvent{Type: model.AuthEventPasswordForgot, UserID: 1, Outcome: model.OutcomeSuccess},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Unknown email",
+			body: `{"email": "unknown@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "unknown@mail.com").Return(nil, db.ErrUserNotFound)
+			},
+			event:          &model.AuthEvent{Type: model.AuthEventPasswordForgot, Outcome: model.OutcomeFailure, Reason: "unknown_user"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid email",
+			body:           `{"email": "not-an-email"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name: "Save token error",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "valid@mail.com").Return(user, nil)
+				r.On("SaveResetToken", mock.AnythingOfType("string"), int64(1), ResetTokenTTL).Return(errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name: "Send mail error",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, m *mocks.Mailer) {
+				u.On("GetByEmail", "valid@mail.com").Return(user, nil)
+				r.On("SaveResetToken", mock.AnythingOfType("string"), int64(1), ResetTokenTTL).Return(nil)
+				m.On("Send", "valid@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					Return(errors.New("smtp error"))
+			},
+			// Ответ тот же, что для незнакомого email, а в журнале видно, что письмо не ушло
+			event:          &model.AuthEvent{Type: model.AuthEventPasswordForgot, UserID: 1, Outcome: model.OutcomeFailure, Reason: "mail_failed"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			mailer := mocks.NewMailer(t)
+			auditor := mocks.NewAuditor(t)
+			tc.mockSetup(users, redisMock, mailer)
+			if tc.event != nil {
+				auditor.On("Record", mock.Anything, *tc.event).Once()
+			}
+
+			handler := Forgot(slogdiscard.NewDiscardLogger(), users, redisMock, mailer, auditor, "http://app.test")
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+			if tc.expectedStatus == http.StatusOK {
+				require.JSONEq(t, `{"status": "reset link sent"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestForgotStoresHashOfSentToken(t *testing.T) {
+	users := mocks.NewUserCreater(t)
+	redisMock := mocks.NewRedisRepo(t)
+	mailer := mocks.NewMailer(t)
+
+	var storedHash, sentBody string
+	users.On("GetByEmail", "valid@mail.com").Return(&model.User{ID: 1, Email: "valid@mail.com"}, nil)
+	redisMock.On("SaveResetToken", mock.AnythingOfType("string"), int64(1), ResetTokenTTL).
+		Run(func(args mock.Arguments) { storedHash = args.String(0) }).Return(nil)
+	mailer.On("Send", "valid@mail.com", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { sentBody = args.String(2) }).Return(nil)
+
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, mock.AnythingOfType("model.AuthEvent"))
+
+	handler := Forgot(slogdiscard.NewDiscardLogger(), users, redisMock, mailer, auditor, "http://app.test")
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewBufferString(`{"email": "valid@mail.com"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	_, rest, found := strings.Cut(sentBody, "token=")
+	require.True(t, found)
+	sentToken := strings.Fields(rest)[0]
+	require.NotEqual(t, sentToken, storedHash)
+	require.Equal(t, secret.Hash(sentToken), storedHash)
+}
+
+var testHasher = passhash.New(passhash.Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+var testPolicy, _ = passpolicy.New(passpolicy.Config{MinLength: 8, MaxLength: 128})
+
+func TestResetHandler(t *testing.T) {
+	tokenHash := secret.Hash("reset-token")
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater, *mocks.RedisRepo)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			body: `{"token": "reset-token", "password": "newPassword", "repeat_password": "newPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo) {
+				r.On("ConsumeResetToken", tokenHash).Return(int64(1), nil)
+				u.On("UpdatePassword", int64(1), mock.MatchedBy(func(hash string) bool {
+					_, err := testHasher.Verify("newPassword", hash)
+					return err == nil
+				})).Return(nil)
+				r.On("RevokeAllSessions", int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Passwords mismatch",
+			body:           `{"token": "reset-token", "password": "newPassword", "repeat_password": "otherPassword"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			// Токен не погашен, пользователь может отправить другой пароль
+			name:           "Password too short",
+			body:           `{"token": "reset-token", "password": "short1", "repeat_password": "short1"}`,
+			mockSetup:      func(u *mocks.UserCreater, r *mocks.RedisRepo) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "password is too short: minimum 8 characters",
+		},
+		{
+			name: "Token already used or expired",
+			body: `{"token": "reset-token", "password": "newPassword", "repeat_password": "newPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo) {
+				r.On("ConsumeResetToken", tokenHash).Return(int64(0), cache.ErrResetTokenNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid or expired token",
+		},
+		{
+			name: "Update password error",
+			body: `{"token": "reset-token", "password": "newPassword", "repeat_password": "newPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo) {
+				r.On("ConsumeResetToken", tokenHash).Return(int64(1), nil)
+				u.On("UpdatePassword", int64(1), mock.AnythingOfType("string")).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name: "Revoke sessions error",
+			body: `{"token": "reset-token", "password": "newPassword", "repeat_password": "newPassword"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo) {
+				r.On("ConsumeResetToken", tokenHash).Return(int64(1), nil)
+				u.On("UpdatePassword", int64(1), mock.AnythingOfType("string")).Return(nil)
+				r.On("RevokeAllSessions", int64(1)).Return(errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			tc.mockSetup(users, redisMock)
+
+			// Смена пароля попадает в журнал, даже если отозвать сессии потом не удалось
+			auditor := mocks.NewAuditor(t)
+			switch {
+			case tc.expectedStatus == http.StatusOK || tc.name == "Revoke sessions error":
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventPasswordReset, UserID: 1, Outcome: model.OutcomeSuccess}).Once()
+			case tc.respError == "invalid or expired token":
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventPasswordReset, Outcome: model.OutcomeFailure, Reason: "invalid_token"}).Once()
+			}
+
+			handler := Reset(slogdiscard.NewDiscardLogger(), users, redisMock, testHasher, testPolicy, auditor)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestChangeHandler(t *testing.T) {
+	hash, err := testHasher.Hash("currentPassword")
+	require.NoError(t, err)
+	user := &model.User{ID: 1, Email: "valid@mail.com", Role: model.RoleUser, Password: hash}
+
+	cases := []struct {
+		name           string
+		user           *model.User
+		body           string
+		updateError    error
+		revokeError    error
+		lockout        time.Duration
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:           "Success",
+			user:           user,
+			body:           `{"current_password": "currentPassword", "password": "newPassword", "repeat_password": "newPassword"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Wrong current password",
+			user:           user,
+			body:           `{"current_password": "wrongPassword", "password": "newPassword", "repeat_password": "newPassword"}`,
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "invalid credentials",
+		},
+		{
+			name:           "Locked out",
+			user:           user,
+			body:           `{"current_password": "currentPassword", "password": "newPassword", "repeat_password": "newPassword"}`,
+			lockout:        time.Minute,
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many attempts",
+		},
+		{
+			name:           "Account without password",
+			user:           &model.User{ID: 1, Email: "oidc@mail.com", Role: model.RoleUser},
+			body:           `{"password": "newPassword", "repeat_password": "newPassword"}`,
+			expectedStatus: http.StatusBadRequest,
+			respError:      "password is not set, use password reset",
+		},
+		{
+			name:           "Password too short",
+			user:           user,
+			body:           `{"current_password": "currentPassword", "password": "short1", "repeat_password": "short1"}`,
+			expectedStatus: http.StatusBadRequest,
+			respError:      "password is too short: minimum 8 characters",
+		},
+		{
+			name:           "Update password error",
+			user:           user,
+			body:           `{"current_password": "currentPassword", "password": "newPassword", "repeat_password": "newPassword"}`,
+			updateError:    errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name:           "Revoke sessions error",
+			user:           user,
+			body:           `{"current_password": "currentPassword", "password": "newPassword", "repeat_password": "newPassword"}`,
+			revokeError:    errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			auditor := mocks.NewAuditor(t)
+			limiter := mocks.NewAttemptLimiter(t)
+
+			users.On("GetByID", int64(1)).Return(tc.user, nil)
+			if tc.user.Password != "" {
+				limiter.On("Check", "valid@mail.com", mock.AnythingOfType("string")).Return(tc.lockout, nil)
+			}
+			updated := tc.expectedStatus == http.StatusOK || tc.updateError != nil || tc.revokeError != nil
+			if updated {
+				users.On("UpdatePassword", int64(1), mock.MatchedBy(func(hash string) bool {
+					_, err := testHasher.Verify("newPassword", hash)
+					return err == nil
+				})).Return(tc.updateError)
+			}
+			if tc.expectedStatus == http.StatusOK || tc.revokeError != nil {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: 1, Outcome: model.OutcomeSuccess}).Once()
+				// Текущая сессия не отзывается
+				redisMock.On("RevokeOtherSessions", int64(1), "family-1").Return(tc.revokeError)
+			}
+			if tc.respError == "invalid credentials" {
+				limiter.On("RegisterFailure", "valid@mail.com", mock.AnythingOfType("string")).Return(time.Duration(0), nil)
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: 1, Outcome: model.OutcomeFailure, Reason: "invalid_password"}).Once()
+			}
+			if tc.lockout > 0 {
+				auditor.On("Record", mock.Anything, model.AuthEvent{Type: model.AuthEventPasswordChange, UserID: 1, Outcome: model.OutcomeFailure, Reason: "locked_out"}).Once()
+			}
+
+			handler := Change(slogdiscard.NewDiscardLogger(), users, redisMock, testHasher, testPolicy, limiter, auditor)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/password/change", bytes.NewBufferString(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserKey, &token.Claims{UserID: 1, Role: model.RoleUser, FamilyID: "family-1"}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code, rr.Body.String())
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+			if tc.lockout > 0 {
+				require.Equal(t, "60", rr.Header().Get("Retry-After"))
+			}
+			if !updated {
+				users.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestChangeUnauthorized(t *testing.T) {
+	handler := Change(slogdiscard.NewDiscardLogger(), mocks.NewUserCreater(t), mocks.NewRedisRepo(t), testHasher, testPolicy, mocks.NewAttemptLimiter(t), mocks.NewAuditor(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/change", bytes.NewBufferString(`{}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -1,10 +1,15 @@
 package profile
 
 import (
+	"errors"
 	"log/slog"
 	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
 	"mentorlink/pkg/token"
 	"net/http"
 
@@ -12,11 +17,33 @@ import (
 	"github.com/go-chi/render"
 )
 
-type UserGetter interface {
-	GetByEmail(email string) (*model.User, error)
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserStore
+type UserStore interface {
+	GetByID(id int64) (*model.User, error)
+	UpdateProfile(userID int64, p model.Profile) error
 }
 
-func Get(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
+type profileView struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	model.Profile
+	Verified         bool `json:"verified"`
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+func view(u *model.User) profileView {
+	return profileView{
+		ID:               u.ID,
+		Email:            u.Email,
+		Role:             u.Role,
+		Profile:          u.Profile,
+		Verified:         u.IsVerified(),
+		TwoFactorEnabled: u.TOTPEnabled,
+	}
+}
+
+func Get(log *slog.Logger, users UserStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.profile.Get"
 		log := log.With(
@@ -24,7 +51,7 @@ func Get(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		claims, ok := r.Context().Value("user").(*token.Claims)
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
 		if !ok || claims == nil {
 			log.Error("failed to get user claims")
 			render.Status(r, http.StatusUnauthorized)
@@ -32,8 +59,14 @@ func Get(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
 			return
 		}
 
-		user, err := userGetter.GetByEmail(claims.Email)
+		user, err := users.GetByID(claims.UserID)
 		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				log.Warn("user from token not found", slog.Int64("user_id", claims.UserID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("user not found"))
+				return
+			}
 			log.Error("failed to get user", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, response.Error("failed to get user profile"))
@@ -41,10 +74,80 @@ func Get(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
 		}
 
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, map[string]any{
-			"id":    user.ID,
-			"email": user.Email,
-			"role":  user.Role,
-		})
+		render.JSON(w, r, view(user))
 	}
-} 
\ No newline at end of file
+}
+
+func Update(log *slog.Logger, users UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.profile.Update"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		var req requests.UpdateProfile
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				log.Warn("user from token not found", slog.Int64("user_id", claims.UserID))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("user not found"))
+				return
+			}
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to get user profile"))
+			return
+		}
+
+		applyUpdate(&user.Profile, req)
+
+		if err := users.UpdateProfile(user.ID, user.Profile); err != nil {
+			log.Error("failed to update profile", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to update profile"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, view(user))
+	}
+}
+
+func applyUpdate(p *model.Profile, req requests.UpdateProfile) {
+	if req.DisplayName != nil {
+		p.DisplayName = *req.DisplayName
+	}
+	if req.AvatarURL != nil {
+		p.AvatarURL = *req.AvatarURL
+	}
+	if req.Contact != nil {
+		p.Contact = *req.Contact
+	}
+	if req.Timezone != nil {
+		p.Timezone = *req.Timezone
+	}
+}
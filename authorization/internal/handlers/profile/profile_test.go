@@ -0,0 +1,225 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	claims := &token.Claims{UserID: 1, Role: "user", TokenType: token.TypeAccess}
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func testUser() *model.User {
+	verifiedAt := time.Now()
+	return &model.User{
+		ID:         1,
+		Email:      "valid@mail.com",
+		Role:       "user",
+		VerifiedAt: &verifiedAt,
+		Profile: model.Profile{
+			DisplayName: "Ivan",
+			AvatarURL:   "https://cdn.example.com/a.png",
+			Contact:     "@ivan",
+			Timezone:    "Europe/Moscow",
+		},
+	}
+}
+
+func TestGetHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		withClaims     bool
+		mockSetup      func(*mocks.UserCreater)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name:       "Success",
+			withClaims: true,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(testUser(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "No claims",
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusUnauthorized,
+			respError:      "unauthorized",
+		},
+		{
+			name:       "User not found",
+			withClaims: true,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(nil, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			respError:      "user not found",
+		},
+		{
+			name:       "Storage error",
+			withClaims: true,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "failed to get user profile",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			tc.mockSetup(users)
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/profile", nil)
+			if tc.withClaims {
+				req = withClaims(req)
+			}
+			rr := httptest.NewRecorder()
+			Get(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+				return
+			}
+
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, "valid@mail.com", resp["email"])
+			require.Equal(t, "Ivan", resp["display_name"])
+			require.Equal(t, "https://cdn.example.com/a.png", resp["avatar_url"])
+			require.Equal(t, "@ivan", resp["contact"])
+			require.Equal(t, "Europe/Moscow", resp["timezone"])
+			require.Equal(t, true, resp["verified"])
+			require.NotContains(t, resp, "password")
+		})
+	}
+}
+
+func TestUpdateHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Partial update keeps other fields",
+			body: `{"display_name": "Ivan P.", "timezone": "Asia/Tokyo"}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(testUser(), nil)
+				u.On("UpdateProfile", int64(1), model.Profile{
+					DisplayName: "Ivan P.",
+					AvatarURL:   "https://cdn.example.com/a.png",
+					Contact:     "@ivan",
+					Timezone:    "Asia/Tokyo",
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Empty string clears field",
+			body: `{"avatar_url": ""}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(testUser(), nil)
+				u.On("UpdateProfile", int64(1), model.Profile{
+					DisplayName: "Ivan",
+					Contact:     "@ivan",
+					Timezone:    "Europe/Moscow",
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid timezone",
+			body:           `{"timezone": "Mars/Olympus"}`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Invalid avatar url",
+			body:           `{"avatar_url": "not a url"}`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Javascript avatar url",
+			body:           `{"avatar_url": "javascript:alert(1)"}`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Data avatar url",
+			body:           `{"avatar_url": "data:image/svg+xml;base64,PHN2Zz4="}`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+		{
+			name:           "Invalid JSON",
+			body:           `{invalid`,
+			mockSetup:      func(u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request body",
+		},
+		{
+			name: "Update error",
+			body: `{"display_name": "Ivan P."}`,
+			mockSetup: func(u *mocks.UserCreater) {
+				u.On("GetByID", int64(1)).Return(testUser(), nil)
+				u.On("UpdateProfile", int64(1), model.Profile{
+					DisplayName: "Ivan P.",
+					AvatarURL:   "https://cdn.example.com/a.png",
+					Contact:     "@ivan",
+					Timezone:    "Europe/Moscow",
+				}).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "failed to update profile",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			tc.mockSetup(users)
+
+			req := withClaims(httptest.NewRequest(http.MethodPatch, "/auth/profile", bytes.NewBufferString(tc.body)))
+			rr := httptest.NewRecorder()
+			Update(slogdiscard.NewDiscardLogger(), users).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"mentorlink/internal/domain/model"
 	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/handlers/mocks"
 	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/cache"
 	"mentorlink/pkg/token"
 	"net/http"
 	"net/http/httptest"
@@ -26,7 +28,23 @@ func TestRefreshHandler(t *testing.T) {
 		invalidTypeToken    = "invalid.type.token"
 		failGenerateAcc     = "fail.generate.access"
 		failGenerateRefresh = "fail.generate.refresh"
+		noFamilyToken       = "no.family.token"
+		reusedToken         = "reused.refresh.token"
+		failRotateToken     = "fail.rotate.token"
+		revokedSession      = "revoked.session.token"
 	)
+	familyClaims := func() *token.Claims {
+		return &token.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "old_jti",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: "refresh",
+			FamilyID:  "family_1",
+			UserID:    123,
+			Role:      "user",
+		}
+	}
 	cases := []struct {
 		name           string
 		request        requests.RFToken
@@ -42,25 +60,17 @@ func TestRefreshHandler(t *testing.T) {
 			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
 				r.On("IsBlackListed", validRefreshToken).Return(false, nil)
 
-				tm.On("ParseToken", validRefreshToken).Return(
-					&token.Claims{
-						RegisteredClaims: jwt.RegisteredClaims{
-							ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-						},
-						TokenType: "refresh",
-						UserID:    123,
-						Role:      "user",
-					},
-					nil,
-				)
+				tm.On("ParseToken", validRefreshToken).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
 
-				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access").
-					Return("new_access_token", nil)
-				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh").
-					Return("new_refresh_token", nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("new_access_token", "access_jti", nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+					Return("new_refresh_token", "new_jti", nil)
 
-				// ... если затем AddToBlackList, то тоже настраиваем:
-				r.On("AddToBlackList", validRefreshToken, anyPositiveInt64()).Return(nil)
+				// старый jti должен смениться новым внутри того же семейства
+				r.On("RotateFamily", "family_1", "old_jti", "new_jti", anyPositiveInt64()).Return(nil)
+				r.On("TouchSession", "family_1", anyPositiveInt64()).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedResp: map[string]interface{}{
@@ -162,20 +172,11 @@ func TestRefreshHandler(t *testing.T) {
 			},
 			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
 				r.On("IsBlackListed", failGenerateAcc).Return(false, nil)
-				tm.On("ParseToken", failGenerateAcc).Return(
-					&token.Claims{
-						RegisteredClaims: jwt.RegisteredClaims{
-							ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-						},
-						TokenType: "refresh",
-						UserID:    123,
-						Role:      "user",
-					},
-					nil,
-				)
+				tm.On("ParseToken", failGenerateAcc).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
 				// Ошибка при генерации access токена
-				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access").
-					Return("", errors.New("generate access token error"))
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("", "", errors.New("generate access token error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedResp: map[string]interface{}{
@@ -190,7 +191,29 @@ func TestRefreshHandler(t *testing.T) {
 			},
 			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
 				r.On("IsBlackListed", failGenerateRefresh).Return(false, nil)
-				tm.On("ParseToken", failGenerateRefresh).Return(
+				tm.On("ParseToken", failGenerateRefresh).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
+				// Сначала генерируем access, ок
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("new_access_token", "access_jti", nil)
+				// Ошибка при генерации refresh
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+					Return("", "", errors.New("generate refresh token error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: map[string]interface{}{
+				"error":  "server error",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Token Without Family",
+			request: requests.RFToken{
+				RefreshToken: noFamilyToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", noFamilyToken).Return(false, nil)
+				tm.On("ParseToken", noFamilyToken).Return(
 					&token.Claims{
 						RegisteredClaims: jwt.RegisteredClaims{
 							ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
@@ -201,12 +224,104 @@ func TestRefreshHandler(t *testing.T) {
 					},
 					nil,
 				)
-				// Сначала генерируем access, ок
-				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access").
-					Return("new_access_token", nil)
-				// Ошибка при генерации refresh
-				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh").
-					Return("", errors.New("generate refresh token error"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: map[string]interface{}{
+				"error":  "invalid token",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Reused Token Revokes Family",
+			request: requests.RFToken{
+				RefreshToken: reusedToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", reusedToken).Return(false, nil)
+				tm.On("ParseToken", reusedToken).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("new_access_token", "access_jti", nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+					Return("new_refresh_token", "new_jti", nil)
+				// токен уже был ротирован раньше — семейство отзывается целиком
+				r.On("RotateFamily", "family_1", "old_jti", "new_jti", anyPositiveInt64()).Return(cache.ErrTokenReused)
+				r.On("RevokeFamily", "family_1").Return(nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: map[string]interface{}{
+				"error":  "token reuse detected",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Reused Token With Revoke Error",
+			request: requests.RFToken{
+				RefreshToken: reusedToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", reusedToken).Return(false, nil)
+				tm.On("ParseToken", reusedToken).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("new_access_token", "access_jti", nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+					Return("new_refresh_token", "new_jti", nil)
+				r.On("RotateFamily", "family_1", "old_jti", "new_jti", anyPositiveInt64()).Return(cache.ErrTokenReused)
+				r.On("RevokeFamily", "family_1").Return(errors.New("redis error"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: map[string]interface{}{
+				"error":  "token reuse detected",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Session Revoked",
+			request: requests.RFToken{
+				RefreshToken: revokedSession,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", revokedSession).Return(false, nil)
+				tm.On("ParseToken", revokedSession).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(false, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedResp: map[string]interface{}{
+				"error":  "session revoked",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Session Check Error",
+			request: requests.RFToken{
+				RefreshToken: revokedSession,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", revokedSession).Return(false, nil)
+				tm.On("ParseToken", revokedSession).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(false, errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedResp: map[string]interface{}{
+				"error":  "server error",
+				"status": "ERROR",
+			},
+		},
+		{
+			name: "Rotate Family Error",
+			request: requests.RFToken{
+				RefreshToken: failRotateToken,
+			},
+			mockSetup: func(r *mocks.RedisRepo, tm *mocks.TokenMn) {
+				r.On("IsBlackListed", failRotateToken).Return(false, nil)
+				tm.On("ParseToken", failRotateToken).Return(familyClaims(), nil)
+				r.On("IsSessionActive", "family_1").Return(true, nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+					Return("new_access_token", "access_jti", nil)
+				tm.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+					Return("new_refresh_token", "new_jti", nil)
+				r.On("RotateFamily", "family_1", "old_jti", "new_jti", anyPositiveInt64()).Return(errors.New("redis error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedResp: map[string]interface{}{
@@ -223,10 +338,27 @@ func TestRefreshHandler(t *testing.T) {
 
 			tc.mockSetup(redisMock, tokenMock)
 
+			// В журнал попадают успешные обновления и отказы по отозванной сессии или повтору токена
+			auditor := mocks.NewAuditor(t)
+			expectEvent := func(outcome, reason string) {
+				auditor.On("Record", mock.Anything, model.AuthEvent{
+					Type: model.AuthEventRefresh, UserID: 123, Outcome: outcome, Reason: reason,
+				}).Once()
+			}
+			switch {
+			case tc.expectedStatus == http.StatusOK:
+				expectEvent(model.OutcomeSuccess, "")
+			case tc.expectedResp["error"] == "token reuse detected":
+				expectEvent(model.OutcomeFailure, "token_reuse")
+			case tc.expectedResp["error"] == "session revoked":
+				expectEvent(model.OutcomeFailure, "session_revoked")
+			}
+
 			handler := RefreshTokens(
 				slogdiscard.NewDiscardLogger(),
 				redisMock,
 				tokenMock,
+				auditor,
 			)
 
 			body, _ := json.Marshal(tc.request)
@@ -257,6 +389,68 @@ func TestRefreshHandler(t *testing.T) {
 	}
 }
 
+// После обнаружения повтора сессия семейства отозвана: следующий refresh того же семейства отклоняется
+func TestRefreshAfterReuseSessionRevoked(t *testing.T) {
+	claims := func(jti string) *token.Claims {
+		return &token.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        jti,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: "refresh",
+			FamilyID:  "family_1",
+			UserID:    123,
+			Role:      "user",
+		}
+	}
+
+	redisMock := mocks.NewRedisRepo(t)
+	tokenMock := mocks.NewTokenMn(t)
+	auditor := mocks.NewAuditor(t)
+
+	revoked := false
+	redisMock.On("IsBlackListed", mock.Anything).Return(false, nil)
+	redisMock.On("IsSessionActive", "family_1").Return(
+		func(string) bool { return !revoked },
+		func(string) error { return nil },
+	)
+	redisMock.On("RevokeFamily", "family_1").Run(func(mock.Arguments) { revoked = true }).Return(nil).Once()
+	redisMock.On("RotateFamily", "family_1", "stolen_jti", "new_jti", anyPositiveInt64()).Return(cache.ErrTokenReused).Once()
+
+	tokenMock.On("ParseToken", "reused.refresh.token").Return(claims("stolen_jti"), nil)
+	tokenMock.On("ParseToken", "latest.refresh.token").Return(claims("latest_jti"), nil)
+	tokenMock.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "access", "family_1").
+		Return("new_access_token", "access_jti", nil).Once()
+	tokenMock.On("GenerateToken", int64(123), "user", mock.AnythingOfType("time.Duration"), "refresh", "family_1").
+		Return("new_refresh_token", "new_jti", nil).Once()
+
+	auditor.On("Record", mock.Anything, model.AuthEvent{
+		Type: model.AuthEventRefresh, UserID: 123, Outcome: model.OutcomeFailure, Reason: "token_reuse",
+	}).Once()
+	auditor.On("Record", mock.Anything, model.AuthEvent{
+		Type: model.AuthEventRefresh, UserID: 123, Outcome: model.OutcomeFailure, Reason: "session_revoked",
+	}).Once()
+
+	handler := RefreshTokens(slogdiscard.NewDiscardLogger(), redisMock, tokenMock, auditor)
+	refresh := func(rt string) map[string]interface{} {
+		body, _ := json.Marshal(requests.RFToken{RefreshToken: rt})
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	require.Equal(t, "token reuse detected", refresh("reused.refresh.token")["error"])
+
+	redisMock.AssertCalled(t, "RevokeFamily", "family_1")
+
+	require.Equal(t, "session revoked", refresh("latest.refresh.token")["error"])
+}
+
 func anyPositiveInt64() interface{} {
 	return mock.MatchedBy(func(x int64) bool {
 		return x > 0
@@ -1,11 +1,14 @@
 package refresh
 
 import (
+	"errors"
 	"log/slog"
+	"mentorlink/internal/domain/model"
 	"mentorlink/internal/domain/requests"
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/lib/logger/sl"
 	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/storage/cache"
 	"mentorlink/pkg/token"
 	"net/http"
 	"time"
@@ -20,16 +23,24 @@ var (
 )
 
 type RedisRepo interface {
-	AddToBlackList(token string, exp int64) error
 	IsBlackListed(token string) (bool, error)
+	RotateFamily(familyID, oldTokenID, newTokenID string, exp int64) error
+	RevokeFamily(familyID string) error
+	IsSessionActive(sessionID string) (bool, error)
+	TouchSession(sessionID string, exp int64) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
 }
 
 type TokenMn interface {
-	GenerateToken(userID int64, role string, ttl time.Duration, tokenType string) (string, error)
+	GenerateToken(userID int64, role string, ttl time.Duration, tokenType, familyID string) (string, string, error)
 	ParseToken(tokenStr string) (*token.Claims, error)
 }
 
-func RefreshTokens(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.HandlerFunc {
+func RefreshTokens(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn, auditor Auditor) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.refresh.RefreshTokens"
 		log := log.With(
@@ -74,18 +85,41 @@ func RefreshTokens(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.
 			return
 		}
 
-		if claims.TokenType != "refresh" {
+		if claims.TokenType != token.TypeRefresh {
 			log.Error("invalid token type", slog.String("type", claims.TokenType))
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, response.Error("invalid token type"))
 			return
 		}
 
-		newAccess, err := tokenMn.GenerateToken(
+		if claims.FamilyID == "" || claims.ID == "" {
+			log.Error("refresh token without family", slog.Int64("user_id", claims.UserID))
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("invalid token"))
+			return
+		}
+
+		active, err := redisRepo.IsSessionActive(claims.FamilyID)
+		if err != nil {
+			log.Error("failed to check session", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+		if !active {
+			log.Warn("session revoked", slog.String("family_id", claims.FamilyID))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventRefresh, UserID: claims.UserID, Outcome: model.OutcomeFailure, Reason: "session_revoked"})
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("session revoked"))
+			return
+		}
+
+		newAccess, _, err := tokenMn.GenerateToken(
 			claims.UserID,
 			claims.Role,
 			time.Duration(AccessTokenTTL)*time.Second,
-			"access",
+			token.TypeAccess,
+			claims.FamilyID,
 		)
 		if err != nil {
 			log.Error("failed to generate access token", sl.Err(err))
@@ -94,11 +128,12 @@ func RefreshTokens(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.
 			return
 		}
 
-		newRefresh, err := tokenMn.GenerateToken(
+		newRefresh, newRefreshID, err := tokenMn.GenerateToken(
 			claims.UserID,
 			claims.Role,
 			time.Duration(RefreshTokenTTL)*time.Second,
-			"refresh",
+			token.TypeRefresh,
+			claims.FamilyID,
 		)
 		if err != nil {
 			log.Error("failed to generate refresh token", sl.Err(err))
@@ -107,12 +142,37 @@ func RefreshTokens(log *slog.Logger, redisRepo RedisRepo, tokenMn TokenMn) http.
 			return
 		}
 
-		if err := redisRepo.AddToBlackList(req.RefreshToken, claims.ExpiresAt.Unix()); err != nil {
-			log.Error("falied to adding token to black list", sl.Err(err))
+		exp := time.Now().Add(time.Duration(RefreshTokenTTL) * time.Second).Unix()
+		err = redisRepo.RotateFamily(claims.FamilyID, claims.ID, newRefreshID, exp)
+		if errors.Is(err, cache.ErrTokenReused) {
+			// Уже использованный refresh токен: считаем семейство скомпрометированным
+			log.Warn("security event: refresh token reuse detected, revoking token family",
+				slog.String("event", "refresh_token_reuse"),
+				slog.Int64("user_id", claims.UserID),
+				slog.String("family_id", claims.FamilyID),
+				slog.String("jti", claims.ID),
+			)
+			if err := redisRepo.RevokeFamily(claims.FamilyID); err != nil {
+				log.Error("failed to revoke token family", sl.Err(err))
+			}
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventRefresh, UserID: claims.UserID, Outcome: model.OutcomeFailure, Reason: "token_reuse"})
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("token reuse detected"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to rotate token family", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, response.Error("server error"))
+			return
 		}
 
+		if err := redisRepo.TouchSession(claims.FamilyID, exp); err != nil {
+			log.Error("failed to update session", sl.Err(err))
+		}
+
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventRefresh, UserID: claims.UserID, Outcome: model.OutcomeSuccess})
+
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, map[string]any{
 			"access_token":  newAccess,
@@ -1,7 +1,6 @@
 package register
 
 import (
-	"context"
 	"errors"
 	"log/slog"
 	"mentorlink/internal/domain/model"
@@ -9,25 +8,41 @@ import (
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/lib/logger/sl"
 	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/outbox"
 	"mentorlink/internal/storage/db"
 	"net/http"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@latest --name=UserCreater
 type UserCreater interface {
-	CreateUser(u *model.User) error
+	CreateUser(u *model.User, outbox func(u *model.User) []model.OutboxMessage) error
 	GetByEmail(email string) (*model.User, error)
 }
 
-type NewMentor interface {
-	NewMentor(ctx context.Context, mentorEmail, contact string) error
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordHasher
+type PasswordHasher interface {
+	Hash(password string) (string, error)
 }
 
-func Register(ctx context.Context, log *slog.Logger, userCreater UserCreater, newMentor NewMentor) http.HandlerFunc {
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PasswordPolicy
+type PasswordPolicy interface {
+	Check(password string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=VerificationSender
+type VerificationSender interface {
+	SendVerification(u *model.User) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Auditor
+type Auditor interface {
+	Record(r *http.Request, e model.AuthEvent)
+}
+
+func Register(log *slog.Logger, userCreater UserCreater, hasher PasswordHasher, policy PasswordPolicy, verifier VerificationSender, auditor Auditor) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.register.Register"
 		log := log.With(
@@ -51,6 +66,13 @@ func Register(ctx context.Context, log *slog.Logger, userCreater UserCreater, ne
 			return
 		}
 
+		if err := policy.Check(req.Password); err != nil {
+			log.Warn("password rejected by policy", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
 		existing, err := userCreater.GetByEmail(req.Email)
 		if err != nil && !errors.Is(err, db.ErrUserNotFound) {
 			log.Error("failed to check user existence", sl.Err(err))
@@ -61,12 +83,13 @@ func Register(ctx context.Context, log *slog.Logger, userCreater UserCreater, ne
 
 		if existing != nil {
 			log.Error("user already exists", slog.String("email", req.Email))
+			auditor.Record(r, model.AuthEvent{Type: model.AuthEventRegister, UserID: existing.ID, Outcome: model.OutcomeFailure, Reason: "email_taken"})
 			render.Status(r, http.StatusConflict)
 			render.JSON(w, r, response.Error("user already exists"))
 			return
 		}
 
-		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hash, err := hasher.Hash(req.Password)
 		if err != nil {
 			log.Error("failed to hash password", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -76,31 +99,41 @@ func Register(ctx context.Context, log *slog.Logger, userCreater UserCreater, ne
 
 		user := &model.User{
 			Email:    req.Email,
-			Password: string(hash),
+			Password: hash,
 			Role:     req.Role,
+			Profile:  model.Profile{Contact: req.Contact},
 		}
 
-		if err = userCreater.CreateUser(user); err != nil {
+		// Запись о менторе уходит в сервис менторов через outbox вместе с созданием пользователя,
+		// так что сбой gRPC не оставляет пользователя-ментора без записи ментора.
+		// Сообщения собираются после вставки: ментор в других сервисах ключуется id пользователя
+		events := func(u *model.User) []model.OutboxMessage {
+			if u.Role != model.RoleMentor {
+				return nil
+			}
+			return []model.OutboxMessage{outbox.NewMentorMessage(u.ID, u.Email, u.Contact)}
+		}
+
+		if err = userCreater.CreateUser(user, events); err != nil {
 			log.Error("failed to create user", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, response.Error("failed to create user"))
 			return
 		}
 
-		if user.Role == model.RoleMentor {
-			if err := newMentor.NewMentor(ctx, req.Email, req.Contact); err != nil {
-				log.Error("failed to call NewMentor via gRPC", sl.Err(err))
-				render.Status(r, http.StatusInternalServerError)
-				render.JSON(w, r, response.Error("mentor registration failed"))
-				return
-			}
+		auditor.Record(r, model.AuthEvent{Type: model.AuthEventRegister, UserID: user.ID, Outcome: model.OutcomeSuccess})
+
+		// Аккаунт уже создан, письмо можно будет запросить повторно через /auth/verify/resend
+		if err := verifier.SendVerification(user); err != nil {
+			log.Error("failed to send verification email", sl.Err(err))
 		}
 
 		render.Status(r, http.StatusCreated)
 		render.JSON(w, r, map[string]any{
-			"id":    user.ID,
-			"email": user.Email,
-			"role":  user.Role,
+			"id":       user.ID,
+			"email":    user.Email,
+			"role":     user.Role,
+			"verified": false,
 		})
 
 	}
@@ -2,7 +2,8 @@ package register
 
 import (
 	"bytes"
-	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,15 +11,35 @@ import (
 	"mentorlink/internal/domain/response"
 	"mentorlink/internal/handlers/mocks"
 	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/passpolicy"
+	"mentorlink/internal/outbox"
 	"mentorlink/internal/storage/db"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+var testHasher = passhash.New(passhash.Config{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+// newTestPolicy политика со списком утёкших паролей из одного "qwerty123"
+func newTestPolicy(t *testing.T) *passpolicy.Policy {
+	t.Helper()
+	sum := sha1.Sum([]byte("qwerty123"))
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ToUpper(hex.EncodeToString(sum[:]))+":3912816\n"), 0o600))
+
+	policy, err := passpolicy.New(passpolicy.Config{MinLength: 8, MaxLength: 128, BreachedListPath: path})
+	require.NoError(t, err)
+	return policy
+}
+
 func TestRegisterHandler(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -29,6 +50,7 @@ func TestRegisterHandler(t *testing.T) {
 		expectedStatus int
 		respError      string
 		mockError      error
+		sendError      error
 	}{
 		{
 			name:           "Success",
@@ -38,6 +60,15 @@ func TestRegisterHandler(t *testing.T) {
 			role:           "user",
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name:           "Verification email failure does not fail registration",
+			email:          "valid@mail.com",
+			password:       "securePassword123",
+			repeatPassword: "securePassword123",
+			role:           "user",
+			sendError:      errors.New("smtp error"),
+			expectedStatus: http.StatusCreated,
+		},
 		{
 			name:           "Passwords mismatch",
 			email:          "test@mail.com",
@@ -47,6 +78,24 @@ func TestRegisterHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			respError:      "server error",
 		},
+		{
+			name:           "Password too short",
+			email:          "test@mail.com",
+			password:       "short1",
+			repeatPassword: "short1",
+			role:           "user",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "password is too short: minimum 8 characters",
+		},
+		{
+			name:           "Breached password",
+			email:          "test@mail.com",
+			password:       "qwerty123",
+			repeatPassword: "qwerty123",
+			role:           "user",
+			expectedStatus: http.StatusBadRequest,
+			respError:      passpolicy.ErrBreached.Error(),
+		},
 		{
 			name:           "User already exists",
 			email:          "exists@mail.com",
@@ -65,6 +114,15 @@ func TestRegisterHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			respError:      "server error",
 		},
+		{
+			name:           "Admin self-registration",
+			email:          "test@mail.com",
+			password:       "password123",
+			repeatPassword: "password123",
+			role:           "admin",
+			expectedStatus: http.StatusBadRequest,
+			respError:      "server error",
+		},
 		{
 			name:           "Internal error",
 			email:          "test@mail.com",
@@ -80,10 +138,10 @@ func TestRegisterHandler(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			userCreaterMock := mocks.NewUserCreater(t)
-			newMentorMock := mocks.NewNewMentor(t)
+			verifierMock := mocks.NewVerificationSender(t)
 
 			// Настройка моков только для кейсов с обращением к БД
-			if tc.name != "Passwords mismatch" && tc.name != "Invalid role" {
+			if tc.expectedStatus != http.StatusBadRequest {
 				if tc.name == "User already exists" {
 					userCreaterMock.On("GetByEmail", tc.email).
 						Return(&model.User{Email: tc.email}, nil)
@@ -94,11 +152,31 @@ func TestRegisterHandler(t *testing.T) {
 			}
 
 			if tc.expectedStatus == http.StatusCreated || tc.mockError != nil {
-				userCreaterMock.On("CreateUser", mock.Anything).
+				userCreaterMock.On("CreateUser", mock.Anything, mock.MatchedBy(func(events func(*model.User) []model.OutboxMessage) bool {
+					return events(&model.User{ID: 1, Email: tc.email, Role: tc.role}) == nil
+				})).
 					Return(tc.mockError).Once()
 			}
 
-			handler := Register(context.Background(), slogdiscard.NewDiscardLogger(), userCreaterMock, newMentorMock)
+			if tc.expectedStatus == http.StatusCreated {
+				verifierMock.On("SendVerification", mock.MatchedBy(func(u *model.User) bool {
+					return u.Email == tc.email && u.VerifiedAt == nil
+				})).Return(tc.sendError)
+			}
+
+			auditor := mocks.NewAuditor(t)
+			switch {
+			case tc.expectedStatus == http.StatusCreated:
+				auditor.On("Record", mock.Anything, mock.MatchedBy(func(e model.AuthEvent) bool {
+					return e.Type == model.AuthEventRegister && e.Outcome == model.OutcomeSuccess
+				})).Once()
+			case tc.name == "User already exists":
+				auditor.On("Record", mock.Anything, mock.MatchedBy(func(e model.AuthEvent) bool {
+					return e.Type == model.AuthEventRegister && e.Outcome == model.OutcomeFailure && e.Reason == "email_taken"
+				})).Once()
+			}
+
+			handler := Register(slogdiscard.NewDiscardLogger(), userCreaterMock, testHasher, newTestPolicy(t), verifierMock, auditor)
 
 			body := fmt.Sprintf(
 				`{"email": "%s", "password": "%s", "repeat_password": "%s", "role": "%s"}`,
@@ -126,9 +204,69 @@ func TestRegisterHandler(t *testing.T) {
 			}
 
 			// Проверка вызовов моков
-			if tc.name != "Passwords mismatch" && tc.name != "Invalid role" {
+			if tc.expectedStatus != http.StatusBadRequest {
 				userCreaterMock.AssertExpectations(t)
 			}
+			verifierMock.AssertExpectations(t)
 		})
 	}
 }
+
+func TestRegisterMentorWritesOutbox(t *testing.T) {
+	userCreaterMock := mocks.NewUserCreater(t)
+	verifierMock := mocks.NewVerificationSender(t)
+
+	var events []model.OutboxMessage
+	var created *model.User
+	userCreaterMock.On("GetByEmail", "mentor@mail.com").Return(nil, db.ErrUserNotFound)
+	userCreaterMock.On("CreateUser", mock.AnythingOfType("*model.User"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(0).(*model.User)
+			// Хранилище вызывает сборщик уже с присвоенным id
+			withID := *created
+			withID.ID = 42
+			events = args.Get(1).(func(*model.User) []model.OutboxMessage)(&withID)
+		}).
+		Return(nil)
+	verifierMock.On("SendVerification", mock.AnythingOfType("*model.User")).Return(nil)
+
+	// Сервис менторов не вызывается синхронно: запись ментора создаёт relay
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, mock.Anything).Maybe()
+
+	handler := Register(slogdiscard.NewDiscardLogger(), userCreaterMock, testHasher, newTestPolicy(t), verifierMock, auditor)
+
+	body := `{"email": "mentor@mail.com", "password": "password123", "repeat_password": "password123", "role": "mentor", "contact": "@mentor"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, []model.OutboxMessage{outbox.NewMentorMessage(42, "mentor@mail.com", "@mentor")}, events)
+
+	// Новые пароли хэшируются Argon2id в формате PHC
+	require.True(t, strings.HasPrefix(created.Password, "$argon2id$"), created.Password)
+	_, err := testHasher.Verify("password123", created.Password)
+	require.NoError(t, err)
+}
+
+func TestRegisterMentorCreateErrorLeavesNothing(t *testing.T) {
+	userCreaterMock := mocks.NewUserCreater(t)
+	verifierMock := mocks.NewVerificationSender(t)
+
+	userCreaterMock.On("GetByEmail", "mentor@mail.com").Return(nil, db.ErrUserNotFound)
+	userCreaterMock.On("CreateUser", mock.AnythingOfType("*model.User"), mock.Anything).
+		Return(errors.New("database error"))
+
+	auditor := mocks.NewAuditor(t)
+	auditor.On("Record", mock.Anything, mock.Anything).Maybe()
+
+	handler := Register(slogdiscard.NewDiscardLogger(), userCreaterMock, testHasher, newTestPolicy(t), verifierMock, auditor)
+
+	body := `{"email": "mentor@mail.com", "password": "password123", "repeat_password": "password123", "role": "mentor"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
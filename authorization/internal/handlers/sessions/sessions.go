@@ -0,0 +1,137 @@
+package sessions
+
+import (
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=SessionStore
+type SessionStore interface {
+	GetSessions(userID int64) ([]model.Session, error)
+	RevokeSession(userID int64, sessionID string) error
+	RevokeAllSessions(userID int64) error
+}
+
+type sessionView struct {
+	model.Session
+	Current bool `json:"current"`
+}
+
+func List(log *slog.Logger, store SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.List"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		sessions, err := store.GetSessions(claims.UserID)
+		if err != nil {
+			log.Error("failed to get sessions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		views := make([]sessionView, 0, len(sessions))
+		for _, s := range sessions {
+			views = append(views, sessionView{
+				Session: s,
+				Current: s.ID == claims.FamilyID,
+			})
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"sessions": views,
+		})
+	}
+}
+
+func Revoke(log *slog.Logger, store SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.Revoke"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		sessionID := chi.URLParam(r, "id")
+
+		err := store.RevokeSession(claims.UserID, sessionID)
+		if errors.Is(err, cache.ErrSessionNotFound) {
+			log.Warn("session not found", slog.String("session_id", sessionID))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("session not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke session", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"status": "session revoked",
+		})
+	}
+}
+
+func RevokeAll(log *slog.Logger, store SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sessions.RevokeAll"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims, ok := r.Context().Value(auth.UserKey).(*token.Claims)
+		if !ok || claims == nil {
+			log.Error("failed to get user claims")
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, response.Error("unauthorized"))
+			return
+		}
+
+		if err := store.RevokeAllSessions(claims.UserID); err != nil {
+			log.Error("failed to revoke sessions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"status": "logged_out_everywhere",
+		})
+	}
+}
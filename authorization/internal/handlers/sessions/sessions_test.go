@@ -0,0 +1,169 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/storage/cache"
+	"mentorlink/pkg/middleware/auth"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/require"
+)
+
+func withClaims(req *http.Request, claims *token.Claims) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), auth.UserKey, claims))
+}
+
+func TestListHandler(t *testing.T) {
+	claims := &token.Claims{UserID: 1, FamilyID: "current"}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	cases := []struct {
+		name           string
+		claims         *token.Claims
+		sessions       []model.Session
+		mockError      error
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:   "Success",
+			claims: claims,
+			sessions: []model.Session{
+				{ID: "current", UserID: 1, UserAgent: "firefox", IP: "10.0.0.1", CreatedAt: now, LastUsedAt: now},
+				{ID: "other", UserID: 1, UserAgent: "curl", IP: "10.0.0.2", CreatedAt: now, LastUsedAt: now},
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"current", "other"},
+		},
+		{
+			name:           "No claims",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Store error",
+			claims:         claims,
+			mockError:      errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mocks.NewSessionStore(t)
+			if tc.claims != nil {
+				store.On("GetSessions", tc.claims.UserID).Return(tc.sessions, tc.mockError)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/sessions", nil)
+			if tc.claims != nil {
+				req = withClaims(req, tc.claims)
+			}
+			rr := httptest.NewRecorder()
+			List(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.expectedStatus == http.StatusOK {
+				var resp struct {
+					Sessions []sessionView `json:"sessions"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Len(t, resp.Sessions, len(tc.expectedIDs))
+				for i, id := range tc.expectedIDs {
+					require.Equal(t, id, resp.Sessions[i].ID)
+					require.Equal(t, id == claims.FamilyID, resp.Sessions[i].Current)
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeHandler(t *testing.T) {
+	claims := &token.Claims{UserID: 1, FamilyID: "current"}
+
+	cases := []struct {
+		name           string
+		sessionID      string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "Success",
+			sessionID:      "other",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Not found",
+			sessionID:      "foreign",
+			mockError:      cache.ErrSessionNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Store error",
+			sessionID:      "other",
+			mockError:      errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mocks.NewSessionStore(t)
+			store.On("RevokeSession", claims.UserID, tc.sessionID).Return(tc.mockError)
+
+			router := chi.NewRouter()
+			router.Delete("/auth/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+				Revoke(slogdiscard.NewDiscardLogger(), store).ServeHTTP(w, withClaims(r, claims))
+			})
+
+			req := httptest.NewRequest(http.MethodDelete, "/auth/sessions/"+tc.sessionID, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRevokeAllHandler(t *testing.T) {
+	claims := &token.Claims{UserID: 1, FamilyID: "current"}
+
+	cases := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "Success",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Store error",
+			mockError:      errors.New("redis error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mocks.NewSessionStore(t)
+			store.On("RevokeAllSessions", claims.UserID).Return(tc.mockError)
+
+			req := withClaims(httptest.NewRequest(http.MethodDelete, "/auth/sessions", nil), claims)
+			rr := httptest.NewRecorder()
+			RevokeAll(slogdiscard.NewDiscardLogger(), store).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package verify
+
+import (
+	"errors"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/requests"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/validate"
+	"mentorlink/internal/lib/verification"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+// ResendInterval ограничивает частоту повторной отправки письма на один адрес
+var ResendInterval = time.Minute
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=UserStore
+type UserStore interface {
+	GetByID(id int64) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	MarkVerified(userID int64, outbox []model.OutboxMessage) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=TokenParser
+type TokenParser interface {
+	ParseToken(tokenStr string) (*token.Claims, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=Throttler
+type Throttler interface {
+	Throttle(key string, window time.Duration) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=VerificationSender
+type VerificationSender interface {
+	SendVerification(u *model.User) error
+}
+
+func Verify(log *slog.Logger, tokenParser TokenParser, users UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.verify.Verify"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.VerifyEmail
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		claims, err := tokenParser.ParseToken(req.Token)
+		if err != nil || claims.TokenType != verification.TokenType {
+			log.Warn("invalid verification token")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid or expired token"))
+			return
+		}
+
+		user, err := users.GetByID(claims.UserID)
+		if err != nil {
+			if errors.Is(err, db.ErrUserNotFound) {
+				log.Warn("user from verification token not found", slog.Int64("user_id", claims.UserID))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid or expired token"))
+				return
+			}
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		if user.IsVerified() {
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, map[string]any{"status": "already verified"})
+			return
+		}
+
+		// Ментор появляется в каталоге только после подтверждения email;
+		// активация доставляется через outbox после создания записи ментора
+		var events []model.OutboxMessage
+		if user.Role == model.RoleMentor && !user.IsDisabled() {
+			events = append(events, outbox.ActivateMentorMessage(user.ID, user.Email))
+		}
+
+		if err := users.MarkVerified(user.ID, events); err != nil {
+			log.Error("failed to mark user verified", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		log.Info("email verified", slog.Int64("user_id", user.ID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "email verified"})
+	}
+}
+
+func Resend(log *slog.Logger, users UserStore, throttler Throttler, sender VerificationSender) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.verify.Resend"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req requests.ResendVerification
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := validate.IsValid(req); err != nil {
+			log.Warn("request is not valid", slog.String("valid", "false"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request"))
+			return
+		}
+
+		allowed, err := throttler.Throttle("verify_resend:"+req.Email, ResendInterval)
+		if err != nil {
+			log.Error("failed to check resend throttle", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+		if !allowed {
+			log.Warn("verification resend throttled", slog.String("email", req.Email))
+			w.Header().Set("Retry-After", strconv.Itoa(int(ResendInterval.Seconds())))
+			render.Status(r, http.StatusTooManyRequests)
+			render.JSON(w, r, response.Error("too many requests"))
+			return
+		}
+
+		user, err := users.GetByEmail(req.Email)
+		if err != nil && !errors.Is(err, db.ErrUserNotFound) {
+			log.Error("failed to get user", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+			return
+		}
+
+		// Не раскрываем, существует ли аккаунт и подтверждён ли он
+		if user == nil || user.IsVerified() {
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, map[string]any{"status": "verification email sent"})
+			return
+		}
+
+		if err := sender.SendVerification(user); err != nil {
+			log.Error("failed to send verification email", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to send email"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{"status": "verification email sent"})
+	}
+}
@@ -0,0 +1,229 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/domain/response"
+	"mentorlink/internal/handlers/mocks"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"mentorlink/internal/lib/verification"
+	"mentorlink/internal/outbox"
+	"mentorlink/internal/storage/db"
+	"mentorlink/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyHandler(t *testing.T) {
+	verifiedAt := time.Now()
+	verifyClaims := &token.Claims{UserID: 1, TokenType: verification.TokenType}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.TokenMn, *mocks.UserCreater)
+		expectedStatus int
+		respError      string
+	}{
+		{
+			name: "Success",
+			body: `{"token": "verify-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "verify-token").Return(verifyClaims, nil)
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, Email: "user@mail.com", Role: "user"}, nil)
+				u.On("MarkVerified", int64(1), []model.OutboxMessage(nil)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Mentor is activated",
+			body: `{"token": "verify-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "verify-token").Return(verifyClaims, nil)
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, Email: "mentor@mail.com", Role: model.RoleMentor}, nil)
+				u.On("MarkVerified", int64(1), []model.OutboxMessage{outbox.ActivateMentorMessage(1, "mentor@mail.com")}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Already verified",
+			body: `{"token": "verify-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "verify-token").Return(verifyClaims, nil)
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, VerifiedAt: &verifiedAt}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid token",
+			body: `{"token": "bad-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "bad-token").Return(nil, errors.New("parse error"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid or expired token",
+		},
+		{
+			name: "Wrong token type",
+			body: `{"token": "access-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "access-token").Return(&token.Claims{UserID: 1, TokenType: "access"}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid or expired token",
+		},
+		{
+			name: "User not found",
+			body: `{"token": "verify-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "verify-token").Return(verifyClaims, nil)
+				u.On("GetByID", int64(1)).Return(nil, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid or expired token",
+		},
+		{
+			name: "Mark verified error",
+			body: `{"token": "verify-token"}`,
+			mockSetup: func(tm *mocks.TokenMn, u *mocks.UserCreater) {
+				tm.On("ParseToken", "verify-token").Return(verifyClaims, nil)
+				u.On("GetByID", int64(1)).Return(&model.User{ID: 1, Role: "user"}, nil)
+				u.On("MarkVerified", int64(1), []model.OutboxMessage(nil)).Return(errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name:           "Empty token",
+			body:           `{"token": ""}`,
+			mockSetup:      func(tm *mocks.TokenMn, u *mocks.UserCreater) {},
+			expectedStatus: http.StatusBadRequest,
+			respError:      "invalid request",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenMn := mocks.NewTokenMn(t)
+			users := mocks.NewUserCreater(t)
+			tc.mockSetup(tokenMn, users)
+
+			handler := Verify(slogdiscard.NewDiscardLogger(), tokenMn, users)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/verify", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
+
+func TestResendHandler(t *testing.T) {
+	verifiedAt := time.Now()
+	pending := &model.User{ID: 1, Email: "valid@mail.com", Role: "user"}
+
+	cases := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.UserCreater, *mocks.RedisRepo, *mocks.VerificationSender)
+		expectedStatus int
+		respError      string
+		retryAfter     string
+	}{
+		{
+			name: "Success",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:valid@mail.com", ResendInterval).Return(true, nil)
+				u.On("GetByEmail", "valid@mail.com").Return(pending, nil)
+				s.On("SendVerification", pending).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Throttled",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:valid@mail.com", ResendInterval).Return(false, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			respError:      "too many requests",
+			retryAfter:     "60",
+		},
+		{
+			name: "Throttle error",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:valid@mail.com", ResendInterval).Return(false, errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "internal error",
+		},
+		{
+			name: "Unknown email",
+			body: `{"email": "unknown@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:unknown@mail.com", ResendInterval).Return(true, nil)
+				u.On("GetByEmail", "unknown@mail.com").Return(nil, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Already verified",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:valid@mail.com", ResendInterval).Return(true, nil)
+				u.On("GetByEmail", "valid@mail.com").Return(&model.User{ID: 1, VerifiedAt: &verifiedAt}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Send error",
+			body: `{"email": "valid@mail.com"}`,
+			mockSetup: func(u *mocks.UserCreater, r *mocks.RedisRepo, s *mocks.VerificationSender) {
+				r.On("Throttle", "verify_resend:valid@mail.com", ResendInterval).Return(true, nil)
+				u.On("GetByEmail", "valid@mail.com").Return(pending, nil)
+				s.On("SendVerification", pending).Return(errors.New("smtp error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			respError:      "failed to send email",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			users := mocks.NewUserCreater(t)
+			redisMock := mocks.NewRedisRepo(t)
+			sender := mocks.NewVerificationSender(t)
+			tc.mockSetup(users, redisMock, sender)
+
+			handler := Resend(slogdiscard.NewDiscardLogger(), users, redisMock, sender)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/verify/resend", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expectedStatus, rr.Code)
+			require.Equal(t, tc.retryAfter, rr.Header().Get("Retry-After"))
+
+			if tc.respError != "" {
+				var resp response.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package kafka связывает сервис авторизации с остальными сервисами через Kafka:
+// публикует пользовательские события из outbox и собирает части выгрузок данных,
+// которые присылают review и mentor.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/storage/cache"
+
+	"github.com/IBM/sarama"
+)
+
+type Config struct {
+	Brokers          []string `env:"KAFKA_BROKERS" env-separator:"," env-default:"kafka:9092"`
+	UserEventsTopic  string   `env:"KAFKA_USER_EVENTS_TOPIC" env-default:"user-events"`
+	ExportPartsTopic string   `env:"KAFKA_EXPORT_PARTS_TOPIC" env-default:"user-export-parts"`
+	GroupID          string   `env:"KAFKA_GROUP_ID" env-default:"auth-service"`
+}
+
+type Producer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewProducer(cfg Config) (*Producer, error) {
+	config := sarama.NewConfig()
+
+	// Гарантия доставки, как у продюсера отзывов
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 3
+	config.Producer.Idempotent = true
+	config.Net.MaxOpenRequests = 1
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	return &Producer{producer: producer, topic: cfg.UserEventsTopic}, nil
+}
+
+// PublishUserEvent отправляет событие синхронно; ctx не прерывает отправку,
+// sarama ограничивает её своими таймаутами
+func (p *Producer) PublishUserEvent(_ context.Context, key string, value []byte) error {
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) Close() error {
+	return p.producer.Close()
+}
+
+// ExportPart часть выгрузки данных пользователя от одного сервиса
+type ExportPart struct {
+	ExportID string          `json:"export_id"`
+	UserID   int64           `json:"user_id"`
+	Service  string          `json:"service"`
+	Data     json.RawMessage `json:"data"`
+}
+
+type ExportStore interface {
+	SaveExportPart(exportID string, userID int64, service string, data []byte) error
+}
+
+type ExportConsumer struct {
+	group   sarama.ConsumerGroup
+	topic   string
+	handler *exportHandler
+	log     *slog.Logger
+}
+
+func NewExportConsumer(cfg Config, store ExportStore, log *slog.Logger) (*ExportConsumer, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_8_0_0
+	// Части выгрузки, пришедшие пока сервис лежал, тоже нужны
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	group, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	log = log.With(slog.String("component", "kafka.export_consumer"))
+	return &ExportConsumer{
+		group:   group,
+		topic:   cfg.ExportPartsTopic,
+		handler: &exportHandler{store: store, log: log},
+		log:     log,
+	}, nil
+}
+
+// Run читает топик частей выгрузки, пока не отменён ctx
+func (c *ExportConsumer) Run(ctx context.Context) {
+	for {
+		if err := c.group.Consume(ctx, []string{c.topic}, c.handler); err != nil {
+			c.log.Error("failed to consume messages", sl.Err(err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (c *ExportConsumer) Close() error {
+	return c.group.Close()
+}
+
+type exportHandler struct {
+	store ExportStore
+	log   *slog.Logger
+}
+
+func (h *exportHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *exportHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *exportHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		if err := h.handle(msg.Value); err != nil {
+			// Без коммита оффсета сообщение придёт снова после перебалансировки
+			h.log.Error("failed to save export part", sl.Err(err))
+			continue
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+func (h *exportHandler) handle(value []byte) error {
+	var part ExportPart
+	if err := json.Unmarshal(value, &part); err != nil {
+		// Повтор не поможет, сообщение пропускаем
+		h.log.Error("failed to unmarshal export part", sl.Err(err))
+		return nil
+	}
+
+	err := h.store.SaveExportPart(part.ExportID, part.UserID, part.Service, part.Data)
+	if errors.Is(err, cache.ErrExportNotFound) {
+		// Выгрузка истекла или часть пришла не от того пользователя
+		h.log.Warn("export part for unknown export", slog.String("export_id", part.ExportID), slog.String("service", part.Service))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("export %s, service %s: %w", part.ExportID, part.Service, err)
+	}
+	h.log.Info("export part saved", slog.String("export_id", part.ExportID), slog.String("service", part.Service))
+	return nil
+}
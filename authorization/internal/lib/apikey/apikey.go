@@ -0,0 +1,68 @@
+// Package apikey выпускает и разбирает персональные API ключи вида ml_<prefix>_<secret>
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"mentorlink/pkg/token"
+	"slices"
+	"strings"
+)
+
+const (
+	// Scheme схема заголовка Authorization: ApiKey <key>
+	Scheme = "ApiKey"
+
+	keyPrefix   = "ml_"
+	prefixBytes = 5  // 8 символов base32, достаточно, чтобы различать ключи в списке
+	secretBytes = 32 // 256 бит, перебор невозможен, поэтому хватает sha256 без соли
+)
+
+var prefixEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generate возвращает новый ключ и его публичный префикс
+func Generate() (key, prefix string, err error) {
+	p := make([]byte, prefixBytes)
+	s := make([]byte, secretBytes)
+	if _, err := rand.Read(p); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+	if _, err := rand.Read(s); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+
+	prefix = keyPrefix + strings.ToLower(prefixEncoding.EncodeToString(p))
+	key = prefix + "_" + hex.EncodeToString(s)
+	return key, prefix, nil
+}
+
+// LooksValid отсекает строки, которые не могут быть нашим ключом, до похода в базу
+func LooksValid(key string) bool {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return false
+	}
+	parts := strings.Split(key, "_")
+	return len(parts) == 3 && len(parts[1]) == 8 && len(parts[2]) == hex.EncodedLen(secretBytes)
+}
+
+// AllowedScopes права роли, которые можно выдать ключу.
+// Администрирование пользователей доступно только из интерактивной сессии
+func AllowedScopes(role string) []string {
+	scopes := slices.Clone(token.RoleScopes[role])
+	return slices.DeleteFunc(scopes, func(s string) bool { return s == token.ScopeUserAdmin })
+}
+
+// EffectiveScope пересечение прав ключа с тем, что роль владельца разрешает сейчас:
+// после понижения роли старый ключ не сохраняет лишних прав
+func EffectiveScope(keyScope, role string) string {
+	allowed := AllowedScopes(role)
+	var scopes []string
+	for _, s := range strings.Fields(keyScope) {
+		if slices.Contains(allowed, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
@@ -0,0 +1,56 @@
+// Package audit пишет события аутентификации в журнал auth_events
+package audit
+
+import (
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/lib/logger/sl"
+	"mentorlink/internal/lib/realip"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+const maxUserAgentLen = 512
+
+type Store interface {
+	SaveAuthEvent(e *model.AuthEvent) error
+}
+
+type Recorder struct {
+	store Store
+	log   *slog.Logger
+}
+
+func New(store Store, log *slog.Logger) *Recorder {
+	return &Recorder{store: store, log: log}
+}
+
+// Record дополняет событие данными запроса и сохраняет его. Ошибка записи
+// только логируется: недоступный журнал не должен ломать вход
+func (a *Recorder) Record(r *http.Request, e model.AuthEvent) {
+	e.IP = realip.FromRequest(r)
+	e.UserAgent = truncate(r.UserAgent(), maxUserAgentLen)
+	e.RequestID = middleware.GetReqID(r.Context())
+
+	if err := a.store.SaveAuthEvent(&e); err != nil {
+		a.log.Error("failed to save auth event",
+			slog.String("type", e.Type),
+			slog.Int64("user_id", e.UserID),
+			slog.String("outcome", e.Outcome),
+			slog.String("request_id", e.RequestID),
+			sl.Err(err),
+		)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Не режем многобайтовый символ пополам
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	saved []model.AuthEvent
+	err   error
+}
+
+func (s *fakeStore) SaveAuthEvent(e *model.AuthEvent) error {
+	s.saved = append(s.saved, *e)
+	return s.err
+}
+
+func TestRecordFillsRequestData(t *testing.T) {
+	store := &fakeStore{}
+	rec := New(store, slogdiscard.NewDiscardLogger())
+
+	req := httptest.NewRequest("POST", "/auth/login", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	req.Header.Set("User-Agent", "test-agent")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "req-1"))
+
+	rec.Record(req, model.AuthEvent{Type: model.AuthEventLogin, UserID: 7, Outcome: model.OutcomeSuccess})
+
+	require.Equal(t, []model.AuthEvent{{
+		Type:      model.AuthEventLogin,
+		UserID:    7,
+		IP:        "10.0.0.1",
+		UserAgent: "test-agent",
+		RequestID: "req-1",
+		Outcome:   model.OutcomeSuccess,
+	}}, store.saved)
+}
+
+func TestRecordTruncatesUserAgent(t *testing.T) {
+	store := &fakeStore{}
+	req := httptest.NewRequest("POST", "/auth/login", nil)
+	// Многобайтовый символ на границе не должен разрезаться
+	req.Header.Set("User-Agent", strings.Repeat("a", maxUserAgentLen-1)+"я")
+
+	New(store, slogdiscard.NewDiscardLogger()).Record(req, model.AuthEvent{Type: model.AuthEventLogin})
+
+	require.Equal(t, strings.Repeat("a", maxUserAgentLen-1), store.saved[0].UserAgent)
+}
+
+func TestRecordStoreErrorIsNotFatal(t *testing.T) {
+	store := &fakeStore{err: errors.New("db error")}
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+
+	require.NotPanics(t, func() {
+		New(store, slogdiscard.NewDiscardLogger()).Record(req, model.AuthEvent{Type: model.AuthEventLogout})
+	})
+}
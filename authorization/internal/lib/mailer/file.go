@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer не отправляет письма, а дописывает их в файл (или только в лог, если путь пустой).
+// Используется локально вместо SMTP сервера.
+type FileMailer struct {
+	mu   sync.Mutex
+	from string
+	path string
+	log  *slog.Logger
+}
+
+func NewFileMailer(from, path string, log *slog.Logger) *FileMailer {
+	return &FileMailer{from: from, path: path, log: log}
+}
+
+func (m *FileMailer) Send(to, subject, body string) error {
+	const op = "lib.mailer.FileMailer.Send"
+
+	m.log.Info("mail sent",
+		slog.String("component", "mailer"),
+		slog.String("to", to),
+		slog.String("subject", subject),
+	)
+
+	if m.path == "" {
+		m.log.Debug("mail body", slog.String("to", to), slog.String("body", body))
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	entry := fmt.Sprintf("--- %s ---\r\n%s\r\n", time.Now().Format(time.RFC3339), buildMessage(m.from, to, subject, body))
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
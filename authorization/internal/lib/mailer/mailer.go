@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type Config struct {
+	Driver       string `env:"MAIL_DRIVER" env-default:"log"`
+	From         string `env:"MAIL_FROM" env-default:"no-reply@mentorlink.local"`
+	FilePath     string `env:"MAIL_FILE_PATH" env-default:"./mail.log"`
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     string `env:"SMTP_PORT" env-default:"587"`
+	SMTPUser     string `env:"SMTP_USER"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+}
+
+// New выбирает реализацию по MAIL_DRIVER: smtp для боевого окружения, file/log для локального
+func New(cfg Config, log *slog.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("mailer: SMTP_HOST is required for smtp driver")
+		}
+		return NewSMTPMailer(cfg), nil
+	case DriverFile:
+		return NewFileMailer(cfg.From, cfg.FilePath, log), nil
+	case DriverLog:
+		return NewFileMailer(cfg.From, "", log), nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,158 @@
+// Package oidc вход через внешних OpenID Connect провайдеров: authorization code + PKCE
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrNonceMismatch   = errors.New("id token nonce mismatch")
+	ErrNoIDToken       = errors.New("token response has no id_token")
+)
+
+type ProviderConfig struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"` // openid добавляется всегда
+}
+
+// Providers список провайдеров из OIDC_PROVIDERS в виде JSON массива
+type Providers []ProviderConfig
+
+// SetValue вызывается cleanenv при чтении переменной окружения
+func (p *Providers) SetValue(s string) error {
+	if strings.TrimSpace(s) == "" {
+		*p = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(s), p)
+}
+
+type Config struct {
+	Providers Providers `env:"OIDC_PROVIDERS"`
+	// Публичный адрес, на который провайдер вернёт пользователя: <base>/auth/oidc/<name>/callback
+	RedirectBaseURL string        `env:"OIDC_REDIRECT_BASE_URL" env-default:"http://localhost:8080"`
+	StateTTL        time.Duration `env:"OIDC_STATE_TTL" env-default:"10m"`
+}
+
+// Claims то, что сервису нужно из id_token
+type Claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+type Provider struct {
+	Name     string
+	oauth    oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// AuthCodeURL адрес страницы входа провайдера с PKCE challenge (S256) и nonce
+func (p *Provider) AuthCodeURL(state, codeVerifier, nonce string) string {
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier), gooidc.Nonce(nonce))
+}
+
+// Exchange меняет code на токены и проверяет id_token: подпись, issuer, audience, срок и nonce
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	tok, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrNoIDToken
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	var claims Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("parse id token claims: %w", err)
+	}
+	return &claims, nil
+}
+
+// Registry лениво загружает discovery документы: недоступный провайдер не мешает старту сервиса
+type Registry struct {
+	cfg       Config
+	configs   map[string]ProviderConfig
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+func NewRegistry(cfg Config) (*Registry, error) {
+	configs := make(map[string]ProviderConfig, len(cfg.Providers))
+	for _, pc := range cfg.Providers {
+		if pc.Name == "" || pc.Issuer == "" || pc.ClientID == "" {
+			return nil, fmt.Errorf("oidc provider %q: name, issuer and client_id are required", pc.Name)
+		}
+		if _, ok := configs[pc.Name]; ok {
+			return nil, fmt.Errorf("oidc provider %q is configured twice", pc.Name)
+		}
+		configs[pc.Name] = pc
+	}
+	return &Registry{
+		cfg:       cfg,
+		configs:   configs,
+		providers: make(map[string]*Provider, len(configs)),
+	}, nil
+}
+
+func (r *Registry) Provider(ctx context.Context, name string) (*Provider, error) {
+	pc, ok := r.configs[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.providers[name]; ok {
+		return p, nil
+	}
+
+	discovered, err := gooidc.NewProvider(ctx, pc.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover oidc provider %q: %w", name, err)
+	}
+
+	scopes := append([]string{gooidc.ScopeOpenID}, pc.Scopes...)
+	if len(pc.Scopes) == 0 {
+		scopes = append(scopes, "email", "profile")
+	}
+
+	p := &Provider{
+		Name: name,
+		oauth: oauth2.Config{
+			ClientID:     pc.ClientID,
+			ClientSecret: pc.ClientSecret,
+			Endpoint:     discovered.Endpoint(),
+			RedirectURL:  strings.TrimSuffix(r.cfg.RedirectBaseURL, "/") + "/auth/oidc/" + name + "/callback",
+			Scopes:       scopes,
+		},
+		verifier: discovered.Verifier(&gooidc.Config{ClientID: pc.ClientID}),
+	}
+	r.providers[name] = p
+	return p, nil
+}
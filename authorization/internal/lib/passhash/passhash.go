@@ -0,0 +1,118 @@
+// Package passhash хэширует пароли Argon2id в формате PHC
+// ($argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>) и проверяет старые bcrypt хэши.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrUnknownFormat = errors.New("unknown password hash format")
+)
+
+const (
+	saltLen = 16
+	keyLen  = 32
+)
+
+// Config параметры Argon2id, по умолчанию рекомендации OWASP
+type Config struct {
+	Memory      uint32 `env:"ARGON2_MEMORY_KIB" env-default:"65536"`
+	Iterations  uint32 `env:"ARGON2_ITERATIONS" env-default:"3"`
+	Parallelism uint8  `env:"ARGON2_PARALLELISM" env-default:"2"`
+}
+
+type Hasher struct {
+	cfg Config
+}
+
+func New(cfg Config) *Hasher {
+	return &Hasher{cfg: cfg}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("passhash: generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.cfg.Iterations, h.cfg.Memory, h.cfg.Parallelism, keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.cfg.Memory, h.cfg.Iterations, h.cfg.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify сверяет пароль с хэшем. rehash=true значит, что хэш устарел (bcrypt или
+// другие параметры Argon2id) и после успешного входа его стоит пересчитать через Hash
+func (h *Hasher) Verify(password, encoded string) (rehash bool, err error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, ErrMismatch
+		}
+		if err != nil {
+			return false, fmt.Errorf("passhash: %w", err)
+		}
+		return true, nil
+	}
+
+	p, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, ErrMismatch
+	}
+
+	return p != h.cfg || len(salt) != saltLen || len(key) != keyLen, nil
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func decode(encoded string) (Config, []byte, []byte, error) {
+	var p Config
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrUnknownFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrUnknownFormat
+	}
+
+	return p, salt, key, nil
+}
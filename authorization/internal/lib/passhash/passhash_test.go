@@ -0,0 +1,69 @@
+package passhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testConfig = Config{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashAndVerify(t *testing.T) {
+	h := New(testConfig)
+
+	encoded, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"), encoded)
+
+	rehash, err := h.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	require.False(t, rehash)
+
+	_, err = h.Verify("wrong horse", encoded)
+	require.ErrorIs(t, err, ErrMismatch)
+
+	// Соль случайная, одинаковые пароли дают разные хэши
+	other, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	require.NotEqual(t, encoded, other)
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	h := New(testConfig)
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret1"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	rehash, err := h.Verify("secret1", string(legacy))
+	require.NoError(t, err)
+	require.True(t, rehash)
+
+	_, err = h.Verify("secret2", string(legacy))
+	require.ErrorIs(t, err, ErrMismatch)
+}
+
+func TestVerifyOutdatedParams(t *testing.T) {
+	encoded, err := New(testConfig).Hash("secret1")
+	require.NoError(t, err)
+
+	rehash, err := New(Config{Memory: 2048, Iterations: 1, Parallelism: 1}).Verify("secret1", encoded)
+	require.NoError(t, err)
+	require.True(t, rehash)
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	h := New(testConfig)
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+	} {
+		_, err := h.Verify("secret1", encoded)
+		require.ErrorIs(t, err, ErrUnknownFormat, encoded)
+	}
+}
@@ -0,0 +1,118 @@
+// Package passpolicy проверяет новые пароли: длину и наличие в списке утёкших паролей.
+//
+// Список хранится локально в формате диапазонов Have I Been Pwned: строки
+// "<SHA-1 в hex>[:<count>]", пустые строки и строки с # пропускаются. В памяти
+// хэши лежат по 5-символьным префиксам, как в k-anonymity API, чтобы список
+// можно было заменить удалённым источником без изменения проверки.
+package passpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrTooShort = errors.New("password is too short")
+	ErrTooLong  = errors.New("password is too long")
+	ErrBreached = errors.New("password has appeared in a data breach, choose another one")
+)
+
+const prefixLen = 5
+
+type Config struct {
+	MinLength int `env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	MaxLength int `env:"PASSWORD_MAX_LENGTH" env-default:"128"`
+
+	// Пустой путь отключает проверку по списку утёкших паролей
+	BreachedListPath string `env:"PASSWORD_BREACHED_LIST"`
+}
+
+type Policy struct {
+	minLength int
+	maxLength int
+	breached  map[string]map[string]struct{}
+}
+
+func New(cfg Config) (*Policy, error) {
+	const op = "lib.passpolicy.New"
+
+	p := &Policy{minLength: cfg.MinLength, maxLength: cfg.MaxLength}
+	if cfg.BreachedListPath == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(cfg.BreachedListPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	if p.breached, err = readList(f); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, cfg.BreachedListPath, err)
+	}
+	return p, nil
+}
+
+// Breached число хэшей в загруженном списке
+func (p *Policy) Breached() int {
+	n := 0
+	for _, suffixes := range p.breached {
+		n += len(suffixes)
+	}
+	return n
+}
+
+// Check возвращает ошибку, текст которой можно показать пользователю
+func (p *Policy) Check(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < p.minLength {
+		return fmt.Errorf("%w: minimum %d characters", ErrTooShort, p.minLength)
+	}
+	if p.maxLength > 0 && n > p.maxLength {
+		return fmt.Errorf("%w: maximum %d characters", ErrTooLong, p.maxLength)
+	}
+
+	if p.breached != nil {
+		sum := sha1.Sum([]byte(password))
+		h := strings.ToUpper(hex.EncodeToString(sum[:]))
+		if _, ok := p.breached[h[:prefixLen]][h[prefixLen:]]; ok {
+			return ErrBreached
+		}
+	}
+	return nil
+}
+
+func readList(r io.Reader) (map[string]map[string]struct{}, error) {
+	list := make(map[string]map[string]struct{})
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		h, _, _ := strings.Cut(s, ":")
+		h = strings.ToUpper(h)
+		if _, err := hex.DecodeString(h); err != nil || len(h) != 2*sha1.Size {
+			return nil, fmt.Errorf("line %d: invalid sha1 %q", line, h)
+		}
+
+		bucket, ok := list[h[:prefixLen]]
+		if !ok {
+			bucket = make(map[string]struct{})
+			list[h[:prefixLen]] = bucket
+		}
+		bucket[h[prefixLen:]] = struct{}{}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,16 @@
+package realip
+
+import (
+	"net"
+	"net/http"
+)
+
+// FromRequest возвращает IP клиента без порта.
+// RemoteAddr уже переписан middleware.RealIP, если запрос пришёл через gateway.
+func FromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,12 @@
+package secret
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Hash возвращает sha256 одноразового токена, чтобы в хранилище не лежали сами токены
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package totp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateRecoveryCodes возвращает n одноразовых кодов вида xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("totp: generate recovery code: %w", err)
+		}
+		h := hex.EncodeToString(b)
+		codes = append(codes, h[:5]+"-"+h[5:])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode приводит введённый пользователем код к виду, в котором он хэшировался
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) == 10 && !strings.Contains(code, "-") {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
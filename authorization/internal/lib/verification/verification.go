@@ -0,0 +1,51 @@
+package verification
+
+import (
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"net/url"
+	"time"
+)
+
+const TokenType = "email_verify"
+
+var TokenTTL = 24 * time.Hour
+
+type TokenMn interface {
+	GenerateToken(userID int64, role string, ttl time.Duration, tokenType, familyID string) (string, string, error)
+}
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// Sender отправляет пользователю ссылку с подписанным токеном подтверждения email
+type Sender struct {
+	tokenMn TokenMn
+	mailer  Mailer
+	appURL  string
+}
+
+func NewSender(tokenMn TokenMn, mailer Mailer, appURL string) *Sender {
+	return &Sender{tokenMn: tokenMn, mailer: mailer, appURL: appURL}
+}
+
+func (s *Sender) SendVerification(u *model.User) error {
+	const op = "lib.verification.SendVerification"
+
+	token, _, err := s.tokenMn.GenerateToken(u.ID, u.Role, TokenTTL, TokenType, "")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"Добро пожаловать в MentorLink!\n\nПодтвердите email, перейдя по ссылке:\n%s\n\nСсылка действует %d часа.",
+		link, int(TokenTTL.Hours()),
+	)
+
+	if err := s.mailer.Send(u.Email, "MentorLink: подтверждение email", body); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
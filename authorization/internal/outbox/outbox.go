@@ -0,0 +1,225 @@
+// Package outbox доставляет в другие сервисы изменения, записанные вместе с пользователем
+// в одной транзакции: события ментора идут в сервис менторов по gRPC, события
+// пользователя публикуются в Kafka. Relay повторяет доставку до успеха, обработчики
+// на стороне получателей идемпотентны, поэтому повтор уже применённого сообщения безопасен.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/lib/logger/sl"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	KindNewMentor        = "mentor.new"
+	KindActivateMentor   = "mentor.activate"
+	KindDeactivateMentor = "mentor.deactivate"
+
+	KindUserDeleted     = "user.deleted"
+	KindExportRequested = "user.export_requested"
+	KindEmailChanged    = "user.email_changed"
+
+	// KindIDBackfill связывает записи, которые review и mentor создали по email, с id пользователя.
+	// Пишется миграцией 000010 для всех существующих пользователей
+	KindIDBackfill = "user.id_backfill"
+)
+
+// mentorPayload mentor_id нет в сообщениях, записанных до перехода на id
+type mentorPayload struct {
+	MentorID    int64  `json:"mentor_id,omitempty"`
+	MentorEmail string `json:"mentor_email"`
+	Contact     string `json:"contact,omitempty"`
+}
+
+func NewMentorMessage(mentorID int64, mentorEmail, contact string) model.OutboxMessage {
+	payload, _ := json.Marshal(mentorPayload{MentorID: mentorID, MentorEmail: mentorEmail, Contact: contact})
+	return model.OutboxMessage{Kind: KindNewMentor, Payload: payload}
+}
+
+func ActivateMentorMessage(mentorID int64, mentorEmail string) model.OutboxMessage {
+	payload, _ := json.Marshal(mentorPayload{MentorID: mentorID, MentorEmail: mentorEmail})
+	return model.OutboxMessage{Kind: KindActivateMentor, Payload: payload}
+}
+
+func DeactivateMentorMessage(mentorID int64, mentorEmail string) model.OutboxMessage {
+	payload, _ := json.Marshal(mentorPayload{MentorID: mentorID, MentorEmail: mentorEmail})
+	return model.OutboxMessage{Kind: KindDeactivateMentor, Payload: payload}
+}
+
+// UserEvent сообщение в топике пользовательских событий; review и mentor по нему
+// удаляют данные пользователя, присылают свою часть выгрузки или переносят данные
+// ментора на новый email
+type UserEvent struct {
+	Type     string `json:"type"`
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	ExportID string `json:"export_id,omitempty"`
+	OldEmail string `json:"old_email,omitempty"`
+}
+
+func UserDeletedMessage(u *model.User) model.OutboxMessage {
+	return userMessage(UserEvent{Type: KindUserDeleted, UserID: u.ID, Email: u.Email, Role: u.Role})
+}
+
+func ExportRequestedMessage(exportID string, u *model.User) model.OutboxMessage {
+	return userMessage(UserEvent{Type: KindExportRequested, UserID: u.ID, Email: u.Email, Role: u.Role, ExportID: exportID})
+}
+
+// EmailChangedMessage u - пользователь до смены email
+func EmailChangedMessage(u *model.User, newEmail string) model.OutboxMessage {
+	return userMessage(UserEvent{Type: KindEmailChanged, UserID: u.ID, Email: newEmail, Role: u.Role, OldEmail: u.Email})
+}
+
+func userMessage(e UserEvent) model.OutboxMessage {
+	payload, _ := json.Marshal(e)
+	return model.OutboxMessage{Kind: e.Type, Payload: payload}
+}
+
+type Store interface {
+	ClaimOutbox(limit int, lease time.Duration) ([]model.OutboxMessage, error)
+	MarkOutboxProcessed(id int64) error
+	MarkOutboxFailed(id int64, lastError string, nextAttempt time.Time) error
+}
+
+type MentorService interface {
+	NewMentor(ctx context.Context, mentorID int64, mentorEmail, contact string) error
+	ActivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	DeactivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+}
+
+// EventPublisher публикует пользовательские события; key задаёт партицию,
+// так что события одного пользователя обрабатываются по порядку
+type EventPublisher interface {
+	PublishUserEvent(ctx context.Context, key string, value []byte) error
+}
+
+type Config struct {
+	Interval    time.Duration `env:"OUTBOX_INTERVAL" env-default:"2s"`
+	BatchSize   int           `env:"OUTBOX_BATCH_SIZE" env-default:"50"`
+	Lease       time.Duration `env:"OUTBOX_LEASE" env-default:"1m"`
+	BaseBackoff time.Duration `env:"OUTBOX_BASE_BACKOFF" env-default:"2s"`
+	MaxBackoff  time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"5m"`
+	CallTimeout time.Duration `env:"OUTBOX_CALL_TIMEOUT" env-default:"5s"`
+}
+
+type Relay struct {
+	log     *slog.Logger
+	store   Store
+	mentors MentorService
+	events  EventPublisher
+	cfg     Config
+	now     func() time.Time
+}
+
+func NewRelay(log *slog.Logger, store Store, mentors MentorService, events EventPublisher, cfg Config) *Relay {
+	return &Relay{
+		log:     log.With(slog.String("component", "outbox.relay")),
+		store:   store,
+		mentors: mentors,
+		events:  events,
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+// Run обрабатывает outbox каждые Interval, пока не отменён ctx
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessBatch(ctx); err != nil {
+			r.log.Error("failed to process outbox", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch доставляет одну пачку сообщений и возвращает число успешно доставленных
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	const op = "outbox.Relay.ProcessBatch"
+
+	msgs, err := r.store.ClaimOutbox(r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	// Активация ментора имеет смысл только после его создания
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].ID < msgs[j].ID })
+
+	delivered := 0
+	for _, m := range msgs {
+		if err := r.deliver(ctx, m); err != nil {
+			next := r.now().Add(r.backoff(m.Attempts))
+			r.log.Warn("outbox delivery failed",
+				slog.Int64("id", m.ID),
+				slog.String("kind", m.Kind),
+				slog.Int("attempts", m.Attempts+1),
+				slog.Time("next_attempt_at", next),
+				sl.Err(err),
+			)
+			if err := r.store.MarkOutboxFailed(m.ID, err.Error(), next); err != nil {
+				return delivered, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+
+		if err := r.store.MarkOutboxProcessed(m.ID); err != nil {
+			return delivered, fmt.Errorf("%s: %w", op, err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (r *Relay) deliver(ctx context.Context, m model.OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.CallTimeout)
+	defer cancel()
+
+	switch m.Kind {
+	case KindUserDeleted, KindExportRequested, KindEmailChanged, KindIDBackfill:
+		var e UserEvent
+		if err := json.Unmarshal(m.Payload, &e); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return r.events.PublishUserEvent(ctx, strconv.FormatInt(e.UserID, 10), m.Payload)
+	}
+
+	var p mentorPayload
+	if err := json.Unmarshal(m.Payload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+
+	switch m.Kind {
+	case KindNewMentor:
+		return r.mentors.NewMentor(ctx, p.MentorID, p.MentorEmail, p.Contact)
+	case KindActivateMentor:
+		return r.mentors.ActivateMentor(ctx, p.MentorID, p.MentorEmail)
+	case KindDeactivateMentor:
+		return r.mentors.DeactivateMentor(ctx, p.MentorID, p.MentorEmail)
+	default:
+		return fmt.Errorf("unknown outbox kind %q", m.Kind)
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := 0; i < attempts; i++ {
+		d *= 2
+		if d >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,308 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/lib/logger/slogdiscard"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memStore повторяет семантику таблицы outbox: сообщение остаётся в очереди,
+// пока не отмечено обработанным, и не выдаётся раньше next_attempt_at
+type memStore struct {
+	now  func() time.Time
+	msgs []*storedMessage
+}
+
+type storedMessage struct {
+	model.OutboxMessage
+	nextAttempt time.Time
+	processed   bool
+	lastError   string
+}
+
+func (s *memStore) add(m model.OutboxMessage) {
+	m.ID = int64(len(s.msgs) + 1)
+	s.msgs = append(s.msgs, &storedMessage{OutboxMessage: m, nextAttempt: s.now()})
+}
+
+func (s *memStore) pending() []*storedMessage {
+	var res []*storedMessage
+	for _, m := range s.msgs {
+		if !m.processed {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func (s *memStore) ClaimOutbox(limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	var res []model.OutboxMessage
+	// Отдаём в обратном порядке, чтобы проверить сортировку в relay
+	for i := len(s.msgs) - 1; i >= 0 && len(res) < limit; i-- {
+		m := s.msgs[i]
+		if m.processed || m.nextAttempt.After(s.now()) {
+			continue
+		}
+		m.nextAttempt = s.now().Add(lease)
+		res = append(res, m.OutboxMessage)
+	}
+	return res, nil
+}
+
+func (s *memStore) MarkOutboxProcessed(id int64) error {
+	s.msgs[id-1].processed = true
+	return nil
+}
+
+func (s *memStore) MarkOutboxFailed(id int64, lastError string, nextAttempt time.Time) error {
+	m := s.msgs[id-1]
+	m.Attempts++
+	m.lastError = lastError
+	m.nextAttempt = nextAttempt
+	return nil
+}
+
+// fakeMentors имитирует сервис менторов, первые failures вызовов падают
+type fakeMentors struct {
+	failures int
+	calls    []string
+	mentors  map[int64]string
+	active   map[int64]bool
+}
+
+func newFakeMentors(failures int) *fakeMentors {
+	return &fakeMentors{failures: failures, mentors: map[int64]string{}, active: map[int64]bool{}}
+}
+
+func (f *fakeMentors) fail() bool {
+	if f.failures > 0 {
+		f.failures--
+		return true
+	}
+	return false
+}
+
+func (f *fakeMentors) NewMentor(_ context.Context, mentorID int64, mentorEmail, _ string) error {
+	f.calls = append(f.calls, KindNewMentor)
+	if f.fail() {
+		return errors.New("rpc error: code = Unavailable")
+	}
+	f.mentors[mentorID] = mentorEmail
+	return nil
+}
+
+func (f *fakeMentors) ActivateMentor(_ context.Context, mentorID int64, _ string) error {
+	f.calls = append(f.calls, KindActivateMentor)
+	if f.fail() {
+		return errors.New("rpc error: code = Unavailable")
+	}
+	if _, ok := f.mentors[mentorID]; !ok {
+		return errors.New("rpc error: code = NotFound")
+	}
+	f.active[mentorID] = true
+	return nil
+}
+
+func (f *fakeMentors) DeactivateMentor(_ context.Context, mentorID int64, _ string) error {
+	f.calls = append(f.calls, KindDeactivateMentor)
+	if f.fail() {
+		return errors.New("rpc error: code = Unavailable")
+	}
+	f.active[mentorID] = false
+	return nil
+}
+
+// fakePublisher запоминает опубликованные события, первые failures публикаций падают
+type fakePublisher struct {
+	failures int
+	keys     []string
+	events   []UserEvent
+}
+
+func (p *fakePublisher) PublishUserEvent(_ context.Context, key string, value []byte) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("kafka: client has run out of available brokers")
+	}
+	var e UserEvent
+	if err := json.Unmarshal(value, &e); err != nil {
+		return err
+	}
+	p.keys = append(p.keys, key)
+	p.events = append(p.events, e)
+	return nil
+}
+
+var testConfig = Config{
+	BatchSize:   10,
+	Lease:       time.Minute,
+	BaseBackoff: time.Second,
+	MaxBackoff:  10 * time.Second,
+	CallTimeout: time.Second,
+}
+
+func newTestRelay(store *memStore, mentors MentorService, clock *time.Time) *Relay {
+	r := NewRelay(slogdiscard.NewDiscardLogger(), store, mentors, &fakePublisher{}, testConfig)
+	r.now = func() time.Time { return *clock }
+	return r
+}
+
+func TestRelayRetriesAfterMentorServiceFailure(t *testing.T) {
+	clock := time.Now()
+	store := &memStore{now: func() time.Time { return clock }}
+	mentors := newFakeMentors(1)
+	relay := newTestRelay(store, mentors, &clock)
+
+	// Пользователь создан, сообщение записано в той же транзакции
+	store.add(NewMentorMessage(5, "mentor@mail.com", "@mentor"))
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+
+	// Сбой gRPC не теряет сообщение: оно ждёт повтора с backoff
+	pending := store.pending()
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.Contains(t, pending[0].lastError, "Unavailable")
+	require.Equal(t, clock.Add(testConfig.BaseBackoff), pending[0].nextAttempt)
+	require.NotContains(t, mentors.mentors, int64(5))
+
+	// До истечения backoff сообщение не выдаётся
+	delivered, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+
+	clock = clock.Add(testConfig.BaseBackoff)
+	delivered, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+
+	require.Empty(t, store.pending())
+	require.Equal(t, "mentor@mail.com", mentors.mentors[5])
+}
+
+func TestRelayActivatesAfterCreate(t *testing.T) {
+	clock := time.Now()
+	store := &memStore{now: func() time.Time { return clock }}
+	mentors := newFakeMentors(0)
+	relay := newTestRelay(store, mentors, &clock)
+
+	store.add(NewMentorMessage(5, "mentor@mail.com", ""))
+	store.add(ActivateMentorMessage(5, "mentor@mail.com"))
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+	require.Equal(t, []string{KindNewMentor, KindActivateMentor}, mentors.calls)
+	require.True(t, mentors.active[5])
+}
+
+func TestRelayDeliversLegacyMentorMessage(t *testing.T) {
+	store := &memStore{now: time.Now}
+	mentors := newFakeMentors(0)
+	relay := NewRelay(slogdiscard.NewDiscardLogger(), store, mentors, &fakePublisher{}, testConfig)
+
+	// Сообщение, записанное до перехода на id: сервис менторов найдёт ментора по email
+	store.add(model.OutboxMessage{Kind: KindNewMentor, Payload: []byte(`{"mentor_email":"mentor@mail.com"}`)})
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, "mentor@mail.com", mentors.mentors[0])
+}
+
+func TestRelayUnknownKindStaysPending(t *testing.T) {
+	clock := time.Now()
+	store := &memStore{now: func() time.Time { return clock }}
+	relay := newTestRelay(store, newFakeMentors(0), &clock)
+
+	store.add(model.OutboxMessage{Kind: "mentor.unknown", Payload: []byte(`{}`)})
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, delivered)
+	require.Len(t, store.pending(), 1)
+	require.Contains(t, store.pending()[0].lastError, "unknown outbox kind")
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := NewRelay(slogdiscard.NewDiscardLogger(), &memStore{}, newFakeMentors(0), &fakePublisher{}, testConfig)
+
+	require.Equal(t, time.Second, relay.backoff(0))
+	require.Equal(t, 2*time.Second, relay.backoff(1))
+	require.Equal(t, 8*time.Second, relay.backoff(3))
+	require.Equal(t, 10*time.Second, relay.backoff(4))
+	require.Equal(t, 10*time.Second, relay.backoff(30))
+}
+
+func TestRelayPublishesUserEvents(t *testing.T) {
+	clock := time.Now()
+	store := &memStore{now: func() time.Time { return clock }}
+	events := &fakePublisher{failures: 1}
+	relay := NewRelay(slogdiscard.NewDiscardLogger(), store, newFakeMentors(0), events, testConfig)
+	relay.now = func() time.Time { return clock }
+
+	user := &model.User{ID: 7, Email: "gone@mail.com", Role: model.RoleMentor}
+	store.add(ExportRequestedMessage("exp1", user))
+	store.add(UserDeletedMessage(user))
+
+	// Kafka недоступна: оба сообщения остаются в outbox
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Len(t, store.pending(), 1)
+
+	clock = clock.Add(testConfig.BaseBackoff)
+	delivered, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Empty(t, store.pending())
+
+	require.Equal(t, []string{"7", "7"}, events.keys)
+	require.ElementsMatch(t, []UserEvent{
+		{Type: KindExportRequested, UserID: 7, Email: "gone@mail.com", Role: model.RoleMentor, ExportID: "exp1"},
+		{Type: KindUserDeleted, UserID: 7, Email: "gone@mail.com", Role: model.RoleMentor},
+	}, events.events)
+}
+
+func TestRelayPublishesEmailChange(t *testing.T) {
+	store := &memStore{now: time.Now}
+	events := &fakePublisher{}
+	relay := NewRelay(slogdiscard.NewDiscardLogger(), store, newFakeMentors(0), events, testConfig)
+
+	store.add(EmailChangedMessage(&model.User{ID: 7, Email: "old@mail.com", Role: model.RoleMentor}, "new@mail.com"))
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+
+	// Ключ тот же, что у остальных событий пользователя, поэтому порядок с удалением сохраняется
+	require.Equal(t, []string{"7"}, events.keys)
+	require.Equal(t, []UserEvent{
+		{Type: KindEmailChanged, UserID: 7, Email: "new@mail.com", Role: model.RoleMentor, OldEmail: "old@mail.com"},
+	}, events.events)
+}
+
+func TestRelayPublishesIDBackfill(t *testing.T) {
+	store := &memStore{now: time.Now}
+	events := &fakePublisher{}
+	relay := NewRelay(slogdiscard.NewDiscardLogger(), store, newFakeMentors(0), events, testConfig)
+
+	// Так сообщение пишет миграция 000010
+	store.add(model.OutboxMessage{Kind: KindIDBackfill, Payload: []byte(`{"type":"user.id_backfill","user_id":7,"email":"mentor@mail.com","role":"mentor"}`)})
+
+	delivered, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, []string{"7"}, events.keys)
+	require.Equal(t, []UserEvent{
+		{Type: KindIDBackfill, UserID: 7, Email: "mentor@mail.com", Role: model.RoleMentor},
+	}, events.events)
+}
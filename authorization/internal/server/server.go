@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mentorlink/internal/config"
+	pb "mentorlink/pkg/api/proto"
+	"net"
+	"net/http"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// Server HTTP API для клиентов и gRPC AuthService для других сервисов в одном процессе
+type Server struct {
+	grpcServer   *grpc.Server
+	httpServer   *http.Server
+	grpcListener net.Listener
+}
+
+func New(cfg *config.Config, handler http.Handler, authSrv pb.AuthServiceServer) (*Server, error) {
+	grpcListener, err := net.Listen("tcp", cfg.GRPCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.GRPCAddress, err)
+	}
+
+	grpcSrv := grpc.NewServer()
+	pb.RegisterAuthServiceServer(grpcSrv, authSrv)
+
+	httpSrv := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      handler,
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	return &Server{
+		grpcServer:   grpcSrv,
+		httpServer:   httpSrv,
+		grpcListener: grpcListener,
+	}, nil
+}
+
+// Start блокируется, пока работают оба сервера; ошибка одного из них возвращается наружу
+func (s *Server) Start(log *slog.Logger) error {
+	eg := errgroup.Group{}
+
+	eg.Go(func() error {
+		log.Info("starting grpc server", slog.String("address", s.grpcListener.Addr().String()))
+		if err := s.grpcServer.Serve(s.grpcListener); err != nil {
+			return fmt.Errorf("gRPC server error: %w", err)
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		log.Info("starting http server", slog.String("address", s.httpServer.Addr))
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("HTTP server error: %w", err)
+		}
+		return nil
+	})
+
+	return eg.Wait()
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	s.grpcServer.GracefulStop()
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrEmailChangeNotFound = errors.New("email change not found")
+
+const emailChangePrefix = "email_change:"
+
+// SaveEmailChange сохраняет запрос на смену email под хэшем токена подтверждения
+func (r *RedisRepository) SaveEmailChange(tokenHash string, userID int64, newEmail string, ttl time.Duration) error {
+	const op = "storage.cache.SaveEmailChange"
+	key := emailChangePrefix + tokenHash
+
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(key, map[string]interface{}{
+			"user_id":   userID,
+			"new_email": newEmail,
+		})
+		pipe.Expire(key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ConsumeEmailChange атомарно читает и удаляет запрос, ссылка из письма срабатывает один раз
+func (r *RedisRepository) ConsumeEmailChange(tokenHash string) (int64, string, error) {
+	const op = "storage.cache.ConsumeEmailChange"
+	key := emailChangePrefix + tokenHash
+
+	var get *redis.StringStringMapCmd
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		get = pipe.HGetAll(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := get.Val()
+	if len(fields) == 0 {
+		return 0, "", ErrEmailChangeNotFound
+	}
+	userID, err := strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return userID, fields["new_email"], nil
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrExportNotFound = errors.New("export not found")
+
+const (
+	exportPrefix     = "export:"
+	exportPartPrefix = "part:"
+)
+
+// saveExportPart пишет часть, только если выгрузка ещё жива и принадлежит этому пользователю
+var saveExportPart = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "user_id") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[2], ARGV[3])
+return 1
+`)
+
+// CreateExport заводит выгрузку с частью сервиса авторизации; остальные части
+// дописываются по мере ответов review и mentor, всё удаляется через ttl
+func (r *RedisRepository) CreateExport(exportID string, userID int64, authPart []byte, ttl time.Duration) error {
+	const op = "storage.cache.CreateExport"
+	key := exportPrefix + exportID
+
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(key, map[string]interface{}{
+			"user_id":                 userID,
+			"created_at":              time.Now().Unix(),
+			exportPartPrefix + "auth": authPart,
+		})
+		pipe.Expire(key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *RedisRepository) SaveExportPart(exportID string, userID int64, service string, data []byte) error {
+	const op = "storage.cache.SaveExportPart"
+
+	saved, err := saveExportPart.Run(r.Client,
+		[]string{exportPrefix + exportID},
+		strconv.FormatInt(userID, 10), exportPartPrefix+service, data,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if saved == 0 {
+		return ErrExportNotFound
+	}
+	return nil
+}
+
+func (r *RedisRepository) GetExport(exportID string) (*model.DataExport, error) {
+	const op = "storage.cache.GetExport"
+
+	fields, err := r.Client.HGetAll(exportPrefix + exportID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(fields) == 0 {
+		return nil, ErrExportNotFound
+	}
+
+	userID, err := strconv.ParseInt(fields["user_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+
+	e := &model.DataExport{
+		ID:        exportID,
+		UserID:    userID,
+		CreatedAt: time.Unix(createdAt, 0),
+		Parts:     make(map[string]json.RawMessage),
+	}
+	for k, v := range fields {
+		if service, ok := strings.CutPrefix(k, exportPartPrefix); ok {
+			e.Parts[service] = json.RawMessage(v)
+		}
+	}
+	return e, nil
+}
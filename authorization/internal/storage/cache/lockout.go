@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	failPrefix = "login_fail:"
+	lockPrefix = "login_lock:"
+)
+
+// LockoutPolicy задаёт, после скольких неудачных входов и на сколько блокируется вход
+type LockoutPolicy struct {
+	AccountAttempts int           `env:"LOGIN_ACCOUNT_ATTEMPTS" env-default:"5"`
+	IPAttempts      int           `env:"LOGIN_IP_ATTEMPTS" env-default:"20"`
+	BaseLockout     time.Duration `env:"LOGIN_BASE_LOCKOUT" env-default:"30s"`
+	MaxLockout      time.Duration `env:"LOGIN_MAX_LOCKOUT" env-default:"1h"`
+	Window          time.Duration `env:"LOGIN_FAIL_WINDOW" env-default:"15m"`
+}
+
+// LoginLimiter считает неудачные попытки входа по аккаунту и по IP.
+// После порога каждая следующая ошибка удваивает блокировку, но не больше MaxLockout.
+type LoginLimiter struct {
+	Client *redis.Client
+	policy LockoutPolicy
+}
+
+func NewLoginLimiter(redisClient *redis.Client, policy LockoutPolicy) *LoginLimiter {
+	return &LoginLimiter{Client: redisClient, policy: policy}
+}
+
+func accountKey(email string) string { return "account:" + email }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// Check возвращает, сколько ещё действует блокировка для email или IP; 0 если вход разрешён
+func (l *LoginLimiter) Check(email, ip string) (time.Duration, error) {
+	const op = "storage.cache.LoginLimiter.Check"
+
+	pipe := l.Client.Pipeline()
+	accountTTL := pipe.PTTL(lockPrefix + accountKey(email))
+	ipTTL := pipe.PTTL(lockPrefix + ipKey(ip))
+	if _, err := pipe.Exec(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return maxDuration(accountTTL.Val(), ipTTL.Val()), nil
+}
+
+// RegisterFailure учитывает неудачную попытку и возвращает наложенную блокировку, если порог превышен
+func (l *LoginLimiter) RegisterFailure(email, ip string) (time.Duration, error) {
+	const op = "storage.cache.LoginLimiter.RegisterFailure"
+
+	accountLock, err := l.fail(accountKey(email), l.policy.AccountAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	ipLock, err := l.fail(ipKey(ip), l.policy.IPAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return maxDuration(accountLock, ipLock), nil
+}
+
+// Reset сбрасывает счётчик аккаунта после успешного входа; счётчик IP живёт своё окно
+func (l *LoginLimiter) Reset(email string) error {
+	const op = "storage.cache.LoginLimiter.Reset"
+	if err := l.Client.Del(failPrefix+accountKey(email), lockPrefix+accountKey(email)).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Unlock снимает блокировку вручную; ip может быть пустым
+func (l *LoginLimiter) Unlock(email, ip string) error {
+	const op = "storage.cache.LoginLimiter.Unlock"
+	keys := []string{failPrefix + accountKey(email), lockPrefix + accountKey(email)}
+	if ip != "" {
+		keys = append(keys, failPrefix+ipKey(ip), lockPrefix+ipKey(ip))
+	}
+	if err := l.Client.Del(keys...).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (l *LoginLimiter) fail(key string, threshold int) (time.Duration, error) {
+	pipe := l.Client.TxPipeline()
+	count := pipe.Incr(failPrefix + key)
+	pipe.Expire(failPrefix+key, l.policy.Window)
+	if _, err := pipe.Exec(); err != nil {
+		return 0, err
+	}
+
+	lockout := l.policy.lockoutFor(int(count.Val()), threshold)
+	if lockout == 0 {
+		return 0, nil
+	}
+	if err := l.Client.Set(lockPrefix+key, count.Val(), lockout).Err(); err != nil {
+		return 0, err
+	}
+	return lockout, nil
+}
+
+// lockoutFor: BaseLockout на пороге, дальше экспоненциально
+func (p LockoutPolicy) lockoutFor(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	lockout := p.BaseLockout
+	for i := threshold; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+	return lockout
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	// PTTL возвращает отрицательные значения для отсутствующих ключей
+	if a < 0 {
+		a = 0
+	}
+	if b < 0 {
+		b = 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = LockoutPolicy{
+	AccountAttempts: 3,
+	IPAttempts:      5,
+	BaseLockout:     30 * time.Second,
+	MaxLockout:      4 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+func newTestLimiter(t *testing.T) (*LoginLimiter, *miniredis.Miniredis) {
+	t.Helper()
+	client, mr := newTestClient(t)
+	return NewLoginLimiter(client, testPolicy), mr
+}
+
+func TestLockoutFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		threshold int
+		want      time.Duration
+	}{
+		{name: "below account threshold", failures: 2, threshold: testPolicy.AccountAttempts, want: 0},
+		{name: "account threshold", failures: 3, threshold: testPolicy.AccountAttempts, want: 30 * time.Second},
+		{name: "doubles after threshold", failures: 4, threshold: testPolicy.AccountAttempts, want: time.Minute},
+		{name: "doubles again", failures: 6, threshold: testPolicy.AccountAttempts, want: 4 * time.Minute},
+		{name: "capped at max", failures: 7, threshold: testPolicy.AccountAttempts, want: 4 * time.Minute},
+		{name: "far past the cap", failures: 100, threshold: testPolicy.AccountAttempts, want: 4 * time.Minute},
+		{name: "ip below its threshold", failures: 4, threshold: testPolicy.IPAttempts, want: 0},
+		{name: "ip threshold", failures: 5, threshold: testPolicy.IPAttempts, want: 30 * time.Second},
+		{name: "disabled threshold", failures: 10, threshold: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, testPolicy.lockoutFor(tt.failures, tt.threshold))
+		})
+	}
+}
+
+func TestRegisterFailureLongerLockWins(t *testing.T) {
+	tests := []struct {
+		name     string
+		emails   []string
+		wantLast time.Duration
+	}{
+		{
+			// аккаунт: 5 ошибок -> 2m, IP: 5 ошибок -> 30s
+			name:     "account lock longer than ip",
+			emails:   []string{"a@x.io", "a@x.io", "a@x.io", "a@x.io", "a@x.io"},
+			wantLast: 2 * time.Minute,
+		},
+		{
+			// у каждого аккаунта по одной ошибке, IP достигает порога
+			name:     "ip lock when accounts are below threshold",
+			emails:   []string{"a@x.io", "b@x.io", "c@x.io", "d@x.io", "e@x.io"},
+			wantLast: 30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, _ := newTestLimiter(t)
+
+			var lock time.Duration
+			var err error
+			for _, email := range tt.emails {
+				lock, err = limiter.RegisterFailure(email, "10.0.0.1")
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantLast, lock)
+
+			left, err := limiter.Check(tt.emails[len(tt.emails)-1], "10.0.0.1")
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLast, left)
+		})
+	}
+}
+
+func TestRegisterFailureBelowThreshold(t *testing.T) {
+	limiter, mr := newTestLimiter(t)
+
+	for i := 0; i < testPolicy.AccountAttempts-1; i++ {
+		lock, err := limiter.RegisterFailure("a@x.io", "10.0.0.1")
+		require.NoError(t, err)
+		require.Zero(t, lock)
+	}
+
+	left, err := limiter.Check("a@x.io", "10.0.0.1")
+	require.NoError(t, err)
+	require.Zero(t, left)
+	require.Equal(t, testPolicy.Window, mr.TTL(failPrefix+accountKey("a@x.io")))
+}
+
+func TestCheckLockExpires(t *testing.T) {
+	limiter, mr := newTestLimiter(t)
+
+	for i := 0; i < testPolicy.AccountAttempts; i++ {
+		_, err := limiter.RegisterFailure("a@x.io", "10.0.0.1")
+		require.NoError(t, err)
+	}
+	left, err := limiter.Check("a@x.io", "10.0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, left)
+
+	mr.FastForward(31 * time.Second)
+
+	left, err = limiter.Check("a@x.io", "10.0.0.2")
+	require.NoError(t, err)
+	require.Zero(t, left)
+}
+
+func TestReset(t *testing.T) {
+	limiter, mr := newTestLimiter(t)
+
+	for i := 0; i < testPolicy.AccountAttempts; i++ {
+		_, err := limiter.RegisterFailure("a@x.io", "10.0.0.1")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, limiter.Reset("a@x.io"))
+
+	left, err := limiter.Check("a@x.io", "10.0.0.1")
+	require.NoError(t, err)
+	require.Zero(t, left)
+	require.False(t, mr.Exists(failPrefix+accountKey("a@x.io")))
+	// счётчик IP не сбрасывается успешным входом
+	count, err := mr.Get(failPrefix + ipKey("10.0.0.1"))
+	require.NoError(t, err)
+	require.Equal(t, "3", count)
+}
+
+func TestUnlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		ip         string
+		wantIPLock bool
+	}{
+		{name: "account and ip", ip: "10.0.0.1"},
+		{name: "account only", ip: "", wantIPLock: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, mr := newTestLimiter(t)
+
+			for i := 0; i < testPolicy.IPAttempts; i++ {
+				_, err := limiter.RegisterFailure("a@x.io", "10.0.0.1")
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, limiter.Unlock("a@x.io", tt.ip))
+
+			require.False(t, mr.Exists(lockPrefix+accountKey("a@x.io")))
+			require.False(t, mr.Exists(failPrefix+accountKey("a@x.io")))
+			require.Equal(t, tt.wantIPLock, mr.Exists(lockPrefix+ipKey("10.0.0.1")))
+			require.Equal(t, tt.wantIPLock, mr.Exists(failPrefix+ipKey("10.0.0.1")))
+
+			left, err := limiter.Check("a@x.io", "10.0.0.2")
+			require.NoError(t, err)
+			require.Zero(t, left)
+		})
+	}
+}
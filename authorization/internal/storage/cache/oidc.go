@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrOIDCStateNotFound = errors.New("oidc state not found")
+
+const oidcStatePrefix = "oidc_state:"
+
+// SaveOIDCState запоминает PKCE verifier и nonce до возврата пользователя от провайдера
+func (r *RedisRepository) SaveOIDCState(state string, s model.OIDCState, ttl time.Duration) error {
+	const op = "storage.cache.SaveOIDCState"
+	key := oidcStatePrefix + state
+
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(key, map[string]interface{}{
+			"provider": s.Provider,
+			"verifier": s.CodeVerifier,
+			"nonce":    s.Nonce,
+		})
+		pipe.Expire(key, ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ConsumeOIDCState атомарно читает и удаляет state, повторный callback с ним не пройдёт
+func (r *RedisRepository) ConsumeOIDCState(state string) (*model.OIDCState, error) {
+	const op = "storage.cache.ConsumeOIDCState"
+	key := oidcStatePrefix + state
+
+	var get *redis.StringStringMapCmd
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		get = pipe.HGetAll(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := get.Val()
+	if len(fields) == 0 {
+		return nil, ErrOIDCStateNotFound
+	}
+	return &model.OIDCState{
+		Provider:     fields["provider"],
+		CodeVerifier: fields["verifier"],
+		Nonce:        fields["nonce"],
+	}, nil
+}
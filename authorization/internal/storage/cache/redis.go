@@ -1,12 +1,36 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-redis/redis"
 )
 
+var ErrTokenReused = errors.New("refresh token reused")
+
+const familyPrefix = "family:"
+
+// rotateScript атомарно заменяет текущий jti семейства, если предъявлен последний выданный токен
+var rotateScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "EX", ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// allowScript увеличивает счётчик и ставит срок окна в одном вызове, чтобы ключ не остался без TTL.
+// Срок ставится и ключу, оставшемуся без TTL после прежнего неатомарного INCR + EXPIRE.
+var allowScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 or redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
 type RedisConfig struct {
 	Host     string `env:"REDIS_HOST" env-required:"true"`
 	Port     string `env:"REDIS_PORT" env-required:"true"`
@@ -45,6 +69,17 @@ func (r *RedisRepository) AddToBlackList(token string, exp int64) error {
 	return nil
 }
 
+// AddToBlackListOnce атомарно заносит токен в blacklist; false, если он там уже был.
+// Нужен для одноразовых токенов, где проверка и запись отдельными командами дали бы гонку
+func (r *RedisRepository) AddToBlackListOnce(token string, exp int64) (bool, error) {
+	const op = "storage.cache.AddToBlackListOnce"
+	ok, err := r.Client.SetNX(token, "", ttlUntil(exp)).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
+
 func (r *RedisRepository) IsBlackListed(token string) (bool, error) {
 	const op = "storage.cache.isBlackListed"
 	_, err := r.Client.Get(token).Result()
@@ -56,3 +91,83 @@ func (r *RedisRepository) IsBlackListed(token string) (bool, error) {
 	}
 	return true, nil
 }
+
+func (r *RedisRepository) StartFamily(familyID, tokenID string, exp int64) error {
+	const op = "storage.cache.StartFamily"
+	err := r.Client.Set(familyPrefix+familyID, tokenID, ttlUntil(exp)).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RotateFamily переводит семейство на новый refresh токен.
+// Если oldTokenID не является последним выданным в семействе, возвращается ErrTokenReused.
+func (r *RedisRepository) RotateFamily(familyID, oldTokenID, newTokenID string, exp int64) error {
+	const op = "storage.cache.RotateFamily"
+	rotated, err := rotateScript.Run(
+		r.Client,
+		[]string{familyPrefix + familyID},
+		oldTokenID, newTokenID, int64(ttlUntil(exp).Seconds()),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rotated == 0 {
+		return ErrTokenReused
+	}
+	return nil
+}
+
+// RevokeFamily отзывает семейство токенов вместе с сессией, которой оно принадлежит
+func (r *RedisRepository) RevokeFamily(familyID string) error {
+	const op = "storage.cache.RevokeFamily"
+
+	userID, err := r.Client.HGet(sessionPrefix+familyID, "user_id").Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(sessionPrefix+familyID, familyPrefix+familyID)
+		if userID != "" {
+			pipe.SRem(userSessionsPrefix+userID, familyID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func ttlUntil(exp int64) time.Duration {
+	ttl := exp - time.Now().Unix()
+	if ttl <= 0 {
+		ttl = 60
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// Allow разрешает не больше limit действий за window для данного ключа
+func (r *RedisRepository) Allow(key string, limit int64, window time.Duration) (bool, error) {
+	const op = "storage.cache.Allow"
+	key = "ratelimit:" + key
+
+	// Окно фиксированное: срок ставится первым запросом и не продлевается следующими
+	n, err := allowScript.Run(r.Client, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n <= limit, nil
+}
+
+// Throttle разрешает действие не чаще одного раза за window для данного ключа
+func (r *RedisRepository) Throttle(key string, window time.Duration) (bool, error) {
+	const op = "storage.cache.Throttle"
+	ok, err := r.Client.SetNX("throttle:"+key, "", window).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"mentorlink/internal/domain/model"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+func newTestRepo(t *testing.T) (*RedisRepository, *miniredis.Miniredis) {
+	t.Helper()
+	client, mr := newTestClient(t)
+	return NewRedisRepository(client), mr
+}
+
+func TestRotateFamilyCurrentToken(t *testing.T) {
+	repo, mr := newTestRepo(t)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	require.NoError(t, repo.StartFamily("family_1", "jti_1", exp))
+	require.NoError(t, repo.RotateFamily("family_1", "jti_1", "jti_2", exp))
+
+	current, err := mr.Get(familyPrefix + "family_1")
+	require.NoError(t, err)
+	require.Equal(t, "jti_2", current)
+	require.True(t, mr.TTL(familyPrefix+"family_1") > 0)
+}
+
+func TestRotateFamilyStaleToken(t *testing.T) {
+	repo, mr := newTestRepo(t)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	require.NoError(t, repo.StartFamily("family_1", "jti_1", exp))
+	require.NoError(t, repo.RotateFamily("family_1", "jti_1", "jti_2", exp))
+
+	err := repo.RotateFamily("family_1", "jti_1", "jti_3", exp)
+	require.ErrorIs(t, err, ErrTokenReused)
+
+	current, err := mr.Get(familyPrefix + "family_1")
+	require.NoError(t, err)
+	require.Equal(t, "jti_2", current)
+}
+
+func TestRevokeFamily(t *testing.T) {
+	repo, mr := newTestRepo(t)
+	exp := time.Now().Add(time.Hour).Unix()
+	now := time.Now()
+
+	require.NoError(t, repo.CreateSession(&model.Session{
+		ID: "family_1", UserID: 123, CreatedAt: now, LastUsedAt: now,
+	}, exp))
+	require.NoError(t, repo.CreateSession(&model.Session{
+		ID: "family_2", UserID: 123, CreatedAt: now, LastUsedAt: now,
+	}, exp))
+	require.NoError(t, repo.StartFamily("family_1", "jti_1", exp))
+
+	require.NoError(t, repo.RevokeFamily("family_1"))
+
+	require.False(t, mr.Exists(sessionPrefix+"family_1"))
+	require.False(t, mr.Exists(familyPrefix+"family_1"))
+	members, err := mr.Members(userSessionsPrefix + "123")
+	require.NoError(t, err)
+	require.Equal(t, []string{"family_2"}, members)
+}
+
+func TestAllow(t *testing.T) {
+	repo, mr := newTestRepo(t)
+
+	for i := 0; i < 3; i++ {
+		ok, err := repo.Allow("login:10.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	ok, err := repo.Allow("login:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// следующие запросы не продлевают окно
+	require.Equal(t, time.Minute, mr.TTL("ratelimit:login:10.0.0.1"))
+
+	mr.FastForward(time.Minute)
+
+	ok, err = repo.Allow("login:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, mr.TTL("ratelimit:login:10.0.0.1"))
+}
+
+func TestAllowRestoresMissingTTL(t *testing.T) {
+	repo, mr := newTestRepo(t)
+	require.NoError(t, mr.Set("ratelimit:login:10.0.0.1", "7"))
+
+	ok, err := repo.Allow("login:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, time.Minute, mr.TTL("ratelimit:login:10.0.0.1"))
+}
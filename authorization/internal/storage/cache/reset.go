@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrResetTokenNotFound = errors.New("reset token not found")
+
+const resetPrefix = "password_reset:"
+
+// SaveResetToken сохраняет хэш токена сброса пароля; сам токен в Redis не хранится
+func (r *RedisRepository) SaveResetToken(tokenHash string, userID int64, ttl time.Duration) error {
+	const op = "storage.cache.SaveResetToken"
+	if err := r.Client.Set(resetPrefix+tokenHash, userID, ttl).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ConsumeResetToken атомарно читает и удаляет токен, поэтому воспользоваться им можно только один раз
+func (r *RedisRepository) ConsumeResetToken(tokenHash string) (int64, error) {
+	const op = "storage.cache.ConsumeResetToken"
+	key := resetPrefix + tokenHash
+
+	var get *redis.StringCmd
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		get = pipe.Get(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, ErrResetTokenNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	userID, err := strconv.ParseInt(get.Val(), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return userID, nil
+}
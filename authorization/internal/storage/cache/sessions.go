@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+const (
+	sessionPrefix      = "session:"
+	userSessionsPrefix = "user_sessions:"
+)
+
+func (r *RedisRepository) CreateSession(s *model.Session, exp int64) error {
+	const op = "storage.cache.CreateSession"
+	key := sessionPrefix + s.ID
+	userKey := userSessionsPrefix + strconv.FormatInt(s.UserID, 10)
+
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(key, map[string]interface{}{
+			"user_id":      s.UserID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt.Unix(),
+			"last_used_at": s.LastUsedAt.Unix(),
+		})
+		pipe.Expire(key, ttlUntil(exp))
+		pipe.SAdd(userKey, s.ID)
+		pipe.Expire(userKey, ttlUntil(exp))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// TouchSession обновляет время последнего использования и продлевает сессию до exp
+func (r *RedisRepository) TouchSession(sessionID string, exp int64) error {
+	const op = "storage.cache.TouchSession"
+	key := sessionPrefix + sessionID
+
+	userID, err := r.Client.HGet(key, "user_id").Result()
+	if err == redis.Nil {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(key, "last_used_at", time.Now().Unix())
+		pipe.Expire(key, ttlUntil(exp))
+		pipe.Expire(userSessionsPrefix+userID, ttlUntil(exp))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *RedisRepository) IsSessionActive(sessionID string) (bool, error) {
+	const op = "storage.cache.IsSessionActive"
+	n, err := r.Client.Exists(sessionPrefix + sessionID).Result()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
+
+// SessionOwner возвращает пользователя, которому принадлежит действующая сессия
+func (r *RedisRepository) SessionOwner(sessionID string) (int64, error) {
+	const op = "storage.cache.SessionOwner"
+	userID, err := r.Client.HGet(sessionPrefix+sessionID, "user_id").Int64()
+	if err == redis.Nil {
+		return 0, ErrSessionNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return userID, nil
+}
+
+func (r *RedisRepository) GetSessions(userID int64) ([]model.Session, error) {
+	const op = "storage.cache.GetSessions"
+	userKey := userSessionsPrefix + strconv.FormatInt(userID, 10)
+
+	ids, err := r.Client.SMembers(userKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sessions := make([]model.Session, 0, len(ids))
+	for _, id := range ids {
+		fields, err := r.Client.HGetAll(sessionPrefix + id).Result()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if len(fields) == 0 {
+			// сессия истекла, убираем её из индекса пользователя
+			r.Client.SRem(userKey, id)
+			continue
+		}
+		sessions = append(sessions, sessionFromHash(id, userID, fields))
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession удаляет сессию пользователя и отзывает её семейство токенов
+func (r *RedisRepository) RevokeSession(userID int64, sessionID string) error {
+	const op = "storage.cache.RevokeSession"
+	userKey := userSessionsPrefix + strconv.FormatInt(userID, 10)
+
+	member, err := r.Client.SIsMember(userKey, sessionID).Result()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !member {
+		return ErrSessionNotFound
+	}
+
+	_, err = r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(sessionPrefix+sessionID, familyPrefix+sessionID)
+		pipe.SRem(userKey, sessionID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *RedisRepository) RevokeAllSessions(userID int64) error {
+	const op = "storage.cache.RevokeAllSessions"
+	userKey := userSessionsPrefix + strconv.FormatInt(userID, 10)
+
+	ids, err := r.Client.SMembers(userKey).Result()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := make([]string, 0, 2*len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, sessionPrefix+id, familyPrefix+id)
+	}
+	keys = append(keys, userKey)
+
+	if err := r.Client.Del(keys...).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions отзывает все сессии пользователя, кроме текущей
+func (r *RedisRepository) RevokeOtherSessions(userID int64, keepID string) error {
+	const op = "storage.cache.RevokeOtherSessions"
+	userKey := userSessionsPrefix + strconv.FormatInt(userID, 10)
+
+	ids, err := r.Client.SMembers(userKey).Result()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var keys, revoked []string
+	for _, id := range ids {
+		if id == keepID {
+			continue
+		}
+		keys = append(keys, sessionPrefix+id, familyPrefix+id)
+		revoked = append(revoked, id)
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+
+	_, err = r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(keys...)
+		members := make([]interface{}, len(revoked))
+		for i, id := range revoked {
+			members[i] = id
+		}
+		pipe.SRem(userKey, members...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func sessionFromHash(id string, userID int64, fields map[string]string) model.Session {
+	createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+	lastUsedAt, _ := strconv.ParseInt(fields["last_used_at"], 10, 64)
+	return model.Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  fields["user_agent"],
+		IP:         fields["ip"],
+		CreatedAt:  time.Unix(createdAt, 0).UTC(),
+		LastUsedAt: time.Unix(lastUsedAt, 0).UTC(),
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"strings"
+)
+
+// UserFilter параметры выборки пользователей для администратора
+type UserFilter struct {
+	Query  string // подстрока email или отображаемого имени
+	Role   string
+	Limit  int
+	Offset int
+}
+
+// ListUsers возвращает страницу пользователей и общее число подходящих под фильтр
+func (s *Storage) ListUsers(f UserFilter) ([]model.User, int, error) {
+	const op = "storage.db.ListUsers"
+
+	var (
+		conds []string
+		args  []any
+	)
+	if f.Query != "" {
+		args = append(args, "%"+escapeLike(f.Query)+"%")
+		conds = append(conds, fmt.Sprintf("(email ILIKE $%d OR display_name ILIKE $%d)", len(args), len(args)))
+	}
+	if f.Role != "" {
+		args = append(args, f.Role)
+		conds = append(conds, fmt.Sprintf("role = $%d", len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := s.db.Get(&total, `SELECT COUNT(*) FROM users`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY id LIMIT $%d OFFSET $%d`,
+		userColumns, where, len(args)-1, len(args))
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, f.Limit)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return users, total, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetRole меняет роль и в той же транзакции кладёт сообщения для сервиса менторов
+func (s *Storage) SetRole(userID int64, role string, outbox []model.OutboxMessage) error {
+	const op = "storage.db.SetRole"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE users SET role=$1 WHERE id=$2`, role, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := insertOutbox(tx, outbox); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetDisabled отключает или включает аккаунт вместе с сообщениями для сервиса менторов
+func (s *Storage) SetDisabled(userID int64, disabled bool, outbox []model.OutboxMessage) error {
+	const op = "storage.db.SetDisabled"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET disabled_at = NULL WHERE id=$1`
+	if disabled {
+		query = `UPDATE users SET disabled_at = COALESCE(disabled_at, NOW()) WHERE id=$1`
+	}
+	result, err := tx.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := insertOutbox(tx, outbox); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// EnsureAdmin создаёт подтверждённого администратора, если пользователя с таким email ещё нет.
+// Существующий аккаунт не меняется; возвращает true, если администратор создан
+func (s *Storage) EnsureAdmin(email, passwordHash string) (bool, error) {
+	const op = "storage.db.EnsureAdmin"
+	query := `INSERT INTO users (email, password, role, verified_at)
+			  VALUES ($1, $2, $3, NOW())
+			  ON CONFLICT (email) DO NOTHING`
+	result, err := s.db.Exec(query, email, passwordHash, model.RoleAdmin)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return rows > 0, nil
+}
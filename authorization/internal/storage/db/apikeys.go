@@ -0,0 +1,89 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"mentorlink/internal/domain/model"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyLimit    = errors.New("api key limit reached")
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scope, created_at, expires_at, last_used_at, revoked_at`
+
+// CreateAPIKey сохраняет ключ, если у пользователя меньше limit действующих ключей.
+// Проверка и вставка выполняются одним запросом, чтобы параллельные запросы не обошли лимит
+func (s *Storage) CreateAPIKey(k *model.APIKey, limit int) error {
+	const op = "storage.db.CreateAPIKey"
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scope, expires_at)
+			  SELECT $1, $2, $3, $4, $5, $6
+			  WHERE (SELECT COUNT(*) FROM api_keys
+			         WHERE user_id = $1 AND revoked_at IS NULL
+			           AND (expires_at IS NULL OR expires_at > NOW())) < $7
+			  RETURNING id, created_at`
+	err := s.db.QueryRow(query, k.UserID, k.Name, k.Prefix, k.KeyHash, k.Scope, k.ExpiresAt, limit).
+		Scan(&k.ID, &k.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyLimit
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ListAPIKeys возвращает неотозванные ключи пользователя, включая истёкшие
+func (s *Storage) ListAPIKeys(userID int64) ([]model.APIKey, error) {
+	const op = "storage.db.ListAPIKeys"
+	keys := []model.APIKey{}
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys
+			  WHERE user_id = $1 AND revoked_at IS NULL
+			  ORDER BY id`
+	if err := s.db.Select(&keys, query, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ пользователя; чужой или уже отозванный ключ считается ненайденным
+func (s *Storage) RevokeAPIKey(userID, id int64) error {
+	const op = "storage.db.RevokeAPIKey"
+	result, err := s.db.Exec(`UPDATE api_keys SET revoked_at = NOW()
+			  WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (s *Storage) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	const op = "storage.db.GetAPIKeyByHash"
+	var k model.APIKey
+	err := s.db.Get(&k, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &k, nil
+}
+
+// TouchAPIKey отмечает время последнего использования ключа
+func (s *Storage) TouchAPIKey(id int64) error {
+	const op = "storage.db.TouchAPIKey"
+	if _, err := s.db.Exec(`UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
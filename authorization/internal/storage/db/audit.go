@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"strings"
+	"time"
+)
+
+// AuthEventFilter параметры выборки журнала аутентификации для администратора
+type AuthEventFilter struct {
+	UserID int64 // 0 - все пользователи
+	Type   string
+	From   time.Time // включительно, нулевое значение - без ограничения
+	To     time.Time // не включительно
+	Limit  int
+	Offset int
+}
+
+func (s *Storage) SaveAuthEvent(e *model.AuthEvent) error {
+	const op = "storage.db.SaveAuthEvent"
+
+	query := `INSERT INTO auth_events (type, user_id, ip, user_agent, request_id, outcome, reason)
+			  VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7)`
+	_, err := s.db.Exec(query, e.Type, e.UserID, e.IP, e.UserAgent, e.RequestID, e.Outcome, e.Reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ListAuthEvents возвращает страницу событий, новые первыми, и общее число подходящих под фильтр
+func (s *Storage) ListAuthEvents(f AuthEventFilter) ([]model.AuthEvent, int, error) {
+	const op = "storage.db.ListAuthEvents"
+
+	var (
+		conds []string
+		args  []any
+	)
+	if f.UserID != 0 {
+		args = append(args, f.UserID)
+		conds = append(conds, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if f.Type != "" {
+		args = append(args, f.Type)
+		conds = append(conds, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := s.db.Get(&total, `SELECT COUNT(*) FROM auth_events`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := fmt.Sprintf(`SELECT id, type, COALESCE(user_id, 0) AS user_id, ip, user_agent, request_id, outcome, reason, created_at
+			  FROM auth_events%s
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	events := make([]model.AuthEvent, 0, f.Limit)
+	if err := s.db.Select(&events, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, total, nil
+}
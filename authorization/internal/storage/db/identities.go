@@ -0,0 +1,72 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"mentorlink/internal/domain/model"
+)
+
+// GetByIdentity ищет пользователя, связанного с учётной записью провайдера
+func (s *Storage) GetByIdentity(provider, subject string) (*model.User, error) {
+	const op = "storage.db.GetByIdentity"
+	query := `SELECT ` + userColumns + ` FROM users
+			  WHERE id = (SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2)`
+	user, err := scanUser(s.db.QueryRow(query, provider, subject))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return user, nil
+}
+
+func (s *Storage) LinkIdentity(userID int64, identity model.Identity) error {
+	const op = "storage.db.LinkIdentity"
+	query := `INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)`
+	if _, err := s.db.Exec(query, userID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CreateUserWithIdentity создаёт пользователя без пароля вместе со связью с провайдером.
+// Email, подтверждённый провайдером, сразу считается подтверждённым
+func (s *Storage) CreateUserWithIdentity(u *model.User, identity model.Identity) error {
+	const op = "storage.db.CreateUserWithIdentity"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO users (email, password, role, verified_at, display_name)
+			  VALUES ($1, '', $2, $3, $4)
+			  RETURNING id`
+	if err := tx.QueryRow(query, u.Email, u.Role, u.VerifiedAt, u.DisplayName).Scan(&u.ID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO user_identities (user_id, provider, subject, email) VALUES ($1, $2, $3, $4)`,
+		u.ID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) ListIdentities(userID int64) ([]model.Identity, error) {
+	const op = "storage.db.ListIdentities"
+	identities := []model.Identity{}
+	query := `SELECT provider, subject, email FROM user_identities WHERE user_id = $1 ORDER BY id`
+	if err := s.db.Select(&identities, query, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return identities, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"fmt"
+	"mentorlink/internal/domain/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func insertOutbox(tx *sqlx.Tx, msgs []model.OutboxMessage) error {
+	for _, m := range msgs {
+		if _, err := tx.Exec(`INSERT INTO outbox (kind, payload) VALUES ($1, $2)`, m.Kind, m.Payload); err != nil {
+			return fmt.Errorf("insert outbox: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimOutbox забирает готовые к отправке сообщения и откладывает их на lease,
+// чтобы параллельный relay не взял те же самые
+func (s *Storage) ClaimOutbox(limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	const op = "storage.db.ClaimOutbox"
+	query := `UPDATE outbox SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+			  WHERE id IN (
+				  SELECT id FROM outbox
+				  WHERE processed_at IS NULL AND next_attempt_at <= NOW()
+				  ORDER BY id
+				  LIMIT $1
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING id, kind, payload, attempts`
+
+	var msgs []model.OutboxMessage
+	if err := s.db.Select(&msgs, query, limit, lease.Milliseconds()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return msgs, nil
+}
+
+func (s *Storage) MarkOutboxProcessed(id int64) error {
+	const op = "storage.db.MarkOutboxProcessed"
+	if _, err := s.db.Exec(`UPDATE outbox SET processed_at = NOW(), last_error = '' WHERE id=$1`, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) MarkOutboxFailed(id int64, lastError string, nextAttempt time.Time) error {
+	const op = "storage.db.MarkOutboxFailed"
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id=$1`
+	if _, err := s.db.Exec(query, id, lastError, nextAttempt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// EnqueueOutbox кладёт сообщения, не связанные с изменением пользователя
+func (s *Storage) EnqueueOutbox(msgs []model.OutboxMessage) error {
+	const op = "storage.db.EnqueueOutbox"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertOutbox(tx, msgs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -7,10 +7,13 @@ import (
 	"mentorlink/internal/domain/model"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email already taken")
+)
 
 type Config struct {
 	UserName string `env:"POSTGRES_USER" env-required:"true"`
@@ -36,30 +39,111 @@ func NewStorage(cfg Config) (*Storage, error) {
 	return &Storage{db: db}, nil
 }
 
-func (s *Storage) CreateUser(u *model.User) error {
-	const op = "stoage.db.SaveURL"
-	query := `INSERT INTO users (email, password, role)
-			  VALUES ($1, $2, $3)
+const userColumns = `id, email, password, role, verified_at, disabled_at, COALESCE(totp_secret, ''), totp_enabled,
+	display_name, avatar_url, contact, timezone`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*model.User, error) {
+	user := &model.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.VerifiedAt,
+		&user.DisabledAt,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.DisplayName,
+		&user.AvatarURL,
+		&user.Contact,
+		&user.Timezone,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser создаёт пользователя и в той же транзакции кладёт в outbox сообщения,
+// которые outbox строит по пользователю с уже присвоенным id
+func (s *Storage) CreateUser(u *model.User, outbox func(u *model.User) []model.OutboxMessage) error {
+	const op = "stoage.db.CreateUser"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO users (email, password, role, contact)
+			  VALUES ($1, $2, $3, $4)
 			  RETURNING id`
 	var newID int64
-	err := s.db.QueryRow(query, u.Email, u.Password, u.Role).Scan(&newID)
+	err = tx.QueryRow(query, u.Email, u.Password, u.Role, u.Contact).Scan(&newID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+
+	created := *u
+	created.ID = newID
+	if outbox != nil {
+		if err := insertOutbox(tx, outbox(&created)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	u.ID = newID
 	return nil
 }
 
+// ChangeEmail меняет email и в той же транзакции кладёт сообщения для review и mentor,
+// которые хранят данные ментора по email. Переход по ссылке из письма подтверждает и новый адрес
+func (s *Storage) ChangeEmail(userID int64, newEmail string, outbox []model.OutboxMessage) error {
+	const op = "storage.db.ChangeEmail"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE users SET email=$1, verified_at=COALESCE(verified_at, NOW()) WHERE id=$2`, newEmail, userID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := insertOutbox(tx, outbox); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
 func (s *Storage) GetByEmail(email string) (*model.User, error) {
 	const op = "storage.db.SaveURL"
-	query := `SELECT id, email, password, role FROM users WHERE email=$1`
-	user := &model.User{}
-	err := s.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Password,
-		&user.Role,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email=$1`
+	user, err := scanUser(s.db.QueryRow(query, email))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrUserNotFound
 	}
@@ -68,3 +152,191 @@ func (s *Storage) GetByEmail(email string) (*model.User, error) {
 	}
 	return user, nil
 }
+
+func (s *Storage) UpdatePassword(userID int64, passwordHash string) error {
+	const op = "storage.db.UpdatePassword"
+	query := `UPDATE users SET password=$1 WHERE id=$2`
+	result, err := s.db.Exec(query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Storage) GetByID(id int64) (*model.User, error) {
+	const op = "storage.db.GetByID"
+	query := `SELECT ` + userColumns + ` FROM users WHERE id=$1`
+	user, err := scanUser(s.db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s, %w", op, err)
+	}
+	return user, nil
+}
+
+// GetUsersByIDs возвращает найденных пользователей в порядке id; отсутствующие id пропускаются
+func (s *Storage) GetUsersByIDs(ids []int64) ([]model.User, error) {
+	const op = "storage.db.GetUsersByIDs"
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ANY($1) ORDER BY id`
+	rows, err := s.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, len(ids))
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return users, nil
+}
+
+func (s *Storage) MarkVerified(userID int64, outbox []model.OutboxMessage) error {
+	const op = "storage.db.MarkVerified"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET verified_at = NOW() WHERE id=$1 AND verified_at IS NULL`
+	if _, err := tx.Exec(query, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := insertOutbox(tx, outbox); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SaveTOTPEnrollment сохраняет новый секрет и заменяет коды восстановления; 2FA остаётся выключенной до подтверждения
+func (s *Storage) SaveTOTPEnrollment(userID int64, secret string, recoveryCodeHashes []string) error {
+	const op = "storage.db.SaveTOTPEnrollment"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET totp_secret=$1, totp_enabled=FALSE WHERE id=$2`, secret, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(`INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) EnableTOTP(userID int64) error {
+	const op = "storage.db.EnableTOTP"
+	query := `UPDATE users SET totp_enabled=TRUE WHERE id=$1 AND totp_secret IS NOT NULL`
+	result, err := s.db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UseRecoveryCode гасит код восстановления; false, если код не найден или уже использован
+func (s *Storage) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	const op = "storage.db.UseRecoveryCode"
+	query := `UPDATE recovery_codes SET used_at=NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`
+	result, err := s.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return rows > 0, nil
+}
+
+func (s *Storage) UpdateProfile(userID int64, p model.Profile) error {
+	const op = "storage.db.UpdateProfile"
+	query := `UPDATE users SET display_name=$1, avatar_url=$2, contact=$3, timezone=$4 WHERE id=$5`
+	result, err := s.db.Exec(query, p.DisplayName, p.AvatarURL, p.Contact, p.Timezone, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteUser удаляет пользователя вместе с ключами, кодами восстановления и OIDC
+// учётками (ON DELETE CASCADE) и в той же транзакции кладёт сообщения в outbox
+func (s *Storage) DeleteUser(userID int64, outbox []model.OutboxMessage) error {
+	const op = "storage.db.DeleteUser"
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM users WHERE id=$1`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := insertOutbox(tx, outbox); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
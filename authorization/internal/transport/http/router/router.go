@@ -1,51 +1,127 @@
 package router
 
 import (
-	"context"
 	"log/slog"
+	"mentorlink/internal/domain/model"
+	"mentorlink/internal/handlers/account"
+	"mentorlink/internal/handlers/admin"
+	"mentorlink/internal/handlers/apikeys"
+	"mentorlink/internal/handlers/email"
+	"mentorlink/internal/handlers/jwks"
 	"mentorlink/internal/handlers/login"
 	"mentorlink/internal/handlers/logout"
+	"mentorlink/internal/handlers/mfa"
+	"mentorlink/internal/handlers/password"
 	"mentorlink/internal/handlers/profile"
-	"mentorlink/internal/handlers/register"
 	"mentorlink/internal/handlers/refresh"
+	"mentorlink/internal/handlers/register"
+	"mentorlink/internal/handlers/sessions"
+	"mentorlink/internal/handlers/verify"
+	"mentorlink/internal/lib/audit"
+	"mentorlink/internal/lib/mailer"
+	"mentorlink/internal/lib/oidc"
+	"mentorlink/internal/lib/passhash"
+	"mentorlink/internal/lib/passpolicy"
+	"mentorlink/internal/lib/verification"
+	mwLogger "mentorlink/internal/middleware/logger"
+	"mentorlink/internal/storage/cache"
 	"mentorlink/internal/storage/db"
 	"mentorlink/pkg/middleware/auth"
 	"mentorlink/pkg/token"
 	"net/http"
+	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
 )
 
-func SetupRouter(
-	ctx context.Context,
-	log *slog.Logger,
-	storage *db.Storage,
-	tokenManager *token.TokenManager,
-) http.Handler {
+// Deps зависимости HTTP слоя, собранные в main
+type Deps struct {
+	Storage      *db.Storage
+	TokenManager *token.TokenManager
+	Redis        *cache.RedisRepository
+	LoginLimiter *cache.LoginLimiter
+	Mailer       mailer.Mailer
+	Hasher       *passhash.Hasher
+	Policy       *passpolicy.Policy
+	Verifier     *verification.Sender
+	Audit        *audit.Recorder
+	OIDC         *oidc.Registry
+	OIDCStateTTL time.Duration
+	AppURL       string
+}
+
+func SetupRouter(log *slog.Logger, d Deps) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.Logger)
+	r.Use(middleware.RealIP)
+	r.Use(mwLogger.New(log))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.URLFormat)
 	r.Use(render.SetContentType(render.ContentTypeJSON))
 
 	// Public routes
 	r.Group(func(r chi.Router) {
-		r.Post("/auth/register", register.Register(ctx, log, storage, storage))
-		r.Post("/auth/login", login.Login(log, storage, tokenManager))
+		r.Get("/.well-known/jwks.json", jwks.Get(d.TokenManager))
+
+		r.Post("/auth/register", register.Register(log, d.Storage, d.Hasher, d.Policy, d.Verifier, d.Audit))
+		r.Post("/auth/login", login.Login(log, d.Storage, d.Hasher, d.TokenManager, d.Redis, d.LoginLimiter, d.Audit))
+		r.Post("/auth/login/mfa", login.CompleteMFA(log, d.Storage, d.TokenManager, d.Redis, d.LoginLimiter, d.Audit))
+		r.Get("/auth/oidc/{provider}/start", login.OIDCStart(log, d.OIDC, d.Redis, d.OIDCStateTTL))
+		r.Get("/auth/oidc/{provider}/callback", login.OIDCCallback(log, d.OIDC, d.Storage, d.TokenManager, d.Redis, d.Audit))
+		r.Post("/auth/magic", login.MagicRequest(log, d.Storage, d.Redis, d.TokenManager, d.Mailer, d.AppURL))
+		r.Post("/auth/magic/verify", login.MagicVerify(log, d.Storage, d.TokenManager, d.Redis, d.Audit))
+		r.Post("/auth/logout", logout.Logout(log, d.Redis, d.TokenManager, d.Audit))
+		r.Post("/auth/refresh", refresh.RefreshTokens(log, d.Redis, d.TokenManager, d.Audit))
+		r.Post("/auth/verify", verify.Verify(log, d.TokenManager, d.Storage))
+		r.Post("/auth/verify/resend", verify.Resend(log, d.Storage, d.Redis, d.Verifier))
+		r.Post("/auth/password/forgot", password.Forgot(log, d.Storage, d.Redis, d.Mailer, d.Audit, d.AppURL))
+		r.Post("/auth/password/reset", password.Reset(log, d.Storage, d.Redis, d.Hasher, d.Policy, d.Audit))
+		r.Post("/auth/email/confirm", email.Confirm(log, d.Storage, d.Redis, d.Mailer, d.Audit))
 	})
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
-		r.Use(auth.AuthMiddleware(tokenManager, log))
+		r.Use(auth.AuthMiddleware(d.TokenManager, d.Redis, log))
+
+		r.Get("/auth/profile", profile.Get(log, d.Storage))
+		r.Patch("/auth/profile", profile.Update(log, d.Storage))
+
+		r.Get("/auth/sessions", sessions.List(log, d.Redis))
+		r.Delete("/auth/sessions", sessions.RevokeAll(log, d.Redis))
+		r.Delete("/auth/sessions/{id}", sessions.Revoke(log, d.Redis))
+
+		r.Post("/auth/password/change", password.Change(log, d.Storage, d.Redis, d.Hasher, d.Policy, d.LoginLimiter, d.Audit))
+		r.Post("/auth/email/change", email.Change(log, d.Storage, d.Hasher, d.Redis, d.Mailer, d.LoginLimiter, d.Audit, d.AppURL))
+		r.Delete("/auth/account", account.Delete(log, d.Storage, d.Hasher, d.Redis, d.LoginLimiter, d.Audit))
+		r.Post("/auth/account/export", account.RequestExport(log, d.Storage, d.Redis))
+		r.Get("/auth/account/export/{id}", account.GetExport(log, d.Redis))
+
+		r.Post("/auth/2fa/enroll", mfa.Enroll(log, d.Storage))
+		r.Post("/auth/2fa/confirm", mfa.Confirm(log, d.Storage, d.Audit))
+
+		r.Post("/auth/api-keys", apikeys.Create(log, d.Storage))
+		r.Get("/auth/api-keys", apikeys.List(log, d.Storage))
+		r.Delete("/auth/api-keys/{id}", apikeys.Revoke(log, d.Storage))
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireRole(log, model.RoleAdmin))
+
+			r.Post("/auth/admin/unlock", admin.Unlock(log, d.LoginLimiter))
+
+			r.Get("/auth/admin/users", admin.ListUsers(log, d.Storage))
+			r.Patch("/auth/admin/users/{id}/role", admin.ChangeRole(log, d.Storage, d.Redis, d.Audit))
+			r.Post("/auth/admin/users/{id}/disable", admin.Disable(log, d.Storage, d.Redis, d.Audit))
+			r.Post("/auth/admin/users/{id}/enable", admin.Enable(log, d.Storage, d.Audit))
+			r.Post("/auth/admin/users/{id}/logout", admin.ForceLogout(log, d.Redis, d.Audit))
 
-		r.Post("/auth/logout", logout.Logout(log, tokenManager))
-		r.Post("/auth/refresh", refresh.Refresh(log, tokenManager, storage))
-		r.Get("/auth/profile", profile.Get(log, storage))
+			r.Get("/auth/admin/audit", admin.ListAuthEvents(log, d.Storage))
+		})
 	})
 
 	return r
-} 
\ No newline at end of file
+}
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP NULL;
+
+-- Уже существующие аккаунты считаем подтверждёнными
+UPDATE users SET verified_at = NOW() WHERE verified_at IS NULL;
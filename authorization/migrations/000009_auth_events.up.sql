@@ -0,0 +1,16 @@
+-- Журнал аутентификации. Внешнего ключа на users нет: записи должны
+-- пережить удаление аккаунта, а при неудачном входе пользователь может быть неизвестен
+CREATE TABLE IF NOT EXISTS auth_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    user_id INTEGER,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    outcome VARCHAR(20) NOT NULL,
+    reason VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS auth_events_user_id_created_at_idx ON auth_events (user_id, created_at);
+CREATE INDEX IF NOT EXISTS auth_events_created_at_idx ON auth_events (created_at);
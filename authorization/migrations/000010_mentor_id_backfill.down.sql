@@ -0,0 +1 @@
+DELETE FROM outbox WHERE kind = 'user.id_backfill' AND processed_at IS NULL;
@@ -0,0 +1,9 @@
+-- Сервисы review и mentor переходят с email ментора на id пользователя.
+-- Записи, созданные до перехода, связываются по событию user.id_backfill,
+-- которое relay публикует в топик пользовательских событий. Событие получают
+-- все пользователи: запись ментора могла остаться и после снятия роли
+INSERT INTO outbox (kind, payload)
+SELECT 'user.id_backfill',
+       jsonb_build_object('type', 'user.id_backfill', 'user_id', id, 'email', email, 'role', role)
+FROM users
+ORDER BY id;
@@ -0,0 +1,118 @@
+// Package authclient клиент gRPC AuthService для других сервисов.
+// Сгенерированный код лежит в mentorlink/pkg/api/proto, здесь удобные обёртки над ним
+package authclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pb "mentorlink/pkg/api/proto"
+	"mentorlink/pkg/token"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrInactive токен или ключ недействителен: подпись, срок, отзыв или отключённый владелец
+	ErrInactive     = errors.New("credentials are not active")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type Client struct {
+	client pb.AuthServiceClient
+	conn   *grpc.ClientConn
+}
+
+// New подключается к AuthService; opts заменяют транспорт по умолчанию (без TLS внутри сети)
+func New(addr string, opts ...grpc.DialOption) (*Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial auth service: %w", err)
+	}
+
+	return &Client{
+		client: pb.NewAuthServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// IntrospectToken проверяет access токен вместе с сессией, которую мог отозвать пользователь
+func (c *Client) IntrospectToken(ctx context.Context, tokenStr string) (*token.Claims, error) {
+	resp, err := c.client.IntrospectToken(ctx, &pb.IntrospectTokenRequest{Token: tokenStr})
+	if err != nil {
+		return nil, fmt.Errorf("IntrospectToken RPC call failed: %w", err)
+	}
+	return claimsFrom(resp)
+}
+
+func (c *Client) IntrospectAPIKey(ctx context.Context, key string) (*token.Claims, error) {
+	resp, err := c.client.IntrospectAPIKey(ctx, &pb.IntrospectAPIKeyRequest{Key: key})
+	if err != nil {
+		return nil, fmt.Errorf("IntrospectAPIKey RPC call failed: %w", err)
+	}
+	return claimsFrom(resp)
+}
+
+func (c *Client) GetUser(ctx context.Context, userID int64) (*pb.User, error) {
+	resp, err := c.client.GetUser(ctx, &pb.GetUserRequest{UserId: userID})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetUser RPC call failed: %w", err)
+	}
+	return resp.User, nil
+}
+
+// GetUsersByIDs возвращает пользователей по id; отсутствующих в ответе нет
+func (c *Client) GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*pb.User, error) {
+	resp, err := c.client.GetUsersByIDs(ctx, &pb.GetUsersByIDsRequest{UserIds: userIDs})
+	if err != nil {
+		return nil, fmt.Errorf("GetUsersByIDs RPC call failed: %w", err)
+	}
+
+	users := make(map[int64]*pb.User, len(resp.Users))
+	for _, u := range resp.Users {
+		users[u.Id] = u
+	}
+	return users, nil
+}
+
+func (c *Client) IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	resp, err := c.client.IsSessionActive(ctx, &pb.IsSessionActiveRequest{UserId: userID, SessionId: sessionID})
+	if err != nil {
+		return false, fmt.Errorf("IsSessionActive RPC call failed: %w", err)
+	}
+	return resp.Active, nil
+}
+
+func claimsFrom(resp *pb.IntrospectResponse) (*token.Claims, error) {
+	if !resp.Active {
+		return nil, ErrInactive
+	}
+
+	claims := &token.Claims{
+		UserID:    resp.UserId,
+		Role:      resp.Role,
+		TokenType: token.TypeAccess,
+		Scope:     resp.Scope,
+		FamilyID:  resp.SessionId,
+	}
+	if resp.ExpiresAt > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(resp.ExpiresAt, 0))
+	}
+	return claims, nil
+}
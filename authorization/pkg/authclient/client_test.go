@@ -0,0 +1,110 @@
+package authclient
+
+import (
+	"context"
+	pb "mentorlink/pkg/api/proto"
+	"mentorlink/pkg/token"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stubServer отвечает фиксированными данными, логика сервера проверяется в internal/grpc/authservice
+type stubServer struct {
+	pb.UnimplementedAuthServiceServer
+}
+
+func (stubServer) IntrospectToken(_ context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectResponse, error) {
+	if req.Token != "good" {
+		return &pb.IntrospectResponse{}, nil
+	}
+	return &pb.IntrospectResponse{
+		Active: true, UserId: 7, Role: "mentor", Scope: "review:write", ExpiresAt: 1700000000, SessionId: "family",
+	}, nil
+}
+
+func (stubServer) GetUser(_ context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if req.UserId != 7 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &pb.GetUserResponse{User: &pb.User{Id: 7, Email: "m@example.com"}}, nil
+}
+
+func (stubServer) GetUsersByIDs(_ context.Context, req *pb.GetUsersByIDsRequest) (*pb.GetUsersByIDsResponse, error) {
+	return &pb.GetUsersByIDsResponse{Users: []*pb.User{{Id: 1}, {Id: 3}}}, nil
+}
+
+func (stubServer) IsSessionActive(_ context.Context, req *pb.IsSessionActiveRequest) (*pb.IsSessionActiveResponse, error) {
+	return &pb.IsSessionActiveResponse{Active: req.SessionId == "family"}, nil
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterAuthServiceServer(srv, stubServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c, err := New("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestIntrospectToken(t *testing.T) {
+	c := newTestClient(t)
+
+	claims, err := c.IntrospectToken(context.Background(), "good")
+	require.NoError(t, err)
+	require.Equal(t, int64(7), claims.UserID)
+	require.Equal(t, token.TypeAccess, claims.TokenType)
+	require.Equal(t, "family", claims.FamilyID)
+	require.True(t, claims.HasScope(token.ScopeReviewWrite))
+	require.Equal(t, int64(1700000000), claims.ExpiresAt.Unix())
+
+	_, err = c.IntrospectToken(context.Background(), "bad")
+	require.ErrorIs(t, err, ErrInactive)
+}
+
+func TestGetUser(t *testing.T) {
+	c := newTestClient(t)
+
+	user, err := c.GetUser(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, "m@example.com", user.Email)
+
+	_, err = c.GetUser(context.Background(), 8)
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestGetUsersByIDs(t *testing.T) {
+	c := newTestClient(t)
+
+	users, err := c.GetUsersByIDs(context.Background(), []int64{1, 2, 3})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Contains(t, users, int64(1))
+	require.NotContains(t, users, int64(2))
+}
+
+func TestIsSessionActive(t *testing.T) {
+	c := newTestClient(t)
+
+	active, err := c.IsSessionActive(context.Background(), 7, "family")
+	require.NoError(t, err)
+	require.True(t, active)
+
+	active, err = c.IsSessionActive(context.Background(), 7, "other")
+	require.NoError(t, err)
+	require.False(t, active)
+}
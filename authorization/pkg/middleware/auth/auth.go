@@ -14,7 +14,11 @@ type contextKey string
 
 const UserKey contextKey = "user"
 
-func AuthMiddleware(tm *token.TokenManager, log *slog.Logger) func(http.Handler) http.Handler {
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+func AuthMiddleware(tm *token.TokenManager, sessions SessionChecker, log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -36,6 +40,37 @@ func AuthMiddleware(tm *token.TokenManager, log *slog.Logger) func(http.Handler)
 				return
 			}
 
+			// mfa_pending токен подтверждает только пароль, к API он доступа не даёт
+			if claims.TokenType == token.TypeMFAPending {
+				log.Warn("MFA is not completed", "user_id", claims.UserID)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "mfa required"})
+				return
+			}
+
+			if claims.TokenType != token.TypeAccess {
+				log.Warn("Invalid token type", "type", claims.TokenType)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "invalid token type"})
+				return
+			}
+
+			if claims.FamilyID != "" {
+				active, err := sessions.IsSessionActive(claims.FamilyID)
+				if err != nil {
+					log.Error("Session check failed", "error", err)
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, map[string]string{"error": "server error"})
+					return
+				}
+				if !active {
+					log.Warn("Session revoked", "family_id", claims.FamilyID)
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "session revoked"})
+					return
+				}
+			}
+
 			ctx := context.WithValue(r.Context(), UserKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"log/slog"
+	"mentorlink/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// RequireRole пропускает запрос, только если роль из токена входит в roles.
+// Ставится после AuthMiddleware, который кладёт claims в контекст
+func RequireRole(log *slog.Logger, roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserKey).(*token.Claims)
+			if !ok || claims == nil {
+				log.Error("Claims missing, RequireRole used without AuthMiddleware")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "token required"})
+				return
+			}
+
+			if _, ok := allowed[claims.Role]; !ok {
+				log.Warn("Role not allowed", "user_id", claims.UserID, "role", claims.Role, "path", r.URL.Path)
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, map[string]string{"error": "forbidden"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
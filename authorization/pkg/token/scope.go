@@ -0,0 +1,35 @@
+package token
+
+import (
+	"slices"
+	"strings"
+)
+
+// Права, которые проверяют сервисы-потребители токена
+const (
+	ScopeReviewWrite    = "review:write"
+	ScopeReviewModerate = "review:moderate"
+	ScopeMentorEditSelf = "mentor:edit_self"
+	ScopeUserAdmin      = "user:admin"
+	ScopeSessionBook    = "session:book"
+)
+
+// RoleScopes политика: какие права получает роль при выдаче access токена
+var RoleScopes = map[string][]string{
+	"user":   {ScopeReviewWrite, ScopeSessionBook},
+	"mentor": {ScopeReviewWrite, ScopeMentorEditSelf, ScopeSessionBook},
+	"admin":  {ScopeReviewWrite, ScopeReviewModerate, ScopeUserAdmin},
+}
+
+// ScopeForRole собирает claim scope через пробел, как принято в OAuth 2.0
+func ScopeForRole(role string) string {
+	return strings.Join(RoleScopes[role], " ")
+}
+
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
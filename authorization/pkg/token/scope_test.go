@@ -0,0 +1,85 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *TokenManager {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.pem"), pem.EncodeToMemory(block), 0o600))
+
+	tm, err := NewTokenManagerFromDir(dir, "test")
+	require.NoError(t, err)
+	return tm
+}
+
+func TestAccessTokenScopesFollowRole(t *testing.T) {
+	tm := newTestManager(t)
+
+	cases := []struct {
+		role   string
+		has    []string
+		hasNot []string
+	}{
+		{
+			role:   "user",
+			has:    []string{ScopeReviewWrite, ScopeSessionBook},
+			hasNot: []string{ScopeReviewModerate, ScopeMentorEditSelf, ScopeUserAdmin},
+		},
+		{
+			role:   "mentor",
+			has:    []string{ScopeReviewWrite, ScopeMentorEditSelf, ScopeSessionBook},
+			hasNot: []string{ScopeReviewModerate, ScopeUserAdmin},
+		},
+		{
+			role:   "admin",
+			has:    []string{ScopeReviewWrite, ScopeReviewModerate, ScopeUserAdmin},
+			hasNot: []string{ScopeMentorEditSelf, ScopeSessionBook},
+		},
+		{
+			role:   "unknown",
+			hasNot: []string{ScopeReviewWrite, ScopeReviewModerate, ScopeMentorEditSelf, ScopeUserAdmin, ScopeSessionBook},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.role, func(t *testing.T) {
+			signed, _, err := tm.GenerateToken(1, tc.role, time.Minute, TypeAccess, "")
+			require.NoError(t, err)
+
+			claims, err := tm.ParseToken(signed)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.has, claims.Scopes())
+			for _, s := range tc.hasNot {
+				require.False(t, claims.HasScope(s), s)
+			}
+		})
+	}
+}
+
+func TestOnlyAccessTokensCarryScopes(t *testing.T) {
+	tm := newTestManager(t)
+
+	for _, tokenType := range []string{TypeRefresh, TypeMFAPending} {
+		signed, _, err := tm.GenerateToken(1, "admin", time.Minute, tokenType, "")
+		require.NoError(t, err)
+
+		claims, err := tm.ParseToken(signed)
+		require.NoError(t, err)
+		require.Empty(t, claims.Scope, tokenType)
+	}
+}
@@ -1,10 +1,14 @@
 package token
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -14,18 +18,33 @@ var (
 	ErrInvalidSigningMethod = errors.New("invalid signing method")
 	ErrTokenExpired         = errors.New("token expired")
 	ErrMissingUserID        = errors.New("missing user_id")
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrNoActiveKey          = errors.New("active signing key not found")
+)
+
+// Типы токенов, которые выдаёт сервис авторизации
+const (
+	TypeAccess     = "access"
+	TypeRefresh    = "refresh"
+	TypeMFAPending = "mfa_pending" // пароль проверен, ждём код 2FA
+	TypeMagicLink  = "magic_link"  // одноразовая ссылка для входа без пароля
 )
 
 type Claims struct {
 	UserID    int64  `json:"user_id"`
 	Role      string `json:"role"`
 	TokenType string `json:"token_type"`
+	FamilyID  string `json:"family_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type TokenManager struct {
+	// Подписываем активным ключом, проверяем любым из опубликованных
+	activeKID  string
 	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	publicKeys map[string]*rsa.PublicKey
+	kids       []string
 }
 
 func NewTokenmanagerRSA(privateKeyPath, publicKeyPath string) (*TokenManager, error) {
@@ -52,24 +71,133 @@ func NewTokenmanagerRSA(privateKeyPath, publicKeyPath string) (*TokenManager, er
 		return nil, err
 	}
 
+	kid := Thumbprint(pubKey)
 	return &TokenManager{
+		activeKID:  kid,
 		privateKey: privKey,
-		publicKey:  pubKey,
+		publicKeys: map[string]*rsa.PublicKey{kid: pubKey},
+		kids:       []string{kid},
 	}, nil
 }
 
-func (tm *TokenManager) GenerateToken(userID int64, role string, ttl time.Duration, tokenType string) (string, error) {
+// NewTokenManagerFromDir загружает набор ключей из каталога: <kid>.pem с приватным ключом
+// или <kid>.pub.pem с публичным ключом, который уже не подписывает, но ещё проверяет токены.
+func NewTokenManagerFromDir(dir, activeKID string) (*TokenManager, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys dir %s: %v", dir, err)
+	}
+
+	tm := &TokenManager{
+		activeKID:  activeKID,
+		publicKeys: make(map[string]*rsa.PublicKey),
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %v", name, err)
+		}
+
+		if kid, ok := strings.CutSuffix(name, ".pub.pem"); ok {
+			pubKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse public key %s: %w", name, err)
+			}
+			tm.addKey(kid, pubKey)
+			continue
+		}
+
+		kid := strings.TrimSuffix(name, ".pem")
+		privKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key %s: %w", name, err)
+		}
+		tm.addKey(kid, &privKey.PublicKey)
+		if kid == activeKID {
+			tm.privateKey = privKey
+		}
+	}
+
+	if tm.privateKey == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoActiveKey, activeKID)
+	}
+	return tm, nil
+}
+
+func (tm *TokenManager) addKey(kid string, pub *rsa.PublicKey) {
+	if _, ok := tm.publicKeys[kid]; !ok {
+		tm.kids = append(tm.kids, kid)
+	}
+	tm.publicKeys[kid] = pub
+}
+
+// ActiveKID идентификатор ключа, которым подписываются новые токены
+func (tm *TokenManager) ActiveKID() string {
+	return tm.activeKID
+}
+
+// JWKS публичные ключи для проверки токенов другими сервисами
+func (tm *TokenManager) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(tm.kids))}
+	for _, kid := range tm.kids {
+		set.Keys = append(set.Keys, NewJWK(kid, tm.publicKeys[kid]))
+	}
+	return set
+}
+
+// NewID возвращает случайный идентификатор для jti и семейства токенов
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("failed to read random bytes: %w", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// GenerateToken подписывает токен и возвращает его вместе с jti
+func (tm *TokenManager) GenerateToken(userID int64, role string, ttl time.Duration, tokenType, familyID string) (string, string, error) {
 	claims := &Claims{
 		UserID:    userID,
 		Role:      role,
 		TokenType: tokenType,
+		FamilyID:  familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        NewID(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 		},
 	}
 
+	// Права нужны только для доступа к API, refresh и служебные токены их не несут
+	if tokenType == TypeAccess {
+		claims.Scope = ScopeForRole(role)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(tm.privateKey)
+	token.Header["kid"] = tm.activeKID
+	signed, err := token.SignedString(tm.privateKey)
+	if err != nil {
+		return "", "", err
+	}
+	return signed, claims.ID, nil
+}
+
+// keyFor выбирает ключ по kid; токены без kid выпущены до ротации и подписаны активным ключом
+func (tm *TokenManager) keyFor(t *jwt.Token) (*rsa.PublicKey, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = tm.activeKID
+	}
+	pub, ok := tm.publicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return pub, nil
 }
 
 // Проверяем подпись публичным ключом
@@ -78,9 +206,13 @@ func (tm *TokenManager) ParseToken(tokenStr string) (*Claims, error) {
 		if t.Method != jwt.SigningMethodRS256 {
 			return nil, ErrInvalidSigningMethod
 		}
-		return tm.publicKey, nil
+		return tm.keyFor(t)
 	})
 
+	if token == nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		if claims.ExpiresAt.Before(time.Now()) {
 			return nil, ErrTokenExpired
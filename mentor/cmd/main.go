@@ -4,13 +4,21 @@ import (
 	"context"
 	"log/slog"
 	"mentor/internal/config"
+	"mentor/internal/kafka"
 	"mentor/internal/lib/logger/sl"
+	"mentor/internal/outbox"
 	"mentor/internal/server"
 	"mentor/internal/storage/cache"
 	"mentor/internal/storage/db"
+	grpcclient "mentor/internal/transport/grpc/client"
+	"mentor/internal/userdata"
+	"mentor/pkg/token"
 	"os"
 	"os/signal"
 	"syscall"
+
+	// Часовые пояса профилей проверяются и в образе без системной базы tzdata
+	_ "time/tzdata"
 )
 
 const (
@@ -34,17 +42,73 @@ func main() {
 
 	log.Debug("debug messages are enabled")
 
-	storage, err := db.NewStorage(cfg.Config)
+	storage, err := db.NewStorage(cfg.Config, cfg.Ranking)
 	if err != nil {
 		log.Error("error created storage", sl.Err(err))
 		os.Exit(1)
 	}
 
+	// Оценки заполняются после миграции и меняются вместе с приором; каталог при ошибке остаётся доступным
+	if rescored, err := storage.RecomputeRanking(ctx); err != nil {
+		log.Error("failed to recompute ranking scores", sl.Err(err))
+	} else if rescored > 0 {
+		log.Info("ranking scores recomputed", slog.Int64("mentors", rescored))
+	}
+
 	redisClient := cache.New(cfg.RedisConfig)
 
 	redisRepository := cache.NewRedisRepository(redisClient)
 
-	server, err := server.New(ctx, log, cfg, storage, redisRepository)
+	producer, err := kafka.NewProducer([]string{cfg.KafkaBroker}, cfg.ExportPartsTopic)
+	if err != nil {
+		log.Error("failed to initialize Kafka producer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer producer.Close()
+
+	// Удаление и выгрузка данных пользователя по событиям сервиса авторизации
+	userEvents, err := kafka.NewConsumer(
+		[]string{cfg.KafkaBroker},
+		cfg.KafkaGroupID,
+		userdata.NewProcessor(log, storage, redisRepository, producer),
+		log,
+	)
+	if err != nil {
+		log.Error("failed to initialize user events consumer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer userEvents.Close()
+
+	consumerCtx, stopConsumer := context.WithCancel(ctx)
+	defer stopConsumer()
+	go userEvents.Run(consumerCtx, cfg.UserEventsTopic)
+
+	sessionEvents, err := kafka.NewProducer([]string{cfg.KafkaBroker}, cfg.SessionEventsTopic)
+	if err != nil {
+		log.Error("failed to initialize session events producer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer sessionEvents.Close()
+
+	// Relay публикует события о занятиях, записанные в outbox при бронировании, отмене и переносе
+	go outbox.NewRelay(log, storage, sessionEvents, cfg.Outbox).Run(consumerCtx)
+
+	// Ключи берём у сервиса авторизации; если он ещё не поднялся, догрузим при первом запросе
+	keys := token.NewJWKSCache(cfg.JWKSURL)
+	if err := keys.Refresh(ctx); err != nil {
+		log.Warn("failed to load JWKS on start", sl.Err(err))
+	}
+	keys.Start(ctx, cfg.JWKSRefreshInterval, log)
+
+	// Через сервис авторизации проверяем, не отозвана ли сессия, в которой выдан токен
+	authClient, err := grpcclient.NewAuthClient(cfg.AuthServiceAddress)
+	if err != nil {
+		log.Error("failed to create auth client", sl.Err(err))
+		os.Exit(1)
+	}
+	defer authClient.Close()
+
+	server, err := server.New(ctx, log, cfg, storage, redisRepository, token.NewTokenManager(keys), authClient)
 	if err != nil {
 		log.Error("failed to create server", sl.Err(err))
 		cancel()
@@ -62,6 +126,7 @@ func main() {
 	}()
 
 	<-doneChan
+	stopConsumer()
 
 	err = server.Stop(ctx)
 	if err != nil {
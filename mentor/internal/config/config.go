@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"mentor/internal/outbox"
 	"mentor/internal/storage/cache"
 	postgres "mentor/internal/storage/db"
 	"time"
@@ -11,11 +12,23 @@ import (
 
 type Config struct {
 	postgres.Config
+	postgres.Ranking
 	cache.RedisConfig
+	Outbox outbox.Config
 
 	AddressServerHTTP string `env:"ADDRESS_SERVER_HTTP" env-required:"true"`
 	GRPCPort          int    `env:"GRPC_PORT" env-required:"true"`
 
+	KafkaBroker        string `env:"KAFKA_BROKERS" env-required:"true"`
+	UserEventsTopic    string `env:"KAFKA_USER_EVENTS_TOPIC" env-default:"user-events"`
+	ExportPartsTopic   string `env:"KAFKA_EXPORT_PARTS_TOPIC" env-default:"user-export-parts"`
+	SessionEventsTopic string `env:"KAFKA_SESSION_EVENTS_TOPIC" env-default:"session-events"`
+	KafkaGroupID       string `env:"KAFKA_GROUP_ID" env-default:"mentor-service"`
+
+	JWKSURL             string        `env:"JWKS_URL" env-required:"true"`
+	JWKSRefreshInterval time.Duration `env:"JWKS_REFRESH_INTERVAL" env-default:"5m"`
+	AuthServiceAddress  string        `env:"AUTH_SERVICE_ADDRESS" env-required:"true"`
+
 	Env string `env:"ENV" env-required:"true"`
 
 	Timeout     time.Duration `env:"TIMEOUT" env-default:"4s"`
@@ -0,0 +1,112 @@
+package models
+
+import "time"
+
+// Availability расписание ментора. Окна и даты исключений заданы в его часовом поясе Timezone,
+// занятие длится SessionMinutes, слоты нарезаются от начала каждого окна
+type Availability struct {
+	Timezone       string         `json:"timezone"`
+	SessionMinutes int            `json:"session_minutes"`
+	Weekly         []WeeklyWindow `json:"weekly"`
+	Exceptions     []DayException `json:"exceptions"`
+}
+
+// Window время в формате "15:04"; End "24:00" - до конца суток
+type Window struct {
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	StartMinute int    `json:"-" db:"start_minute"`
+	EndMinute   int    `json:"-" db:"end_minute"`
+}
+
+// WeeklyWindow Weekday как в time.Weekday: 0 - воскресенье
+type WeeklyWindow struct {
+	Weekday int `json:"weekday" db:"weekday"`
+	Window
+}
+
+// DayException заменяет недельное расписание на дату "2006-01-02"; пустой Windows - выходной
+type DayException struct {
+	Date    string   `json:"date"`
+	Windows []Window `json:"windows"`
+}
+
+type Slot struct {
+	Start time.Time `json:"start" db:"starts_at"`
+	End   time.Time `json:"end" db:"ends_at"`
+}
+
+// OpenSlots свободные слоты ментора; время в UTC, Timezone - для показа
+type OpenSlots struct {
+	MentorID       int64  `json:"mentor_id"`
+	Timezone       string `json:"timezone"`
+	SessionMinutes int    `json:"session_minutes"`
+	Slots          []Slot `json:"slots"`
+}
+
+// Статусы занятия
+const (
+	SessionBooked    = "booked"
+	SessionCancelled = "cancelled"
+)
+
+// Session занятие ментора MentorID с учеником MenteeID. При переносе старое занятие
+// отменяется, а новое ссылается на него через RescheduledFrom
+type Session struct {
+	ID              int64      `json:"id" db:"id"`
+	MentorID        int64      `json:"mentor_id" db:"mentor_id"`
+	MenteeID        int64      `json:"mentee_id" db:"mentee_id"`
+	StartsAt        time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt          time.Time  `json:"ends_at" db:"ends_at"`
+	Status          string     `json:"status" db:"status"`
+	Note            string     `json:"note" db:"note"`
+	RescheduledFrom int64      `json:"rescheduled_from,omitempty" db:"rescheduled_from"`
+	CancelledBy     int64      `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancelReason    string     `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+}
+
+// Типы событий о занятиях
+const (
+	EventSessionBooked    = "session.booked"
+	EventSessionCancelled = "session.cancelled"
+)
+
+// SessionEvent сообщение в топике занятий. Перенос публикуется двумя событиями:
+// session.cancelled с RescheduledTo и session.booked с RescheduledFrom
+type SessionEvent struct {
+	Type            string    `json:"type"` // session.booked/session.cancelled
+	SessionID       int64     `json:"session_id"`
+	MentorID        int64     `json:"mentor_id"`
+	MenteeID        int64     `json:"mentee_id"`
+	StartsAt        time.Time `json:"starts_at"`
+	EndsAt          time.Time `json:"ends_at"`
+	RescheduledFrom int64     `json:"rescheduled_from,omitempty"`
+	RescheduledTo   int64     `json:"rescheduled_to,omitempty"`
+	CancelledBy     int64     `json:"cancelled_by,omitempty"`
+	Reason          string    `json:"reason,omitempty"`
+}
+
+// NewSessionEvent событие о текущем состоянии занятия
+func NewSessionEvent(eventType string, s *Session) SessionEvent {
+	return SessionEvent{
+		Type:            eventType,
+		SessionID:       s.ID,
+		MentorID:        s.MentorID,
+		MenteeID:        s.MenteeID,
+		StartsAt:        s.StartsAt,
+		EndsAt:          s.EndsAt,
+		RescheduledFrom: s.RescheduledFrom,
+		CancelledBy:     s.CancelledBy,
+		Reason:          s.CancelReason,
+	}
+}
+
+// OutboxMessage событие, записанное в одной транзакции с изменением занятия
+type OutboxMessage struct {
+	ID       int64  `db:"id"`
+	Kind     string `db:"kind"`
+	Payload  []byte `db:"payload"`
+	Attempts int    `db:"attempts"`
+}
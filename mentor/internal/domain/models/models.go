@@ -1,7 +1,62 @@
 package models
 
+// MentorTable запись каталога. mentor_id пустой у записей, которые ещё не связаны
+// с пользователем сервиса авторизации; mentor_email оставлен на время перехода клиентов на id
 type MentorTable struct {
+	MentorID      int64   `json:"mentor_id,omitempty" db:"mentor_id"`
 	MentorEmail   string  `json:"mentor_email" db:"mentor_email"`
 	Contact       string  `json:"contact" db:"contact"`
 	AverageRating float32 `json:"average_rating" db:"average_rating"`
+	RankingScore  float32 `json:"ranking_score" db:"ranking_score"`
+	Profile       Profile `json:"profile" db:"-"`
+}
+
+// MentorPage страница каталога. Total - число менторов под фильтром без учёта
+// пагинации, NextCursor пустой на последней странице
+type MentorPage struct {
+	Mentors    []MentorTable `json:"mentors"`
+	Total      int           `json:"total"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// MentorProfile все данные ментора, которые хранит сервис
+type MentorProfile struct {
+	MentorID      int64   `json:"mentor_id,omitempty" db:"mentor_id"`
+	MentorEmail   string  `json:"mentor_email" db:"mentor_email"`
+	Contact       string  `json:"contact" db:"contact"`
+	Status        string  `json:"status" db:"status"`
+	CountReviews  int     `json:"count_reviews" db:"count_reviews"`
+	AverageRating float32 `json:"average_rating" db:"average_rating"`
+	RankingScore  float32 `json:"ranking_score" db:"ranking_score"`
+	Profile       Profile `json:"profile" db:"-"`
+}
+
+// Profile описание, которое ментор заполняет сам. HourlyPrice в целых единицах
+// валюты платформы, 0 - цена не указана
+type Profile struct {
+	Name        string   `json:"name"`
+	Headline    string   `json:"headline"`
+	Bio         string   `json:"bio"`
+	Skills      []string `json:"skills"`
+	Seniority   string   `json:"seniority"`
+	Languages   []string `json:"languages"`
+	Timezone    string   `json:"timezone"`
+	HourlyPrice int      `json:"hourly_price"`
+	AvatarURL   string   `json:"avatar_url"`
+}
+
+// RatingStats рейтинг ментора. Histogram - число отзывов по звёздам от "1" до "5"
+type RatingStats struct {
+	MentorID  int64          `json:"mentor_id"`
+	Count     int            `json:"count"`
+	Average   float32        `json:"average"`
+	Histogram map[string]int `json:"histogram"`
+	Trend     []RatingTrend  `json:"trend"`
+}
+
+// RatingTrend отзывы, оставленные или изменённые за последние Days дней
+type RatingTrend struct {
+	Days    int     `json:"days"`
+	Count   int     `json:"count"`
+	Average float32 `json:"average"`
 }
@@ -1,11 +1,62 @@
 package requests
 
+import (
+	"mentor/internal/domain/models"
+	"time"
+)
+
+// RatingRequest MentorID = 0 у клиентов, которые ещё передают только email,
+// ReviewID = 0 у событий старого формата
 type RatingRequest struct {
+	ReviewID    int64   `json:"review_id" db:"review_id"`
+	MentorID    int64   `json:"mentor_id" db:"mentor_id"`
 	MentorEmail string  `json:"mentor_email" db:"mentor_email"`
 	Rating      float32 `json:"rating" db:"rating"`
 }
 
+// MentorRequest Profile задаётся, только если при регистрации прислали начальный профиль
 type MentorRequest struct {
-	MentorEmail string `json:"mentor_email" db:"mentor_email"`
-	Contact     string `json:"contact" db:"contact"`
+	MentorID    int64           `json:"mentor_id" db:"mentor_id"`
+	MentorEmail string          `json:"mentor_email" db:"mentor_email"`
+	Contact     string          `json:"contact" db:"contact"`
+	Profile     *models.Profile `json:"profile,omitempty" db:"-"`
+}
+
+// ReconcileRequest рейтинг ментора по данным сервиса отзывов.
+// Stars[i] - число отзывов на i+1 звёзд, Recent - отзывы за последние 90 дней
+type ReconcileRequest struct {
+	MentorID  int64
+	Stars     [5]int
+	SumRating float64
+	Recent    []RatedReview
+}
+
+type RatedReview struct {
+	ReviewID int64
+	Rating   float32
+	RatedAt  time.Time
+}
+
+// Сортировки каталога. SortScore - по байесовской оценке, сортировка по умолчанию;
+// SortRating - по простому среднему
+const (
+	SortScore   = "score"
+	SortRating  = "rating"
+	SortReviews = "reviews"
+	SortNewest  = "newest"
+	SortPrice   = "price"
+)
+
+// MentorSearch фильтры каталога. Нулевые значения означают, что фильтр не задан:
+// ментор должен владеть всеми Skills и хотя бы одним из Languages
+type MentorSearch struct {
+	Skills    []string
+	Languages []string
+	MinPrice  int
+	MaxPrice  int
+	MinRating float64
+	Query     string
+	Sort      string
+	Limit     int
+	Cursor    string
 }
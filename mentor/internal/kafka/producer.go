@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mentor/internal/domain/models"
+
+	"github.com/IBM/sarama"
+)
+
+type Producer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewProducer(brokers []string, topic string) (*Producer, error) {
+	config := sarama.NewConfig()
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 3
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	return &Producer{producer: producer, topic: topic}, nil
+}
+
+// SendExportPart отправляет сервису авторизации часть выгрузки данных пользователя
+func (p *Producer) SendExportPart(part *models.ExportPart) error {
+	jsonData, err := json.Marshal(part)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(part.ExportID),
+		Value: sarama.ByteEncoder(jsonData),
+	}
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// Publish отправляет готовое сообщение в топик продюсера; ctx не прерывает отправку,
+// sarama ограничивает её своими таймаутами
+func (p *Producer) Publish(_ context.Context, key string, value []byte) error {
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (p *Producer) Close() error {
+	return p.producer.Close()
+}
@@ -0,0 +1,116 @@
+// Package profile проверяет и приводит к единому виду профиль ментора
+// перед сохранением: одни и те же правила для HTTP и gRPC
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"mentor/internal/domain/models"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalid = errors.New("invalid profile")
+
+const (
+	MaxName        = 100
+	MaxHeadline    = 120
+	MaxBio         = 4000
+	MaxSkills      = 20
+	MaxSkill       = 50
+	MaxLanguages   = 10
+	MaxAvatarURL   = 500
+	MaxHourlyPrice = 1_000_000
+)
+
+// Seniorities допустимые уровни; пустая строка - уровень не указан
+var Seniorities = []string{"junior", "middle", "senior", "lead", "principal"}
+
+// Коды языков ISO 639-1/639-2: en, ru, deu
+var languageRe = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// Normalize обрезает пробелы, приводит навыки и языки к нижнему регистру,
+// убирает повторы и проверяет ограничения. Ошибка оборачивает ErrInvalid
+func Normalize(p *models.Profile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if utf8.RuneCountInString(p.Name) > MaxName {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalid, MaxName)
+	}
+
+	p.Headline = strings.TrimSpace(p.Headline)
+	if utf8.RuneCountInString(p.Headline) > MaxHeadline {
+		return fmt.Errorf("%w: headline is longer than %d characters", ErrInvalid, MaxHeadline)
+	}
+
+	p.Bio = strings.TrimSpace(p.Bio)
+	if utf8.RuneCountInString(p.Bio) > MaxBio {
+		return fmt.Errorf("%w: bio is longer than %d characters", ErrInvalid, MaxBio)
+	}
+
+	p.Skills = normalizeList(p.Skills)
+	if len(p.Skills) > MaxSkills {
+		return fmt.Errorf("%w: more than %d skills", ErrInvalid, MaxSkills)
+	}
+	for _, skill := range p.Skills {
+		if utf8.RuneCountInString(skill) > MaxSkill {
+			return fmt.Errorf("%w: skill %q is longer than %d characters", ErrInvalid, skill, MaxSkill)
+		}
+	}
+
+	p.Seniority = strings.ToLower(strings.TrimSpace(p.Seniority))
+	if p.Seniority != "" && !slices.Contains(Seniorities, p.Seniority) {
+		return fmt.Errorf("%w: seniority must be one of %s", ErrInvalid, strings.Join(Seniorities, ", "))
+	}
+
+	p.Languages = normalizeList(p.Languages)
+	if len(p.Languages) > MaxLanguages {
+		return fmt.Errorf("%w: more than %d languages", ErrInvalid, MaxLanguages)
+	}
+	for _, lang := range p.Languages {
+		if !languageRe.MatchString(lang) {
+			return fmt.Errorf("%w: language %q is not an ISO 639 code", ErrInvalid, lang)
+		}
+	}
+
+	p.Timezone = strings.TrimSpace(p.Timezone)
+	if p.Timezone != "" {
+		// Local зависит от настроек сервера, а не от ментора
+		if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "Local" {
+			return fmt.Errorf("%w: unknown timezone %q", ErrInvalid, p.Timezone)
+		}
+	}
+
+	if p.HourlyPrice < 0 || p.HourlyPrice > MaxHourlyPrice {
+		return fmt.Errorf("%w: hourly_price must be between 0 and %d", ErrInvalid, MaxHourlyPrice)
+	}
+
+	p.AvatarURL = strings.TrimSpace(p.AvatarURL)
+	if p.AvatarURL != "" {
+		if len(p.AvatarURL) > MaxAvatarURL {
+			return fmt.Errorf("%w: avatar_url is longer than %d characters", ErrInvalid, MaxAvatarURL)
+		}
+		u, err := url.Parse(p.AvatarURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: avatar_url must be an http(s) URL", ErrInvalid)
+		}
+	}
+
+	return nil
+}
+
+// normalizeList пустой список остаётся пустым, а не nil, чтобы в JSON был []
+func normalizeList(items []string) []string {
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || slices.Contains(res, item) {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package profile
+
+import (
+	"errors"
+	"mentor/internal/domain/models"
+	"slices"
+	"strings"
+	"testing"
+	_ "time/tzdata"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       models.Profile
+		wantErr bool
+	}{
+		{name: "empty profile", p: models.Profile{}},
+		{
+			name: "full profile",
+			p: models.Profile{
+				Headline:    "Backend engineer",
+				Bio:         "Ten years of Go",
+				Skills:      []string{"go", "postgres"},
+				Seniority:   "senior",
+				Languages:   []string{"en", "ru"},
+				Timezone:    "Europe/Moscow",
+				HourlyPrice: 5000,
+				AvatarURL:   "https://cdn.example.com/a.png",
+			},
+		},
+		{name: "long name", p: models.Profile{Name: strings.Repeat("я", MaxName+1)}, wantErr: true},
+		{name: "long headline", p: models.Profile{Headline: strings.Repeat("я", MaxHeadline+1)}, wantErr: true},
+		{name: "headline at limit in runes", p: models.Profile{Headline: strings.Repeat("я", MaxHeadline)}},
+		{name: "long bio", p: models.Profile{Bio: strings.Repeat("a", MaxBio+1)}, wantErr: true},
+		{name: "too many skills", p: models.Profile{Skills: numbered("skill", MaxSkills+1)}, wantErr: true},
+		{name: "long skill", p: models.Profile{Skills: []string{strings.Repeat("a", MaxSkill+1)}}, wantErr: true},
+		{name: "unknown seniority", p: models.Profile{Seniority: "guru"}, wantErr: true},
+		{name: "seniority in upper case", p: models.Profile{Seniority: " Lead "}},
+		{name: "language is not a code", p: models.Profile{Languages: []string{"english"}}, wantErr: true},
+		{name: "too many languages", p: models.Profile{Languages: numbered("l", MaxLanguages+1)}, wantErr: true},
+		{name: "unknown timezone", p: models.Profile{Timezone: "Mars/Olympus"}, wantErr: true},
+		{name: "server local timezone", p: models.Profile{Timezone: "Local"}, wantErr: true},
+		{name: "negative price", p: models.Profile{HourlyPrice: -1}, wantErr: true},
+		{name: "price over limit", p: models.Profile{HourlyPrice: MaxHourlyPrice + 1}, wantErr: true},
+		{name: "http avatar", p: models.Profile{AvatarURL: "http://cdn.example.com/a.png"}},
+		{name: "javascript avatar", p: models.Profile{AvatarURL: "javascript:alert(1)"}, wantErr: true},
+		{name: "data avatar", p: models.Profile{AvatarURL: "data:image/png;base64,AAAA"}, wantErr: true},
+		{name: "avatar without host", p: models.Profile{AvatarURL: "https:///a.png"}, wantErr: true},
+		{name: "relative avatar", p: models.Profile{AvatarURL: "/a.png"}, wantErr: true},
+		{
+			name:    "long avatar",
+			p:       models.Profile{AvatarURL: "https://cdn.example.com/" + strings.Repeat("a", MaxAvatarURL)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Normalize(&tt.p)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("expected ErrInvalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeCleansFields(t *testing.T) {
+	p := models.Profile{
+		Name:      " Ivan Petrov ",
+		Headline:  "  Backend engineer ",
+		Skills:    []string{" Go", "go", "", "PostgreSQL "},
+		Seniority: " Senior",
+		Languages: []string{"EN", "en", " ru"},
+		Timezone:  " Europe/Moscow ",
+		AvatarURL: " https://cdn.example.com/a.png ",
+	}
+	if err := Normalize(&p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Name != "Ivan Petrov" || p.Headline != "Backend engineer" || p.Seniority != "senior" || p.Timezone != "Europe/Moscow" {
+		t.Fatalf("fields not trimmed: %+v", p)
+	}
+	if p.AvatarURL != "https://cdn.example.com/a.png" {
+		t.Fatalf("avatar not trimmed: %q", p.AvatarURL)
+	}
+	if !slices.Equal(p.Skills, []string{"go", "postgresql"}) {
+		t.Fatalf("skills: %v", p.Skills)
+	}
+	if !slices.Equal(p.Languages, []string{"en", "ru"}) {
+		t.Fatalf("languages: %v", p.Languages)
+	}
+
+	// Пустые списки отдаются в JSON как [], а не null
+	empty := models.Profile{}
+	if err := Normalize(&empty); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty.Skills == nil || empty.Languages == nil {
+		t.Fatalf("lists must not be nil: %+v", empty)
+	}
+}
+
+func numbered(prefix string, n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = prefix + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	return res
+}
@@ -0,0 +1,244 @@
+// Package schedule проверяет расписание ментора и нарезает его на слоты для бронирования.
+// Расписание хранится в часовом поясе ментора, слоты считаются в UTC по конкретным датам,
+// поэтому переход на летнее время сдвигает слоты в UTC, но не в поясе ментора
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"mentor/internal/domain/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid availability")
+
+const (
+	DefaultSessionMinutes = 60
+	MinSessionMinutes     = 15
+	MaxSessionMinutes     = 240
+	MaxWeeklyWindows      = 50
+	MaxExceptions         = 100
+	MaxDayWindows         = 10
+
+	// MaxRange самый длинный период, за который можно запросить слоты
+	MaxRange = 31 * 24 * time.Hour
+	// Horizon насколько вперёд можно бронировать
+	Horizon = 90 * 24 * time.Hour
+
+	dateLayout = "2006-01-02"
+)
+
+// Normalize проверяет расписание, приводит время к виду "15:04", заполняет минуты окон
+// и сортирует окна. Ошибка оборачивает ErrInvalid
+func Normalize(a *models.Availability) error {
+	a.Timezone = strings.TrimSpace(a.Timezone)
+	if a.Timezone == "" {
+		return fmt.Errorf("%w: timezone is required", ErrInvalid)
+	}
+	// Local зависит от настроек сервера, а не от ментора
+	if _, err := time.LoadLocation(a.Timezone); err != nil || a.Timezone == "Local" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalid, a.Timezone)
+	}
+
+	if a.SessionMinutes == 0 {
+		a.SessionMinutes = DefaultSessionMinutes
+	}
+	if a.SessionMinutes < MinSessionMinutes || a.SessionMinutes > MaxSessionMinutes || a.SessionMinutes%5 != 0 {
+		return fmt.Errorf("%w: session_minutes must be a multiple of 5 between %d and %d",
+			ErrInvalid, MinSessionMinutes, MaxSessionMinutes)
+	}
+
+	if len(a.Weekly) > MaxWeeklyWindows {
+		return fmt.Errorf("%w: more than %d weekly windows", ErrInvalid, MaxWeeklyWindows)
+	}
+	if a.Weekly == nil {
+		a.Weekly = []models.WeeklyWindow{}
+	}
+	for i := range a.Weekly {
+		w := &a.Weekly[i]
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return fmt.Errorf("%w: weekday must be between 0 (sunday) and 6", ErrInvalid)
+		}
+		if err := normalizeWindow(&w.Window, a.SessionMinutes); err != nil {
+			return err
+		}
+	}
+	slices.SortFunc(a.Weekly, func(x, y models.WeeklyWindow) int {
+		if x.Weekday != y.Weekday {
+			return x.Weekday - y.Weekday
+		}
+		return x.StartMinute - y.StartMinute
+	})
+	for i := 1; i < len(a.Weekly); i++ {
+		prev, cur := a.Weekly[i-1], a.Weekly[i]
+		if prev.Weekday == cur.Weekday && cur.StartMinute < prev.EndMinute {
+			return fmt.Errorf("%w: windows %s-%s and %s-%s overlap on weekday %d",
+				ErrInvalid, prev.Start, prev.End, cur.Start, cur.End, cur.Weekday)
+		}
+	}
+
+	if len(a.Exceptions) > MaxExceptions {
+		return fmt.Errorf("%w: more than %d exceptions", ErrInvalid, MaxExceptions)
+	}
+	if a.Exceptions == nil {
+		a.Exceptions = []models.DayException{}
+	}
+	for i := range a.Exceptions {
+		if err := normalizeException(&a.Exceptions[i], a.SessionMinutes); err != nil {
+			return err
+		}
+	}
+	slices.SortFunc(a.Exceptions, func(x, y models.DayException) int {
+		return strings.Compare(x.Date, y.Date)
+	})
+	for i := 1; i < len(a.Exceptions); i++ {
+		if a.Exceptions[i].Date == a.Exceptions[i-1].Date {
+			return fmt.Errorf("%w: duplicate exception for %s", ErrInvalid, a.Exceptions[i].Date)
+		}
+	}
+	return nil
+}
+
+func normalizeException(e *models.DayException, sessionMinutes int) error {
+	day, err := time.Parse(dateLayout, strings.TrimSpace(e.Date))
+	if err != nil {
+		return fmt.Errorf("%w: exception date %q must be YYYY-MM-DD", ErrInvalid, e.Date)
+	}
+	e.Date = day.Format(dateLayout)
+
+	if len(e.Windows) > MaxDayWindows {
+		return fmt.Errorf("%w: more than %d windows on %s", ErrInvalid, MaxDayWindows, e.Date)
+	}
+	if e.Windows == nil {
+		e.Windows = []models.Window{}
+	}
+	for i := range e.Windows {
+		if err := normalizeWindow(&e.Windows[i], sessionMinutes); err != nil {
+			return err
+		}
+	}
+	slices.SortFunc(e.Windows, func(x, y models.Window) int { return x.StartMinute - y.StartMinute })
+	for i := 1; i < len(e.Windows); i++ {
+		if e.Windows[i].StartMinute < e.Windows[i-1].EndMinute {
+			return fmt.Errorf("%w: windows overlap on %s", ErrInvalid, e.Date)
+		}
+	}
+	return nil
+}
+
+// normalizeWindow окно короче занятия не даёт ни одного слота, поэтому считается ошибкой
+func normalizeWindow(w *models.Window, sessionMinutes int) error {
+	start, err := parseClock(w.Start)
+	if err != nil || start == 24*60 {
+		return fmt.Errorf("%w: start %q must be HH:MM", ErrInvalid, w.Start)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("%w: end %q must be HH:MM", ErrInvalid, w.End)
+	}
+	if end-start < sessionMinutes {
+		return fmt.Errorf("%w: window %s-%s is shorter than a session", ErrInvalid, w.Start, w.End)
+	}
+	w.StartMinute, w.EndMinute = start, end
+	w.Start, w.End = Clock(start), Clock(end)
+	return nil
+}
+
+// parseClock минуты от начала суток; "24:00" допустимо как конец окна
+func parseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &h, &m); err != nil {
+		return 0, err
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("clock out of range")
+	}
+	return h*60 + m, nil
+}
+
+// Clock минуты от начала суток в виде "15:04"
+func Clock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// Slots слоты расписания, которые начинаются в [from, to) и не пересекаются с busy
+func Slots(a *models.Availability, from, to time.Time, busy []models.Slot) ([]models.Slot, error) {
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone: %w", err)
+	}
+
+	weekly := make(map[time.Weekday][]models.Window)
+	for _, w := range a.Weekly {
+		weekly[time.Weekday(w.Weekday)] = append(weekly[time.Weekday(w.Weekday)], w.Window)
+	}
+	exceptions := make(map[string][]models.Window, len(a.Exceptions))
+	for _, e := range a.Exceptions {
+		exceptions[e.Date] = e.Windows
+	}
+
+	slots := []models.Slot{}
+	first := from.In(loc)
+	last := to.In(loc)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); !day.After(last); day = day.AddDate(0, 0, 1) {
+		windows, ok := exceptions[day.Format(dateLayout)]
+		if !ok {
+			windows = weekly[day.Weekday()]
+		}
+
+		y, m, d := day.Date()
+		for _, w := range windows {
+			for minute := w.StartMinute; minute+a.SessionMinutes <= w.EndMinute; minute += a.SessionMinutes {
+				start := time.Date(y, m, d, 0, minute, 0, 0, loc)
+				end := start.Add(time.Duration(a.SessionMinutes) * time.Minute)
+				// В день перевода часов слот, попавший на пропущенный или повторённый час,
+				// начинается или заканчивается по часам ментора не тогда, когда в расписании
+				if !atWallClock(start, y, m, d, minute) || !atWallClock(end, y, m, d, minute+a.SessionMinutes) {
+					continue
+				}
+
+				slot := models.Slot{Start: start.UTC(), End: end.UTC()}
+				if slot.Start.Before(from) || !slot.Start.Before(to) {
+					continue
+				}
+				if overlaps(slot, busy) {
+					continue
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots, nil
+}
+
+// Find слот расписания, который начинается ровно в start
+func Find(a *models.Availability, start time.Time) (models.Slot, bool, error) {
+	slots, err := Slots(a, start, start.Add(time.Nanosecond), nil)
+	if err != nil {
+		return models.Slot{}, false, err
+	}
+	for _, slot := range slots {
+		if slot.Start.Equal(start) {
+			return slot, true, nil
+		}
+	}
+	return models.Slot{}, false, nil
+}
+
+// atWallClock показывают ли часы в поясе t дату y-m-d плюс minute минут; minute может выходить за сутки
+func atWallClock(t time.Time, y int, m time.Month, d, minute int) bool {
+	want := time.Date(y, m, d, 0, minute, 0, 0, time.UTC)
+	got := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return got.Equal(want)
+}
+
+func overlaps(slot models.Slot, busy []models.Slot) bool {
+	for _, b := range busy {
+		if slot.Start.Before(b.End) && b.Start.Before(slot.End) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,314 @@
+package schedule
+
+import (
+	"errors"
+	"mentor/internal/domain/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func availability(t *testing.T, a models.Availability) *models.Availability {
+	t.Helper()
+	if err := Normalize(&a); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	return &a
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func window(start, end string) models.Window {
+	return models.Window{Start: start, End: end}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       models.Availability
+		wantErr bool
+	}{
+		{name: "defaults", a: models.Availability{Timezone: "UTC"}},
+		{name: "no timezone", a: models.Availability{}, wantErr: true},
+		{name: "server local timezone", a: models.Availability{Timezone: "Local"}, wantErr: true},
+		{name: "unknown timezone", a: models.Availability{Timezone: "Mars/Olympus"}, wantErr: true},
+		{name: "session not a multiple of 5", a: models.Availability{Timezone: "UTC", SessionMinutes: 42}, wantErr: true},
+		{name: "session too long", a: models.Availability{Timezone: "UTC", SessionMinutes: MaxSessionMinutes + 5}, wantErr: true},
+		{
+			name: "window shorter than session",
+			a: models.Availability{Timezone: "UTC", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("10:00", "10:30")},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "weekday out of range",
+			a: models.Availability{Timezone: "UTC", Weekly: []models.WeeklyWindow{
+				{Weekday: 7, Window: window("10:00", "12:00")},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "overlapping weekly windows",
+			a: models.Availability{Timezone: "UTC", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("10:00", "12:00")},
+				{Weekday: 1, Window: window("11:00", "13:00")},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "window until midnight",
+			a: models.Availability{Timezone: "UTC", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("23:00", "24:00")},
+			}},
+		},
+		{
+			name: "window from midnight of next day",
+			a: models.Availability{Timezone: "UTC", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("24:00", "24:00")},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "bad exception date",
+			a: models.Availability{Timezone: "UTC", Exceptions: []models.DayException{
+				{Date: "2026-13-01"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate exception",
+			a: models.Availability{Timezone: "UTC", Exceptions: []models.DayException{
+				{Date: "2026-06-15"}, {Date: "2026-06-15"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Normalize(&tt.a)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("expected ErrInvalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeSortsAndFormats(t *testing.T) {
+	a := availability(t, models.Availability{
+		Timezone: " Europe/Berlin ",
+		Weekly: []models.WeeklyWindow{
+			{Weekday: 3, Window: window("9:00", "11:00")},
+			{Weekday: 1, Window: window("14:00", "16:00")},
+			{Weekday: 1, Window: window("8:30", "10:00")},
+		},
+	})
+
+	if a.Timezone != "Europe/Berlin" || a.SessionMinutes != DefaultSessionMinutes {
+		t.Fatalf("timezone %q, session %d", a.Timezone, a.SessionMinutes)
+	}
+	got := []string{}
+	for _, w := range a.Weekly {
+		got = append(got, Clock(w.StartMinute)+"-"+w.End)
+	}
+	want := []string{"08:30-10:00", "14:00-16:00", "09:00-11:00"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if a.Weekly[0].Start != "08:30" {
+		t.Fatalf("start not formatted: %q", a.Weekly[0].Start)
+	}
+}
+
+func TestSlots(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        models.Availability
+		from, to string
+		busy     []models.Slot
+		want     []string
+	}{
+		{
+			name: "weekly window",
+			a: models.Availability{Timezone: "Europe/Berlin", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("10:00", "12:30")},
+			}},
+			from: "2026-06-15T00:00:00Z", to: "2026-06-16T00:00:00Z",
+			want: []string{"2026-06-15T08:00:00Z", "2026-06-15T09:00:00Z"},
+		},
+		{
+			name: "from cuts earlier slots",
+			a: models.Availability{Timezone: "Europe/Berlin", Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("10:00", "12:00")},
+			}},
+			from: "2026-06-15T08:30:00Z", to: "2026-06-16T00:00:00Z",
+			want: []string{"2026-06-15T09:00:00Z"},
+		},
+		{
+			name: "busy slot is skipped",
+			a: models.Availability{Timezone: "UTC", SessionMinutes: 30, Weekly: []models.WeeklyWindow{
+				{Weekday: 1, Window: window("10:00", "12:00")},
+			}},
+			from: "2026-06-15T00:00:00Z", to: "2026-06-16T00:00:00Z",
+			busy: []models.Slot{{Start: utc("2026-06-15T10:15:00Z"), End: utc("2026-06-15T11:00:00Z")}},
+			want: []string{"2026-06-15T11:00:00Z", "2026-06-15T11:30:00Z"},
+		},
+		{
+			name: "exception overrides weekly window",
+			a: models.Availability{
+				Timezone: "UTC",
+				Weekly: []models.WeeklyWindow{
+					{Weekday: 1, Window: window("10:00", "12:00")},
+				},
+				Exceptions: []models.DayException{
+					{Date: "2026-06-15", Windows: []models.Window{window("16:00", "17:00")}},
+				},
+			},
+			from: "2026-06-15T00:00:00Z", to: "2026-06-23T00:00:00Z",
+			want: []string{"2026-06-15T16:00:00Z", "2026-06-22T10:00:00Z", "2026-06-22T11:00:00Z"},
+		},
+		{
+			name: "empty exception is a day off",
+			a: models.Availability{
+				Timezone: "UTC",
+				Weekly: []models.WeeklyWindow{
+					{Weekday: 1, Window: window("10:00", "11:00")},
+				},
+				Exceptions: []models.DayException{{Date: "2026-06-15"}},
+			},
+			from: "2026-06-15T00:00:00Z", to: "2026-06-23T00:00:00Z",
+			want: []string{"2026-06-22T10:00:00Z"},
+		},
+		{
+			name: "exception on a day without weekly windows",
+			a: models.Availability{
+				Timezone: "UTC",
+				Exceptions: []models.DayException{
+					{Date: "2026-06-20", Windows: []models.Window{window("09:00", "10:00")}},
+				},
+			},
+			from: "2026-06-15T00:00:00Z", to: "2026-06-23T00:00:00Z",
+			want: []string{"2026-06-20T09:00:00Z"},
+		},
+		{
+			// 2026-03-08 в Нью-Йорке часы переводят с 02:00 на 03:00
+			name: "spring forward drops slots around the missing hour",
+			a: models.Availability{Timezone: "America/New_York", Weekly: []models.WeeklyWindow{
+				{Weekday: 0, Window: window("00:00", "05:00")},
+			}},
+			from: "2026-03-08T00:00:00Z", to: "2026-03-09T00:00:00Z",
+			want: []string{"2026-03-08T05:00:00Z", "2026-03-08T07:00:00Z", "2026-03-08T08:00:00Z"},
+		},
+		{
+			// 2026-03-29 в Берлине часы переводят с 02:00 на 03:00
+			name: "spring forward keeps slots that fit",
+			a: models.Availability{Timezone: "Europe/Berlin", SessionMinutes: 30, Weekly: []models.WeeklyWindow{
+				{Weekday: 0, Window: window("01:00", "04:00")},
+			}},
+			from: "2026-03-28T00:00:00Z", to: "2026-03-30T00:00:00Z",
+			want: []string{"2026-03-29T00:00:00Z", "2026-03-29T01:00:00Z", "2026-03-29T01:30:00Z"},
+		},
+		{
+			// 2026-11-01 в Нью-Йорке час 01:00-02:00 проходит дважды
+			name: "fall back drops the slot in the repeated hour",
+			a: models.Availability{Timezone: "America/New_York", Weekly: []models.WeeklyWindow{
+				{Weekday: 0, Window: window("00:00", "04:00")},
+			}},
+			from: "2026-11-01T00:00:00Z", to: "2026-11-02T00:00:00Z",
+			want: []string{"2026-11-01T04:00:00Z", "2026-11-01T07:00:00Z", "2026-11-01T08:00:00Z"},
+		},
+		{
+			// 2026-10-25 в Берлине час 02:00-03:00 проходит дважды
+			name: "fall back slots keep their length",
+			a: models.Availability{Timezone: "Europe/Berlin", SessionMinutes: 90, Weekly: []models.WeeklyWindow{
+				{Weekday: 0, Window: window("00:00", "06:00")},
+			}},
+			from: "2026-10-24T00:00:00Z", to: "2026-10-26T00:00:00Z",
+			want: []string{"2026-10-24T22:00:00Z", "2026-10-25T02:00:00Z", "2026-10-25T03:30:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := availability(t, tt.a)
+			slots, err := Slots(a, utc(tt.from), utc(tt.to), tt.busy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make([]string, 0, len(slots))
+			for _, s := range slots {
+				if d := s.End.Sub(s.Start); d != time.Duration(a.SessionMinutes)*time.Minute {
+					t.Errorf("slot %s lasts %s", s.Start.Format(time.RFC3339), d)
+				}
+				got = append(got, s.Start.Format(time.RFC3339))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	a := availability(t, models.Availability{
+		Timezone: "Europe/Berlin",
+		Weekly: []models.WeeklyWindow{
+			{Weekday: 1, Window: window("10:00", "12:00")},
+		},
+		Exceptions: []models.DayException{
+			{Date: "2026-06-16", Windows: []models.Window{window("14:00", "15:00")}},
+			{Date: "2026-06-22"},
+		},
+	})
+
+	tests := []struct {
+		name  string
+		start string
+		found bool
+	}{
+		{name: "weekly slot", start: "2026-06-15T08:00:00Z", found: true},
+		{name: "second weekly slot", start: "2026-06-15T09:00:00Z", found: true},
+		{name: "off the grid", start: "2026-06-15T08:30:00Z"},
+		{name: "after the window", start: "2026-06-15T10:00:00Z"},
+		{name: "exception slot", start: "2026-06-16T12:00:00Z", found: true},
+		{name: "day off by exception", start: "2026-06-22T08:00:00Z"},
+		{name: "next regular week", start: "2026-06-29T08:00:00Z", found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := utc(tt.start)
+			slot, found, err := Find(a, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if found && (!slot.Start.Equal(start) || !slot.End.Equal(start.Add(time.Hour))) {
+				t.Fatalf("unexpected slot %v", slot)
+			}
+		})
+	}
+}
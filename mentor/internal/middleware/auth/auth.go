@@ -0,0 +1,83 @@
+package mwAuth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentor/pkg/token"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+type contextKey string
+
+const UserKey contextKey = "user"
+
+// SessionChecker спрашивает сервис авторизации, не отозвана ли сессия пользователя
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+// AuthMiddleware принимает только access токены сервиса авторизации.
+// Персональные API ключи профиль ментора не меняют, поэтому интроспекции здесь нет
+func AuthMiddleware(tokenMn *token.TokenManager, auth SessionChecker, log *slog.Logger) func(http.Handler) http.Handler {
+	sessions := newSessionCache(auth, sessionCacheTTL)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+
+			tokenStr, ok := strings.CutPrefix(authHeader, "Bearer ")
+			if !ok || tokenStr == "" {
+				log.Warn("Bearer token missing")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "token required"})
+				return
+			}
+
+			claims, err := tokenMn.ParseToken(tokenStr)
+			if err != nil {
+				if errors.Is(err, token.ErrTokenExpired) {
+					log.Warn("Token expired", "error", err)
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "token expired"})
+				} else {
+					log.Warn("Token validation failed", "error", err)
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "invalid token"})
+				}
+				return
+			}
+
+			if claims.TokenType != token.TypeAccess {
+				log.Warn("Invalid token type", "type", claims.TokenType)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "invalid token type"})
+				return
+			}
+
+			// Подпись не знает о выходе пользователя: токен с сессией живёт, пока жива сессия
+			if claims.FamilyID != "" {
+				active, err := sessions.IsSessionActive(r.Context(), claims.UserID, claims.FamilyID)
+				if err != nil {
+					log.Error("Session check failed", "error", err)
+					render.Status(r, http.StatusServiceUnavailable)
+					render.JSON(w, r, map[string]string{"error": "auth service unavailable"})
+					return
+				}
+				if !active {
+					log.Warn("Session revoked", "user_id", claims.UserID)
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "session revoked"})
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), UserKey, claims)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package mwAuth
+
+import (
+	"log/slog"
+	"mentor/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// RequireScope пропускает запрос, только если в токене есть scope.
+// Ставится после AuthMiddleware, который кладёт claims в контекст
+func RequireScope(log *slog.Logger, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserKey).(*token.Claims)
+			if !ok || claims == nil {
+				log.Error("Claims missing, RequireScope used without AuthMiddleware")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "token required"})
+				return
+			}
+
+			if !claims.HasScope(scope) {
+				log.Warn("Scope missing", "user_id", claims.UserID, "scope", scope, "path", r.URL.Path)
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, map[string]string{"error": "insufficient scope"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package mwAuth
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sessionCacheTTL сколько помнить, что сессия жива. Отозванная сессия перестаёт
+// открывать API не позже чем через это время
+const sessionCacheTTL = 5 * time.Second
+
+// sessionCache избавляет от запроса в сервис авторизации на каждый вызов API.
+// Хранятся только активные сессии: отказ всегда переспрашивается
+type sessionCache struct {
+	client SessionChecker
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	active map[string]time.Time
+}
+
+func newSessionCache(client SessionChecker, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+		active: make(map[string]time.Time),
+	}
+}
+
+func (c *sessionCache) IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	key := strconv.FormatInt(userID, 10) + ":" + sessionID
+	now := c.now()
+
+	c.mu.Lock()
+	until, ok := c.active[key]
+	c.mu.Unlock()
+	if ok && now.Before(until) {
+		return true, nil
+	}
+
+	active, err := c.client.IsSessionActive(ctx, userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !active {
+		delete(c.active, key)
+		return false, nil
+	}
+	for k, exp := range c.active {
+		if !now.Before(exp) {
+			delete(c.active, k)
+		}
+	}
+	c.active[key] = now.Add(c.ttl)
+	return true, nil
+}
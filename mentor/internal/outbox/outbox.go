@@ -0,0 +1,132 @@
+// Package outbox публикует в Kafka события о занятиях, записанные в одной транзакции
+// с бронированием. Relay повторяет публикацию до успеха, поэтому получатель может
+// увидеть событие дважды и должен различать их по session_id и type.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/lib/logger/sl"
+	"strconv"
+	"time"
+)
+
+type Store interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	MarkOutboxProcessed(ctx context.Context, id int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time) error
+}
+
+// EventPublisher key задаёт партицию: события одного ментора обрабатываются по порядку
+type EventPublisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+}
+
+type Config struct {
+	Interval    time.Duration `env:"OUTBOX_INTERVAL" env-default:"2s"`
+	BatchSize   int           `env:"OUTBOX_BATCH_SIZE" env-default:"50"`
+	Lease       time.Duration `env:"OUTBOX_LEASE" env-default:"1m"`
+	BaseBackoff time.Duration `env:"OUTBOX_BASE_BACKOFF" env-default:"2s"`
+	MaxBackoff  time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"5m"`
+	CallTimeout time.Duration `env:"OUTBOX_CALL_TIMEOUT" env-default:"5s"`
+}
+
+type Relay struct {
+	log    *slog.Logger
+	store  Store
+	events EventPublisher
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewRelay(log *slog.Logger, store Store, events EventPublisher, cfg Config) *Relay {
+	return &Relay{
+		log:    log.With(slog.String("component", "outbox.relay")),
+		store:  store,
+		events: events,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run обрабатывает outbox каждые Interval, пока не отменён ctx
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessBatch(ctx); err != nil {
+			r.log.Error("failed to process outbox", sl.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch публикует одну пачку сообщений и возвращает число опубликованных
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	const op = "outbox.Relay.ProcessBatch"
+
+	msgs, err := r.store.ClaimOutbox(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivered := 0
+	for _, m := range msgs {
+		if err := r.deliver(ctx, m); err != nil {
+			next := r.now().Add(r.backoff(m.Attempts))
+			r.log.Warn("outbox delivery failed",
+				slog.Int64("id", m.ID),
+				slog.String("kind", m.Kind),
+				slog.Int("attempts", m.Attempts+1),
+				slog.Time("next_attempt_at", next),
+				sl.Err(err),
+			)
+			if err := r.store.MarkOutboxFailed(ctx, m.ID, err.Error(), next); err != nil {
+				return delivered, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+
+		if err := r.store.MarkOutboxProcessed(ctx, m.ID); err != nil {
+			return delivered, fmt.Errorf("%s: %w", op, err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (r *Relay) deliver(ctx context.Context, m models.OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.CallTimeout)
+	defer cancel()
+
+	switch m.Kind {
+	case models.EventSessionBooked, models.EventSessionCancelled:
+		var e models.SessionEvent
+		if err := json.Unmarshal(m.Payload, &e); err != nil {
+			return fmt.Errorf("decode payload: %w", err)
+		}
+		return r.events.Publish(ctx, strconv.FormatInt(e.MentorID, 10), m.Payload)
+	default:
+		return fmt.Errorf("unknown outbox kind %q", m.Kind)
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := 0; i < attempts; i++ {
+		d *= 2
+		if d >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return d
+}
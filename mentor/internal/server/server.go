@@ -7,11 +7,21 @@ import (
 	"mentor/internal/config"
 	"mentor/internal/domain/models"
 	"mentor/internal/domain/requests"
+	mwAuth "mentor/internal/middleware/auth"
 	"mentor/internal/transport/grpc/mentorservice"
+	"mentor/internal/transport/http/handlers/getavailability"
+	"mentor/internal/transport/http/handlers/getmentor"
 	get "mentor/internal/transport/http/handlers/getmentors"
+	"mentor/internal/transport/http/handlers/getrating"
+	"mentor/internal/transport/http/handlers/getslots"
+	"mentor/internal/transport/http/handlers/sessions"
+	"mentor/internal/transport/http/handlers/setavailability"
+	"mentor/internal/transport/http/handlers/updateprofile"
 	client "mentor/pkg/api/proto"
+	"mentor/pkg/token"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"golang.org/x/sync/errgroup"
@@ -22,13 +32,28 @@ type PostgresRepository interface {
 	UpdateMentor(ctx context.Context, mentor *requests.RatingRequest) error
 	DeleteReviewByMentor(ctx context.Context, mentor *requests.RatingRequest) error
 	CreateMentor(ctx context.Context, mentor *requests.MentorRequest) error
-	Get(ctx context.Context) ([]models.MentorTable, error)
-	MentorExists(ctx context.Context, mentorEmail string) (bool, error)
+	SearchMentors(ctx context.Context, search *requests.MentorSearch) (*models.MentorPage, error)
+	GetPublicMentor(ctx context.Context, mentorID int64) (*models.MentorTable, error)
+	UpdateProfile(ctx context.Context, mentorID int64, profile *models.Profile) error
+	GetActiveMentor(ctx context.Context, mentorID int64, mentorEmail string) (*models.MentorProfile, error)
+	ActivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	DeactivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	ReconcileRating(ctx context.Context, req *requests.ReconcileRequest) (bool, error)
+	GetRatingStats(ctx context.Context, mentorID int64) (*models.RatingStats, error)
+	SaveAvailability(ctx context.Context, mentorID int64, availability *models.Availability) error
+	GetAvailability(ctx context.Context, mentorID int64) (*models.Availability, error)
+	BookedPeriods(ctx context.Context, mentorID int64, from, to time.Time) ([]models.Slot, error)
+	BookSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, sessionID, userID int64) (*models.Session, error)
+	ListSessions(ctx context.Context, userID int64, from time.Time, limit int) ([]models.Session, error)
+	CancelSession(ctx context.Context, sessionID, userID int64, reason string) (*models.Session, error)
+	RescheduleSession(ctx context.Context, sessionID, userID int64, slot models.Slot) (*models.Session, error)
 }
 
 type RedisRepository interface {
-	GetMentors(ctx context.Context) ([]models.MentorTable, error, bool)
-	SaveMentors(ctx context.Context, mentor []models.MentorTable) error
+	GetMentors(ctx context.Context, query string) (*models.MentorPage, error, bool)
+	SaveMentors(ctx context.Context, query string, page *models.MentorPage) error
+	InvalidateMentors(ctx context.Context) error
 }
 
 type Server struct {
@@ -37,7 +62,7 @@ type Server struct {
 	grpcListener net.Listener
 }
 
-func New(ctx context.Context, log *slog.Logger, cfg *config.Config, postgresRepository PostgresRepository, redisRepository RedisRepository) (*Server, error) {
+func New(ctx context.Context, log *slog.Logger, cfg *config.Config, postgresRepository PostgresRepository, redisRepository RedisRepository, tokenMn *token.TokenManager, auth mwAuth.SessionChecker) (*Server, error) {
 	gRPCaddr := fmt.Sprintf(":%d", cfg.GRPCPort)
 	grpcListener, err := net.Listen("tcp", gRPCaddr)
 	if err != nil {
@@ -51,6 +76,32 @@ func New(ctx context.Context, log *slog.Logger, cfg *config.Config, postgresRepo
 
 	router := chi.NewRouter()
 	router.Get("/mentors/get", get.Get(ctx, log, postgresRepository, redisRepository))
+	router.Get("/mentors/{id}", getmentor.Get(log, postgresRepository))
+	router.Get("/mentors/{id}/rating", getrating.Get(log, postgresRepository))
+	router.Get("/mentors/{id}/availability", getavailability.Get(log, postgresRepository))
+	router.Get("/mentors/{id}/slots", getslots.Get(log, postgresRepository))
+
+	// Ментор правит только свой профиль и расписание: id берётся из токена
+	router.Group(func(r chi.Router) {
+		r.Use(
+			mwAuth.AuthMiddleware(tokenMn, auth, log),
+			mwAuth.RequireScope(log, token.ScopeMentorEditSelf),
+		)
+		r.Put("/mentors/me", updateprofile.Update(log, postgresRepository, redisRepository))
+		r.Put("/mentors/me/availability", setavailability.Set(log, postgresRepository))
+	})
+
+	// Занятия видны и ученику, и ментору; чужое занятие отвечает 404
+	router.Group(func(r chi.Router) {
+		r.Use(
+			mwAuth.AuthMiddleware(tokenMn, auth, log),
+			mwAuth.RequireScope(log, token.ScopeSessionBook),
+		)
+		r.Get("/mentors/sessions", sessions.List(log, postgresRepository))
+		r.Post("/mentors/sessions", sessions.Book(log, postgresRepository))
+		r.Post("/mentors/sessions/{id}/cancel", sessions.Cancel(log, postgresRepository))
+		r.Post("/mentors/sessions/{id}/reschedule", sessions.Reschedule(log, postgresRepository))
+	})
 
 	httpSrv := &http.Server{
 		Addr:         cfg.AddressServerHTTP,
@@ -35,9 +35,12 @@ func NewRedisRepository(redisClient *redis.Client) *RedisRepository {
 	return &RedisRepository{Client: redisClient}
 }
 
-func (r *RedisRepository) GetMentors(ctx context.Context) ([]models.MentorTable, error, bool) {
+// mentorsPrefix общий префикс страниц каталога; ключ страницы - нормализованный запрос
+const mentorsPrefix = "mentors:"
+
+func (r *RedisRepository) GetMentors(ctx context.Context, query string) (*models.MentorPage, error, bool) {
 	const op = "storage.cache.GetMentors"
-	key := "mentors"
+	key := mentorsPrefix + query
 	cacheData, err := r.Client.Get(key).Result()
 	if err == redis.Nil {
 		return nil, nil, false
@@ -46,20 +49,20 @@ func (r *RedisRepository) GetMentors(ctx context.Context) ([]models.MentorTable,
 		return nil, fmt.Errorf("%s: %w", op, err), false
 	}
 
-	var mentors []models.MentorTable
-	if err := json.Unmarshal([]byte(cacheData), &mentors); err != nil {
+	var page models.MentorPage
+	if err := json.Unmarshal([]byte(cacheData), &page); err != nil {
 		_ = r.Client.Del(key)
 		return nil, fmt.Errorf("%s: invalid cache data: %w", op, err), false
 	}
 
-	return mentors, nil, true
+	return &page, nil, true
 }
 
-func (r *RedisRepository) SaveMentors(ctx context.Context, mentor []models.MentorTable) error {
+func (r *RedisRepository) SaveMentors(ctx context.Context, query string, page *models.MentorPage) error {
 	const op = "storage.cache.SaveMentor"
-	key := "mentors"
+	key := mentorsPrefix + query
 
-	data, err := json.Marshal(mentor)
+	data, err := json.Marshal(page)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -69,3 +72,25 @@ func (r *RedisRepository) SaveMentors(ctx context.Context, mentor []models.Mento
 	}
 	return nil
 }
+
+// InvalidateMentors сбрасывает все закэшированные страницы каталога, чтобы изменения были видны сразу.
+// Страницы живут минуту, поэтому ключей немного и SCAN обходится дёшево
+func (r *RedisRepository) InvalidateMentors(ctx context.Context) error {
+	const op = "storage.cache.InvalidateMentors"
+	var cursor uint64
+	for {
+		keys, next, err := r.Client.Scan(cursor, mentorsPrefix+"*", 100).Result()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if len(keys) > 0 {
+			if err := r.Client.Del(keys...).Err(); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,445 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mentor/internal/domain/models"
+	"mentor/internal/lib/schedule"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNoSchedule      = errors.New("mentor has not published availability")
+	ErrSlotTaken       = errors.New("slot is already booked")
+	ErrMenteeBusy      = errors.New("mentee has another session at this time")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionClosed   = errors.New("session is cancelled or already started")
+)
+
+// Причины отмены, которые ставит сам сервис
+const (
+	ReasonRescheduled = "rescheduled"
+	ReasonUserDeleted = "user_deleted"
+)
+
+// sessionColumns поля занятия для запросов по mentor_sessions s с mentors m
+const sessionColumns = `s.id, COALESCE(m.mentor_id, 0) AS mentor_id, s.mentee_id,
+			  lower(s.period) AS starts_at, upper(s.period) AS ends_at, s.status, s.note,
+			  COALESCE(s.rescheduled_from, 0) AS rescheduled_from, COALESCE(s.cancelled_by, 0) AS cancelled_by,
+			  s.cancel_reason, s.created_at, s.cancelled_at`
+
+// SaveAvailability заменяет расписание ментора целиком; уже забронированные занятия остаются
+func (s *Storage) SaveAvailability(ctx context.Context, mentorID int64, a *models.Availability) error {
+	const op = "storage.db.postgres.SaveAvailability"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var rowID int64
+	err = tx.GetContext(ctx, &rowID, `SELECT id FROM mentors WHERE mentor_id=$1 FOR UPDATE`, mentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMentorNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `INSERT INTO mentor_schedules (mentor_row_id, timezone, session_minutes)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (mentor_row_id) DO UPDATE
+			  SET timezone=EXCLUDED.timezone, session_minutes=EXCLUDED.session_minutes, updated_at=NOW()`
+	if _, err := tx.ExecContext(ctx, query, rowID, a.Timezone, a.SessionMinutes); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	weekdays := make([]int64, 0, len(a.Weekly))
+	starts := make([]int64, 0, len(a.Weekly))
+	ends := make([]int64, 0, len(a.Weekly))
+	for _, w := range a.Weekly {
+		weekdays = append(weekdays, int64(w.Weekday))
+		starts = append(starts, int64(w.StartMinute))
+		ends = append(ends, int64(w.EndMinute))
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_availability WHERE mentor_row_id=$1`, rowID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	query = `INSERT INTO mentor_availability (mentor_row_id, weekday, start_minute, end_minute)
+			 SELECT $1, w, st, en FROM unnest($2::int[], $3::int[], $4::int[]) AS t(w, st, en)`
+	if _, err := tx.ExecContext(ctx, query, rowID, pq.Array(weekdays), pq.Array(starts), pq.Array(ends)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Выходной хранится строкой без окна: в массивах он помечен -1
+	var days []string
+	starts, ends = starts[:0], ends[:0]
+	for _, e := range a.Exceptions {
+		if len(e.Windows) == 0 {
+			days, starts, ends = append(days, e.Date), append(starts, -1), append(ends, -1)
+		}
+		for _, w := range e.Windows {
+			days, starts, ends = append(days, e.Date), append(starts, int64(w.StartMinute)), append(ends, int64(w.EndMinute))
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_availability_exceptions WHERE mentor_row_id=$1`, rowID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	query = `INSERT INTO mentor_availability_exceptions (mentor_row_id, day, start_minute, end_minute)
+			 SELECT $1, d::date, NULLIF(st, -1), NULLIF(en, -1) FROM unnest($2::text[], $3::int[], $4::int[]) AS t(d, st, en)`
+	if _, err := tx.ExecContext(ctx, query, rowID, pq.StringArray(days), pq.Array(starts), pq.Array(ends)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetAvailability расписание ментора из каталога; прошедшие исключения не возвращаются
+func (s *Storage) GetAvailability(ctx context.Context, mentorID int64) (*models.Availability, error) {
+	const op = "storage.db.postgres.GetAvailability"
+	query := `SELECT m.id, s.timezone, s.session_minutes
+			  FROM mentors m
+			  LEFT JOIN mentor_schedules s ON s.mentor_row_id = m.id
+			  WHERE m.mentor_id=$1 AND m.status='active'`
+
+	var row struct {
+		ID             int64          `db:"id"`
+		Timezone       sql.NullString `db:"timezone"`
+		SessionMinutes sql.NullInt64  `db:"session_minutes"`
+	}
+	err := s.db.GetContext(ctx, &row, query, mentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMentorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !row.Timezone.Valid {
+		return nil, ErrNoSchedule
+	}
+
+	a := &models.Availability{
+		Timezone:       row.Timezone.String,
+		SessionMinutes: int(row.SessionMinutes.Int64),
+		Weekly:         []models.WeeklyWindow{},
+		Exceptions:     []models.DayException{},
+	}
+
+	query = `SELECT weekday, start_minute, end_minute FROM mentor_availability
+			 WHERE mentor_row_id=$1
+			 ORDER BY weekday, start_minute`
+	if err := s.db.SelectContext(ctx, &a.Weekly, query, row.ID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range a.Weekly {
+		fillClock(&a.Weekly[i].Window)
+	}
+
+	// Вчерашняя дата в поясе ментора может быть сегодняшней в UTC
+	query = `SELECT to_char(day, 'YYYY-MM-DD') AS day, start_minute, end_minute
+			 FROM mentor_availability_exceptions
+			 WHERE mentor_row_id=$1 AND day >= CURRENT_DATE - 1
+			 ORDER BY day, start_minute NULLS FIRST`
+	var exceptions []struct {
+		Day         string        `db:"day"`
+		StartMinute sql.NullInt64 `db:"start_minute"`
+		EndMinute   sql.NullInt64 `db:"end_minute"`
+	}
+	if err := s.db.SelectContext(ctx, &exceptions, query, row.ID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, e := range exceptions {
+		if n := len(a.Exceptions); n == 0 || a.Exceptions[n-1].Date != e.Day {
+			a.Exceptions = append(a.Exceptions, models.DayException{Date: e.Day, Windows: []models.Window{}})
+		}
+		if !e.StartMinute.Valid {
+			continue
+		}
+		w := models.Window{StartMinute: int(e.StartMinute.Int64), EndMinute: int(e.EndMinute.Int64)}
+		fillClock(&w)
+		last := &a.Exceptions[len(a.Exceptions)-1]
+		last.Windows = append(last.Windows, w)
+	}
+	return a, nil
+}
+
+func fillClock(w *models.Window) {
+	w.Start, w.End = schedule.Clock(w.StartMinute), schedule.Clock(w.EndMinute)
+}
+
+// BookedPeriods занятые интервалы ментора, которые пересекаются с [from, to)
+func (s *Storage) BookedPeriods(ctx context.Context, mentorID int64, from, to time.Time) ([]models.Slot, error) {
+	const op = "storage.db.postgres.BookedPeriods"
+	query := `SELECT lower(s.period) AS starts_at, upper(s.period) AS ends_at
+			  FROM mentor_sessions s
+			  JOIN mentors m ON m.id = s.mentor_row_id
+			  WHERE m.mentor_id=$1 AND s.status='booked' AND s.period && tstzrange($2::timestamptz, $3::timestamptz)
+			  ORDER BY lower(s.period)`
+	periods := []models.Slot{}
+	if err := s.db.SelectContext(ctx, &periods, query, mentorID, from, to); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return periods, nil
+}
+
+// BookSession бронирует занятие; пересечение с другим занятием ментора или ученика
+// отклоняет ограничение исключения в базе
+func (s *Storage) BookSession(ctx context.Context, session *models.Session) error {
+	const op = "storage.db.postgres.BookSession"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var rowID int64
+	err = tx.GetContext(ctx, &rowID, `SELECT id FROM mentors WHERE mentor_id=$1 AND status='active' FOR SHARE`, session.MentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMentorNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := insertSession(ctx, tx, rowID, session); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := insertSessionEvent(ctx, tx, models.NewSessionEvent(models.EventSessionBooked, session)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func insertSession(ctx context.Context, tx *sqlx.Tx, rowID int64, session *models.Session) error {
+	query := `INSERT INTO mentor_sessions (mentor_row_id, mentee_id, period, note, rescheduled_from)
+			  VALUES ($1, $2, tstzrange($3::timestamptz, $4::timestamptz), $5, NULLIF($6, 0))
+			  RETURNING id, status, created_at`
+	err := tx.QueryRowxContext(ctx, query, rowID, session.MenteeID, session.StartsAt, session.EndsAt,
+		session.Note, session.RescheduledFrom).Scan(&session.ID, &session.Status, &session.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+		if pqErr.Constraint == "mentor_sessions_mentee_overlap" {
+			return ErrMenteeBusy
+		}
+		return ErrSlotTaken
+	}
+	if err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+func insertSessionEvent(ctx context.Context, tx *sqlx.Tx, event models.SessionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal session event: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO outbox (kind, payload) VALUES ($1, $2)`, event.Type, payload); err != nil {
+		return fmt.Errorf("insert outbox: %w", err)
+	}
+	return nil
+}
+
+// GetSession занятие, в котором userID ментор или ученик; чужие занятия не находятся
+func (s *Storage) GetSession(ctx context.Context, sessionID, userID int64) (*models.Session, error) {
+	const op = "storage.db.postgres.GetSession"
+	query := `SELECT ` + sessionColumns + `
+			  FROM mentor_sessions s
+			  JOIN mentors m ON m.id = s.mentor_row_id
+			  WHERE s.id=$1 AND (s.mentee_id=$2 OR m.mentor_id=$2)`
+
+	var session models.Session
+	err := s.db.GetContext(ctx, &session, query, sessionID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &session, nil
+}
+
+// ListSessions занятия пользователя как ментора и как ученика, которые заканчиваются после from
+func (s *Storage) ListSessions(ctx context.Context, userID int64, from time.Time, limit int) ([]models.Session, error) {
+	const op = "storage.db.postgres.ListSessions"
+	query := `SELECT ` + sessionColumns + `
+			  FROM mentor_sessions s
+			  JOIN mentors m ON m.id = s.mentor_row_id
+			  WHERE (s.mentee_id=$1 OR m.mentor_id=$1) AND upper(s.period) > $2
+			  ORDER BY lower(s.period), s.id
+			  LIMIT $3`
+	sessions := []models.Session{}
+	if err := s.db.SelectContext(ctx, &sessions, query, userID, from, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sessions, nil
+}
+
+// lockSession блокирует занятие, которое ещё можно отменить или перенести
+func lockSession(ctx context.Context, tx *sqlx.Tx, sessionID, userID int64) (*models.Session, int64, error) {
+	query := `SELECT ` + sessionColumns + `, s.mentor_row_id
+			  FROM mentor_sessions s
+			  JOIN mentors m ON m.id = s.mentor_row_id
+			  WHERE s.id=$1 AND (s.mentee_id=$2 OR m.mentor_id=$2)
+			  FOR UPDATE OF s`
+
+	var row struct {
+		models.Session
+		MentorRowID int64 `db:"mentor_row_id"`
+	}
+	err := tx.GetContext(ctx, &row, query, sessionID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("lock session: %w", err)
+	}
+	if row.Status != models.SessionBooked || !row.StartsAt.After(time.Now()) {
+		return nil, 0, ErrSessionClosed
+	}
+	return &row.Session, row.MentorRowID, nil
+}
+
+func cancelSession(ctx context.Context, tx *sqlx.Tx, session *models.Session, userID int64, reason string) error {
+	query := `UPDATE mentor_sessions
+			  SET status='cancelled', cancelled_by=$2, cancel_reason=$3, cancelled_at=NOW()
+			  WHERE id=$1
+			  RETURNING cancelled_at`
+	if err := tx.QueryRowxContext(ctx, query, session.ID, userID, reason).Scan(&session.CancelledAt); err != nil {
+		return fmt.Errorf("cancel session: %w", err)
+	}
+	session.Status = models.SessionCancelled
+	session.CancelledBy = userID
+	session.CancelReason = reason
+	return nil
+}
+
+// CancelSession отменяет занятие по просьбе ментора или ученика, пока оно не началось
+func (s *Storage) CancelSession(ctx context.Context, sessionID, userID int64, reason string) (*models.Session, error) {
+	const op = "storage.db.postgres.CancelSession"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	session, _, err := lockSession(ctx, tx, sessionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := cancelSession(ctx, tx, session, userID, reason); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := insertSessionEvent(ctx, tx, models.NewSessionEvent(models.EventSessionCancelled, session)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return session, nil
+}
+
+// RescheduleSession отменяет занятие и в той же транзакции бронирует новое на slot;
+// если новое время занято, старое занятие остаётся
+func (s *Storage) RescheduleSession(ctx context.Context, sessionID, userID int64, slot models.Slot) (*models.Session, error) {
+	const op = "storage.db.postgres.RescheduleSession"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	old, rowID, err := lockSession(ctx, tx, sessionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := cancelSession(ctx, tx, old, userID, ReasonRescheduled); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	session := &models.Session{
+		MentorID:        old.MentorID,
+		MenteeID:        old.MenteeID,
+		StartsAt:        slot.Start,
+		EndsAt:          slot.End,
+		Note:            old.Note,
+		RescheduledFrom: old.ID,
+	}
+	if err := insertSession(ctx, tx, rowID, session); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cancelled := models.NewSessionEvent(models.EventSessionCancelled, old)
+	cancelled.RescheduledTo = session.ID
+	if err := insertSessionEvent(ctx, tx, cancelled); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := insertSessionEvent(ctx, tx, models.NewSessionEvent(models.EventSessionBooked, session)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return session, nil
+}
+
+// DeleteUserSessions отменяет будущие занятия удалённого пользователя с событиями для второй
+// стороны и удаляет занятия, где он ученик. Занятия ментора удалятся вместе с его записью.
+// При повторной доставке отменять уже нечего
+func (s *Storage) DeleteUserSessions(ctx context.Context, userID int64) (int, error) {
+	const op = "storage.db.postgres.DeleteUserSessions"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + sessionColumns + `
+			  FROM mentor_sessions s
+			  JOIN mentors m ON m.id = s.mentor_row_id
+			  WHERE (s.mentee_id=$1 OR m.mentor_id=$1) AND s.status='booked' AND lower(s.period) > NOW()
+			  FOR UPDATE OF s`
+	var sessions []models.Session
+	if err := tx.SelectContext(ctx, &sessions, query, userID); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range sessions {
+		if err := cancelSession(ctx, tx, &sessions[i], userID, ReasonUserDeleted); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := insertSessionEvent(ctx, tx, models.NewSessionEvent(models.EventSessionCancelled, &sessions[i])); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_sessions WHERE mentee_id=$1`, userID); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return len(sessions), nil
+}
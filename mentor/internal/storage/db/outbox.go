@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"mentor/internal/domain/models"
+	"time"
+)
+
+// ClaimOutbox забирает готовые к отправке сообщения и откладывает их на lease,
+// чтобы параллельный relay не взял те же самые
+func (s *Storage) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	const op = "storage.db.postgres.ClaimOutbox"
+	query := `UPDATE outbox SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+			  WHERE id IN (
+				  SELECT id FROM outbox
+				  WHERE processed_at IS NULL AND next_attempt_at <= NOW()
+				  ORDER BY id
+				  LIMIT $1
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING id, kind, payload, attempts`
+
+	var msgs []models.OutboxMessage
+	if err := s.db.SelectContext(ctx, &msgs, query, limit, lease.Milliseconds()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return msgs, nil
+}
+
+func (s *Storage) MarkOutboxProcessed(ctx context.Context, id int64) error {
+	const op = "storage.db.postgres.MarkOutboxProcessed"
+	if _, err := s.db.ExecContext(ctx, `UPDATE outbox SET processed_at = NOW(), last_error = '' WHERE id=$1`, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) MarkOutboxFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time) error {
+	const op = "storage.db.postgres.MarkOutboxFailed"
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id=$1`
+	if _, err := s.db.ExecContext(ctx, query, id, lastError, nextAttempt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -2,14 +2,19 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"mentor/internal/domain/models"
 	"mentor/internal/domain/requests"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+var ErrMentorNotFound = errors.New("mentor not found")
+
 type Config struct {
 	UserName string `env:"POSTGRES_USER" env-required:"true"`
 	Password string `env:"POSTGRES_PASSWORD" env-required:"true"`
@@ -18,11 +23,44 @@ type Config struct {
 	DBName   string `env:"POSTGRES_DB" env-required:"true"`
 }
 
+// Ranking приор байесовского среднего: ментор без отзывов получает PriorMean,
+// а каждый отзыв сдвигает оценку тем слабее, чем больше PriorWeight
+type Ranking struct {
+	PriorMean   float64 `env:"RANKING_PRIOR_MEAN" env-default:"3.5"`
+	PriorWeight float64 `env:"RANKING_PRIOR_WEIGHT" env-default:"10"`
+}
+
+// Score байесовское среднее (PriorWeight*PriorMean + sum) / (PriorWeight + count).
+// Единственное место, где считается ranking_score: запросы сохраняют его значение
+func (r Ranking) Score(count int, sum float64) float64 {
+	return (r.PriorWeight*r.PriorMean + sum) / (r.PriorWeight + float64(count))
+}
+
+// scoreRow счётчики отзывов ментора, по которым считается ranking_score
+type scoreRow struct {
+	ID    int64   `db:"id"`
+	Count int     `db:"count_reviews"`
+	Sum   float64 `db:"sum_rating"`
+}
+
+// saveScore пересчитывает ranking_score по счётчикам, которые ментор получил в этой транзакции
+func (s *Storage) saveScore(ctx context.Context, tx *sqlx.Tx, row scoreRow) error {
+	_, err := tx.ExecContext(ctx, `UPDATE mentors SET ranking_score=$2 WHERE id=$1`,
+		row.ID, s.ranking.Score(row.Count, row.Sum))
+	return err
+}
+
 type Storage struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	ranking Ranking
 }
 
-func NewStorage(cfg Config) (*Storage, error) {
+func NewStorage(cfg Config, ranking Ranking) (*Storage, error) {
+	// При нулевом весе оценка ментора без отзывов делится на ноль
+	if ranking.PriorWeight <= 0 {
+		return nil, fmt.Errorf("ranking prior weight must be positive, got %v", ranking.PriorWeight)
+	}
+
 	dsn := fmt.Sprintf("host=%s port=%s  user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.UserName, cfg.Password, cfg.DBName)
 
@@ -31,70 +69,330 @@ func NewStorage(cfg Config) (*Storage, error) {
 		return nil, fmt.Errorf("error with connect to database: %w", err)
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{db: db, ranking: ranking}, nil
+}
+
+// RecomputeRanking пересчитывает оценки после смены приора в конфиге и после миграции,
+// которая добавила ranking_score; при прежнем приоре строки не меняются
+func (s *Storage) RecomputeRanking(ctx context.Context) (int64, error) {
+	const op = "storage.db.postgres.RecomputeRanking"
+
+	var rows []struct {
+		scoreRow
+		Score float64 `db:"ranking_score"`
+	}
+	query := `SELECT id, count_reviews, sum_rating, ranking_score FROM mentors`
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var ids pq.Int64Array
+	var scores pq.Float64Array
+	for _, row := range rows {
+		score := s.ranking.Score(row.Count, row.Sum)
+		if math.Abs(score-row.Score) > 1e-9 {
+			ids = append(ids, row.ID)
+			scores = append(scores, score)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query = `UPDATE mentors m SET ranking_score = t.score
+			 FROM unnest($1::bigint[], $2::float8[]) AS t(id, score)
+			 WHERE m.id = t.id`
+	result, err := s.db.ExecContext(ctx, query, ids, scores)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated, nil
+}
+
+// mentorKey колонка и значение для поиска ментора: по id, а для клиентов,
+// которые ещё передают только email, по email
+func mentorKey(mentorID int64, mentorEmail string) (string, any) {
+	if mentorID > 0 {
+		return "mentor_id", mentorID
+	}
+	return "mentor_email", mentorEmail
+}
+
+// profileColumns поля профиля для запросов по mentors m с profileJoin;
+// у ментора, который ещё не заполнил профиль, они пустые
+const profileColumns = `COALESCE(p.name, '') AS name, COALESCE(p.headline, '') AS headline, COALESCE(p.bio, '') AS bio,
+			  COALESCE(p.seniority, '') AS seniority, COALESCE(p.timezone, '') AS timezone,
+			  COALESCE(p.hourly_price, 0) AS hourly_price, COALESCE(p.avatar_url, '') AS avatar_url,
+			  ARRAY(SELECT skill FROM mentor_skills WHERE mentor_row_id = m.id ORDER BY position) AS skills,
+			  ARRAY(SELECT language FROM mentor_languages WHERE mentor_row_id = m.id ORDER BY position) AS languages`
+
+const profileJoin = `LEFT JOIN mentor_profiles p ON p.mentor_row_id = m.id`
+
+type profileRow struct {
+	Name        string         `db:"name"`
+	Headline    string         `db:"headline"`
+	Bio         string         `db:"bio"`
+	Seniority   string         `db:"seniority"`
+	Timezone    string         `db:"timezone"`
+	HourlyPrice int            `db:"hourly_price"`
+	AvatarURL   string         `db:"avatar_url"`
+	Skills      pq.StringArray `db:"skills"`
+	Languages   pq.StringArray `db:"languages"`
+}
+
+func (r *profileRow) profile() models.Profile {
+	return models.Profile{
+		Name:        r.Name,
+		Headline:    r.Headline,
+		Bio:         r.Bio,
+		Skills:      append([]string{}, r.Skills...),
+		Seniority:   r.Seniority,
+		Languages:   append([]string{}, r.Languages...),
+		Timezone:    r.Timezone,
+		HourlyPrice: r.HourlyPrice,
+		AvatarURL:   r.AvatarURL,
+	}
+}
+
+type mentorRow struct {
+	models.MentorTable
+	profileRow
+}
+
+func (r *mentorRow) mentor() models.MentorTable {
+	mentor := r.MentorTable
+	mentor.Profile = r.profile()
+	return mentor
 }
 
 func (s *Storage) CreateMentor(ctx context.Context, mentor *requests.MentorRequest) error {
 	const op = "storage.db.postgres.SaveMentor"
-	queury := `INSERT INTO mentors (mentor_email, contact)
-			   VALUES($1, $2)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// Сервис авторизации доставляет NewMentor через outbox и может повторить вызов,
+	// поэтому повторная вставка того же ментора не ошибка, не сбрасывает статус
+	// и не затирает профиль, который ментор успел изменить
+	queury := `INSERT INTO mentors (mentor_id, mentor_email, contact, status, ranking_score)
+			   VALUES(NULLIF($1, 0), $2, $3, 'pending', $4)
+			   ON CONFLICT DO NOTHING
 			   RETURNING id`
-	var newID int64
-	err := s.db.QueryRow(queury, mentor.MentorEmail, mentor.Contact).Scan(&newID)
+	var rowID int64
+	err = tx.QueryRowxContext(ctx, queury, mentor.MentorID, mentor.MentorEmail, mentor.Contact, s.ranking.Score(0, 0)).Scan(&rowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if mentor.Profile != nil {
+		if err := saveProfile(ctx, tx, rowID, mentor.Profile); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	return nil
 }
 
-func (s *Storage) Get(ctx context.Context) ([]models.MentorTable, error) {
-	const op = "storage.db.postgres.Get"
-	query := `SELECT mentor_email, contact, average_rating
+// UpdateProfile заменяет профиль ментора целиком
+func (s *Storage) UpdateProfile(ctx context.Context, mentorID int64, profile *models.Profile) error {
+	const op = "storage.db.postgres.UpdateProfile"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var rowID int64
+	err = tx.GetContext(ctx, &rowID, `SELECT id FROM mentors WHERE mentor_id=$1 FOR UPDATE`, mentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMentorNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := saveProfile(ctx, tx, rowID, profile); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// saveProfile навыки и языки перезаписываются, их порядок берётся из профиля
+func saveProfile(ctx context.Context, tx *sqlx.Tx, rowID int64, profile *models.Profile) error {
+	query := `INSERT INTO mentor_profiles (mentor_row_id, name, headline, bio, seniority, timezone, hourly_price, avatar_url)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  ON CONFLICT (mentor_row_id) DO UPDATE
+			  SET name=EXCLUDED.name, headline=EXCLUDED.headline, bio=EXCLUDED.bio, seniority=EXCLUDED.seniority,
+			      timezone=EXCLUDED.timezone, hourly_price=EXCLUDED.hourly_price,
+			      avatar_url=EXCLUDED.avatar_url, updated_at=NOW()`
+	_, err := tx.ExecContext(ctx, query, rowID, profile.Name, profile.Headline, profile.Bio, profile.Seniority,
+		profile.Timezone, profile.HourlyPrice, profile.AvatarURL)
+	if err != nil {
+		return fmt.Errorf("save profile: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_skills WHERE mentor_row_id=$1`, rowID); err != nil {
+		return fmt.Errorf("delete skills: %w", err)
+	}
+	query = `INSERT INTO mentor_skills (mentor_row_id, position, skill)
+			 SELECT $1, ord, skill FROM unnest($2::text[]) WITH ORDINALITY AS t(skill, ord)`
+	if _, err := tx.ExecContext(ctx, query, rowID, pq.StringArray(profile.Skills)); err != nil {
+		return fmt.Errorf("save skills: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_languages WHERE mentor_row_id=$1`, rowID); err != nil {
+		return fmt.Errorf("delete languages: %w", err)
+	}
+	query = `INSERT INTO mentor_languages (mentor_row_id, position, language)
+			 SELECT $1, ord, language FROM unnest($2::text[]) WITH ORDINALITY AS t(language, ord)`
+	if _, err := tx.ExecContext(ctx, query, rowID, pq.StringArray(profile.Languages)); err != nil {
+		return fmt.Errorf("save languages: %w", err)
+	}
+	return nil
+}
+
+// GetPublicMentor карточка ментора из каталога; неактивные менторы не показываются
+func (s *Storage) GetPublicMentor(ctx context.Context, mentorID int64) (*models.MentorTable, error) {
+	const op = "storage.db.postgres.GetPublicMentor"
+	query := `SELECT COALESCE(m.mentor_id, 0) AS mentor_id, m.mentor_email, m.contact, m.average_rating, m.ranking_score,
+			  ` + profileColumns + `
+			  FROM mentors m
+			  ` + profileJoin + `
+			  WHERE m.mentor_id=$1 AND m.status='active'`
+
+	var row mentorRow
+	err := s.db.GetContext(ctx, &row, query, mentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMentorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	mentor := row.mentor()
+	return &mentor, nil
+}
+
+// GetActiveMentor ищет ментора, который виден в каталоге
+func (s *Storage) GetActiveMentor(ctx context.Context, mentorID int64, mentorEmail string) (*models.MentorProfile, error) {
+	const op = "storage.db.postgres.GetActiveMentor"
+	column, key := mentorKey(mentorID, mentorEmail)
+	query := `SELECT COALESCE(mentor_id, 0) AS mentor_id, mentor_email, contact, status, count_reviews, average_rating, ranking_score
 			  FROM mentors
-			  ORDER BY average_rating DESC;`
+			  WHERE ` + column + `=$1 AND status='active'`
 
-	var mentors []models.MentorTable
-	err := s.db.Select(&mentors, query)
+	var mentor models.MentorProfile
+	err := s.db.GetContext(ctx, &mentor, query, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMentorNotFound
+	}
 	if err != nil {
-		return []models.MentorTable{}, fmt.Errorf("%s, %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return mentors, nil
+	return &mentor, nil
 }
 
-func (s *Storage) UpdateMentor(ctx context.Context, mentor *requests.RatingRequest) error {
-	const op = "storage.db.postgres.UpdateMentor"
-	query := `UPDATE mentors
-			  SET count_reviews = count_reviews + 1, sum_rating = sum_rating + $1
-			  WHERE mentor_email=$2`
-	_, err := s.db.Exec(query, mentor.Rating, mentor.MentorEmail)
+func (s *Storage) ActivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error {
+	const op = "storage.db.postgres.ActivateMentor"
+	column, key := mentorKey(mentorID, mentorEmail)
+	query := `UPDATE mentors SET status='active' WHERE ` + column + `=$1`
+	result, err := s.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if rows == 0 {
+		return ErrMentorNotFound
+	}
+	return nil
+}
 
+// DeactivateMentor скрывает ментора из каталога; отсутствие записи не ошибка,
+// скрывать в этом случае нечего
+func (s *Storage) DeactivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error {
+	const op = "storage.db.postgres.DeactivateMentor"
+	column, key := mentorKey(mentorID, mentorEmail)
+	query := `UPDATE mentors SET status='inactive' WHERE ` + column + `=$1`
+	if _, err := s.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	return nil
 }
 
-func (s *Storage) DeleteReviewByMentor(ctx context.Context, mentor *requests.RatingRequest) error {
-	const op = "storage.db.postgres.DeleteReviewByMentor"
-	query := `UPDATE mentors
-			  SET count_reviews = count_reviews - 1, sum_rating = sum_rating - $1
-			  WHERE mentor_email=$2`
-	_, err := s.db.Exec(query, mentor.Rating, mentor.MentorEmail)
-	if err != nil {
+// DeleteMentor удаляет профиль ментора вместе с рейтингом; отсутствие записи не ошибка,
+// событие об удалении пользователя приходит и для тех, кто не был ментором.
+// Email нужен для записей, которые ещё не связаны с id
+func (s *Storage) DeleteMentor(ctx context.Context, mentorID int64, mentorEmail string) error {
+	const op = "storage.db.postgres.DeleteMentor"
+	query := `DELETE FROM mentors WHERE mentor_id=$1 OR mentor_email=$2`
+	if _, err := s.db.ExecContext(ctx, query, mentorID, mentorEmail); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	return nil
+}
 
+// RekeyMentor меняет email ментора; запись ищется по id, а если она ещё не связана, по старому email
+func (s *Storage) RekeyMentor(ctx context.Context, mentorID int64, oldEmail, newEmail string) error {
+	const op = "storage.db.postgres.RekeyMentor"
+	query := `UPDATE mentors SET mentor_email=$1 WHERE mentor_id=$2 OR mentor_email=$3`
+	if _, err := s.db.ExecContext(ctx, query, newEmail, mentorID, oldEmail); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	return nil
 }
 
-func (s *Storage) MentorExists(ctx context.Context, mentorEmail string) (bool, error) {
-	const op = "storage.db.postgres.CheckMentorByEmail"
-	query := `SELECT EXISTS(SELECT 1 FROM mentors WHERE mentor_email=$1)`
-	var exists bool
-	err := s.db.GetContext(ctx, &exists, query, mentorEmail)
+// LinkMentorID связывает запись, созданную по email, с id пользователя
+func (s *Storage) LinkMentorID(ctx context.Context, mentorID int64, mentorEmail string) error {
+	const op = "storage.db.postgres.LinkMentorID"
+	query := `UPDATE mentors SET mentor_id=$1 WHERE mentor_email=$2 AND mentor_id IS NULL`
+	if _, err := s.db.ExecContext(ctx, query, mentorID, mentorEmail); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetMentor все данные ментора вместе с профилем, в том числе неактивного
+func (s *Storage) GetMentor(ctx context.Context, mentorID int64, mentorEmail string) (*models.MentorProfile, error) {
+	const op = "storage.db.postgres.GetMentor"
+	query := `SELECT COALESCE(m.mentor_id, 0) AS mentor_id, m.mentor_email, m.contact, m.status,
+			  m.count_reviews, m.average_rating, m.ranking_score,
+			  ` + profileColumns + `
+			  FROM mentors m
+			  ` + profileJoin + `
+			  WHERE m.mentor_id=$1 OR m.mentor_email=$2
+			  LIMIT 1`
+
+	var row struct {
+		models.MentorProfile
+		profileRow
+	}
+	err := s.db.GetContext(ctx, &row, query, mentorID, mentorEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMentorNotFound
+	}
 	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return exists, nil
+
+	mentor := row.MentorProfile
+	mentor.Profile = row.profile()
+	return &mentor, nil
 }
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRankingScore(t *testing.T) {
+	ranking := Ranking{PriorMean: 3.5, PriorWeight: 10}
+
+	tests := []struct {
+		name  string
+		count int
+		sum   float64
+		want  float64
+	}{
+		{name: "no reviews", want: 3.5},
+		{name: "one 5.0 review", count: 1, sum: 5, want: 40.0 / 11},
+		{name: "200 reviews at 4.8", count: 200, sum: 200 * 4.8, want: 995.0 / 210},
+		{name: "one 1.0 review", count: 1, sum: 1, want: 36.0 / 11},
+		// После удаления единственного отзыва оценка возвращается к приору
+		{name: "deleted down to zero", count: 1 - 1, sum: 5 - 5, want: 3.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranking.Score(tt.count, tt.sum); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Score(%d, %v) = %v, want %v", tt.count, tt.sum, got, tt.want)
+			}
+		})
+	}
+
+	// Один случайный отзыв не обгоняет стабильно высокий рейтинг
+	if one, many := ranking.Score(1, 5), ranking.Score(200, 960); one >= many {
+		t.Fatalf("single 5.0 review (%v) ranks above 200 reviews at 4.8 (%v)", one, many)
+	}
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/requests"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// TrendWindows окна динамики оценок в днях
+var TrendWindows = []int{30, 90}
+
+// starColumn счётчик звезды для оценки: округление до целого в пределах 1..5,
+// так же считает сервис отзывов при сверке
+func starColumn(rating float32) string {
+	star := int(math.Round(float64(rating)))
+	star = max(1, min(5, star))
+	return "stars_" + strconv.Itoa(star)
+}
+
+// UpdateMentor учитывает новый отзыв. Kafka может доставить событие повторно, поэтому
+// отзыв с review_id засчитывается только при первой вставке в mentor_ratings
+func (s *Storage) UpdateMentor(ctx context.Context, mentor *requests.RatingRequest) error {
+	const op = "storage.db.postgres.UpdateMentor"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	column, key := mentorKey(mentor.MentorID, mentor.MentorEmail)
+	var rowID int64
+	err = tx.GetContext(ctx, &rowID, `SELECT id FROM mentors WHERE `+column+`=$1 FOR UPDATE`, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if mentor.ReviewID > 0 {
+		query := `INSERT INTO mentor_ratings (review_id, mentor_row_id, rating, rated_at)
+				  VALUES ($1, $2, $3, NOW())
+				  ON CONFLICT (review_id) DO NOTHING`
+		result, err := tx.ExecContext(ctx, query, mentor.ReviewID, rowID, mentor.Rating)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if inserted == 0 {
+			return nil
+		}
+	}
+
+	star := starColumn(mentor.Rating)
+	query := `UPDATE mentors
+			  SET count_reviews = count_reviews + 1, sum_rating = sum_rating + $1,
+			      ` + star + ` = ` + star + ` + 1
+			  WHERE id=$2
+			  RETURNING id, count_reviews, sum_rating`
+	var row scoreRow
+	if err := tx.GetContext(ctx, &row, query, mentor.Rating, rowID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.saveScore(ctx, tx, row); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteReviewByMentor(ctx context.Context, mentor *requests.RatingRequest) error {
+	const op = "storage.db.postgres.DeleteReviewByMentor"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	column, key := mentorKey(mentor.MentorID, mentor.MentorEmail)
+	star := starColumn(mentor.Rating)
+	// Счётчик звезды не уходит в минус, если отзыв был поставлен до его появления
+	query := `UPDATE mentors
+			  SET count_reviews = count_reviews - 1, sum_rating = sum_rating - $1,
+			      ` + star + ` = GREATEST(` + star + ` - 1, 0)
+			  WHERE ` + column + `=$2
+			  RETURNING id, count_reviews, sum_rating`
+	var row scoreRow
+	err = tx.GetContext(ctx, &row, query, mentor.Rating, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.saveScore(ctx, tx, row); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if mentor.ReviewID > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_ratings WHERE review_id=$1`, mentor.ReviewID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetRatingStats рейтинг активного ментора с распределением по звёздам и динамикой
+func (s *Storage) GetRatingStats(ctx context.Context, mentorID int64) (*models.RatingStats, error) {
+	const op = "storage.db.postgres.GetRatingStats"
+	query := `SELECT id, count_reviews, average_rating, stars_1, stars_2, stars_3, stars_4, stars_5
+			  FROM mentors
+			  WHERE mentor_id=$1 AND status='active'`
+
+	var row struct {
+		ID            int64   `db:"id"`
+		CountReviews  int     `db:"count_reviews"`
+		AverageRating float32 `db:"average_rating"`
+		Stars1        int     `db:"stars_1"`
+		Stars2        int     `db:"stars_2"`
+		Stars3        int     `db:"stars_3"`
+		Stars4        int     `db:"stars_4"`
+		Stars5        int     `db:"stars_5"`
+	}
+	err := s.db.GetContext(ctx, &row, query, mentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMentorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats := &models.RatingStats{
+		MentorID: mentorID,
+		Count:    row.CountReviews,
+		Average:  row.AverageRating,
+		Histogram: map[string]int{
+			"1": row.Stars1, "2": row.Stars2, "3": row.Stars3, "4": row.Stars4, "5": row.Stars5,
+		},
+		Trend: make([]models.RatingTrend, 0, len(TrendWindows)),
+	}
+
+	query = `SELECT COUNT(*) AS count, COALESCE(ROUND(AVG(rating)::NUMERIC, 1), 0) AS average
+			 FROM mentor_ratings
+			 WHERE mentor_row_id=$1 AND rated_at > NOW() - make_interval(days => $2)`
+	for _, days := range TrendWindows {
+		trend := models.RatingTrend{Days: days}
+		if err := s.db.GetContext(ctx, &trend, query, row.ID, days); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		stats.Trend = append(stats.Trend, trend)
+	}
+	return stats, nil
+}
+
+// ReconcileRating заменяет счётчики и динамику ментора данными сервиса отзывов.
+// Возвращает true, если счётчики разошлись с присланными
+func (s *Storage) ReconcileRating(ctx context.Context, req *requests.ReconcileRequest) (bool, error) {
+	const op = "storage.db.postgres.ReconcileRating"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var old struct {
+		ID        int64         `db:"id"`
+		Count     int           `db:"count_reviews"`
+		SumRating float64       `db:"sum_rating"`
+		Stars     pq.Int64Array `db:"stars"`
+	}
+	query := `SELECT id, count_reviews, sum_rating, ARRAY[stars_1, stars_2, stars_3, stars_4, stars_5] AS stars
+			  FROM mentors
+			  WHERE mentor_id=$1
+			  FOR UPDATE`
+	err = tx.GetContext(ctx, &old, query, req.MentorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrMentorNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, drift := ratingDrift(old.Count, old.SumRating, old.Stars, req)
+
+	query = `UPDATE mentors
+			 SET count_reviews=$2, sum_rating=$3,
+			     stars_1=$4, stars_2=$5, stars_3=$6, stars_4=$7, stars_5=$8,
+			     ranking_score=$9
+			 WHERE id=$1`
+	_, err = tx.ExecContext(ctx, query, old.ID, count, req.SumRating,
+		req.Stars[0], req.Stars[1], req.Stars[2], req.Stars[3], req.Stars[4],
+		s.ranking.Score(count, req.SumRating))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentor_ratings WHERE mentor_row_id=$1`, old.ID); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make(pq.Int64Array, 0, len(req.Recent))
+	ratings := make(pq.Float64Array, 0, len(req.Recent))
+	ratedAt := make(pq.Int64Array, 0, len(req.Recent))
+	for _, r := range req.Recent {
+		ids = append(ids, r.ReviewID)
+		ratings = append(ratings, float64(r.Rating))
+		ratedAt = append(ratedAt, r.RatedAt.Unix())
+	}
+	query = `INSERT INTO mentor_ratings (review_id, mentor_row_id, rating, rated_at)
+			 SELECT id, $1, rating, to_timestamp(rated_at)
+			 FROM unnest($2::bigint[], $3::float8[], $4::bigint[]) AS t(id, rating, rated_at)
+			 ON CONFLICT (review_id) DO UPDATE
+			 SET mentor_row_id=EXCLUDED.mentor_row_id, rating=EXCLUDED.rating, rated_at=EXCLUDED.rated_at`
+	if _, err := tx.ExecContext(ctx, query, old.ID, ids, ratings, ratedAt); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return drift, nil
+}
+
+// ratingDrift число отзывов по счётчикам сервиса отзывов и разошлись ли с ними
+// сохранённые count, sum и stars
+func ratingDrift(count int, sum float64, stars []int64, req *requests.ReconcileRequest) (int, bool) {
+	total := 0
+	drift := math.Abs(sum-req.SumRating) > 1e-6
+	for i, n := range req.Stars {
+		total += n
+		if i >= len(stars) || stars[i] != int64(n) {
+			drift = true
+		}
+	}
+	return total, drift || count != total
+}
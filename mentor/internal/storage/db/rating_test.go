@@ -0,0 +1,70 @@
+package db
+
+import (
+	"mentor/internal/domain/requests"
+	"testing"
+)
+
+func TestStarColumn(t *testing.T) {
+	tests := []struct {
+		rating float32
+		want   string
+	}{
+		{rating: 0, want: "stars_1"},
+		{rating: -2, want: "stars_1"},
+		{rating: 1, want: "stars_1"},
+		{rating: 1.49, want: "stars_1"},
+		{rating: 1.5, want: "stars_2"},
+		{rating: 3, want: "stars_3"},
+		{rating: 4.5, want: "stars_5"},
+		{rating: 5, want: "stars_5"},
+		{rating: 7, want: "stars_5"},
+	}
+	for _, tt := range tests {
+		if got := starColumn(tt.rating); got != tt.want {
+			t.Errorf("starColumn(%v) = %q, want %q", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestRatingDriftAllReviewsDeleted(t *testing.T) {
+	// Сервис отзывов присылает нули ментору, у которого удалили все отзывы
+	req := &requests.ReconcileRequest{MentorID: 1}
+	count, drift := ratingDrift(2, 9, []int64{0, 0, 0, 1, 1}, req)
+	if count != 0 || !drift {
+		t.Fatalf("count = %d, drift = %v; want 0, true", count, drift)
+	}
+	if count, drift := ratingDrift(0, 0, []int64{0, 0, 0, 0, 0}, req); count != 0 || drift {
+		t.Fatalf("already zero: count = %d, drift = %v", count, drift)
+	}
+}
+
+func TestRatingDrift(t *testing.T) {
+	req := &requests.ReconcileRequest{MentorID: 1, Stars: [5]int{0, 1, 0, 2, 3}, SumRating: 25}
+
+	tests := []struct {
+		name      string
+		count     int
+		sum       float64
+		stars     []int64
+		wantDrift bool
+	}{
+		{name: "in sync", count: 6, sum: 25, stars: []int64{0, 1, 0, 2, 3}},
+		{name: "float noise in sum", count: 6, sum: 25 + 1e-9, stars: []int64{0, 1, 0, 2, 3}},
+		{name: "sum differs", count: 6, sum: 24, stars: []int64{0, 1, 0, 2, 3}, wantDrift: true},
+		{name: "count differs", count: 7, sum: 25, stars: []int64{0, 1, 0, 2, 3}, wantDrift: true},
+		{name: "star moved", count: 6, sum: 25, stars: []int64{0, 1, 0, 3, 2}, wantDrift: true},
+		{name: "stars missing", count: 6, sum: 25, stars: []int64{0, 1, 0}, wantDrift: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, drift := ratingDrift(tt.count, tt.sum, tt.stars, req)
+			if count != 6 {
+				t.Fatalf("count = %d, want 6", count)
+			}
+			if drift != tt.wantDrift {
+				t.Fatalf("drift = %v, want %v", drift, tt.wantDrift)
+			}
+		})
+	}
+}
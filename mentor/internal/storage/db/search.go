@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/requests"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortSpec выражение сортировки; при равных значениях порядок задаёт mentors.id,
+// поэтому пара (key, id) однозначно определяет место в выдаче
+type sortSpec struct {
+	key  string
+	desc bool
+}
+
+var sorts = map[string]sortSpec{
+	requests.SortScore:   {key: "m.ranking_score", desc: true},
+	requests.SortRating:  {key: "m.average_rating", desc: true},
+	requests.SortReviews: {key: "m.count_reviews", desc: true},
+	requests.SortNewest:  {key: "m.id", desc: true},
+	// Менторы без цены в конце выдачи
+	requests.SortPrice: {key: "COALESCE(NULLIF(p.hourly_price, 0), 2147483647)", desc: false},
+}
+
+// cursor позиция последнего ментора страницы. Sort не даёт продолжить
+// выдачу курсором от другой сортировки
+type cursor struct {
+	Sort  string  `json:"s"`
+	Value float64 `json:"v"`
+	ID    int64   `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s, sort string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type searchRow struct {
+	mentorRow
+	RowID   int64   `db:"row_id"`
+	SortKey float64 `db:"sort_key"`
+}
+
+// searchQuery запросы страницы каталога: count считает всех подходящих менторов,
+// page выбирает страницу после курсора с одной лишней строкой
+type searchQuery struct {
+	count     string
+	countArgs []any
+	page      string
+	pageArgs  []any
+}
+
+func buildSearch(search *requests.MentorSearch) (*searchQuery, error) {
+	spec, ok := sorts[search.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", search.Sort)
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	where := []string{"m.status = 'active'"}
+	for _, skill := range search.Skills {
+		where = append(where, `EXISTS (SELECT 1 FROM mentor_skills s WHERE s.mentor_row_id = m.id AND s.skill = `+arg(skill)+`)`)
+	}
+	if len(search.Languages) > 0 {
+		where = append(where, `EXISTS (SELECT 1 FROM mentor_languages l WHERE l.mentor_row_id = m.id AND l.language = ANY(`+arg(pq.StringArray(search.Languages))+`))`)
+	}
+	if search.MinPrice > 0 {
+		where = append(where, "p.hourly_price >= "+arg(search.MinPrice))
+	}
+	if search.MaxPrice > 0 {
+		where = append(where, "p.hourly_price > 0 AND p.hourly_price <= "+arg(search.MaxPrice))
+	}
+	if search.MinRating > 0 {
+		where = append(where, "m.average_rating >= "+arg(search.MinRating))
+	}
+	if search.Query != "" {
+		where = append(where, "p.search_vector @@ websearch_to_tsquery('simple', "+arg(search.Query)+")")
+	}
+
+	from := `FROM mentors m
+			  ` + profileJoin + `
+			  WHERE ` + strings.Join(where, " AND ")
+	q := &searchQuery{
+		count:     `SELECT COUNT(*) ` + from,
+		countArgs: append([]any{}, args...),
+	}
+
+	cmp, order := ">", "ASC"
+	if spec.desc {
+		cmp, order = "<", "DESC"
+	}
+	if search.Cursor != "" {
+		c, err := decodeCursor(search.Cursor, search.Sort)
+		if err != nil {
+			return nil, err
+		}
+		from += " AND ((" + spec.key + ")::float8, m.id) " + cmp + " (" + arg(c.Value) + "::float8, " + arg(c.ID) + ")"
+	}
+
+	// Лишняя строка показывает, есть ли следующая страница
+	q.page = `SELECT m.id AS row_id, (` + spec.key + `)::float8 AS sort_key,
+			  COALESCE(m.mentor_id, 0) AS mentor_id, m.mentor_email, m.contact, m.average_rating, m.ranking_score,
+			  ` + profileColumns + `
+			  ` + from + `
+			  ORDER BY ` + spec.key + ` ` + order + `, m.id ` + order + `
+			  LIMIT ` + arg(search.Limit+1)
+	q.pageArgs = args
+	return q, nil
+}
+
+// SearchMentors страница активных менторов под фильтром, пагинация по курсору
+func (s *Storage) SearchMentors(ctx context.Context, search *requests.MentorSearch) (*models.MentorPage, error) {
+	const op = "storage.db.postgres.SearchMentors"
+
+	q, err := buildSearch(search)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &models.MentorPage{Mentors: []models.MentorTable{}}
+	if err := s.db.GetContext(ctx, &page.Total, q.count, q.countArgs...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var rows []searchRow
+	if err := s.db.SelectContext(ctx, &rows, q.page, q.pageArgs...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(rows) > search.Limit {
+		rows = rows[:search.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeCursor(cursor{Sort: search.Sort, Value: last.SortKey, ID: last.RowID})
+	}
+	for i := range rows {
+		page.Mentors = append(page.Mentors, rows[i].mentor())
+	}
+	return page, nil
+}
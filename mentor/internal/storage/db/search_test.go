@@ -0,0 +1,168 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"mentor/internal/domain/requests"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := cursor{Sort: requests.SortRating, Value: 4.75, ID: 42}
+	got, err := decodeCursor(encodeCursor(want), requests.SortRating)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != want {
+		t.Fatalf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"s":"score","v":1,"id":1}`))},
+		{name: "not json", cursor: raw("score:1:1")},
+		{name: "other sort", cursor: encodeCursor(cursor{Sort: requests.SortPrice, Value: 1, ID: 1})},
+		{name: "no sort", cursor: raw(`{"v":1,"id":1}`)},
+		{name: "zero id", cursor: encodeCursor(cursor{Sort: requests.SortScore, Value: 1})},
+		{name: "negative id", cursor: raw(`{"s":"score","v":1,"id":-5}`)},
+		{name: "value of wrong type", cursor: raw(`{"s":"score","v":"1","id":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, requests.SortScore); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSortSpecs(t *testing.T) {
+	// Каждой сортировке, которую принимает API, нужно выражение в запросе
+	all := []string{requests.SortScore, requests.SortRating, requests.SortReviews, requests.SortNewest, requests.SortPrice}
+	if len(sorts) != len(all) {
+		t.Fatalf("sorts has %d entries, want %d", len(sorts), len(all))
+	}
+	for _, sort := range all {
+		spec, ok := sorts[sort]
+		if !ok || spec.key == "" {
+			t.Fatalf("no sort spec for %q", sort)
+		}
+		// Дешёвые менторы первыми, для остальных сортировок лучшие первыми
+		if spec.desc != (sort != requests.SortPrice) {
+			t.Fatalf("sort %q has desc = %v", sort, spec.desc)
+		}
+	}
+}
+
+func TestBuildSearchOrder(t *testing.T) {
+	tests := []struct {
+		sort      string
+		wantOrder string
+	}{
+		{sort: requests.SortScore, wantOrder: "ORDER BY m.ranking_score DESC, m.id DESC"},
+		{sort: requests.SortRating, wantOrder: "ORDER BY m.average_rating DESC, m.id DESC"},
+		{sort: requests.SortPrice, wantOrder: "ORDER BY COALESCE(NULLIF(p.hourly_price, 0), 2147483647) ASC, m.id ASC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			q, err := buildSearch(&requests.MentorSearch{Sort: tt.sort, Limit: 20})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(q.page, tt.wantOrder) {
+				t.Fatalf("page query has no %q:\n%s", tt.wantOrder, q.page)
+			}
+			if !strings.Contains(q.page, "m.ranking_score,") {
+				t.Fatalf("page query does not select ranking_score:\n%s", q.page)
+			}
+			// Лишняя строка для next_cursor
+			if limit := q.pageArgs[len(q.pageArgs)-1]; limit != 21 {
+				t.Fatalf("limit arg = %v, want 21", limit)
+			}
+		})
+	}
+}
+
+func TestBuildSearchCursor(t *testing.T) {
+	search := &requests.MentorSearch{
+		Sort:   requests.SortScore,
+		Limit:  20,
+		Skills: []string{"go"},
+		Cursor: encodeCursor(cursor{Sort: requests.SortScore, Value: 4.2, ID: 17}),
+	}
+	q, err := buildSearch(search)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Курсор сужает страницу, но не общее число
+	if len(q.countArgs) != 1 || len(q.pageArgs) != 4 {
+		t.Fatalf("count args %v, page args %v", q.countArgs, q.pageArgs)
+	}
+	if q.pageArgs[1] != 4.2 || q.pageArgs[2] != int64(17) {
+		t.Fatalf("cursor args %v", q.pageArgs)
+	}
+	if !strings.Contains(q.page, "((m.ranking_score)::float8, m.id) < ($2::float8, $3)") {
+		t.Fatalf("no keyset condition:\n%s", q.page)
+	}
+
+	search.Sort = requests.SortPrice
+	if _, err := buildSearch(search); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("cursor from another sort: expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestBuildSearchByName(t *testing.T) {
+	q, err := buildSearch(&requests.MentorSearch{Sort: requests.SortScore, Limit: 20, Query: "Ivan Petrov"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	match := "p.search_vector @@ websearch_to_tsquery('simple', $1)"
+	if !strings.Contains(q.count, match) || !strings.Contains(q.page, match) {
+		t.Fatalf("query does not match search_vector:\n%s", q.page)
+	}
+	if q.countArgs[0] != "Ivan Petrov" {
+		t.Fatalf("query arg = %v", q.countArgs[0])
+	}
+}
+
+// TestSearchVectorCoversName search_vector собирается в миграции: проверяем, что последняя
+// её версия ищет по имени с наибольшим весом, а также по заголовку и описанию
+func TestSearchVectorCoversName(t *testing.T) {
+	files, err := filepath.Glob("../../../migrations/*.up.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations: %v", err)
+	}
+	slices.Sort(files)
+
+	var definition string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "search_vector tsvector GENERATED") {
+			definition = string(data)
+		}
+	}
+
+	for _, part := range []string{
+		"setweight(to_tsvector('simple', name), 'A')",
+		"setweight(to_tsvector('simple', headline), 'B')",
+		"setweight(to_tsvector('simple', bio), 'C')",
+	} {
+		if !strings.Contains(definition, part) {
+			t.Fatalf("search_vector has no %q:\n%s", part, definition)
+		}
+	}
+}
@@ -2,10 +2,18 @@ package mentorservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mentor/internal/domain/models"
 	"mentor/internal/domain/requests"
+	"mentor/internal/lib/profile"
+	"mentor/internal/storage/db"
 	client "mentor/pkg/api/proto"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -17,7 +25,10 @@ type PostgresRepository interface {
 	UpdateMentor(ctx context.Context, mentor *requests.RatingRequest) error
 	DeleteReviewByMentor(ctx context.Context, mentor *requests.RatingRequest) error
 	CreateMentor(ctx context.Context, mentor *requests.MentorRequest) error
-	MentorExists(ctx context.Context, mentorEmail string) (bool, error)
+	GetActiveMentor(ctx context.Context, mentorID int64, mentorEmail string) (*models.MentorProfile, error)
+	ActivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	DeactivateMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	ReconcileRating(ctx context.Context, req *requests.ReconcileRequest) (bool, error)
 }
 
 type MentorService struct {
@@ -32,42 +43,45 @@ func NewMentorService(log *slog.Logger, repo PostgresRepository) *MentorService
 
 func (s *MentorService) MethodMentorRating(ctx context.Context, req *client.RatingRequest) (*client.Response, error) {
 	s.log.Debug("processing rating reuqest",
-		"mentor_email", req.MentorEmail,
+		"mentor_id", req.MentorId,
+		"review_id", req.ReviewId,
 		"rating", req.Rating,
 		"action", req.Action,
 	)
 	request := &requests.RatingRequest{
+		ReviewID:    req.ReviewId,
+		MentorID:    req.MentorId,
 		MentorEmail: req.MentorEmail,
 		Rating:      req.Rating,
 	}
 
 	switch req.Action {
 	case ActionDelete:
-		s.log.Info("starting review deletion", "mentor_email", req.MentorEmail)
+		s.log.Info("starting review deletion", "mentor_id", req.MentorId)
 		err := s.repo.DeleteReviewByMentor(ctx, request)
 		if err != nil {
 			s.log.Error("review deletion failed",
 				"error", err,
-				"mentor_email", req.MentorEmail)
+				"mentor_id", req.MentorId)
 			return &client.Response{
 					Success: false,
 					Message: "error",
 				},
 				fmt.Errorf("failed to delete review: %w", err)
 		}
-		s.log.Info("mentor successfully deleted", "mentor_email", req.MentorEmail)
+		s.log.Info("mentor successfully deleted", "mentor_id", req.MentorId)
 		return &client.Response{
 			Success: true,
 			Message: "ok",
 		}, nil
 
 	case ActionUpdate:
-		s.log.Info("starting mentor update", "mentor_email", req.MentorEmail)
+		s.log.Info("starting mentor update", "mentor_id", req.MentorId)
 		err := s.repo.UpdateMentor(ctx, request)
 		if err != nil {
 			s.log.Error("mentor update failed",
 				"error", err,
-				"mentor_email", req.MentorEmail,
+				"mentor_id", req.MentorId,
 				"rating", req.Rating)
 			return &client.Response{
 					Success: false,
@@ -75,7 +89,7 @@ func (s *MentorService) MethodMentorRating(ctx context.Context, req *client.Rati
 				},
 				fmt.Errorf("failed to update review: %w", err)
 		}
-		s.log.Info("mentor successfully updated", "mentor_email", req.MentorEmail)
+		s.log.Info("mentor successfully updated", "mentor_id", req.MentorId)
 		return &client.Response{
 			Success: true,
 			Message: "ok",
@@ -84,7 +98,7 @@ func (s *MentorService) MethodMentorRating(ctx context.Context, req *client.Rati
 	default:
 		s.log.Warn("unknown action requested",
 			"action", req.Action,
-			"mentor_email", req.MentorEmail)
+			"mentor_id", req.MentorId)
 		return &client.Response{
 				Success: false,
 				Message: "error: action don't matched",
@@ -96,19 +110,43 @@ func (s *MentorService) MethodMentorRating(ctx context.Context, req *client.Rati
 
 func (s *MentorService) NewMentor(ctx context.Context, req *client.MentorRequest) (*client.Response, error) {
 	s.log.Debug("creating new mentor",
-		"mentor_email", req.MentorEmail,
+		"mentor_id", req.MentorId,
 		"contact", req.Contact)
 
 	request := &requests.MentorRequest{
+		MentorID:    req.MentorId,
 		MentorEmail: req.MentorEmail,
 		Contact:     req.Contact,
 	}
 
+	if p := req.GetProfile(); p != nil {
+		request.Profile = &models.Profile{
+			Name:        p.Name,
+			Headline:    p.Headline,
+			Bio:         p.Bio,
+			Skills:      p.Skills,
+			Seniority:   p.Seniority,
+			Languages:   p.Languages,
+			Timezone:    p.Timezone,
+			HourlyPrice: int(p.HourlyPrice),
+			AvatarURL:   p.AvatarUrl,
+		}
+		// Повтор с тем же профилем не поможет, поэтому InvalidArgument, а не внутренняя ошибка
+		if err := profile.Normalize(request.Profile); err != nil {
+			s.log.Warn("invalid initial profile", "error", err, "mentor_id", req.MentorId)
+			return &client.Response{
+					Success: false,
+					Message: err.Error(),
+				},
+				status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
 	err := s.repo.CreateMentor(ctx, request)
 	if err != nil {
 		s.log.Error("mentor creation failed",
 			"error", err,
-			"mentor_email", req.MentorEmail,
+			"mentor_id", req.MentorId,
 			"contact", req.Contact)
 		return &client.Response{
 				Success: false,
@@ -117,21 +155,29 @@ func (s *MentorService) NewMentor(ctx context.Context, req *client.MentorRequest
 			fmt.Errorf("failed to create mentor: %w", err)
 	}
 
-	s.log.Info("mentor successfully created", "mentor_email", req.MentorEmail)
+	s.log.Info("mentor successfully created", "mentor_id", req.MentorId)
 	return &client.Response{
 		Success: true,
 		Message: "ok",
 	}, nil
 }
 
+// CheckMentor ищет активного ментора по id или, для старых клиентов, по email
+// и возвращает оба ключа, чтобы клиент мог сохранить id
 func (s *MentorService) CheckMentor(ctx context.Context, req *client.CheckRequest) (*client.CheckResponse, error) {
-	mentorEmail := req.MentorEmail
-
-	s.log.Debug("checking mentor existence", "mentor_email", mentorEmail)
+	s.log.Debug("checking mentor existence", "mentor_id", req.MentorId)
 
-	exists, err := s.repo.MentorExists(ctx, mentorEmail)
+	mentor, err := s.repo.GetActiveMentor(ctx, req.MentorId, req.MentorEmail)
+	if errors.Is(err, db.ErrMentorNotFound) {
+		s.log.Info("mentor does not exist", "mentor_id", req.MentorId)
+		return &client.CheckResponse{
+			Success: true,
+			Exists:  false,
+			Message: "not exists",
+		}, nil
+	}
 	if err != nil {
-		s.log.Error("failed to check mentor existence", "error", err, "mentor_email", mentorEmail)
+		s.log.Error("failed to check mentor existence", "error", err, "mentor_id", req.MentorId)
 
 		return &client.CheckResponse{
 			Success: false,
@@ -140,19 +186,109 @@ func (s *MentorService) CheckMentor(ctx context.Context, req *client.CheckReques
 		}, fmt.Errorf("failed to check mentor: %w", err)
 	}
 
-	if exists {
-		s.log.Info("mentor exists", "mentor_email", mentorEmail)
-		return &client.CheckResponse{
+	s.log.Info("mentor exists", "mentor_id", mentor.MentorID)
+	return &client.CheckResponse{
+		Success:     true,
+		Exists:      true,
+		Message:     "exists",
+		MentorId:    mentor.MentorID,
+		MentorEmail: mentor.MentorEmail,
+	}, nil
+}
+
+func (s *MentorService) ActivateMentor(ctx context.Context, req *client.ActivateRequest) (*client.Response, error) {
+	s.log.Debug("activating mentor", "mentor_id", req.MentorId)
+
+	if err := s.repo.ActivateMentor(ctx, req.MentorId, req.MentorEmail); err != nil {
+		s.log.Error("mentor activation failed",
+			"error", err,
+			"mentor_id", req.MentorId)
+		return &client.Response{
+				Success: false,
+				Message: "error",
+			},
+			fmt.Errorf("failed to activate mentor: %w", err)
+	}
+
+	s.log.Info("mentor successfully activated", "mentor_id", req.MentorId)
+	return &client.Response{
+		Success: true,
+		Message: "ok",
+	}, nil
+}
+
+func (s *MentorService) DeactivateMentor(ctx context.Context, req *client.DeactivateRequest) (*client.Response, error) {
+	s.log.Debug("deactivating mentor", "mentor_id", req.MentorId)
+
+	if err := s.repo.DeactivateMentor(ctx, req.MentorId, req.MentorEmail); err != nil {
+		s.log.Error("mentor deactivation failed",
+			"error", err,
+			"mentor_id", req.MentorId)
+		return &client.Response{
+				Success: false,
+				Message: "error",
+			},
+			fmt.Errorf("failed to deactivate mentor: %w", err)
+	}
+
+	s.log.Info("mentor successfully deactivated", "mentor_id", req.MentorId)
+	return &client.Response{
+		Success: true,
+		Message: "ok",
+	}, nil
+}
+
+// ReconcileRating принимает рейтинг ментора, пересчитанный сервисом отзывов
+func (s *MentorService) ReconcileRating(ctx context.Context, req *client.ReconcileRequest) (*client.Response, error) {
+	s.log.Debug("reconciling mentor rating", "mentor_id", req.MentorId)
+
+	if req.MentorId <= 0 || len(req.Stars) != 5 {
+		return &client.Response{
+				Success: false,
+				Message: "mentor_id and 5 star buckets are required",
+			},
+			status.Error(codes.InvalidArgument, "mentor_id and 5 star buckets are required")
+	}
+
+	request := &requests.ReconcileRequest{
+		MentorID:  req.MentorId,
+		SumRating: req.SumRating,
+		Recent:    make([]requests.RatedReview, 0, len(req.Recent)),
+	}
+	for i, n := range req.Stars {
+		request.Stars[i] = int(n)
+	}
+	for _, r := range req.Recent {
+		request.Recent = append(request.Recent, requests.RatedReview{
+			ReviewID: r.ReviewId,
+			Rating:   r.Rating,
+			RatedAt:  time.Unix(r.RatedAt, 0),
+		})
+	}
+
+	drift, err := s.repo.ReconcileRating(ctx, request)
+	if errors.Is(err, db.ErrMentorNotFound) {
+		// Ментор удалён, а отзывы о нём ещё не вычищены: сверять нечего
+		s.log.Info("mentor for reconciliation not found", "mentor_id", req.MentorId)
+		return &client.Response{
 			Success: true,
-			Exists:  true,
-			Message: "exists",
+			Message: "not exists",
 		}, nil
 	}
+	if err != nil {
+		s.log.Error("rating reconciliation failed", "error", err, "mentor_id", req.MentorId)
+		return &client.Response{
+				Success: false,
+				Message: "error",
+			},
+			fmt.Errorf("failed to reconcile rating: %w", err)
+	}
 
-	s.log.Info("mentor does not exist", "mentor_email", mentorEmail)
-	return &client.CheckResponse{
+	if drift {
+		s.log.Warn("mentor rating drift corrected", "mentor_id", req.MentorId)
+	}
+	return &client.Response{
 		Success: true,
-		Exists:  false,
-		Message: "not exists",
+		Message: "ok",
 	}, nil
 }
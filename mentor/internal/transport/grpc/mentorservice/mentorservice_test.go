@@ -0,0 +1,106 @@
+package mentorservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mentor/internal/domain/requests"
+	"mentor/internal/storage/db"
+	client "mentor/pkg/api/proto"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeRepo реализует только сверку рейтинга, остальные методы не вызываются
+type fakeRepo struct {
+	PostgresRepository
+	drift bool
+	err   error
+	got   *requests.ReconcileRequest
+}
+
+func (f *fakeRepo) ReconcileRating(_ context.Context, req *requests.ReconcileRequest) (bool, error) {
+	f.got = req
+	return f.drift, f.err
+}
+
+func TestReconcileRating(t *testing.T) {
+	valid := &client.ReconcileRequest{
+		MentorId:  7,
+		Stars:     []int32{0, 1, 0, 2, 3},
+		SumRating: 25,
+		Recent:    []*client.RatedReview{{ReviewId: 11, Rating: 4, RatedAt: 1_700_000_000}},
+	}
+
+	tests := []struct {
+		name        string
+		req         *client.ReconcileRequest
+		drift       bool
+		repoErr     error
+		wantCode    codes.Code
+		wantMessage string
+		wantRepo    bool
+	}{
+		{name: "in sync", req: valid, wantCode: codes.OK, wantMessage: "ok", wantRepo: true},
+		{name: "drift corrected", req: valid, drift: true, wantCode: codes.OK, wantMessage: "ok", wantRepo: true},
+		{
+			name:        "no mentor id",
+			req:         &client.ReconcileRequest{Stars: []int32{0, 0, 0, 0, 0}},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "mentor_id and 5 star buckets are required",
+		},
+		{
+			name:        "wrong number of buckets",
+			req:         &client.ReconcileRequest{MentorId: 7, Stars: []int32{1, 2, 3}},
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "mentor_id and 5 star buckets are required",
+		},
+		{name: "mentor deleted", req: valid, repoErr: db.ErrMentorNotFound, wantCode: codes.OK, wantMessage: "not exists", wantRepo: true},
+		{name: "storage error", req: valid, repoErr: errors.New("db down"), wantCode: codes.Unknown, wantMessage: "error", wantRepo: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{drift: tt.drift, err: tt.repoErr}
+			svc := NewMentorService(slog.New(slog.NewTextHandler(io.Discard, nil)), repo)
+
+			resp, err := svc.ReconcileRating(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (%v)", code, tt.wantCode, err)
+			}
+			if resp.Message != tt.wantMessage || resp.Success != (err == nil) {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+			if (repo.got != nil) != tt.wantRepo {
+				t.Fatalf("repo called = %v, want %v", repo.got != nil, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestReconcileRatingConvertsRequest(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewMentorService(slog.New(slog.NewTextHandler(io.Discard, nil)), repo)
+
+	_, err := svc.ReconcileRating(context.Background(), &client.ReconcileRequest{
+		MentorId:  7,
+		Stars:     []int32{0, 1, 0, 2, 3},
+		SumRating: 25,
+		Recent:    []*client.RatedReview{{ReviewId: 11, Rating: 4, RatedAt: 1_700_000_000}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := repo.got
+	if got.MentorID != 7 || got.SumRating != 25 || got.Stars != [5]int{0, 1, 0, 2, 3} {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	if len(got.Recent) != 1 || got.Recent[0].ReviewID != 11 || got.Recent[0].Rating != 4 ||
+		!got.Recent[0].RatedAt.Equal(time.Unix(1_700_000_000, 0)) {
+		t.Fatalf("unexpected recent reviews %+v", got.Recent)
+	}
+}
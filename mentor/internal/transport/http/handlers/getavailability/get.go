@@ -0,0 +1,62 @@
+package getavailability
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/storage/db"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type GetAvailability interface {
+	GetAvailability(ctx context.Context, mentorID int64) (*models.Availability, error)
+}
+
+// Get недельное расписание ментора и предстоящие исключения в его часовом поясе
+func Get(log *slog.Logger, getAvailability GetAvailability) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.getavailability.get.Get"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid mentor id"))
+			return
+		}
+
+		availability, err := getAvailability.GetAvailability(r.Context(), id)
+		if errors.Is(err, db.ErrMentorNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor not found"))
+			return
+		}
+		if errors.Is(err, db.ErrNoSchedule) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor has not published availability"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get availability", sl.Err(err), slog.Int64("mentor_id", id))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"availability": availability,
+		})
+	}
+}
@@ -2,55 +2,194 @@ package get
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"mentor/internal/domain/models"
+	"mentor/internal/domain/requests"
 	"mentor/internal/domain/response"
 	"mentor/internal/lib/logger/sl"
+	"mentor/internal/storage/db"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+	MaxQuery     = 200
+	MaxFilters   = 10
+)
+
 type GetMentors interface {
-	Get(ctx context.Context) ([]models.MentorTable, error)
+	SearchMentors(ctx context.Context, search *requests.MentorSearch) (*models.MentorPage, error)
 }
 
 type RedisRepository interface {
-	GetMentors(ctx context.Context) ([]models.MentorTable, error, bool)
-	SaveMentors(ctx context.Context, mentor []models.MentorTable) error
+	GetMentors(ctx context.Context, query string) (*models.MentorPage, error, bool)
+	SaveMentors(ctx context.Context, query string, page *models.MentorPage) error
 }
 
+// Get каталог менторов. Параметры: skill и language (можно повторять), min_price, max_price,
+// min_rating, q - полнотекстовый поиск по заголовку и описанию профиля,
+// sort (score, rating, reviews, newest, price), limit и cursor из next_cursor предыдущей страницы
 func Get(ctx context.Context, log *slog.Logger, getMentors GetMentors, redisRepo RedisRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handler.getmentors.get.Get"
-		log := slog.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		mentors, err, ifExists := redisRepo.GetMentors(ctx)
+		search, err := parseSearch(r.URL.Query())
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+		key := cacheKey(search)
+
+		page, err, ifExists := redisRepo.GetMentors(ctx, key)
 		if err != nil {
 			log.Error("failed to get mentors mrom redis", sl.Err(err))
 		}
 
 		if !ifExists {
-			mentors, err = getMentors.Get(ctx)
+			page, err = getMentors.SearchMentors(ctx, search)
+			if errors.Is(err, db.ErrInvalidCursor) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid cursor"))
+				return
+			}
 			if err != nil {
 				log.Error("failed to get mentors", sl.Err(err))
 				render.Status(r, http.StatusInternalServerError)
 				render.JSON(w, r, response.Error("server error"))
 				return
 			}
-			err := redisRepo.SaveMentors(ctx, mentors)
+			err := redisRepo.SaveMentors(ctx, key, page)
 			if err != nil {
 				log.Error("failed to save mentors from redis", sl.Err(err))
 			}
 		}
 
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, map[string]any{
-			"mentors": mentors,
-		})
+		render.JSON(w, r, page)
+	}
+}
+
+func parseSearch(values url.Values) (*requests.MentorSearch, error) {
+	search := &requests.MentorSearch{
+		Skills:    normalizeList(values["skill"]),
+		Languages: normalizeList(values["language"]),
+		Query:     strings.TrimSpace(values.Get("q")),
+		Sort:      values.Get("sort"),
+		Limit:     DefaultLimit,
+		Cursor:    values.Get("cursor"),
+	}
+
+	if len(search.Skills) > MaxFilters || len(search.Languages) > MaxFilters {
+		return nil, fmt.Errorf("at most %d skills and %d languages", MaxFilters, MaxFilters)
+	}
+	if utf8.RuneCountInString(search.Query) > MaxQuery {
+		return nil, fmt.Errorf("q is longer than %d characters", MaxQuery)
+	}
+
+	switch search.Sort {
+	case "":
+		search.Sort = requests.SortScore
+	case requests.SortScore, requests.SortRating, requests.SortReviews, requests.SortNewest, requests.SortPrice:
+	default:
+		return nil, errors.New("sort must be one of score, rating, reviews, newest, price")
+	}
+
+	var err error
+	if search.MinPrice, err = intParam(values, "min_price", 0, 0); err != nil {
+		return nil, err
+	}
+	if search.MaxPrice, err = intParam(values, "max_price", 0, 0); err != nil {
+		return nil, err
+	}
+	if search.MaxPrice > 0 && search.MinPrice > search.MaxPrice {
+		return nil, errors.New("min_price is greater than max_price")
+	}
+	if search.Limit, err = intParam(values, "limit", DefaultLimit, MaxLimit); err != nil {
+		return nil, err
+	}
+	if search.Limit == 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	if v := values.Get("min_rating"); v != "" {
+		search.MinRating, err = strconv.ParseFloat(v, 64)
+		// NaN не меньше 0 и не больше 5, поэтому проверяем попадание в отрезок
+		if err != nil || !(search.MinRating >= 0 && search.MinRating <= 5) {
+			return nil, errors.New("min_rating must be a number from 0 to 5")
+		}
+	}
+
+	return search, nil
+}
+
+// intParam неотрицательное число; max = 0 - без верхней границы
+func intParam(values url.Values, name string, def, max int) (int, error) {
+	v := values.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || (max > 0 && n > max) {
+		if max > 0 {
+			return 0, fmt.Errorf("%s must be a number from 0 to %d", name, max)
+		}
+		return 0, fmt.Errorf("%s must be a non-negative number", name)
+	}
+	return n, nil
+}
+
+// normalizeList приводит значения к виду, в котором они хранятся в профиле; порядок
+// фильтров не важен, поэтому список сортируется и одинаковые запросы делят кэш
+func normalizeList(items []string) []string {
+	var res []string
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !slices.Contains(res, item) {
+			res = append(res, item)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// cacheKey нормализованный запрос: url.Values.Encode сортирует параметры по имени
+func cacheKey(search *requests.MentorSearch) string {
+	values := url.Values{
+		"skill":    search.Skills,
+		"language": search.Languages,
+		"sort":     {search.Sort},
+		"limit":    {strconv.Itoa(search.Limit)},
+	}
+	if search.MinPrice > 0 {
+		values.Set("min_price", strconv.Itoa(search.MinPrice))
+	}
+	if search.MaxPrice > 0 {
+		values.Set("max_price", strconv.Itoa(search.MaxPrice))
+	}
+	if search.MinRating > 0 {
+		values.Set("min_rating", strconv.FormatFloat(search.MinRating, 'f', -1, 64))
+	}
+	if search.Query != "" {
+		values.Set("q", search.Query)
+	}
+	if search.Cursor != "" {
+		values.Set("cursor", search.Cursor)
 	}
+	return values.Encode()
 }
@@ -0,0 +1,243 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/requests"
+	"mentor/internal/storage/db"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    requests.MentorSearch
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  requests.MentorSearch{Sort: requests.SortScore, Limit: DefaultLimit},
+		},
+		{
+			name:  "all filters",
+			query: "skill=Go&skill=+sql&skill=go&language=EN&min_price=100&max_price=500&min_rating=4.5&q=+backend+&sort=price&limit=5&cursor=abc",
+			want: requests.MentorSearch{
+				Skills:    []string{"go", "sql"},
+				Languages: []string{"en"},
+				MinPrice:  100,
+				MaxPrice:  500,
+				MinRating: 4.5,
+				Query:     "backend",
+				Sort:      requests.SortPrice,
+				Limit:     5,
+				Cursor:    "abc",
+			},
+		},
+		{
+			name:  "max price without min",
+			query: "max_price=300",
+			want:  requests.MentorSearch{Sort: requests.SortScore, Limit: DefaultLimit, MaxPrice: 300},
+		},
+		{name: "unknown sort", query: "sort=name", wantErr: "sort must be one of score, rating, reviews, newest, price"},
+		{name: "limit over max", query: "limit=101", wantErr: "limit must be a number from 0 to 100"},
+		{name: "zero limit", query: "limit=0", wantErr: "limit must be positive"},
+		{name: "negative price", query: "min_price=-1", wantErr: "min_price must be a non-negative number"},
+		{name: "price not a number", query: "max_price=cheap", wantErr: "max_price must be a non-negative number"},
+		{name: "min over max price", query: "min_price=500&max_price=100", wantErr: "min_price is greater than max_price"},
+		{name: "rating over 5", query: "min_rating=6", wantErr: "min_rating must be a number from 0 to 5"},
+		{name: "rating not a number", query: "min_rating=NaN", wantErr: "min_rating must be a number from 0 to 5"},
+		{name: "long query", query: "q=" + strings.Repeat("a", MaxQuery+1), wantErr: "q is longer than 200 characters"},
+		{name: "too many skills", query: manySkills(MaxFilters + 1), wantErr: "at most 10 skills and 10 languages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseSearch(values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func manySkills(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = "skill=s" + string(rune('a'+i))
+	}
+	return strings.Join(parts, "&")
+}
+
+func TestCacheKey(t *testing.T) {
+	key := func(query string) string {
+		t.Helper()
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		search, err := parseSearch(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cacheKey(search)
+	}
+
+	// Одинаковые по смыслу запросы делят запись в кэше
+	same := [][2]string{
+		{"skill=go&skill=sql", "skill=SQL&skill=Go"},
+		{"skill=go", "skill=go&skill=go"},
+		{"", "sort=score&limit=20"},
+		{"min_price=0", ""},
+		{"min_rating=4.50", "min_rating=4.5"},
+		{"q=backend", "q=+backend+"},
+	}
+	for _, pair := range same {
+		if a, b := key(pair[0]), key(pair[1]); a != b {
+			t.Errorf("%q and %q: keys %q and %q differ", pair[0], pair[1], a, b)
+		}
+	}
+
+	different := [][2]string{
+		{"skill=go", "language=go"},
+		{"sort=price", "sort=rating"},
+		{"limit=10", "limit=20"},
+		{"min_price=100", "max_price=100"},
+		{"q=go", ""},
+		{"cursor=a", "cursor=b"},
+	}
+	for _, pair := range different {
+		if a, b := key(pair[0]), key(pair[1]); a == b {
+			t.Errorf("%q and %q share key %q", pair[0], pair[1], a)
+		}
+	}
+}
+
+type fakeSearch struct {
+	page   *models.MentorPage
+	err    error
+	search *requests.MentorSearch
+}
+
+func (f *fakeSearch) SearchMentors(_ context.Context, search *requests.MentorSearch) (*models.MentorPage, error) {
+	f.search = search
+	return f.page, f.err
+}
+
+type fakeCache struct {
+	page  *models.MentorPage
+	saved map[string]*models.MentorPage
+}
+
+func (f *fakeCache) GetMentors(context.Context, string) (*models.MentorPage, error, bool) {
+	return f.page, nil, f.page != nil
+}
+
+func (f *fakeCache) SaveMentors(_ context.Context, key string, page *models.MentorPage) error {
+	f.saved[key] = page
+	return nil
+}
+
+func TestGet(t *testing.T) {
+	page := &models.MentorPage{Mentors: []models.MentorTable{}, Total: 3, NextCursor: "next"}
+
+	tests := []struct {
+		name       string
+		query      string
+		cached     *models.MentorPage
+		searchErr  error
+		wantStatus int
+		wantSearch bool
+		wantSaved  bool
+	}{
+		{name: "cache miss", query: "?skill=go", wantStatus: http.StatusOK, wantSearch: true, wantSaved: true},
+		{name: "cache hit", query: "?skill=go", cached: page, wantStatus: http.StatusOK},
+		{name: "invalid params", query: "?limit=1000", wantStatus: http.StatusBadRequest},
+		{name: "invalid cursor", query: "?cursor=x", searchErr: db.ErrInvalidCursor, wantStatus: http.StatusBadRequest, wantSearch: true},
+		{name: "storage error", query: "", searchErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantSearch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search := &fakeSearch{page: page, err: tt.searchErr}
+			cache := &fakeCache{page: tt.cached, saved: map[string]*models.MentorPage{}}
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			req := httptest.NewRequest(http.MethodGet, "/mentors"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			Get(context.Background(), log, search, cache).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if (search.search != nil) != tt.wantSearch {
+				t.Fatalf("search called = %v, want %v", search.search != nil, tt.wantSearch)
+			}
+			if (len(cache.saved) == 1) != tt.wantSaved {
+				t.Fatalf("saved %d pages", len(cache.saved))
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rr.Body.String(), `"next_cursor":"next"`) {
+				t.Fatalf("unexpected body %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetDefaultsToScoreOrder(t *testing.T) {
+	search := &fakeSearch{page: &models.MentorPage{
+		Mentors: []models.MentorTable{
+			{MentorID: 2, RankingScore: 4.7},
+			{MentorID: 1, RankingScore: 3.6},
+		},
+		Total: 2,
+	}}
+	cache := &fakeCache{saved: map[string]*models.MentorPage{}}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	rr := httptest.NewRecorder()
+	Get(context.Background(), log, search, cache).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mentors/get", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if search.search.Sort != requests.SortScore {
+		t.Fatalf("default sort = %q, want %q", search.search.Sort, requests.SortScore)
+	}
+
+	var page struct {
+		Mentors []map[string]any `json:"mentors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Mentors) != 2 {
+		t.Fatalf("unexpected body %s", rr.Body.String())
+	}
+	for i, want := range []float64{4.7, 3.6} {
+		score, ok := page.Mentors[i]["ranking_score"].(float64)
+		if !ok || math.Abs(score-want) > 1e-6 {
+			t.Fatalf("mentor %d ranking_score = %v, want %v", i, page.Mentors[i]["ranking_score"], want)
+		}
+	}
+}
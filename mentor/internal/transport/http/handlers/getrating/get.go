@@ -0,0 +1,57 @@
+package getrating
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/storage/db"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type GetRating interface {
+	GetRatingStats(ctx context.Context, mentorID int64) (*models.RatingStats, error)
+}
+
+// Get рейтинг ментора: число отзывов, среднее, распределение по звёздам и динамика за 30 и 90 дней
+func Get(log *slog.Logger, getRating GetRating) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.getrating.get.Get"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid mentor id"))
+			return
+		}
+
+		stats, err := getRating.GetRatingStats(r.Context(), id)
+		if errors.Is(err, db.ErrMentorNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get rating", sl.Err(err), slog.Int64("mentor_id", id))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"rating": stats,
+		})
+	}
+}
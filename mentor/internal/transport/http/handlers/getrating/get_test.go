@@ -0,0 +1,86 @@
+package getrating
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/storage/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type fakeRating struct {
+	stats    *models.RatingStats
+	err      error
+	mentorID int64
+}
+
+func (f *fakeRating) GetRatingStats(_ context.Context, mentorID int64) (*models.RatingStats, error) {
+	f.mentorID = mentorID
+	return f.stats, f.err
+}
+
+func TestGet(t *testing.T) {
+	stats := &models.RatingStats{
+		MentorID:  5,
+		Count:     3,
+		Average:   4.3,
+		Histogram: map[string]int{"1": 0, "2": 0, "3": 0, "4": 2, "5": 1},
+		Trend:     []models.RatingTrend{{Days: 30}, {Days: 90}},
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{name: "success", id: "5", wantStatus: http.StatusOK},
+		{name: "id not a number", id: "abc", wantStatus: http.StatusBadRequest, wantError: "invalid mentor id"},
+		{name: "zero id", id: "0", wantStatus: http.StatusBadRequest, wantError: "invalid mentor id"},
+		{name: "not found", id: "5", err: db.ErrMentorNotFound, wantStatus: http.StatusNotFound, wantError: "mentor not found"},
+		{name: "storage error", id: "5", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantError: "server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRating{stats: stats, err: tt.err}
+			router := chi.NewRouter()
+			router.Get("/mentors/{id}/rating", Get(slog.New(slog.NewTextHandler(io.Discard, nil)), repo))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/mentors/"+tt.id+"/rating", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantError != "" {
+				var resp response.Response
+				if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Error != tt.wantError {
+					t.Fatalf("error = %q, want %q", resp.Error, tt.wantError)
+				}
+				return
+			}
+
+			var resp struct {
+				Rating models.RatingStats `json:"rating"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if repo.mentorID != 5 || resp.Rating.Count != 3 || resp.Rating.Histogram["4"] != 2 || len(resp.Rating.Trend) != 2 {
+				t.Fatalf("unexpected rating %+v", resp.Rating)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package getslots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/lib/schedule"
+	"mentor/internal/storage/db"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// DefaultRange период, если to не указан
+const DefaultRange = 7 * 24 * time.Hour
+
+type GetSlots interface {
+	GetAvailability(ctx context.Context, mentorID int64) (*models.Availability, error)
+	BookedPeriods(ctx context.Context, mentorID int64, from, to time.Time) ([]models.Slot, error)
+}
+
+// Get свободные слоты ментора. Параметры from и to в RFC 3339, по умолчанию неделя
+// от текущего момента; прошедшее время и время за горизонтом бронирования отбрасываются
+func Get(log *slog.Logger, getSlots GetSlots) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.getslots.get.Get"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id <= 0 {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid mentor id"))
+			return
+		}
+
+		now := time.Now()
+		from, to, err := parseRange(r.URL.Query(), now)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		availability, err := getSlots.GetAvailability(r.Context(), id)
+		if errors.Is(err, db.ErrMentorNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor not found"))
+			return
+		}
+		if errors.Is(err, db.ErrNoSchedule) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor has not published availability"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get availability", sl.Err(err), slog.Int64("mentor_id", id))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		page := models.OpenSlots{
+			MentorID:       id,
+			Timezone:       availability.Timezone,
+			SessionMinutes: availability.SessionMinutes,
+			Slots:          []models.Slot{},
+		}
+
+		// Период целиком в прошлом или за горизонтом: слотов нет, но это не ошибка
+		from, to = clamp(from, to, now)
+		if from.Before(to) {
+			busy, err := getSlots.BookedPeriods(r.Context(), id, from, to.Add(time.Duration(availability.SessionMinutes)*time.Minute))
+			if err != nil {
+				log.Error("failed to get booked periods", sl.Err(err), slog.Int64("mentor_id", id))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("server error"))
+				return
+			}
+
+			page.Slots, err = schedule.Slots(availability, from, to, busy)
+			if err != nil {
+				log.Error("failed to build slots", sl.Err(err), slog.Int64("mentor_id", id))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("server error"))
+				return
+			}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, page)
+	}
+}
+
+func parseRange(q url.Values, now time.Time) (time.Time, time.Time, error) {
+	from := now
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be an RFC 3339 time")
+		}
+		from = t
+	}
+
+	to := from.Add(DefaultRange)
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be an RFC 3339 time")
+		}
+		to = t
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > schedule.MaxRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("range must not exceed %d days", int(schedule.MaxRange.Hours()/24))
+	}
+	return from, to, nil
+}
+
+// clamp бронировать можно только между текущим моментом и горизонтом
+func clamp(from, to, now time.Time) (time.Time, time.Time) {
+	if from.Before(now) {
+		from = now
+	}
+	if horizon := now.Add(schedule.Horizon); to.After(horizon) {
+		to = horizon
+	}
+	return from, to
+}
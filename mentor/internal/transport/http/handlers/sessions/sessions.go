@@ -0,0 +1,351 @@
+// Package sessions бронирование занятий: ученик бронирует слот из расписания ментора,
+// ментор и ученик могут отменить или перенести занятие, пока оно не началось
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/lib/schedule"
+	mwAuth "mentor/internal/middleware/auth"
+	"mentor/internal/storage/db"
+	"mentor/pkg/token"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	MaxNote      = 1000
+	MaxReason    = 500
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+type Repository interface {
+	GetAvailability(ctx context.Context, mentorID int64) (*models.Availability, error)
+	BookSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, sessionID, userID int64) (*models.Session, error)
+	ListSessions(ctx context.Context, userID int64, from time.Time, limit int) ([]models.Session, error)
+	CancelSession(ctx context.Context, sessionID, userID int64, reason string) (*models.Session, error)
+	RescheduleSession(ctx context.Context, sessionID, userID int64, slot models.Slot) (*models.Session, error)
+}
+
+type bookRequest struct {
+	MentorID int64     `json:"mentor_id"`
+	Start    time.Time `json:"start"`
+	Note     string    `json:"note"`
+}
+
+type cancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+type rescheduleRequest struct {
+	Start time.Time `json:"start"`
+}
+
+// Book бронирует слот, который начинается в start; длительность берётся из расписания ментора
+func Book(log *slog.Logger, repo Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.sessions.Book"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		var req bookRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		req.Note = strings.TrimSpace(req.Note)
+		switch {
+		case req.MentorID <= 0:
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("mentor_id is required"))
+			return
+		case req.MentorID == claims.UserID:
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("cannot book a session with yourself"))
+			return
+		case utf8.RuneCountInString(req.Note) > MaxNote:
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("note is longer than %d characters", MaxNote)))
+			return
+		}
+
+		slot, ok := findSlot(w, r, log, repo, req.MentorID, req.Start)
+		if !ok {
+			return
+		}
+
+		session := &models.Session{
+			MentorID: req.MentorID,
+			MenteeID: claims.UserID,
+			StartsAt: slot.Start,
+			EndsAt:   slot.End,
+			Note:     req.Note,
+		}
+		if err := repo.BookSession(r.Context(), session); err != nil {
+			renderStorageError(w, r, log, err)
+			return
+		}
+
+		log.Info("session booked",
+			slog.Int64("session_id", session.ID),
+			slog.Int64("mentor_id", session.MentorID),
+			slog.Int64("mentee_id", session.MenteeID),
+		)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, map[string]any{
+			"session": session,
+		})
+	}
+}
+
+// Cancel отменяет занятие; отменить может и ментор, и ученик
+func Cancel(log *slog.Logger, repo Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.sessions.Cancel"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		id, ok := sessionID(w, r)
+		if !ok {
+			return
+		}
+
+		// Причина необязательна, поэтому пустое тело допустимо
+		var req cancelRequest
+		if r.ContentLength != 0 {
+			if err := render.DecodeJSON(r.Body, &req); err != nil {
+				log.Error("failed to decode request body", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid request body"))
+				return
+			}
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if utf8.RuneCountInString(req.Reason) > MaxReason {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("reason is longer than %d characters", MaxReason)))
+			return
+		}
+
+		session, err := repo.CancelSession(r.Context(), id, claims.UserID, req.Reason)
+		if err != nil {
+			renderStorageError(w, r, log, err)
+			return
+		}
+
+		log.Info("session cancelled", slog.Int64("session_id", id), slog.Int64("user_id", claims.UserID))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"session": session,
+		})
+	}
+}
+
+// Reschedule переносит занятие на другой слот того же ментора. Старое занятие отменяется,
+// в ответе новое занятие со ссылкой на старое
+func Reschedule(log *slog.Logger, repo Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.sessions.Reschedule"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		id, ok := sessionID(w, r)
+		if !ok {
+			return
+		}
+
+		var req rescheduleRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		current, err := repo.GetSession(r.Context(), id, claims.UserID)
+		if err != nil {
+			renderStorageError(w, r, log, err)
+			return
+		}
+
+		slot, ok := findSlot(w, r, log, repo, current.MentorID, req.Start)
+		if !ok {
+			return
+		}
+
+		session, err := repo.RescheduleSession(r.Context(), id, claims.UserID, slot)
+		if err != nil {
+			renderStorageError(w, r, log, err)
+			return
+		}
+
+		log.Info("session rescheduled",
+			slog.Int64("session_id", id),
+			slog.Int64("new_session_id", session.ID),
+			slog.Int64("user_id", claims.UserID),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"session": session,
+		})
+	}
+}
+
+// List занятия пользователя как ментора и как ученика, которые ещё не закончились
+// к from (RFC 3339, по умолчанию сейчас), в порядке начала; limit до 100
+func List(log *slog.Logger, repo Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.sessions.List"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		from := time.Now()
+		if v := r.URL.Query().Get("from"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("from must be an RFC 3339 time"))
+				return
+			}
+			from = t
+		}
+
+		limit := DefaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > MaxLimit {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(fmt.Sprintf("limit must be between 1 and %d", MaxLimit)))
+				return
+			}
+			limit = n
+		}
+
+		sessions, err := repo.ListSessions(r.Context(), claims.UserID, from, limit)
+		if err != nil {
+			log.Error("failed to list sessions", sl.Err(err), slog.Int64("user_id", claims.UserID))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"sessions": sessions,
+		})
+	}
+}
+
+func sessionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("invalid session id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// findSlot проверяет, что start - начало слота из расписания ментора в пределах горизонта.
+// Занятость слота проверит база при бронировании
+func findSlot(w http.ResponseWriter, r *http.Request, log *slog.Logger, repo Repository, mentorID int64, start time.Time) (models.Slot, bool) {
+	now := time.Now()
+	if start.IsZero() {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("start is required"))
+		return models.Slot{}, false
+	}
+	if !start.After(now) || start.After(now.Add(schedule.Horizon)) {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(fmt.Sprintf("start must be within the next %d days", int(schedule.Horizon.Hours()/24))))
+		return models.Slot{}, false
+	}
+
+	availability, err := repo.GetAvailability(r.Context(), mentorID)
+	if errors.Is(err, db.ErrMentorNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("mentor not found"))
+		return models.Slot{}, false
+	}
+	if err != nil && !errors.Is(err, db.ErrNoSchedule) {
+		log.Error("failed to get availability", sl.Err(err), slog.Int64("mentor_id", mentorID))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("server error"))
+		return models.Slot{}, false
+	}
+
+	var slot models.Slot
+	found := false
+	if err == nil {
+		slot, found, err = schedule.Find(availability, start)
+		if err != nil {
+			log.Error("failed to build slots", sl.Err(err), slog.Int64("mentor_id", mentorID))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return models.Slot{}, false
+		}
+	}
+	if !found {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("slot is not available"))
+		return models.Slot{}, false
+	}
+	return slot, true
+}
+
+func renderStorageError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, db.ErrMentorNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("mentor not found"))
+	case errors.Is(err, db.ErrSessionNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("session not found"))
+	case errors.Is(err, db.ErrSlotTaken):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("slot is already booked"))
+	case errors.Is(err, db.ErrMenteeBusy):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("mentee has another session at this time"))
+	case errors.Is(err, db.ErrSessionClosed):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("session is cancelled or already started"))
+	default:
+		log.Error("session storage failed", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("server error"))
+	}
+}
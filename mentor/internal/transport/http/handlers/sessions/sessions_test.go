@@ -0,0 +1,412 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/schedule"
+	mwAuth "mentor/internal/middleware/auth"
+	"mentor/internal/storage/db"
+	"mentor/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	mentorID = 1
+	menteeID = 2
+)
+
+type fakeRepo struct {
+	availability    *models.Availability
+	availabilityErr error
+	bookErr         error
+	session         *models.Session
+	sessionErr      error
+	cancelErr       error
+	rescheduleErr   error
+
+	booked      *models.Session
+	cancelled   string
+	rescheduled *models.Slot
+}
+
+func (f *fakeRepo) GetAvailability(context.Context, int64) (*models.Availability, error) {
+	return f.availability, f.availabilityErr
+}
+
+func (f *fakeRepo) BookSession(_ context.Context, session *models.Session) error {
+	f.booked = session
+	if f.bookErr != nil {
+		return f.bookErr
+	}
+	session.ID = 10
+	session.Status = "booked"
+	return nil
+}
+
+func (f *fakeRepo) GetSession(context.Context, int64, int64) (*models.Session, error) {
+	return f.session, f.sessionErr
+}
+
+func (f *fakeRepo) ListSessions(context.Context, int64, time.Time, int) ([]models.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) CancelSession(_ context.Context, sessionID, userID int64, reason string) (*models.Session, error) {
+	f.cancelled = reason
+	if f.cancelErr != nil {
+		return nil, f.cancelErr
+	}
+	return &models.Session{ID: sessionID, Status: "cancelled", CancelledBy: userID, CancelReason: reason}, nil
+}
+
+func (f *fakeRepo) RescheduleSession(_ context.Context, sessionID, _ int64, slot models.Slot) (*models.Session, error) {
+	f.rescheduled = &slot
+	if f.rescheduleErr != nil {
+		return nil, f.rescheduleErr
+	}
+	return &models.Session{ID: 11, StartsAt: slot.Start, EndsAt: slot.End, RescheduledFrom: sessionID}, nil
+}
+
+// openAvailability ментор принимает круглые сутки занятиями по часу
+func openAvailability(t *testing.T) *models.Availability {
+	t.Helper()
+	a := &models.Availability{Timezone: "UTC"}
+	for day := 0; day < 7; day++ {
+		a.Weekly = append(a.Weekly, models.WeeklyWindow{Weekday: day, Window: models.Window{Start: "00:00", End: "24:00"}})
+	}
+	if err := schedule.Normalize(a); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func serve(t *testing.T, handler http.HandlerFunc, method, pattern, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Method(method, pattern, handler)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	claims := &token.Claims{UserID: menteeID, Role: "user", TokenType: "access"}
+	req = req.WithContext(context.WithValue(req.Context(), mwAuth.UserKey, claims))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func checkResponse(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantError string) {
+	t.Helper()
+	if rr.Code != wantStatus {
+		t.Fatalf("status = %d, want %d: %s", rr.Code, wantStatus, rr.Body.String())
+	}
+	if wantError == "" {
+		return
+	}
+	var resp response.Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != wantError {
+		t.Fatalf("error = %q, want %q", resp.Error, wantError)
+	}
+}
+
+func startAt(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func TestBook(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	slot := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+
+	tests := []struct {
+		name            string
+		body            string
+		availability    bool
+		availabilityErr error
+		bookErr         error
+		wantStatus      int
+		wantError       string
+		wantBooked      bool
+	}{
+		{
+			name:         "success",
+			body:         `{"mentor_id":1,"start":"` + startAt(slot) + `","note":" intro call "}`,
+			availability: true,
+			wantStatus:   http.StatusCreated,
+			wantBooked:   true,
+		},
+		{name: "invalid body", body: `{"mentor_id":`, wantStatus: http.StatusBadRequest, wantError: "invalid request body"},
+		{name: "no mentor", body: `{"start":"` + startAt(slot) + `"}`, wantStatus: http.StatusBadRequest, wantError: "mentor_id is required"},
+		{
+			name:       "self booking",
+			body:       `{"mentor_id":2,"start":"` + startAt(slot) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "cannot book a session with yourself",
+		},
+		{
+			name:       "long note",
+			body:       `{"mentor_id":1,"start":"` + startAt(slot) + `","note":"` + strings.Repeat("a", MaxNote+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "note is longer than 1000 characters",
+		},
+		{name: "no start", body: `{"mentor_id":1}`, wantStatus: http.StatusBadRequest, wantError: "start is required"},
+		{
+			name:       "start in the past",
+			body:       `{"mentor_id":1,"start":"` + startAt(slot.Add(-48*time.Hour)) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "start must be within the next 90 days",
+		},
+		{
+			name:       "start beyond horizon",
+			body:       `{"mentor_id":1,"start":"` + startAt(slot.Add(schedule.Horizon)) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "start must be within the next 90 days",
+		},
+		{
+			name:         "off the grid",
+			body:         `{"mentor_id":1,"start":"` + startAt(slot.Add(30*time.Minute)) + `"}`,
+			availability: true,
+			wantStatus:   http.StatusConflict,
+			wantError:    "slot is not available",
+		},
+		{
+			name:            "no schedule",
+			body:            `{"mentor_id":1,"start":"` + startAt(slot) + `"}`,
+			availabilityErr: db.ErrNoSchedule,
+			wantStatus:      http.StatusConflict,
+			wantError:       "slot is not available",
+		},
+		{
+			name:            "mentor not found",
+			body:            `{"mentor_id":1,"start":"` + startAt(slot) + `"}`,
+			availabilityErr: db.ErrMentorNotFound,
+			wantStatus:      http.StatusNotFound,
+			wantError:       "mentor not found",
+		},
+		{
+			name:            "availability error",
+			body:            `{"mentor_id":1,"start":"` + startAt(slot) + `"}`,
+			availabilityErr: errors.New("db down"),
+			wantStatus:      http.StatusInternalServerError,
+			wantError:       "server error",
+		},
+		{
+			name:         "slot taken",
+			body:         `{"mentor_id":1,"start":"` + startAt(slot) + `"}`,
+			availability: true,
+			bookErr:      db.ErrSlotTaken,
+			wantStatus:   http.StatusConflict,
+			wantError:    "slot is already booked",
+			wantBooked:   true,
+		},
+		{
+			name:         "mentee busy",
+			body:         `{"mentor_id":1,"start":"` + startAt(slot) + `"}`,
+			availability: true,
+			bookErr:      db.ErrMenteeBusy,
+			wantStatus:   http.StatusConflict,
+			wantError:    "mentee has another session at this time",
+			wantBooked:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{availabilityErr: tt.availabilityErr, bookErr: tt.bookErr}
+			if tt.availability {
+				repo.availability = openAvailability(t)
+			}
+
+			rr := serve(t, Book(log, repo), http.MethodPost, "/sessions", "/sessions", tt.body)
+			checkResponse(t, rr, tt.wantStatus, tt.wantError)
+
+			if (repo.booked != nil) != tt.wantBooked {
+				t.Fatalf("booked = %v, want %v", repo.booked != nil, tt.wantBooked)
+			}
+			if tt.wantBooked {
+				got := repo.booked
+				if got.MentorID != mentorID || got.MenteeID != menteeID ||
+					!got.StartsAt.Equal(slot) || !got.EndsAt.Equal(slot.Add(time.Hour)) {
+					t.Fatalf("unexpected session %+v", got)
+				}
+			}
+			if tt.name == "success" && repo.booked.Note != "intro call" {
+				t.Fatalf("note not trimmed: %q", repo.booked.Note)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		cancelErr  error
+		wantStatus int
+		wantError  string
+		wantReason string
+	}{
+		{name: "without body", path: "/sessions/5/cancel", wantStatus: http.StatusOK},
+		{name: "with reason", path: "/sessions/5/cancel", body: `{"reason":" sick "}`, wantStatus: http.StatusOK, wantReason: "sick"},
+		{name: "invalid id", path: "/sessions/abc/cancel", wantStatus: http.StatusBadRequest, wantError: "invalid session id"},
+		{name: "invalid body", path: "/sessions/5/cancel", body: `{"reason":`, wantStatus: http.StatusBadRequest, wantError: "invalid request body"},
+		{
+			name:       "long reason",
+			path:       "/sessions/5/cancel",
+			body:       `{"reason":"` + strings.Repeat("a", MaxReason+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "reason is longer than 500 characters",
+		},
+		{
+			name:       "not a participant",
+			path:       "/sessions/5/cancel",
+			cancelErr:  db.ErrSessionNotFound,
+			wantStatus: http.StatusNotFound,
+			wantError:  "session not found",
+		},
+		{
+			name:       "already started",
+			path:       "/sessions/5/cancel",
+			cancelErr:  db.ErrSessionClosed,
+			wantStatus: http.StatusConflict,
+			wantError:  "session is cancelled or already started",
+		},
+		{
+			name:       "storage error",
+			path:       "/sessions/5/cancel",
+			cancelErr:  errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "server error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{cancelErr: tt.cancelErr}
+			rr := serve(t, Cancel(log, repo), http.MethodPost, "/sessions/{id}/cancel", tt.path, tt.body)
+			checkResponse(t, rr, tt.wantStatus, tt.wantError)
+			if repo.cancelled != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", repo.cancelled, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	slot := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	current := &models.Session{ID: 5, MentorID: mentorID, MenteeID: menteeID}
+
+	tests := []struct {
+		name            string
+		path            string
+		body            string
+		sessionErr      error
+		availabilityErr error
+		rescheduleErr   error
+		wantStatus      int
+		wantError       string
+		wantRescheduled bool
+	}{
+		{
+			name:            "success",
+			path:            "/sessions/5/reschedule",
+			body:            `{"start":"` + startAt(slot) + `"}`,
+			wantStatus:      http.StatusOK,
+			wantRescheduled: true,
+		},
+		{name: "invalid id", path: "/sessions/0/reschedule", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid session id"},
+		{name: "invalid body", path: "/sessions/5/reschedule", body: `{"start":"tomorrow"}`, wantStatus: http.StatusBadRequest, wantError: "invalid request body"},
+		{
+			name:       "not a participant",
+			path:       "/sessions/5/reschedule",
+			body:       `{"start":"` + startAt(slot) + `"}`,
+			sessionErr: db.ErrSessionNotFound,
+			wantStatus: http.StatusNotFound,
+			wantError:  "session not found",
+		},
+		{
+			name:       "beyond horizon",
+			path:       "/sessions/5/reschedule",
+			body:       `{"start":"` + startAt(slot.Add(schedule.Horizon)) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "start must be within the next 90 days",
+		},
+		{
+			name:       "in the past",
+			path:       "/sessions/5/reschedule",
+			body:       `{"start":"` + startAt(slot.Add(-72*time.Hour)) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "start must be within the next 90 days",
+		},
+		{
+			name:       "off the grid",
+			path:       "/sessions/5/reschedule",
+			body:       `{"start":"` + startAt(slot.Add(15*time.Minute)) + `"}`,
+			wantStatus: http.StatusConflict,
+			wantError:  "slot is not available",
+		},
+		{
+			name:            "mentor removed schedule",
+			path:            "/sessions/5/reschedule",
+			body:            `{"start":"` + startAt(slot) + `"}`,
+			availabilityErr: db.ErrNoSchedule,
+			wantStatus:      http.StatusConflict,
+			wantError:       "slot is not available",
+		},
+		{
+			name:            "slot taken",
+			path:            "/sessions/5/reschedule",
+			body:            `{"start":"` + startAt(slot) + `"}`,
+			rescheduleErr:   db.ErrSlotTaken,
+			wantStatus:      http.StatusConflict,
+			wantError:       "slot is already booked",
+			wantRescheduled: true,
+		},
+		{
+			name:            "session already started",
+			path:            "/sessions/5/reschedule",
+			body:            `{"start":"` + startAt(slot) + `"}`,
+			rescheduleErr:   db.ErrSessionClosed,
+			wantStatus:      http.StatusConflict,
+			wantError:       "session is cancelled or already started",
+			wantRescheduled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{
+				availability:    openAvailability(t),
+				availabilityErr: tt.availabilityErr,
+				session:         current,
+				sessionErr:      tt.sessionErr,
+				rescheduleErr:   tt.rescheduleErr,
+			}
+			if tt.availabilityErr != nil {
+				repo.availability = nil
+			}
+
+			rr := serve(t, Reschedule(log, repo), http.MethodPost, "/sessions/{id}/reschedule", tt.path, tt.body)
+			checkResponse(t, rr, tt.wantStatus, tt.wantError)
+
+			if (repo.rescheduled != nil) != tt.wantRescheduled {
+				t.Fatalf("rescheduled = %v, want %v", repo.rescheduled != nil, tt.wantRescheduled)
+			}
+			if tt.wantRescheduled && (!repo.rescheduled.Start.Equal(slot) || !repo.rescheduled.End.Equal(slot.Add(time.Hour))) {
+				t.Fatalf("unexpected slot %+v", *repo.rescheduled)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package setavailability
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/lib/schedule"
+	mwAuth "mentor/internal/middleware/auth"
+	"mentor/internal/storage/db"
+	"mentor/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type AvailabilitySaver interface {
+	SaveAvailability(ctx context.Context, mentorID int64, availability *models.Availability) error
+}
+
+// Set заменяет расписание ментора из токена. Уже забронированные занятия не отменяются,
+// даже если их время больше не входит в расписание
+func Set(log *slog.Logger, saver AvailabilitySaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.setavailability.set.Set"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		var req models.Availability
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := schedule.Normalize(&req); err != nil {
+			log.Warn("invalid availability", sl.Err(err), slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		err := saver.SaveAvailability(r.Context(), claims.UserID, &req)
+		if errors.Is(err, db.ErrMentorNotFound) {
+			log.Warn("mentor not found", slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to save availability", sl.Err(err), slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		log.Info("mentor availability updated", slog.Int64("mentor_id", claims.UserID))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"availability": req,
+		})
+	}
+}
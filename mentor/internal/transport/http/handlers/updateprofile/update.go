@@ -0,0 +1,79 @@
+package updateprofile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	"mentor/internal/lib/logger/sl"
+	"mentor/internal/lib/profile"
+	mwAuth "mentor/internal/middleware/auth"
+	"mentor/internal/storage/db"
+	"mentor/pkg/token"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type ProfileUpdater interface {
+	UpdateProfile(ctx context.Context, mentorID int64, profile *models.Profile) error
+}
+
+type RedisRepository interface {
+	InvalidateMentors(ctx context.Context) error
+}
+
+// Update заменяет профиль ментора из токена: поля, которых нет в запросе, очищаются
+func Update(log *slog.Logger, updater ProfileUpdater, redisRepo RedisRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.updateprofile.update.Update"
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		claims := r.Context().Value(mwAuth.UserKey).(*token.Claims)
+
+		var req models.Profile
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid request body"))
+			return
+		}
+
+		if err := profile.Normalize(&req); err != nil {
+			log.Warn("invalid profile", sl.Err(err), slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+
+		err := updater.UpdateProfile(r.Context(), claims.UserID, &req)
+		if errors.Is(err, db.ErrMentorNotFound) {
+			log.Warn("mentor not found", slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("mentor not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to update profile", sl.Err(err), slog.Int64("mentor_id", claims.UserID))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("server error"))
+			return
+		}
+
+		// Каталог кэшируется, без сброса изменения появятся только через минуту
+		if err := redisRepo.InvalidateMentors(r.Context()); err != nil {
+			log.Error("failed to invalidate mentors cache", sl.Err(err))
+		}
+
+		log.Info("mentor profile updated", slog.Int64("mentor_id", claims.UserID))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, map[string]any{
+			"profile": req,
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package updateprofile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/domain/response"
+	mwAuth "mentor/internal/middleware/auth"
+	"mentor/internal/storage/db"
+	"mentor/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeUpdater struct {
+	err      error
+	calls    int
+	mentorID int64
+	profile  models.Profile
+}
+
+func (f *fakeUpdater) UpdateProfile(_ context.Context, mentorID int64, p *models.Profile) error {
+	f.calls++
+	f.mentorID = mentorID
+	f.profile = *p
+	return f.err
+}
+
+type fakeRedis struct {
+	err         error
+	invalidated int
+}
+
+func (f *fakeRedis) InvalidateMentors(context.Context) error {
+	f.invalidated++
+	return f.err
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		updateErr       error
+		redisErr        error
+		wantStatus      int
+		wantError       string
+		wantUpdate      bool
+		wantInvalidated bool
+	}{
+		{
+			name:            "success",
+			body:            `{"headline":" Go mentor ","skills":["Go","go"],"avatar_url":"https://cdn.example.com/a.png"}`,
+			wantStatus:      http.StatusOK,
+			wantUpdate:      true,
+			wantInvalidated: true,
+		},
+		{
+			name:       "invalid body",
+			body:       `{"headline":`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid request body",
+		},
+		{
+			name:       "invalid profile",
+			body:       `{"avatar_url":"javascript:alert(1)"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid profile: avatar_url must be an http(s) URL",
+		},
+		{
+			name:       "mentor not found",
+			body:       `{}`,
+			updateErr:  db.ErrMentorNotFound,
+			wantStatus: http.StatusNotFound,
+			wantError:  "mentor not found",
+			wantUpdate: true,
+		},
+		{
+			name:       "storage error",
+			body:       `{}`,
+			updateErr:  errors.New("db down"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "server error",
+			wantUpdate: true,
+		},
+		{
+			name:            "cache error does not fail the update",
+			body:            `{}`,
+			redisErr:        errors.New("redis down"),
+			wantStatus:      http.StatusOK,
+			wantUpdate:      true,
+			wantInvalidated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updater := &fakeUpdater{err: tt.updateErr}
+			redis := &fakeRedis{err: tt.redisErr}
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			req := httptest.NewRequest(http.MethodPut, "/mentors/me/profile", strings.NewReader(tt.body))
+			claims := &token.Claims{UserID: 7, Role: "mentor", TokenType: "access"}
+			req = req.WithContext(context.WithValue(req.Context(), mwAuth.UserKey, claims))
+			rr := httptest.NewRecorder()
+
+			Update(log, updater, redis).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if (updater.calls == 1) != tt.wantUpdate {
+				t.Fatalf("update calls = %d", updater.calls)
+			}
+			if (redis.invalidated == 1) != tt.wantInvalidated {
+				t.Fatalf("invalidate calls = %d", redis.invalidated)
+			}
+			if tt.wantError != "" {
+				var resp response.Response
+				if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Error != tt.wantError {
+					t.Fatalf("error = %q, want %q", resp.Error, tt.wantError)
+				}
+			}
+			if tt.wantUpdate && updater.mentorID != 7 {
+				t.Fatalf("updated mentor %d, want the one from the token", updater.mentorID)
+			}
+		})
+	}
+}
+
+func TestUpdateStoresNormalizedProfile(t *testing.T) {
+	updater := &fakeUpdater{}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	body := `{"headline":" Go mentor ","skills":["Go","go"," SQL "],"languages":["EN"],"seniority":"Senior"}`
+	req := httptest.NewRequest(http.MethodPut, "/mentors/me/profile", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), mwAuth.UserKey, &token.Claims{UserID: 7}))
+	rr := httptest.NewRecorder()
+
+	Update(log, updater, &fakeRedis{}).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Profile models.Profile `json:"profile"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []models.Profile{updater.profile, resp.Profile} {
+		if p.Headline != "Go mentor" || p.Seniority != "senior" ||
+			strings.Join(p.Skills, ",") != "go,sql" || strings.Join(p.Languages, ",") != "en" {
+			t.Fatalf("profile not normalized: %+v", p)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package userdata обрабатывает события сервиса авторизации о пользователях:
+// удаляет профиль удалённого ментора и отменяет занятия пользователя, отдаёт профиль в выгрузку данных
+// переносит профиль на новый email и связывает записи, созданные по email, с id пользователя.
+package userdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mentor/internal/domain/models"
+	"mentor/internal/storage/db"
+)
+
+const (
+	EventUserDeleted     = "user.deleted"
+	EventExportRequested = "user.export_requested"
+	EventEmailChanged    = "user.email_changed"
+	EventIDBackfill      = "user.id_backfill"
+
+	// ServiceName имя части в архиве выгрузки
+	ServiceName = "mentor"
+)
+
+type PostgresRepository interface {
+	GetMentor(ctx context.Context, mentorID int64, mentorEmail string) (*models.MentorProfile, error)
+	DeleteMentor(ctx context.Context, mentorID int64, mentorEmail string) error
+	DeleteUserSessions(ctx context.Context, userID int64) (int, error)
+	RekeyMentor(ctx context.Context, mentorID int64, oldEmail, newEmail string) error
+	LinkMentorID(ctx context.Context, mentorID int64, mentorEmail string) error
+}
+
+type RedisRepository interface {
+	InvalidateMentors(ctx context.Context) error
+}
+
+type Producer interface {
+	SendExportPart(part *models.ExportPart) error
+}
+
+type Processor struct {
+	log      *slog.Logger
+	repo     PostgresRepository
+	cache    RedisRepository
+	producer Producer
+}
+
+func NewProcessor(log *slog.Logger, repo PostgresRepository, cache RedisRepository, producer Producer) *Processor {
+	return &Processor{log: log, repo: repo, cache: cache, producer: producer}
+}
+
+// Handle обрабатывает событие; при ошибке сообщение нужно прочитать повторно
+func (p *Processor) Handle(ctx context.Context, event *models.UserEvent) error {
+	switch event.Type {
+	case EventUserDeleted:
+		return p.deleteUser(ctx, event)
+	case EventExportRequested:
+		return p.export(ctx, event)
+	case EventEmailChanged:
+		return p.rekeyMentor(ctx, event)
+	case EventIDBackfill:
+		return p.linkMentorID(ctx, event)
+	default:
+		p.log.Debug("skipping user event", slog.String("type", event.Type))
+		return nil
+	}
+}
+
+func (p *Processor) deleteUser(ctx context.Context, event *models.UserEvent) error {
+	const op = "userdata.deleteUser"
+
+	// Занятия отменяются до удаления ментора: вместе с его записью они удалятся без событий
+	cancelled, err := p.repo.DeleteUserSessions(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Ментором мог быть и пользователь, у которого роль уже сняли, поэтому удаляем всегда
+	if err := p.repo.DeleteMentor(ctx, event.UserID, event.Email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Каталог сам обновится через минуту, ошибку кэша не повторяем
+	if err := p.cache.InvalidateMentors(ctx); err != nil {
+		p.log.Error("failed to invalidate mentors cache", "error", err)
+	}
+
+	p.log.Info("mentor data deleted", slog.Int64("user_id", event.UserID), slog.Int("sessions_cancelled", cancelled))
+	return nil
+}
+
+func (p *Processor) export(ctx context.Context, event *models.UserEvent) error {
+	const op = "userdata.export"
+
+	// У обычного пользователя здесь ничего нет, но часть всё равно нужна:
+	// сервис авторизации ждёт ответа от всех сервисов
+	var data any
+	mentor, err := p.repo.GetMentor(ctx, event.UserID, event.Email)
+	switch {
+	case errors.Is(err, db.ErrMentorNotFound):
+	case err != nil:
+		return fmt.Errorf("%s: %w", op, err)
+	default:
+		data = mentor
+	}
+
+	err = p.producer.SendExportPart(&models.ExportPart{
+		ExportID: event.ExportID,
+		UserID:   event.UserID,
+		Service:  ServiceName,
+		Data:     data,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// rekeyMentor менторы хранятся по email; повторная доставка события ничего не меняет
+func (p *Processor) rekeyMentor(ctx context.Context, event *models.UserEvent) error {
+	const op = "userdata.rekeyMentor"
+
+	if event.OldEmail == "" || event.OldEmail == event.Email {
+		return nil
+	}
+
+	if err := p.repo.RekeyMentor(ctx, event.UserID, event.OldEmail, event.Email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.cache.InvalidateMentors(ctx); err != nil {
+		p.log.Error("failed to invalidate mentors cache", "error", err)
+	}
+
+	p.log.Info("mentor email changed", slog.Int64("user_id", event.UserID))
+	return nil
+}
+
+// linkMentorID у пользователей, которые не были менторами, обновлять нечего
+func (p *Processor) linkMentorID(ctx context.Context, event *models.UserEvent) error {
+	const op = "userdata.linkMentorID"
+
+	if err := p.repo.LinkMentorID(ctx, event.UserID, event.Email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.cache.InvalidateMentors(ctx); err != nil {
+		p.log.Error("failed to invalidate mentors cache", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+ALTER TABLE mentors
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
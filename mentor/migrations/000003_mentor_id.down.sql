@@ -0,0 +1 @@
+ALTER TABLE mentors DROP COLUMN IF EXISTS mentor_id;
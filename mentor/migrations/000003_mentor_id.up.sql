@@ -0,0 +1,4 @@
+-- id пользователя в сервисе авторизации. NULL у записей, созданных по email
+-- до перехода на id; их связывает событие user.id_backfill
+ALTER TABLE mentors
+    ADD COLUMN IF NOT EXISTS mentor_id BIGINT UNIQUE;
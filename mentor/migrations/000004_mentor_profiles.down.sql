@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mentor_languages;
+DROP TABLE IF EXISTS mentor_skills;
+DROP TABLE IF EXISTS mentor_profiles;
@@ -0,0 +1,30 @@
+-- Профиль ментора. Таблицы ссылаются на mentors.id, а не на mentor_id:
+-- профиль есть и у записей, которые ещё не связаны с пользователем
+CREATE TABLE IF NOT EXISTS mentor_profiles (
+    mentor_row_id INTEGER PRIMARY KEY REFERENCES mentors (id) ON DELETE CASCADE,
+    headline VARCHAR(120) NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    seniority VARCHAR(20) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    -- цена часа в целых единицах валюты платформы, 0 - не указана
+    hourly_price INTEGER NOT NULL DEFAULT 0 CHECK (hourly_price >= 0),
+    avatar_url TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- position сохраняет порядок, в котором ментор перечислил навыки
+CREATE TABLE IF NOT EXISTS mentor_skills (
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    skill VARCHAR(50) NOT NULL,
+    PRIMARY KEY (mentor_row_id, skill)
+);
+
+CREATE INDEX IF NOT EXISTS mentor_skills_skill_idx ON mentor_skills (skill);
+
+CREATE TABLE IF NOT EXISTS mentor_languages (
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    language VARCHAR(8) NOT NULL,
+    PRIMARY KEY (mentor_row_id, language)
+);
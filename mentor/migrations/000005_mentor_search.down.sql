@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS mentors_active_rating_idx;
+DROP INDEX IF EXISTS mentor_languages_language_idx;
+DROP INDEX IF EXISTS mentor_profiles_search_idx;
+ALTER TABLE mentor_profiles DROP COLUMN IF EXISTS search_vector;
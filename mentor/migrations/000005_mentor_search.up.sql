@@ -0,0 +1,11 @@
+-- Полнотекстовый поиск по профилю. Конфигурация simple: профили пишут
+-- на разных языках, стемминг одного языка портит остальные
+ALTER TABLE mentor_profiles
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', headline), 'A') ||
+        setweight(to_tsvector('simple', bio), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS mentor_profiles_search_idx ON mentor_profiles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS mentor_languages_language_idx ON mentor_languages (language);
+CREATE INDEX IF NOT EXISTS mentors_active_rating_idx ON mentors (average_rating DESC, id DESC) WHERE status = 'active';
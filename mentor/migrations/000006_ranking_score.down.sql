@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS mentors_active_score_idx;
+ALTER TABLE mentors DROP COLUMN IF EXISTS ranking_score;
@@ -0,0 +1,6 @@
+-- Байесовское среднее по приору из конфига сервиса. Миграция его не знает,
+-- поэтому оценки заполняет сервис при старте (RecomputeRanking)
+ALTER TABLE mentors
+    ADD COLUMN IF NOT EXISTS ranking_score FLOAT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS mentors_active_score_idx ON mentors (ranking_score DESC, id DESC) WHERE status = 'active';
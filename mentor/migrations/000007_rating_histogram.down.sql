@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS mentor_ratings;
+ALTER TABLE mentors
+    DROP COLUMN IF EXISTS stars_1,
+    DROP COLUMN IF EXISTS stars_2,
+    DROP COLUMN IF EXISTS stars_3,
+    DROP COLUMN IF EXISTS stars_4,
+    DROP COLUMN IF EXISTS stars_5;
@@ -0,0 +1,18 @@
+-- Число отзывов по звёздам; оценка округляется до целого в пределах 1..5.
+-- У существующих менторов счётчики заполнит сверка с сервисом отзывов
+ALTER TABLE mentors
+    ADD COLUMN IF NOT EXISTS stars_1 INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stars_2 INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stars_3 INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stars_4 INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stars_5 INTEGER NOT NULL DEFAULT 0;
+
+-- Текущая оценка каждого отзыва и время, когда она поставлена, для динамики за 30/90 дней
+CREATE TABLE IF NOT EXISTS mentor_ratings (
+    review_id BIGINT PRIMARY KEY,
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    rating FLOAT NOT NULL,
+    rated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS mentor_ratings_mentor_idx ON mentor_ratings (mentor_row_id, rated_at);
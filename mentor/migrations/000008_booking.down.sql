@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS mentor_sessions;
+DROP TABLE IF EXISTS mentor_availability_exceptions;
+DROP TABLE IF EXISTS mentor_availability;
+DROP TABLE IF EXISTS mentor_schedules;
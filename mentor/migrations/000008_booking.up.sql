@@ -0,0 +1,68 @@
+-- btree_gist нужен, чтобы в одном ограничении исключения сравнивать id на равенство
+-- и интервалы на пересечение
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Расписание ментора: окна задаются в минутах от начала суток в его часовом поясе,
+-- поэтому при переходе на летнее время окна не съезжают
+CREATE TABLE IF NOT EXISTS mentor_schedules (
+    mentor_row_id INTEGER PRIMARY KEY REFERENCES mentors (id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL,
+    session_minutes SMALLINT NOT NULL CHECK (session_minutes > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- weekday как в Go: 0 - воскресенье
+CREATE TABLE IF NOT EXISTS mentor_availability (
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_minute SMALLINT NOT NULL CHECK (start_minute >= 0),
+    end_minute SMALLINT NOT NULL CHECK (end_minute <= 1440 AND end_minute > start_minute)
+);
+
+CREATE INDEX IF NOT EXISTS mentor_availability_mentor_idx ON mentor_availability (mentor_row_id);
+
+-- Исключение заменяет недельное расписание на дату; строка без окна - выходной
+CREATE TABLE IF NOT EXISTS mentor_availability_exceptions (
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    start_minute SMALLINT NULL CHECK (start_minute >= 0),
+    end_minute SMALLINT NULL CHECK (end_minute <= 1440 AND end_minute > start_minute),
+    CHECK ((start_minute IS NULL) = (end_minute IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS mentor_availability_exceptions_mentor_idx ON mentor_availability_exceptions (mentor_row_id, day);
+
+-- Пересечения занятий запрещает сама база: проверка в коде не спасает от двух
+-- одновременных бронирований. Отменённые занятия время не занимают
+CREATE TABLE IF NOT EXISTS mentor_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    mentor_row_id INTEGER NOT NULL REFERENCES mentors (id) ON DELETE CASCADE,
+    mentee_id BIGINT NOT NULL,
+    period TSTZRANGE NOT NULL CHECK (NOT isempty(period)),
+    status VARCHAR(16) NOT NULL DEFAULT 'booked' CHECK (status IN ('booked', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    rescheduled_from BIGINT NULL REFERENCES mentor_sessions (id) ON DELETE SET NULL,
+    cancelled_by BIGINT NULL,
+    cancel_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    cancelled_at TIMESTAMPTZ NULL,
+    CONSTRAINT mentor_sessions_mentor_overlap EXCLUDE USING gist (mentor_row_id WITH =, period WITH &&) WHERE (status = 'booked'),
+    CONSTRAINT mentor_sessions_mentee_overlap EXCLUDE USING gist (mentee_id WITH =, period WITH &&) WHERE (status = 'booked')
+);
+
+CREATE INDEX IF NOT EXISTS mentor_sessions_mentee_idx ON mentor_sessions (mentee_id, lower(period));
+
+-- События о занятиях пишутся в одной транзакции с бронированием и публикуются
+-- в Kafka фоновым relay с повторами
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (next_attempt_at) WHERE processed_at IS NULL;
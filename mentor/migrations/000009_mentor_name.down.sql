@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS mentor_profiles_search_idx;
+ALTER TABLE mentor_profiles DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE mentor_profiles
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', headline), 'A') ||
+        setweight(to_tsvector('simple', bio), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS mentor_profiles_search_idx ON mentor_profiles USING GIN (search_vector);
+ALTER TABLE mentor_profiles DROP COLUMN IF EXISTS name;
@@ -0,0 +1,15 @@
+-- Имя ментора ищется вместе с профилем и весит больше заголовка.
+-- Выражение генерируемой колонки не меняется, поэтому колонка пересоздаётся
+ALTER TABLE mentor_profiles
+    ADD COLUMN IF NOT EXISTS name VARCHAR(100) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS mentor_profiles_search_idx;
+ALTER TABLE mentor_profiles DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE mentor_profiles
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') ||
+        setweight(to_tsvector('simple', headline), 'B') ||
+        setweight(to_tsvector('simple', bio), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS mentor_profiles_search_idx ON mentor_profiles USING GIN (search_vector);
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
-// 	protoc        v5.28.3
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
 // source: proto/mentor.proto
 
 package api
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,23 +21,26 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Ментор ищется по mentor_id; mentor_email читается, только если id не задан,
+// и будет удалён после перехода всех клиентов на id.
+// review_id нужен для динамики оценок, 0 у событий старого формата
 type RatingRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Action string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// Deprecated: Marked as deprecated in proto/mentor.proto.
+	MentorEmail   string  `protobuf:"bytes,2,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	Rating        float32 `protobuf:"fixed32,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	MentorId      int64   `protobuf:"varint,4,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	ReviewId      int64   `protobuf:"varint,5,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Action      string  `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
-	MentorEmail string  `protobuf:"bytes,2,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
-	Rating      float32 `protobuf:"fixed32,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RatingRequest) Reset() {
 	*x = RatingRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_mentor_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_mentor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *RatingRequest) String() string {
@@ -47,7 +51,7 @@ func (*RatingRequest) ProtoMessage() {}
 
 func (x *RatingRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mentor_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -69,6 +73,7 @@ func (x *RatingRequest) GetAction() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in proto/mentor.proto.
 func (x *RatingRequest) GetMentorEmail() string {
 	if x != nil {
 		return x.MentorEmail
@@ -83,22 +88,37 @@ func (x *RatingRequest) GetRating() float32 {
 	return 0
 }
 
+func (x *RatingRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+func (x *RatingRequest) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+// mentor_id - id пользователя в сервисе авторизации; profile - необязательный
+// начальный профиль, при повторной доставке того же ментора не применяется
 type MentorRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MentorEmail   string                 `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	Contact       string                 `protobuf:"bytes,2,opt,name=contact,proto3" json:"contact,omitempty"`
+	MentorId      int64                  `protobuf:"varint,3,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	Profile       *MentorProfile         `protobuf:"bytes,4,opt,name=profile,proto3" json:"profile,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	MentorEmail string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
-	Contact     string `protobuf:"bytes,2,opt,name=contact,proto3" json:"contact,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MentorRequest) Reset() {
 	*x = MentorRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_mentor_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_mentor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *MentorRequest) String() string {
@@ -109,7 +129,7 @@ func (*MentorRequest) ProtoMessage() {}
 
 func (x *MentorRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mentor_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -138,21 +158,143 @@ func (x *MentorRequest) GetContact() string {
 	return ""
 }
 
-type CheckRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+func (x *MentorRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+func (x *MentorRequest) GetProfile() *MentorProfile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+// hourly_price в целых единицах валюты платформы, 0 - цена не указана
+type MentorProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Headline      string                 `protobuf:"bytes,1,opt,name=headline,proto3" json:"headline,omitempty"`
+	Bio           string                 `protobuf:"bytes,2,opt,name=bio,proto3" json:"bio,omitempty"`
+	Skills        []string               `protobuf:"bytes,3,rep,name=skills,proto3" json:"skills,omitempty"`
+	Seniority     string                 `protobuf:"bytes,4,opt,name=seniority,proto3" json:"seniority,omitempty"`
+	Languages     []string               `protobuf:"bytes,5,rep,name=languages,proto3" json:"languages,omitempty"`
+	Timezone      string                 `protobuf:"bytes,6,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	HourlyPrice   int32                  `protobuf:"varint,7,opt,name=hourly_price,json=hourlyPrice,proto3" json:"hourly_price,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,8,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	Name          string                 `protobuf:"bytes,9,opt,name=name,proto3" json:"name,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	MentorEmail string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+func (x *MentorProfile) Reset() {
+	*x = MentorProfile{}
+	mi := &file_proto_mentor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *CheckRequest) Reset() {
-	*x = CheckRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_mentor_proto_msgTypes[2]
+func (x *MentorProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MentorProfile) ProtoMessage() {}
+
+func (x *MentorProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mentor_proto_msgTypes[2]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MentorProfile.ProtoReflect.Descriptor instead.
+func (*MentorProfile) Descriptor() ([]byte, []int) {
+	return file_proto_mentor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MentorProfile) GetHeadline() string {
+	if x != nil {
+		return x.Headline
+	}
+	return ""
+}
+
+func (x *MentorProfile) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+func (x *MentorProfile) GetSkills() []string {
+	if x != nil {
+		return x.Skills
+	}
+	return nil
+}
+
+func (x *MentorProfile) GetSeniority() string {
+	if x != nil {
+		return x.Seniority
+	}
+	return ""
+}
+
+func (x *MentorProfile) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *MentorProfile) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *MentorProfile) GetHourlyPrice() int32 {
+	if x != nil {
+		return x.HourlyPrice
+	}
+	return 0
+}
+
+func (x *MentorProfile) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *MentorProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: Marked as deprecated in proto/mentor.proto.
+	MentorEmail   string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	MentorId      int64  `protobuf:"varint,2,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_proto_mentor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *CheckRequest) String() string {
@@ -162,8 +304,8 @@ func (x *CheckRequest) String() string {
 func (*CheckRequest) ProtoMessage() {}
 
 func (x *CheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mentor_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_proto_mentor_proto_msgTypes[3]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -175,9 +317,10 @@ func (x *CheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
 func (*CheckRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mentor_proto_rawDescGZIP(), []int{2}
+	return file_proto_mentor_proto_rawDescGZIP(), []int{3}
 }
 
+// Deprecated: Marked as deprecated in proto/mentor.proto.
 func (x *CheckRequest) GetMentorEmail() string {
 	if x != nil {
 		return x.MentorEmail
@@ -185,23 +328,139 @@ func (x *CheckRequest) GetMentorEmail() string {
 	return ""
 }
 
-type CheckResponse struct {
-	state         protoimpl.MessageState
+func (x *CheckRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+type ActivateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: Marked as deprecated in proto/mentor.proto.
+	MentorEmail   string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	MentorId      int64  `protobuf:"varint,2,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivateRequest) Reset() {
+	*x = ActivateRequest{}
+	mi := &file_proto_mentor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivateRequest) ProtoMessage() {}
+
+func (x *ActivateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mentor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivateRequest.ProtoReflect.Descriptor instead.
+func (*ActivateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mentor_proto_rawDescGZIP(), []int{4}
+}
+
+// Deprecated: Marked as deprecated in proto/mentor.proto.
+func (x *ActivateRequest) GetMentorEmail() string {
+	if x != nil {
+		return x.MentorEmail
+	}
+	return ""
+}
+
+func (x *ActivateRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+type DeactivateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: Marked as deprecated in proto/mentor.proto.
+	MentorEmail   string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	MentorId      int64  `protobuf:"varint,2,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Exists  bool   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
-	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+func (x *DeactivateRequest) Reset() {
+	*x = DeactivateRequest{}
+	mi := &file_proto_mentor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *CheckResponse) Reset() {
-	*x = CheckResponse{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_mentor_proto_msgTypes[3]
+func (x *DeactivateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeactivateRequest) ProtoMessage() {}
+
+func (x *DeactivateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mentor_proto_msgTypes[5]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeactivateRequest.ProtoReflect.Descriptor instead.
+func (*DeactivateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mentor_proto_rawDescGZIP(), []int{5}
+}
+
+// Deprecated: Marked as deprecated in proto/mentor.proto.
+func (x *DeactivateRequest) GetMentorEmail() string {
+	if x != nil {
+		return x.MentorEmail
+	}
+	return ""
+}
+
+func (x *DeactivateRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+// mentor_id и mentor_email заполнены, если ментор найден; mentor_id = 0 у записей,
+// которые ещё не связаны с пользователем
+type CheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Exists        bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	MentorId      int64                  `protobuf:"varint,4,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	MentorEmail   string                 `protobuf:"bytes,5,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_proto_mentor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *CheckResponse) String() string {
@@ -211,8 +470,8 @@ func (x *CheckResponse) String() string {
 func (*CheckResponse) ProtoMessage() {}
 
 func (x *CheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mentor_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_proto_mentor_proto_msgTypes[6]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -224,7 +483,7 @@ func (x *CheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
 func (*CheckResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mentor_proto_rawDescGZIP(), []int{3}
+	return file_proto_mentor_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CheckResponse) GetSuccess() bool {
@@ -248,22 +507,33 @@ func (x *CheckResponse) GetMessage() string {
 	return ""
 }
 
+func (x *CheckResponse) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+func (x *CheckResponse) GetMentorEmail() string {
+	if x != nil {
+		return x.MentorEmail
+	}
+	return ""
+}
+
 type Response struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Response) Reset() {
 	*x = Response{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_mentor_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_mentor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Response) String() string {
@@ -273,8 +543,8 @@ func (x *Response) String() string {
 func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mentor_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_proto_mentor_proto_msgTypes[7]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -286,7 +556,7 @@ func (x *Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Response.ProtoReflect.Descriptor instead.
 func (*Response) Descriptor() ([]byte, []int) {
-	return file_proto_mentor_proto_rawDescGZIP(), []int{4}
+	return file_proto_mentor_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Response) GetSuccess() bool {
@@ -303,83 +573,246 @@ func (x *Response) GetMessage() string {
 	return ""
 }
 
-var File_proto_mentor_proto protoreflect.FileDescriptor
+// ReconcileRequest рейтинг ментора по данным сервиса отзывов: заменяет счётчики,
+// разошедшиеся из-за потерянных или повторных событий. stars[i] - число отзывов
+// на i+1 звёзд, recent - отзывы за последние 90 дней для динамики
+type ReconcileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MentorId      int64                  `protobuf:"varint,1,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	Stars         []int32                `protobuf:"varint,2,rep,packed,name=stars,proto3" json:"stars,omitempty"`
+	SumRating     float64                `protobuf:"fixed64,3,opt,name=sum_rating,json=sumRating,proto3" json:"sum_rating,omitempty"`
+	Recent        []*RatedReview         `protobuf:"bytes,4,rep,name=recent,proto3" json:"recent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var file_proto_mentor_proto_rawDesc = []byte{
-	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x22, 0x62, 0x0a, 0x0d,
-	0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
-	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f,
-	0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e,
-	0x74, 0x6f, 0x72, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69,
-	0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67,
-	0x22, 0x4c, 0x0a, 0x0d, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69,
-	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x45,
-	0x6d, 0x61, 0x69, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x22, 0x31,
-	0x0a, 0x0c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21,
-	0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x45, 0x6d, 0x61, 0x69,
-	0x6c, 0x22, 0x5b, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06,
-	0x65, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x78,
-	0x69, 0x73, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x3e,
-	0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xc0,
-	0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x3d, 0x0a, 0x12, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72,
-	0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e,
-	0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e,
-	0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x34, 0x0a, 0x09, 0x4e, 0x65, 0x77, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x12, 0x15, 0x2e, 0x6d,
-	0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x4d, 0x65,
-	0x6e, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x43, 0x68,
-	0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6d, 0x65, 0x6e,
-	0x74, 0x6f, 0x72, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x42, 0x10, 0x5a, 0x0e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
-	0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *ReconcileRequest) Reset() {
+	*x = ReconcileRequest{}
+	mi := &file_proto_mentor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
+func (x *ReconcileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileRequest) ProtoMessage() {}
+
+func (x *ReconcileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mentor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mentor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReconcileRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+func (x *ReconcileRequest) GetStars() []int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return nil
+}
+
+func (x *ReconcileRequest) GetSumRating() float64 {
+	if x != nil {
+		return x.SumRating
+	}
+	return 0
+}
+
+func (x *ReconcileRequest) GetRecent() []*RatedReview {
+	if x != nil {
+		return x.Recent
+	}
+	return nil
+}
+
+// rated_at - unix время в секундах
+type RatedReview struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReviewId      int64                  `protobuf:"varint,1,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	Rating        float32                `protobuf:"fixed32,2,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatedAt       int64                  `protobuf:"varint,3,opt,name=rated_at,json=ratedAt,proto3" json:"rated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RatedReview) Reset() {
+	*x = RatedReview{}
+	mi := &file_proto_mentor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RatedReview) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RatedReview) ProtoMessage() {}
+
+func (x *RatedReview) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mentor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RatedReview.ProtoReflect.Descriptor instead.
+func (*RatedReview) Descriptor() ([]byte, []int) {
+	return file_proto_mentor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RatedReview) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+func (x *RatedReview) GetRating() float32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *RatedReview) GetRatedAt() int64 {
+	if x != nil {
+		return x.RatedAt
+	}
+	return 0
+}
+
+var File_proto_mentor_proto protoreflect.FileDescriptor
+
+const file_proto_mentor_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/mentor.proto\x12\x06mentor\"\xa0\x01\n" +
+	"\rRatingRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12%\n" +
+	"\fmentor_email\x18\x02 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x16\n" +
+	"\x06rating\x18\x03 \x01(\x02R\x06rating\x12\x1b\n" +
+	"\tmentor_id\x18\x04 \x01(\x03R\bmentorId\x12\x1b\n" +
+	"\treview_id\x18\x05 \x01(\x03R\breviewId\"\x9a\x01\n" +
+	"\rMentorRequest\x12!\n" +
+	"\fmentor_email\x18\x01 \x01(\tR\vmentorEmail\x12\x18\n" +
+	"\acontact\x18\x02 \x01(\tR\acontact\x12\x1b\n" +
+	"\tmentor_id\x18\x03 \x01(\x03R\bmentorId\x12/\n" +
+	"\aprofile\x18\x04 \x01(\v2\x15.mentor.MentorProfileR\aprofile\"\x83\x02\n" +
+	"\rMentorProfile\x12\x1a\n" +
+	"\bheadline\x18\x01 \x01(\tR\bheadline\x12\x10\n" +
+	"\x03bio\x18\x02 \x01(\tR\x03bio\x12\x16\n" +
+	"\x06skills\x18\x03 \x03(\tR\x06skills\x12\x1c\n" +
+	"\tseniority\x18\x04 \x01(\tR\tseniority\x12\x1c\n" +
+	"\tlanguages\x18\x05 \x03(\tR\tlanguages\x12\x1a\n" +
+	"\btimezone\x18\x06 \x01(\tR\btimezone\x12!\n" +
+	"\fhourly_price\x18\a \x01(\x05R\vhourlyPrice\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\b \x01(\tR\tavatarUrl\x12\x12\n" +
+	"\x04name\x18\t \x01(\tR\x04name\"R\n" +
+	"\fCheckRequest\x12%\n" +
+	"\fmentor_email\x18\x01 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x1b\n" +
+	"\tmentor_id\x18\x02 \x01(\x03R\bmentorId\"U\n" +
+	"\x0fActivateRequest\x12%\n" +
+	"\fmentor_email\x18\x01 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x1b\n" +
+	"\tmentor_id\x18\x02 \x01(\x03R\bmentorId\"W\n" +
+	"\x11DeactivateRequest\x12%\n" +
+	"\fmentor_email\x18\x01 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x1b\n" +
+	"\tmentor_id\x18\x02 \x01(\x03R\bmentorId\"\x9b\x01\n" +
+	"\rCheckResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1b\n" +
+	"\tmentor_id\x18\x04 \x01(\x03R\bmentorId\x12!\n" +
+	"\fmentor_email\x18\x05 \x01(\tR\vmentorEmail\">\n" +
+	"\bResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x91\x01\n" +
+	"\x10ReconcileRequest\x12\x1b\n" +
+	"\tmentor_id\x18\x01 \x01(\x03R\bmentorId\x12\x14\n" +
+	"\x05stars\x18\x02 \x03(\x05R\x05stars\x12\x1d\n" +
+	"\n" +
+	"sum_rating\x18\x03 \x01(\x01R\tsumRating\x12+\n" +
+	"\x06recent\x18\x04 \x03(\v2\x13.mentor.RatedReviewR\x06recent\"]\n" +
+	"\vRatedReview\x12\x1b\n" +
+	"\treview_id\x18\x01 \x01(\x03R\breviewId\x12\x16\n" +
+	"\x06rating\x18\x02 \x01(\x02R\x06rating\x12\x19\n" +
+	"\brated_at\x18\x03 \x01(\x03R\aratedAt2\xfd\x02\n" +
+	"\rMentorService\x12=\n" +
+	"\x12MethodMentorRating\x12\x15.mentor.RatingRequest\x1a\x10.mentor.Response\x124\n" +
+	"\tNewMentor\x12\x15.mentor.MentorRequest\x1a\x10.mentor.Response\x12:\n" +
+	"\vCheckMentor\x12\x14.mentor.CheckRequest\x1a\x15.mentor.CheckResponse\x12=\n" +
+	"\x0fReconcileRating\x12\x18.mentor.ReconcileRequest\x1a\x10.mentor.Response\x12;\n" +
+	"\x0eActivateMentor\x12\x17.mentor.ActivateRequest\x1a\x10.mentor.Response\x12?\n" +
+	"\x10DeactivateMentor\x12\x19.mentor.DeactivateRequest\x1a\x10.mentor.ResponseB\x10Z\x0ementor/pkg/apib\x06proto3"
+
 var (
 	file_proto_mentor_proto_rawDescOnce sync.Once
-	file_proto_mentor_proto_rawDescData = file_proto_mentor_proto_rawDesc
+	file_proto_mentor_proto_rawDescData []byte
 )
 
 func file_proto_mentor_proto_rawDescGZIP() []byte {
 	file_proto_mentor_proto_rawDescOnce.Do(func() {
-		file_proto_mentor_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_mentor_proto_rawDescData)
+		file_proto_mentor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_mentor_proto_rawDesc), len(file_proto_mentor_proto_rawDesc)))
 	})
 	return file_proto_mentor_proto_rawDescData
 }
 
-var file_proto_mentor_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
-var file_proto_mentor_proto_goTypes = []interface{}{
-	(*RatingRequest)(nil), // 0: mentor.RatingRequest
-	(*MentorRequest)(nil), // 1: mentor.MentorRequest
-	(*CheckRequest)(nil),  // 2: mentor.CheckRequest
-	(*CheckResponse)(nil), // 3: mentor.CheckResponse
-	(*Response)(nil),      // 4: mentor.Response
+var file_proto_mentor_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_mentor_proto_goTypes = []any{
+	(*RatingRequest)(nil),     // 0: mentor.RatingRequest
+	(*MentorRequest)(nil),     // 1: mentor.MentorRequest
+	(*MentorProfile)(nil),     // 2: mentor.MentorProfile
+	(*CheckRequest)(nil),      // 3: mentor.CheckRequest
+	(*ActivateRequest)(nil),   // 4: mentor.ActivateRequest
+	(*DeactivateRequest)(nil), // 5: mentor.DeactivateRequest
+	(*CheckResponse)(nil),     // 6: mentor.CheckResponse
+	(*Response)(nil),          // 7: mentor.Response
+	(*ReconcileRequest)(nil),  // 8: mentor.ReconcileRequest
+	(*RatedReview)(nil),       // 9: mentor.RatedReview
 }
 var file_proto_mentor_proto_depIdxs = []int32{
-	0, // 0: mentor.MentorService.MethodMentorRating:input_type -> mentor.RatingRequest
-	1, // 1: mentor.MentorService.NewMentor:input_type -> mentor.MentorRequest
-	2, // 2: mentor.MentorService.CheckMentor:input_type -> mentor.CheckRequest
-	4, // 3: mentor.MentorService.MethodMentorRating:output_type -> mentor.Response
-	4, // 4: mentor.MentorService.NewMentor:output_type -> mentor.Response
-	3, // 5: mentor.MentorService.CheckMentor:output_type -> mentor.CheckResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: mentor.MentorRequest.profile:type_name -> mentor.MentorProfile
+	9, // 1: mentor.ReconcileRequest.recent:type_name -> mentor.RatedReview
+	0, // 2: mentor.MentorService.MethodMentorRating:input_type -> mentor.RatingRequest
+	1, // 3: mentor.MentorService.NewMentor:input_type -> mentor.MentorRequest
+	3, // 4: mentor.MentorService.CheckMentor:input_type -> mentor.CheckRequest
+	8, // 5: mentor.MentorService.ReconcileRating:input_type -> mentor.ReconcileRequest
+	4, // 6: mentor.MentorService.ActivateMentor:input_type -> mentor.ActivateRequest
+	5, // 7: mentor.MentorService.DeactivateMentor:input_type -> mentor.DeactivateRequest
+	7, // 8: mentor.MentorService.MethodMentorRating:output_type -> mentor.Response
+	7, // 9: mentor.MentorService.NewMentor:output_type -> mentor.Response
+	6, // 10: mentor.MentorService.CheckMentor:output_type -> mentor.CheckResponse
+	7, // 11: mentor.MentorService.ReconcileRating:output_type -> mentor.Response
+	7, // 12: mentor.MentorService.ActivateMentor:output_type -> mentor.Response
+	7, // 13: mentor.MentorService.DeactivateMentor:output_type -> mentor.Response
+	8, // [8:14] is the sub-list for method output_type
+	2, // [2:8] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proto_mentor_proto_init() }
@@ -387,75 +820,13 @@ func file_proto_mentor_proto_init() {
 	if File_proto_mentor_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_proto_mentor_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RatingRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_mentor_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MentorRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_mentor_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CheckRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_mentor_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CheckResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_mentor_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Response); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_proto_mentor_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_mentor_proto_rawDesc), len(file_proto_mentor_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -464,7 +835,6 @@ func file_proto_mentor_proto_init() {
 		MessageInfos:      file_proto_mentor_proto_msgTypes,
 	}.Build()
 	File_proto_mentor_proto = out.File
-	file_proto_mentor_proto_rawDesc = nil
 	file_proto_mentor_proto_goTypes = nil
 	file_proto_mentor_proto_depIdxs = nil
 }
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v5.28.3
+// - protoc             (unknown)
 // source: proto/mentor.proto
 
 package api
@@ -22,6 +22,9 @@ const (
 	MentorService_MethodMentorRating_FullMethodName = "/mentor.MentorService/MethodMentorRating"
 	MentorService_NewMentor_FullMethodName          = "/mentor.MentorService/NewMentor"
 	MentorService_CheckMentor_FullMethodName        = "/mentor.MentorService/CheckMentor"
+	MentorService_ReconcileRating_FullMethodName    = "/mentor.MentorService/ReconcileRating"
+	MentorService_ActivateMentor_FullMethodName     = "/mentor.MentorService/ActivateMentor"
+	MentorService_DeactivateMentor_FullMethodName   = "/mentor.MentorService/DeactivateMentor"
 )
 
 // MentorServiceClient is the client API for MentorService service.
@@ -31,6 +34,9 @@ type MentorServiceClient interface {
 	MethodMentorRating(ctx context.Context, in *RatingRequest, opts ...grpc.CallOption) (*Response, error)
 	NewMentor(ctx context.Context, in *MentorRequest, opts ...grpc.CallOption) (*Response, error)
 	CheckMentor(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	ReconcileRating(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*Response, error)
+	ActivateMentor(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*Response, error)
+	DeactivateMentor(ctx context.Context, in *DeactivateRequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type mentorServiceClient struct {
@@ -71,6 +77,36 @@ func (c *mentorServiceClient) CheckMentor(ctx context.Context, in *CheckRequest,
 	return out, nil
 }
 
+func (c *mentorServiceClient) ReconcileRating(ctx context.Context, in *ReconcileRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, MentorService_ReconcileRating_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mentorServiceClient) ActivateMentor(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, MentorService_ActivateMentor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mentorServiceClient) DeactivateMentor(ctx context.Context, in *DeactivateRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, MentorService_DeactivateMentor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MentorServiceServer is the server API for MentorService service.
 // All implementations must embed UnimplementedMentorServiceServer
 // for forward compatibility.
@@ -78,6 +114,9 @@ type MentorServiceServer interface {
 	MethodMentorRating(context.Context, *RatingRequest) (*Response, error)
 	NewMentor(context.Context, *MentorRequest) (*Response, error)
 	CheckMentor(context.Context, *CheckRequest) (*CheckResponse, error)
+	ReconcileRating(context.Context, *ReconcileRequest) (*Response, error)
+	ActivateMentor(context.Context, *ActivateRequest) (*Response, error)
+	DeactivateMentor(context.Context, *DeactivateRequest) (*Response, error)
 	mustEmbedUnimplementedMentorServiceServer()
 }
 
@@ -97,6 +136,15 @@ func (UnimplementedMentorServiceServer) NewMentor(context.Context, *MentorReques
 func (UnimplementedMentorServiceServer) CheckMentor(context.Context, *CheckRequest) (*CheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckMentor not implemented")
 }
+func (UnimplementedMentorServiceServer) ReconcileRating(context.Context, *ReconcileRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileRating not implemented")
+}
+func (UnimplementedMentorServiceServer) ActivateMentor(context.Context, *ActivateRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateMentor not implemented")
+}
+func (UnimplementedMentorServiceServer) DeactivateMentor(context.Context, *DeactivateRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivateMentor not implemented")
+}
 func (UnimplementedMentorServiceServer) mustEmbedUnimplementedMentorServiceServer() {}
 func (UnimplementedMentorServiceServer) testEmbeddedByValue()                       {}
 
@@ -172,6 +220,60 @@ func _MentorService_CheckMentor_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MentorService_ReconcileRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MentorServiceServer).ReconcileRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MentorService_ReconcileRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MentorServiceServer).ReconcileRating(ctx, req.(*ReconcileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MentorService_ActivateMentor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MentorServiceServer).ActivateMentor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MentorService_ActivateMentor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MentorServiceServer).ActivateMentor(ctx, req.(*ActivateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MentorService_DeactivateMentor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeactivateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MentorServiceServer).DeactivateMentor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MentorService_DeactivateMentor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MentorServiceServer).DeactivateMentor(ctx, req.(*DeactivateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MentorService_ServiceDesc is the grpc.ServiceDesc for MentorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +293,18 @@ var MentorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckMentor",
 			Handler:    _MentorService_CheckMentor_Handler,
 		},
+		{
+			MethodName: "ReconcileRating",
+			Handler:    _MentorService_ReconcileRating_Handler,
+		},
+		{
+			MethodName: "ActivateMentor",
+			Handler:    _MentorService_ActivateMentor_Handler,
+		},
+		{
+			MethodName: "DeactivateMentor",
+			Handler:    _MentorService_DeactivateMentor_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/mentor.proto",
@@ -1,8 +1,11 @@
 package models
 
+// ReviewEvent ментор определяется по MentorID; Email приходит только в событиях
+// об отзывах, которые ещё не связаны с id ментора
 type ReviewEvent struct {
-	Action string  `json:"action"` // created/updated/deleted
-	ID     int64   `json:"id"`
-	Email  string  `json:"email"`
-	Score  float32 `json:"score"`
+	Action   string  `json:"action"` // created/updated/deleted
+	ID       int64   `json:"id"`
+	MentorID int64   `json:"mentor_id,omitempty"`
+	Email    string  `json:"email,omitempty"`
+	Score    float32 `json:"score"`
 }
@@ -80,7 +80,7 @@ func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 
 func (c *Consumer) Run(ctx context.Context, topic string) {
 	c.handler.processor = func(ctx context.Context, msg *models.ReviewEvent) error {
-		return c.mentorClient.MethodMentorRating(ctx, msg.Action, msg.Email, msg.Score)
+		return c.mentorClient.MethodMentorRating(ctx, msg.Action, msg.ID, msg.MentorID, msg.Email, msg.Score)
 	}
 
 	go func() {
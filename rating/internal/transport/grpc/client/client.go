@@ -35,9 +35,11 @@ func (m *MentorClient) Close() error {
 	return m.conn.Close()
 }
 
-func (m *MentorClient) MethodMentorRating(ctx context.Context, action, mentorEmail string, rating float32) error {
+func (m *MentorClient) MethodMentorRating(ctx context.Context, action string, reviewID, mentorID int64, mentorEmail string, rating float32) error {
 	req := &pb.RatingRequest{
 		Action:      action,
+		ReviewId:    reviewID,
+		MentorId:    mentorID,
 		MentorEmail: mentorEmail,
 		Rating:      rating,
 	}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
-// 	protoc        v5.28.3
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
 // source: proto/rating.proto
 
 package api
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,23 +21,26 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Ментор ищется по mentor_id; mentor_email читается, только если id не задан,
+// и будет удалён после перехода всех клиентов на id.
+// review_id нужен для динамики оценок, 0 у событий старого формата
 type RatingRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Action string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// Deprecated: Marked as deprecated in proto/rating.proto.
+	MentorEmail   string  `protobuf:"bytes,2,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	Rating        float32 `protobuf:"fixed32,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	MentorId      int64   `protobuf:"varint,4,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
+	ReviewId      int64   `protobuf:"varint,5,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Action      string  `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
-	MentorEmail string  `protobuf:"bytes,2,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
-	Rating      float32 `protobuf:"fixed32,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RatingRequest) Reset() {
 	*x = RatingRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_rating_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_rating_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *RatingRequest) String() string {
@@ -47,7 +51,7 @@ func (*RatingRequest) ProtoMessage() {}
 
 func (x *RatingRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rating_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -69,6 +73,7 @@ func (x *RatingRequest) GetAction() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in proto/rating.proto.
 func (x *RatingRequest) GetMentorEmail() string {
 	if x != nil {
 		return x.MentorEmail
@@ -83,22 +88,35 @@ func (x *RatingRequest) GetRating() float32 {
 	return 0
 }
 
+func (x *RatingRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
+func (x *RatingRequest) GetReviewId() int64 {
+	if x != nil {
+		return x.ReviewId
+	}
+	return 0
+}
+
+// mentor_id - id пользователя в сервисе авторизации
 type MentorRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MentorEmail   string                 `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	Contact       string                 `protobuf:"bytes,2,opt,name=contact,proto3" json:"contact,omitempty"`
+	MentorId      int64                  `protobuf:"varint,3,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	MentorEmail string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
-	Contact     string `protobuf:"bytes,2,opt,name=contact,proto3" json:"contact,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MentorRequest) Reset() {
 	*x = MentorRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_rating_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_rating_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *MentorRequest) String() string {
@@ -109,7 +127,7 @@ func (*MentorRequest) ProtoMessage() {}
 
 func (x *MentorRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rating_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -138,21 +156,27 @@ func (x *MentorRequest) GetContact() string {
 	return ""
 }
 
+func (x *MentorRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
 type CheckRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: Marked as deprecated in proto/rating.proto.
+	MentorEmail   string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	MentorId      int64  `protobuf:"varint,2,opt,name=mentor_id,json=mentorId,proto3" json:"mentor_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	MentorEmail string `protobuf:"bytes,1,opt,name=mentor_email,json=mentorEmail,proto3" json:"mentor_email,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckRequest) Reset() {
 	*x = CheckRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_rating_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_rating_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *CheckRequest) String() string {
@@ -163,7 +187,7 @@ func (*CheckRequest) ProtoMessage() {}
 
 func (x *CheckRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rating_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -178,6 +202,7 @@ func (*CheckRequest) Descriptor() ([]byte, []int) {
 	return file_proto_rating_proto_rawDescGZIP(), []int{2}
 }
 
+// Deprecated: Marked as deprecated in proto/rating.proto.
 func (x *CheckRequest) GetMentorEmail() string {
 	if x != nil {
 		return x.MentorEmail
@@ -185,22 +210,26 @@ func (x *CheckRequest) GetMentorEmail() string {
 	return ""
 }
 
+func (x *CheckRequest) GetMentorId() int64 {
+	if x != nil {
+		return x.MentorId
+	}
+	return 0
+}
+
 type Response struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Response) Reset() {
 	*x = Response{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_rating_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_proto_rating_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Response) String() string {
@@ -211,7 +240,7 @@ func (*Response) ProtoMessage() {}
 
 func (x *Response) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_rating_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -242,57 +271,44 @@ func (x *Response) GetMessage() string {
 
 var File_proto_rating_proto protoreflect.FileDescriptor
 
-var file_proto_rating_proto_rawDesc = []byte{
-	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x22, 0x62, 0x0a, 0x0d,
-	0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
-	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f,
-	0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e,
-	0x74, 0x6f, 0x72, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69,
-	0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67,
-	0x22, 0x4c, 0x0a, 0x0d, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69,
-	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x45,
-	0x6d, 0x61, 0x69, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x22, 0x31,
-	0x0a, 0x0c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21,
-	0x0a, 0x0c, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x45, 0x6d, 0x61, 0x69,
-	0x6c, 0x22, 0x3e, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
-	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
-	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x32, 0xbb, 0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x3d, 0x0a, 0x12, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4d, 0x65, 0x6e,
-	0x74, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x2e, 0x6d, 0x65, 0x6e, 0x74,
-	0x6f, 0x72, 0x2e, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x10, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x34, 0x0a, 0x09, 0x4e, 0x65, 0x77, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x12,
-	0x15, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0b, 0x43, 0x68, 0x65, 0x63,
-	0x6b, 0x4d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x2e, 0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72,
-	0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e,
-	0x6d, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x10, 0x5a, 0x0e, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70,
-	0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_proto_rating_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/rating.proto\x12\x06mentor\"\xa0\x01\n" +
+	"\rRatingRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12%\n" +
+	"\fmentor_email\x18\x02 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x16\n" +
+	"\x06rating\x18\x03 \x01(\x02R\x06rating\x12\x1b\n" +
+	"\tmentor_id\x18\x04 \x01(\x03R\bmentorId\x12\x1b\n" +
+	"\treview_id\x18\x05 \x01(\x03R\breviewId\"i\n" +
+	"\rMentorRequest\x12!\n" +
+	"\fmentor_email\x18\x01 \x01(\tR\vmentorEmail\x12\x18\n" +
+	"\acontact\x18\x02 \x01(\tR\acontact\x12\x1b\n" +
+	"\tmentor_id\x18\x03 \x01(\x03R\bmentorId\"R\n" +
+	"\fCheckRequest\x12%\n" +
+	"\fmentor_email\x18\x01 \x01(\tB\x02\x18\x01R\vmentorEmail\x12\x1b\n" +
+	"\tmentor_id\x18\x02 \x01(\x03R\bmentorId\">\n" +
+	"\bResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage2\xbb\x01\n" +
+	"\rMentorService\x12=\n" +
+	"\x12MethodMentorRating\x12\x15.mentor.RatingRequest\x1a\x10.mentor.Response\x124\n" +
+	"\tNewMentor\x12\x15.mentor.MentorRequest\x1a\x10.mentor.Response\x125\n" +
+	"\vCheckMentor\x12\x14.mentor.CheckRequest\x1a\x10.mentor.ResponseB\x10Z\x0erating/pkg/apib\x06proto3"
 
 var (
 	file_proto_rating_proto_rawDescOnce sync.Once
-	file_proto_rating_proto_rawDescData = file_proto_rating_proto_rawDesc
+	file_proto_rating_proto_rawDescData []byte
 )
 
 func file_proto_rating_proto_rawDescGZIP() []byte {
 	file_proto_rating_proto_rawDescOnce.Do(func() {
-		file_proto_rating_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_rating_proto_rawDescData)
+		file_proto_rating_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_rating_proto_rawDesc), len(file_proto_rating_proto_rawDesc)))
 	})
 	return file_proto_rating_proto_rawDescData
 }
 
 var file_proto_rating_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_proto_rating_proto_goTypes = []interface{}{
+var file_proto_rating_proto_goTypes = []any{
 	(*RatingRequest)(nil), // 0: mentor.RatingRequest
 	(*MentorRequest)(nil), // 1: mentor.MentorRequest
 	(*CheckRequest)(nil),  // 2: mentor.CheckRequest
@@ -317,61 +333,11 @@ func file_proto_rating_proto_init() {
 	if File_proto_rating_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_proto_rating_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RatingRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_rating_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MentorRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_rating_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CheckRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_proto_rating_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Response); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_proto_rating_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_rating_proto_rawDesc), len(file_proto_rating_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   4,
 			NumExtensions: 0,
@@ -382,7 +348,6 @@ func file_proto_rating_proto_init() {
 		MessageInfos:      file_proto_rating_proto_msgTypes,
 	}.Build()
 	File_proto_rating_proto = out.File
-	file_proto_rating_proto_rawDesc = nil
 	file_proto_rating_proto_goTypes = nil
 	file_proto_rating_proto_depIdxs = nil
 }
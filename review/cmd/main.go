@@ -13,18 +13,17 @@ import (
 	del "review/internal/handlers/delete"
 	"review/internal/handlers/get"
 	"review/internal/handlers/update"
+	"review/internal/kafka/consumer"
 	kafka "review/internal/kafka/producer"
 	"review/internal/lib/logger/sl"
-	mwAuth "review/internal/middleware/auth"
-	mwLogger "review/internal/middleware/logger"
+	"review/internal/reconcile"
 	"review/internal/storage/cache"
 	"review/internal/storage/db"
+	"review/internal/transport/http/router"
+	"review/internal/userdata"
 	"review/pkg/token"
 	"syscall"
 	"time"
-
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
 )
 
 const (
@@ -51,6 +50,7 @@ func main() {
 	kafkaProducer, err := kafka.NewProducer(
 		[]string{cfg.KafkaBroker},
 		cfg.KafkaTopic,
+		cfg.ExportPartsTopic,
 		log,
 	)
 
@@ -70,11 +70,14 @@ func main() {
 
 	redisRepository := cache.NewRedisRepository(redisClient)
 
-	tokenMn, err := token.NewTokenManagerRSA(cfg.PublicKeyPath)
-	if err != nil {
-		log.Error("error created a new token manager", sl.Err(err))
-		os.Exit(1)
+	// Ключи берём у сервиса авторизации; если он ещё не поднялся, догрузим при первом запросе
+	keys := token.NewJWKSCache(cfg.JWKSURL)
+	if err := keys.Refresh(ctx); err != nil {
+		log.Warn("failed to load JWKS on start", sl.Err(err))
 	}
+	keys.Start(ctx, cfg.JWKSRefreshInterval, log)
+
+	tokenMn := token.NewTokenManager(keys)
 
 	client, err := grpcclient.NewMentorClient(fmt.Sprintf("mentor-server:%s", cfg.MentorServiceAddress))
 	if err != nil {
@@ -90,22 +93,41 @@ func main() {
 		}
 	}()
 
-	router := chi.NewRouter()
+	// Через сервис авторизации проверяются запросы с заголовком Authorization: ApiKey
+	authClient, err := grpcclient.NewAuthClient(cfg.AuthServiceAddress)
+	if err != nil {
+		log.Error("error with new auth grpc client", sl.Err(err))
+		os.Exit(1)
+	}
+	defer authClient.Close()
+
+	// Удаление и выгрузка данных пользователя по событиям сервиса авторизации
+	userEvents, err := consumer.NewConsumer(
+		[]string{cfg.KafkaBroker},
+		cfg.KafkaGroupID,
+		userdata.NewProcessor(log, storage, kafkaProducer),
+		log,
+	)
+	if err != nil {
+		log.Error("failed to initialize user events consumer", sl.Err(err))
+		os.Exit(1)
+	}
+	defer userEvents.Close()
 
-	router.Use(middleware.RequestID)
-	router.Use(middleware.URLFormat)
-	router.Use(mwLogger.New(log))
+	consumerCtx, stopConsumer := context.WithCancel(ctx)
+	defer stopConsumer()
+	go userEvents.Run(consumerCtx, cfg.UserEventsTopic)
 
-	router.Group(func(r chi.Router) {
-		r.Use(mwAuth.AuthMiddleware(tokenMn, log))
-		r.Post("/review/create", create.Create(ctx, log, storage, kafkaProducer, client))
-		r.Put("/review/update", update.Update(log, storage, kafkaProducer))
-		r.Delete("/review/delete/{id}", del.Delete(log, storage, kafkaProducer))
+	// Сверка рейтингов менторов с отзывами, если счётчики разошлись из-за событий
+	go reconcile.New(log, storage, client).Run(consumerCtx, cfg.ReconcileInterval)
 
+	handler := router.New(log, tokenMn, authClient, router.Handlers{
+		Create: create.Create(ctx, log, storage, kafkaProducer, client),
+		Update: update.Update(log, storage, kafkaProducer),
+		Delete: del.Delete(log, storage, kafkaProducer),
+		Get:    get.Get(log, storage, redisRepository),
 	})
 
-	router.Get("/review/get", get.Get(log, storage, redisRepository))
-
 	log.Info("starting server", slog.String("adsress", cfg.Address))
 
 	done := make(chan os.Signal, 1)
@@ -113,7 +135,7 @@ func main() {
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
-		Handler:      router,
+		Handler:      handler,
 		ReadTimeout:  cfg.Timeout,
 		WriteTimeout: cfg.Timeout,
 		IdleTimeout:  cfg.IdleTimeout,
@@ -129,6 +151,8 @@ func main() {
 	<-done
 	log.Info("stopping server")
 
+	stopConsumer()
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -14,12 +14,18 @@ type Config struct {
 	cache.RedisConfig
 	KafkaBroker          string        `env:"KAFKA_BROKERS"`
 	KafkaTopic           string        `env:"KAFKA_TOPIC"`
+	UserEventsTopic      string        `env:"KAFKA_USER_EVENTS_TOPIC" env-default:"user-events"`
+	ExportPartsTopic     string        `env:"KAFKA_EXPORT_PARTS_TOPIC" env-default:"user-export-parts"`
+	KafkaGroupID         string        `env:"KAFKA_GROUP_ID" env-default:"review-service"`
 	Address              string        `env:"ADDRESS" env-required:"true"`
 	MentorServiceAddress string        `env:"MENTOR_SERVICE_ADDRESS" env-required:"true"`
+	AuthServiceAddress   string        `env:"AUTH_SERVICE_ADDRESS" env-required:"true"`
 	Timeout              time.Duration `env:"TIMEOUT" env-default:"4s"`
 	IdleTimeout          time.Duration `env:"IDLE_TIMEOUT" env-default:"60s"`
 	Env                  string        `env:"ENV" env-required:"true"`
-	PublicKeyPath        string        `env:"JWT_PUBLIC_KEY_PATH"`
+	JWKSURL              string        `env:"JWKS_URL" env-required:"true"`
+	JWKSRefreshInterval  time.Duration `env:"JWKS_REFRESH_INTERVAL" env-default:"5m"`
+	ReconcileInterval    time.Duration `env:"RATING_RECONCILE_INTERVAL" env-default:"1h"`
 }
 
 func LoadConfig() *Config {
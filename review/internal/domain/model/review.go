@@ -2,10 +2,13 @@ package model
 
 import "time"
 
+// Review ментор задаётся mentor_id; mentor_email принимается от клиентов,
+// которые ещё не перешли на id, и пока возвращается в ответах
 type Review struct {
 	ID          int64     `json:"id,omitempty" db:"id"`
 	UserID      int64     `db:"user_id"`
-	MentorEmail string    `json:"mentor_email" db:"mentor_email" validate:"required,email"`
+	MentorID    int64     `json:"mentor_id,omitempty" db:"mentor_id" validate:"gte=0"`
+	MentorEmail string    `json:"mentor_email" db:"mentor_email" validate:"required_without=MentorID,omitempty,email"`
 	Rating      float32   `json:"rating" db:"rating"`
 	Comment     string    `json:"comment" db:"comment"`
 	UserContact string    `json:"user_contact" db:"user_contact"`
@@ -13,8 +16,38 @@ type Review struct {
 }
 
 type ReviewEvent struct {
-	Action string  `json:"action"` // created/updated/deleted
-	ID     int64   `json:"id"`
-	Email  string  `json:"email"`
-	Score  float32 `json:"score"`
+	Action   string  `json:"action"` // created/updated/deleted
+	ID       int64   `json:"id"`
+	MentorID int64   `json:"mentor_id,omitempty"`
+	Email    string  `json:"email,omitempty"`
+	Score    float32 `json:"score"`
+}
+
+// NewReviewEvent email ментора попадает в событие, только если отзыв ещё не связан с его id
+func NewReviewEvent(action string, r *Review) *ReviewEvent {
+	event := &ReviewEvent{
+		Action:   action,
+		ID:       r.ID,
+		MentorID: r.MentorID,
+		Score:    r.Rating,
+	}
+	if r.MentorID == 0 {
+		event.Email = r.MentorEmail
+	}
+	return event
+}
+
+// Mentor ментор, найденный в сервисе менторов
+type Mentor struct {
+	ID    int64
+	Email string
+}
+
+// MentorRating рейтинг ментора по отзывам сервиса: Stars[i] - число отзывов на i+1 звёзд,
+// Recent - отзывы, оставленные за окно динамики
+type MentorRating struct {
+	MentorID  int64
+	Stars     [5]int
+	SumRating float64
+	Recent    []Review
 }
@@ -0,0 +1,19 @@
+package model
+
+// UserEvent событие сервиса авторизации о пользователе
+type UserEvent struct {
+	Type     string `json:"type"` // user.deleted/user.export_requested/user.email_changed/user.id_backfill
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	ExportID string `json:"export_id,omitempty"`
+	OldEmail string `json:"old_email,omitempty"` // только для user.email_changed
+}
+
+// ExportPart часть выгрузки данных пользователя, которую собирает сервис авторизации
+type ExportPart struct {
+	ExportID string `json:"export_id"`
+	UserID   int64  `json:"user_id"`
+	Service  string `json:"service"`
+	Data     any    `json:"data"`
+}
@@ -1,5 +1,7 @@
 package requests
 
-type EmailMentor struct {
-	Email string `json:"mentor_email" db:"email" validate:"required,email"`
+// Mentor ищет отзывы по mentor_id; mentor_email оставлен на время перехода клиентов на id
+type Mentor struct {
+	MentorID int64  `json:"mentor_id" db:"mentor_id" validate:"gte=0"`
+	Email    string `json:"mentor_email" db:"email" validate:"required_without=MentorID,omitempty,email"`
 }
@@ -3,6 +3,7 @@ package grpcclient
 import (
 	"context"
 	"fmt"
+	"review/internal/domain/model"
 	pb "review/pkg/api/proto"
 
 	"google.golang.org/grpc"
@@ -34,19 +35,63 @@ func (m *MentorClient) Close() error {
 	return m.conn.Close()
 }
 
-func (m *MentorClient) CheckMentor(ctx context.Context, mentorEmail string) (bool, error) {
+// CheckMentor ищет ментора по id или, для старых клиентов, по email.
+// Возвращает nil, если ментор не найден или не активен
+func (m *MentorClient) CheckMentor(ctx context.Context, mentorID int64, mentorEmail string) (*model.Mentor, error) {
 	req := &pb.CheckRequest{
+		MentorId:    mentorID,
 		MentorEmail: mentorEmail,
 	}
 
 	resp, err := m.client.CheckMentor(ctx, req)
 	if err != nil {
-		return false, fmt.Errorf("CheckMentor RPC call failed: %w", err)
+		return nil, fmt.Errorf("CheckMentor RPC call failed: %w", err)
 	}
 
 	if !resp.Success {
-		return false, fmt.Errorf("server responded with failure: %s", resp.Message)
+		return nil, fmt.Errorf("server responded with failure: %s", resp.Message)
 	}
 
-	return resp.Exists, nil
+	if !resp.Exists {
+		return nil, nil
+	}
+
+	mentor := &model.Mentor{ID: resp.MentorId, Email: resp.MentorEmail}
+	if mentor.ID == 0 {
+		mentor.ID = mentorID
+	}
+	if mentor.Email == "" {
+		mentor.Email = mentorEmail
+	}
+	return mentor, nil
+}
+
+// ReconcileRating отправляет сервису менторов рейтинг, пересчитанный по отзывам
+func (m *MentorClient) ReconcileRating(ctx context.Context, rating *model.MentorRating) error {
+	req := &pb.ReconcileRequest{
+		MentorId:  rating.MentorID,
+		Stars:     make([]int32, 0, len(rating.Stars)),
+		SumRating: rating.SumRating,
+		Recent:    make([]*pb.RatedReview, 0, len(rating.Recent)),
+	}
+	for _, n := range rating.Stars {
+		req.Stars = append(req.Stars, int32(n))
+	}
+	for _, r := range rating.Recent {
+		req.Recent = append(req.Recent, &pb.RatedReview{
+			ReviewId: r.ID,
+			Rating:   r.Rating,
+			RatedAt:  r.CreatedAt.Unix(),
+		})
+	}
+
+	resp, err := m.client.ReconcileRating(ctx, req)
+	if err != nil {
+		return fmt.Errorf("ReconcileRating RPC call failed: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("server responded with failure: %s", resp.Message)
+	}
+	return nil
 }
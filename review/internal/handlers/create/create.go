@@ -18,7 +18,7 @@ import (
 
 type ReviewCreater interface {
 	CreateReview(review *model.Review) (int64, error)
-	IfExist(userID int64, mentorEmail string) (bool, error)
+	IfExist(userID int64, mentor *model.Mentor) (bool, error)
 }
 
 type KafkaProducer interface {
@@ -26,7 +26,7 @@ type KafkaProducer interface {
 }
 
 type CheckMentor interface {
-	CheckMentor(ctx context.Context, mentorEmail string) (bool, error)
+	CheckMentor(ctx context.Context, mentorID int64, mentorEmail string) (*model.Mentor, error)
 }
 
 func Create(ctx context.Context, log *slog.Logger, reviewCreater ReviewCreater, kafkaProducer KafkaProducer, checkMentor CheckMentor) http.HandlerFunc {
@@ -57,7 +57,7 @@ func Create(ctx context.Context, log *slog.Logger, reviewCreater ReviewCreater,
 			return
 		}
 
-		existsMentor, err := checkMentor.CheckMentor(ctx, req.MentorEmail)
+		mentor, err := checkMentor.CheckMentor(ctx, req.MentorID, req.MentorEmail)
 		if err != nil {
 			log.Error("failed to check mentor in mentor-service", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -65,14 +65,18 @@ func Create(ctx context.Context, log *slog.Logger, reviewCreater ReviewCreater,
 			return
 		}
 
-		if !existsMentor {
-			log.Error("mentor doesn't exists", "mentor_email", req.MentorEmail)
+		if mentor == nil {
+			log.Error("mentor doesn't exists", slog.Int64("mentor_id", req.MentorID), "mentor_email", req.MentorEmail)
 			render.Status(r, http.StatusNotFound)
 			render.JSON(w, r, response.Error("mentor doesn't exists"))
 			return
 		}
 
-		existsReview, err := reviewCreater.IfExist(req.UserID, req.MentorEmail)
+		// Отзыв хранит id и актуальный email ментора, даже если клиент прислал только email
+		req.MentorID = mentor.ID
+		req.MentorEmail = mentor.Email
+
+		existsReview, err := reviewCreater.IfExist(req.UserID, mentor)
 		if err != nil {
 			log.Error("falied to find review", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -98,12 +102,8 @@ func Create(ctx context.Context, log *slog.Logger, reviewCreater ReviewCreater,
 			return
 		}
 
-		event := &model.ReviewEvent{
-			Action: "updated",
-			ID:     id,
-			Email:  req.MentorEmail,
-			Score:  req.Rating,
-		}
+		req.ID = id
+		event := model.NewReviewEvent("updated", &req)
 
 		if err := kafkaProducer.SendReviewEvent(event); err != nil {
 			log.Error("failed to send kafka even", sl.Err(err))
@@ -111,7 +111,8 @@ func Create(ctx context.Context, log *slog.Logger, reviewCreater ReviewCreater,
 
 		render.Status(r, http.StatusCreated)
 		render.JSON(w, r, map[string]any{
-			"id": id,
+			"id":        id,
+			"mentor_id": req.MentorID,
 		})
 	}
 }
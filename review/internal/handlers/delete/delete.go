@@ -17,6 +17,7 @@ import (
 
 type DelReview interface {
 	DeleteReview(userID, id int64) error
+	DeleteReviewByID(id int64) error
 	GetReviewByID(id int64) (*model.Review, error)
 }
 
@@ -56,25 +57,32 @@ func Delete(log *slog.Logger, delreview DelReview, kafkaProducer KafkaProducer)
 			return
 		}
 
-		if err := delreview.DeleteReview(claims.UserID, id); err != nil {
+		// Модератор удаляет любой отзыв, остальные только свой
+		if claims.HasScope(token.ScopeReviewModerate) {
+			err = delreview.DeleteReviewByID(id)
+		} else {
+			err = delreview.DeleteReview(claims.UserID, id)
+		}
+		if err != nil {
 			log.Error("failed to delete review", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, response.Error("server error"))
 			return
 		}
 
-		event := &model.ReviewEvent{
-			Action: "deleted",
-			ID:     id,
-			Email:  rev.MentorEmail,
-			Score:  rev.Rating,
-		}
+		event := model.NewReviewEvent("deleted", rev)
 
 		if err := kafkaProducer.SendReviewEvent(event); err != nil {
 			log.Error("failed to send kafka event", sl.Err(err))
 		}
 
 		render.Status(r, http.StatusOK)
+		log.Info("review deleted",
+			slog.Int64("id", id),
+			slog.Int64("user_id", claims.UserID),
+			slog.Bool("moderation", rev.UserID != claims.UserID),
+		)
+
 		render.JSON(w, r, map[string]any{
 			"status": "review deleted",
 		})
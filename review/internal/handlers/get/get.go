@@ -8,18 +8,27 @@ import (
 	requests "review/internal/domain/resuests"
 	"review/internal/lib/logger/sl"
 	"review/internal/lib/validate"
+	"strconv"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 )
 
 type GetReviews interface {
-	GetReviewsByMentorEmail(mentorEmail string) ([]model.Review, error)
+	GetReviewsByMentor(mentorID int64, mentorEmail string) ([]model.Review, error)
 }
 
 type RedisRepo interface {
-	GetReviews(email string) ([]model.Review, error, bool)
-	SaveReviews(email string, reviews []model.Review) error
+	GetReviews(key string) ([]model.Review, error, bool)
+	SaveReviews(key string, reviews []model.Review) error
+}
+
+// cacheKey отзывы, запрошенные по id и по email, кешируются раздельно
+func cacheKey(req *requests.Mentor) string {
+	if req.MentorID > 0 {
+		return "id:" + strconv.FormatInt(req.MentorID, 10)
+	}
+	return req.Email
 }
 
 func Get(log *slog.Logger, getReview GetReviews, redisRepo RedisRepo) http.HandlerFunc {
@@ -30,7 +39,7 @@ func Get(log *slog.Logger, getReview GetReviews, redisRepo RedisRepo) http.Handl
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req requests.EmailMentor
+		var req requests.Mentor
 
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
@@ -45,20 +54,21 @@ func Get(log *slog.Logger, getReview GetReviews, redisRepo RedisRepo) http.Handl
 			return
 		}
 
-		reviews, err, reviewsExists := redisRepo.GetReviews(req.Email)
+		key := cacheKey(&req)
+		reviews, err, reviewsExists := redisRepo.GetReviews(key)
 		if err != nil {
 			log.Error("failed to get reviews from redis", sl.Err(err))
 		}
 
 		if !reviewsExists {
-			reviews, err = getReview.GetReviewsByMentorEmail(req.Email)
+			reviews, err = getReview.GetReviewsByMentor(req.MentorID, req.Email)
 			if err != nil {
 				log.Error("falied to get reviews", sl.Err(err))
 				render.Status(r, http.StatusInternalServerError)
 				render.JSON(w, r, response.Error("server error"))
 				return
 			}
-			err := redisRepo.SaveReviews(req.Email, reviews)
+			err := redisRepo.SaveReviews(key, reviews)
 			if err != nil {
 				log.Error("falied to save reviews from redis", sl.Err(err))
 			}
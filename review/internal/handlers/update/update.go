@@ -63,12 +63,7 @@ func Update(log *slog.Logger, reviewUpdate ReviewUpdate, kafkaProducer KafkaProd
 			return
 		}
 
-		event := &model.ReviewEvent{
-			Action: "deleted",
-			ID:     rev.ID,
-			Email:  rev.MentorEmail,
-			Score:  rev.Rating,
-		}
+		event := model.NewReviewEvent("deleted", rev)
 
 		if err := kafkaProducer.SendReviewEvent(event); err != nil {
 			log.Error("failed to send kafka event", sl.Err(err))
@@ -81,12 +76,10 @@ func Update(log *slog.Logger, reviewUpdate ReviewUpdate, kafkaProducer KafkaProd
 			return
 		}
 
-		event = &model.ReviewEvent{
-			Action: "updated",
-			ID:     req.ID,
-			Email:  req.MentorEmail,
-			Score:  req.Rating,
-		}
+		// Ментор отзыва не меняется, событие уходит по сохранённому ментору
+		req.MentorID = rev.MentorID
+		req.MentorEmail = rev.MentorEmail
+		event = model.NewReviewEvent("updated", &req)
 
 		if err := kafkaProducer.SendReviewEvent(event); err != nil {
 			log.Error("failed to send kafka event", sl.Err(err))
@@ -0,0 +1,104 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"review/internal/domain/model"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+const retryDelay = 5 * time.Second
+
+type Processor interface {
+	Handle(ctx context.Context, event *model.UserEvent) error
+}
+
+// Consumer читает события сервиса авторизации о пользователях
+type Consumer struct {
+	consumer sarama.ConsumerGroup
+	handler  *consumerHandler
+	log      *slog.Logger
+}
+
+type consumerHandler struct {
+	processor Processor
+	log       *slog.Logger
+}
+
+func NewConsumer(brokers []string, groupID string, processor Processor, logger *slog.Logger) (*Consumer, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_8_0_0
+	// Удаление аккаунта нельзя пропустить, даже если сервис лежал в момент события
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return &Consumer{
+		consumer: consumerGroup,
+		handler:  &consumerHandler{processor: processor, log: logger},
+		log:      logger,
+	}, nil
+}
+
+func (h *consumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var event model.UserEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			h.log.Error("falied to unmarshal json", "error", err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		// Пропускать событие нельзя, повторяем до успеха: следующие события
+		// того же пользователя не должны его обогнать
+		for {
+			err := h.processor.Handle(session.Context(), &event)
+			if err == nil {
+				break
+			}
+			h.log.Error("processor error", "error", err, "type", event.Type, "user_id", event.UserID)
+
+			select {
+			case <-session.Context().Done():
+				return nil
+			case <-time.After(retryDelay):
+			}
+		}
+
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+// Run читает топик до отмены ctx
+func (c *Consumer) Run(ctx context.Context, topic string) {
+	for {
+		if err := c.consumer.Consume(ctx, []string{topic}, c.handler); err != nil {
+			c.log.Error("failed to consume messages", "error", err)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (c *Consumer) Close() error {
+	return c.consumer.Close()
+}
@@ -10,12 +10,13 @@ import (
 )
 
 type Producer struct {
-	producer sarama.SyncProducer
-	topic    string
-	logger   *slog.Logger
+	producer    sarama.SyncProducer
+	topic       string
+	exportTopic string
+	logger      *slog.Logger
 }
 
-func NewProducer(brokers []string, topic string, logger *slog.Logger) (*Producer, error) {
+func NewProducer(brokers []string, topic, exportTopic string, logger *slog.Logger) (*Producer, error) {
 	config := sarama.NewConfig()
 
 	// Гарантия доставки
@@ -31,9 +32,10 @@ func NewProducer(brokers []string, topic string, logger *slog.Logger) (*Producer
 	}
 
 	return &Producer{
-		producer: producer,
-		topic:    topic,
-		logger:   logger,
+		producer:    producer,
+		topic:       topic,
+		exportTopic: exportTopic,
+		logger:      logger,
 	}, nil
 }
 
@@ -60,6 +62,25 @@ func (p *Producer) SendReviewEvent(review *model.ReviewEvent) error {
 	return nil
 }
 
+// SendExportPart отправляет сервису авторизации часть выгрузки данных пользователя
+func (p *Producer) SendExportPart(part *model.ExportPart) error {
+	jsonData, err := json.Marshal(part)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: p.exportTopic,
+		Key:   sarama.StringEncoder(part.ExportID),
+		Value: sarama.ByteEncoder(jsonData),
+	}
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
 func (p *Producer) Close() error {
 	return p.producer.Close()
 }
@@ -15,7 +15,17 @@ type contextKey string
 
 const UserKey contextKey = "user"
 
-func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Handler) http.Handler {
+const apiKeyPrefix = "ApiKey "
+
+// AuthClient спрашивает сервис авторизации о персональных API ключах и отозванных сессиях
+type AuthClient interface {
+	IntrospectAPIKey(ctx context.Context, key string) (*token.Claims, error)
+	IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+func AuthMiddleware(tokenMn *token.TokenManager, auth AuthClient, log *slog.Logger) func(http.Handler) http.Handler {
+	sessions := newSessionCache(auth, sessionCacheTTL)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -27,6 +37,26 @@ func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Han
 				return
 			}
 
+			if key, ok := strings.CutPrefix(authHeader, apiKeyPrefix); ok {
+				claims, err := auth.IntrospectAPIKey(r.Context(), key)
+				if errors.Is(err, token.ErrAPIKeyInactive) {
+					log.Warn("Invalid api key")
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "invalid api key"})
+					return
+				}
+				if err != nil {
+					log.Error("Api key introspection failed", "error", err)
+					render.Status(r, http.StatusServiceUnavailable)
+					render.JSON(w, r, map[string]string{"error": "auth service unavailable"})
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), UserKey, claims)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 
 			claims, err := tokenMn.ParseToken(tokenStr)
@@ -44,13 +74,38 @@ func AuthMiddleware(tokenMn *token.TokenManager, log *slog.Logger) func(http.Han
 
 			}
 
-			if claims.TokenType != "access" {
+			// mfa_pending токен подтверждает только пароль, к API он доступа не даёт
+			if claims.TokenType == token.TypeMFAPending {
+				log.Warn("MFA is not completed", "user_id", claims.UserID)
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]string{"error": "mfa required"})
+				return
+			}
+
+			if claims.TokenType != token.TypeAccess {
 				log.Warn("Invalid token type", "type", claims.TokenType)
 				render.Status(r, http.StatusUnauthorized)
 				render.JSON(w, r, map[string]string{"error": "invalid token type"})
 				return
 			}
 
+			// Подпись не знает о выходе пользователя: токен с сессией живёт, пока жива сессия
+			if claims.FamilyID != "" {
+				active, err := sessions.IsSessionActive(r.Context(), claims.UserID, claims.FamilyID)
+				if err != nil {
+					log.Error("Session check failed", "error", err)
+					render.Status(r, http.StatusServiceUnavailable)
+					render.JSON(w, r, map[string]string{"error": "auth service unavailable"})
+					return
+				}
+				if !active {
+					log.Warn("Session revoked", "user_id", claims.UserID)
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, map[string]string{"error": "session revoked"})
+					return
+				}
+			}
+
 			ctx := context.WithValue(r.Context(), UserKey, claims)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,55 @@
+package mwAuth
+
+import (
+	"context"
+	"review/pkg/token"
+	"testing"
+	"time"
+)
+
+type countingAuth struct {
+	active bool
+	calls  int
+}
+
+func (a *countingAuth) IntrospectAPIKey(context.Context, string) (*token.Claims, error) {
+	return nil, token.ErrAPIKeyInactive
+}
+
+func (a *countingAuth) IsSessionActive(context.Context, int64, string) (bool, error) {
+	a.calls++
+	return a.active, nil
+}
+
+func TestSessionCache(t *testing.T) {
+	auth := &countingAuth{active: true}
+	cache := newSessionCache(auth, time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+
+	check := func(want bool) {
+		t.Helper()
+		active, err := cache.IsSessionActive(context.Background(), 1, "family_1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active != want {
+			t.Fatalf("expected active=%v, got %v", want, active)
+		}
+	}
+
+	check(true)
+	check(true)
+	if auth.calls != 1 {
+		t.Fatalf("active session should be cached, got %d calls", auth.calls)
+	}
+
+	// После отзыва кеш отвечает старым значением не дольше ttl
+	auth.active = false
+	now = now.Add(time.Minute)
+	check(false)
+	check(false)
+	if auth.calls != 3 {
+		t.Fatalf("revoked session should not be cached, got %d calls", auth.calls)
+	}
+}
@@ -0,0 +1,78 @@
+// Package reconcile сверяет рейтинги менторов с отзывами: счётчики в сервисе менторов
+// обновляются событиями и расходятся, если событие потеряно или доставлено дважды.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"review/internal/domain/model"
+	"time"
+)
+
+// TrendWindow окно динамики оценок; совпадает с самым длинным окном сервиса менторов
+const TrendWindow = 90 * 24 * time.Hour
+
+type Storage interface {
+	MentorRatings(ctx context.Context, since time.Time) ([]model.MentorRating, error)
+}
+
+type MentorClient interface {
+	ReconcileRating(ctx context.Context, rating *model.MentorRating) error
+}
+
+type Reconciler struct {
+	log     *slog.Logger
+	storage Storage
+	mentors MentorClient
+	now     func() time.Time
+}
+
+func New(log *slog.Logger, storage Storage, mentors MentorClient) *Reconciler {
+	return &Reconciler{log: log, storage: storage, mentors: mentors, now: time.Now}
+}
+
+// Run сверяет рейтинги каждые interval, пока не отменён ctx
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ReconcileOnce(ctx); err != nil {
+				r.log.Error("rating reconciliation failed", "error", err)
+			}
+		}
+	}
+}
+
+// ReconcileOnce отправляет сервису менторов рейтинги всех менторов, связанных с id.
+// Ошибка по одному ментору не останавливает сверку остальных; возвращает число отправленных.
+// Событие, которое ещё в пути, после сверки будет учтено повторно; расхождение исправит
+// следующий проход
+func (r *Reconciler) ReconcileOnce(ctx context.Context) (int, error) {
+	const op = "reconcile.ReconcileOnce"
+
+	ratings, err := r.storage.MentorRatings(ctx, r.now().Add(-TrendWindow))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sent, failed := 0, 0
+	for i := range ratings {
+		if err := r.mentors.ReconcileRating(ctx, &ratings[i]); err != nil {
+			failed++
+			r.log.Warn("failed to reconcile mentor rating", "mentor_id", ratings[i].MentorID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	r.log.Info("mentor ratings reconciled", slog.Int("sent", sent), slog.Int("failed", failed))
+	if failed > 0 {
+		return sent, fmt.Errorf("%s: %d of %d mentors failed", op, failed, len(ratings))
+	}
+	return sent, nil
+}
@@ -0,0 +1,82 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"review/internal/domain/model"
+	"review/internal/lib/logger/slogdiscard"
+	"testing"
+	"time"
+)
+
+type fakeStorage struct {
+	ratings []model.MentorRating
+	since   time.Time
+	err     error
+}
+
+func (s *fakeStorage) MentorRatings(_ context.Context, since time.Time) ([]model.MentorRating, error) {
+	s.since = since
+	return s.ratings, s.err
+}
+
+type fakeClient struct {
+	sent   []int64
+	failOn int64
+}
+
+func (c *fakeClient) ReconcileRating(_ context.Context, rating *model.MentorRating) error {
+	if rating.MentorID == c.failOn {
+		return errors.New("unavailable")
+	}
+	c.sent = append(c.sent, rating.MentorID)
+	return nil
+}
+
+func TestReconcileOnceSendsEveryMentor(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	storage := &fakeStorage{ratings: []model.MentorRating{
+		{MentorID: 1, Stars: [5]int{0, 0, 1, 0, 2}, SumRating: 13},
+		{MentorID: 2, Stars: [5]int{1, 0, 0, 0, 0}, SumRating: 1},
+	}}
+	client := &fakeClient{}
+	r := New(slogdiscard.NewDiscardLogger(), storage, client)
+	r.now = func() time.Time { return now }
+
+	sent, err := r.ReconcileOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 2 || len(client.sent) != 2 {
+		t.Fatalf("expected 2 mentors reconciled, got %d (%v)", sent, client.sent)
+	}
+	if want := now.Add(-TrendWindow); !storage.since.Equal(want) {
+		t.Fatalf("expected recent reviews since %v, got %v", want, storage.since)
+	}
+}
+
+func TestReconcileOnceContinuesAfterFailure(t *testing.T) {
+	storage := &fakeStorage{ratings: []model.MentorRating{{MentorID: 1}, {MentorID: 2}, {MentorID: 3}}}
+	client := &fakeClient{failOn: 2}
+	r := New(slogdiscard.NewDiscardLogger(), storage, client)
+
+	sent, err := r.ReconcileOnce(context.Background())
+	if err == nil {
+		t.Fatal("expected error when a mentor failed")
+	}
+	if sent != 2 || len(client.sent) != 2 || client.sent[1] != 3 {
+		t.Fatalf("expected mentors 1 and 3 reconciled, got %v", client.sent)
+	}
+}
+
+func TestReconcileOnceStorageError(t *testing.T) {
+	client := &fakeClient{}
+	r := New(slogdiscard.NewDiscardLogger(), &fakeStorage{err: errors.New("db down")}, client)
+
+	if _, err := r.ReconcileOnce(context.Background()); err == nil {
+		t.Fatal("expected storage error")
+	}
+	if len(client.sent) != 0 {
+		t.Fatalf("expected nothing sent, got %v", client.sent)
+	}
+}
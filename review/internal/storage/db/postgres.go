@@ -1,10 +1,12 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"review/internal/domain/model"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -34,11 +36,21 @@ func NewStorage(cfg Config) (*Storage, error) {
 	return &Storage{db: db}, nil
 }
 
-func (s *Storage) IfExist(userID int64, mentorEmail string) (bool, error) {
+// mentorKey колонка и значение для поиска отзывов о менторе: по id, а для клиентов,
+// которые ещё передают только email, по email
+func mentorKey(mentorID int64, mentorEmail string) (string, any) {
+	if mentorID > 0 {
+		return "mentor_id", mentorID
+	}
+	return "mentor_email", mentorEmail
+}
+
+// IfExist старые отзывы могут быть ещё не связаны с id ментора, поэтому проверяются оба ключа
+func (s *Storage) IfExist(userID int64, mentor *model.Mentor) (bool, error) {
 	const op = "storage.db.ifExist"
-	query := `SELECT id FROM reviews WHERE user_id=$1 and mentor_email=$2`
+	query := `SELECT id FROM reviews WHERE user_id=$1 and (mentor_id=$2 or mentor_email=$3) LIMIT 1`
 	var id int64
-	err := s.db.Get(&id, query, userID, mentorEmail)
+	err := s.db.Get(&id, query, userID, mentor.ID, mentor.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
@@ -51,12 +63,20 @@ func (s *Storage) IfExist(userID int64, mentorEmail string) (bool, error) {
 
 func (s *Storage) CreateReview(review *model.Review) (int64, error) {
 	const op = "storage.db.CreateReview"
-	query := `INSERT INTO reviews (user_id, mentor_email, rating, comment, user_contact, created_at)
-			  VALUES ($1, $2, $3, $4, $5, $6)
-			  RETURNING id;`
+	// Ментор попадает в список сверки вместе с первым отзывом
+	query := `WITH review AS (
+			      INSERT INTO reviews (user_id, mentor_id, mentor_email, rating, comment, user_contact, created_at)
+			      VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7)
+			      RETURNING id, mentor_id
+			  ), mentor AS (
+			      INSERT INTO reviewed_mentors (mentor_id)
+			      SELECT mentor_id FROM review WHERE mentor_id IS NOT NULL
+			      ON CONFLICT DO NOTHING
+			  )
+			  SELECT id FROM review;`
 
 	var newID int64
-	err := s.db.QueryRow(query, review.UserID, review.MentorEmail, review.Rating, review.Comment, review.UserContact, review.CreatedAt).Scan(&newID)
+	err := s.db.QueryRow(query, review.UserID, review.MentorID, review.MentorEmail, review.Rating, review.Comment, review.UserContact, review.CreatedAt).Scan(&newID)
 	if err != nil {
 		return -1, fmt.Errorf("%s, %w", op, err)
 	}
@@ -64,12 +84,13 @@ func (s *Storage) CreateReview(review *model.Review) (int64, error) {
 	return newID, nil
 }
 
+// UpdateReview ментор отзыва не меняется: он проверен в сервисе менторов при создании
 func (s *Storage) UpdateReview(review *model.Review) error {
 	const op = "storage.db.UpdateReview"
 	query := `UPDATE reviews
-			  SET mentor_email=$1, rating=$2, comment=$3, user_contact=$4
-			  WHERE id=$5 and user_id=$6;`
-	result, err := s.db.Exec(query, review.MentorEmail, review.Rating, review.Comment, review.UserContact, review.ID, review.UserID)
+			  SET rating=$1, comment=$2, user_contact=$3
+			  WHERE id=$4 and user_id=$5;`
+	result, err := s.db.Exec(query, review.Rating, review.Comment, review.UserContact, review.ID, review.UserID)
 	if err != nil {
 		return fmt.Errorf("%s, %w", op, err)
 	}
@@ -85,14 +106,15 @@ func (s *Storage) UpdateReview(review *model.Review) error {
 	return nil
 }
 
-func (s *Storage) GetReviewsByMentorEmail(mentorEmail string) ([]model.Review, error) {
-	const op = "storage.db.GetReviewsBeMentorEmail"
-	query := `SELECT id, mentor_email, rating, comment, user_contact, created_at
+func (s *Storage) GetReviewsByMentor(mentorID int64, mentorEmail string) ([]model.Review, error) {
+	const op = "storage.db.GetReviewsByMentor"
+	column, key := mentorKey(mentorID, mentorEmail)
+	query := `SELECT id, COALESCE(mentor_id, 0) AS mentor_id, mentor_email, rating, comment, user_contact, created_at
 			  FROM reviews 
-			  WHERE mentor_email=$1 
+			  WHERE ` + column + `=$1 
 			  ORDER BY created_at DESC;`
 	var reviews []model.Review
-	err := s.db.Select(&reviews, query, mentorEmail)
+	err := s.db.Select(&reviews, query, key)
 	if err != nil {
 		return []model.Review{}, fmt.Errorf("%s, %w", op, err)
 	}
@@ -115,9 +137,24 @@ func (s *Storage) DeleteReview(userID, id int64) error {
 	return nil
 }
 
+// DeleteReviewByID удаляет отзыв независимо от автора, используется модерацией
+func (s *Storage) DeleteReviewByID(id int64) error {
+	const op = "storage.db.DeleteReviewByID"
+	query := `DELETE FROM reviews WHERE id=$1;`
+	result, err := s.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("%s, %w", op, err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: review with %d id not found", op, id)
+	}
+	return nil
+}
+
 func (s *Storage) GetReviewByID(id int64) (*model.Review, error) {
 	const op = "storage.db.GetReviewByID"
-	query := `SELECT id, mentor_email, rating, comment, user_contact, created_at
+	query := `SELECT id, user_id, COALESCE(mentor_id, 0) AS mentor_id, mentor_email, rating, comment, user_contact, created_at
 			  FROM reviews 
 			  WHERE id=$1`
 
@@ -128,3 +165,151 @@ func (s *Storage) GetReviewByID(id int64) (*model.Review, error) {
 	}
 	return &review, nil
 }
+
+// GetReviewsByUser отзывы, оставленные пользователем, для выгрузки его данных
+func (s *Storage) GetReviewsByUser(ctx context.Context, userID int64) ([]model.Review, error) {
+	const op = "storage.db.GetReviewsByUser"
+	query := `SELECT id, user_id, COALESCE(mentor_id, 0) AS mentor_id, mentor_email, rating, comment, user_contact, created_at
+			  FROM reviews
+			  WHERE user_id=$1
+			  ORDER BY created_at`
+	reviews := []model.Review{}
+	if err := s.db.SelectContext(ctx, &reviews, query, userID); err != nil {
+		return nil, fmt.Errorf("%s, %w", op, err)
+	}
+	return reviews, nil
+}
+
+// DeleteReviewsByUser удаляет отзывы пользователя и вызывает beforeCommit с удалёнными
+// отзывами до фиксации транзакции. Если beforeCommit вернул ошибку, отзывы остаются,
+// поэтому события для пересчёта рейтинга не теряются
+func (s *Storage) DeleteReviewsByUser(ctx context.Context, userID int64, beforeCommit func([]model.Review) error) error {
+	const op = "storage.db.DeleteReviewsByUser"
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s, %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM reviews
+			  WHERE user_id=$1
+			  RETURNING id, user_id, COALESCE(mentor_id, 0) AS mentor_id, mentor_email, rating, comment, user_contact, created_at`
+	var deleted []model.Review
+	if err := tx.SelectContext(ctx, &deleted, query, userID); err != nil {
+		return fmt.Errorf("%s, %w", op, err)
+	}
+
+	if err := beforeCommit(deleted); err != nil {
+		return fmt.Errorf("%s, %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s, %w", op, err)
+	}
+	return nil
+}
+
+// DeleteReviewsAboutMentor удаляет отзывы об удалённом менторе; рейтинг не пересчитывается,
+// записи ментора больше нет
+func (s *Storage) DeleteReviewsAboutMentor(ctx context.Context, mentorID int64, mentorEmail string) (int64, error) {
+	const op = "storage.db.DeleteReviewsAboutMentor"
+	query := `WITH mentor AS (
+			      DELETE FROM reviewed_mentors WHERE mentor_id=$1
+			  )
+			  DELETE FROM reviews WHERE mentor_id=$1 OR mentor_email=$2`
+	result, err := s.db.ExecContext(ctx, query, mentorID, mentorEmail)
+	if err != nil {
+		return 0, fmt.Errorf("%s, %w", op, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+// RekeyMentorEmail обновляет email ментора в его отзывах, пока по email ещё ищут
+func (s *Storage) RekeyMentorEmail(ctx context.Context, mentorID int64, oldEmail, newEmail string) (int64, error) {
+	const op = "storage.db.RekeyMentorEmail"
+	result, err := s.db.ExecContext(ctx, `UPDATE reviews SET mentor_email=$1 WHERE mentor_id=$2 OR mentor_email=$3`, newEmail, mentorID, oldEmail)
+	if err != nil {
+		return 0, fmt.Errorf("%s, %w", op, err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+// LinkMentorID связывает отзывы, оставленные по email, с id ментора
+func (s *Storage) LinkMentorID(ctx context.Context, mentorID int64, mentorEmail string) (int64, error) {
+	const op = "storage.db.LinkMentorID"
+	query := `WITH linked AS (
+			      UPDATE reviews SET mentor_id=$1 WHERE mentor_email=$2 AND mentor_id IS NULL
+			      RETURNING id
+			  ), mentor AS (
+			      INSERT INTO reviewed_mentors (mentor_id)
+			      SELECT $1::bigint WHERE EXISTS (SELECT 1 FROM linked)
+			      ON CONFLICT DO NOTHING
+			  )
+			  SELECT COUNT(*) FROM linked`
+	var rows int64
+	if err := s.db.GetContext(ctx, &rows, query, mentorID, mentorEmail); err != nil {
+		return 0, fmt.Errorf("%s, %w", op, err)
+	}
+	return rows, nil
+}
+
+// MentorRatings рейтинги менторов, у которых были отзывы по id, и их отзывы, оставленные после since.
+// Звезда - оценка, округлённая до целого в пределах 1..5, как в сервисе менторов
+func (s *Storage) MentorRatings(ctx context.Context, since time.Time) ([]model.MentorRating, error) {
+	const op = "storage.db.MentorRatings"
+	// Ментор, у которого удалили все отзывы, приходит с нулями, иначе его счётчики
+	// в сервисе менторов так и останутся прежними
+	query := `SELECT rm.mentor_id,
+			  COUNT(*) FILTER (WHERE r.star = 1) AS stars_1,
+			  COUNT(*) FILTER (WHERE r.star = 2) AS stars_2,
+			  COUNT(*) FILTER (WHERE r.star = 3) AS stars_3,
+			  COUNT(*) FILTER (WHERE r.star = 4) AS stars_4,
+			  COUNT(*) FILTER (WHERE r.star = 5) AS stars_5,
+			  COALESCE(SUM(r.rating), 0) AS sum_rating
+			  FROM reviewed_mentors rm
+			  LEFT JOIN (SELECT mentor_id, rating, LEAST(GREATEST(ROUND(rating), 1), 5) AS star
+			             FROM reviews
+			             WHERE mentor_id IS NOT NULL) r ON r.mentor_id = rm.mentor_id
+			  GROUP BY rm.mentor_id
+			  ORDER BY rm.mentor_id`
+
+	var rows []struct {
+		MentorID  int64   `db:"mentor_id"`
+		Stars1    int     `db:"stars_1"`
+		Stars2    int     `db:"stars_2"`
+		Stars3    int     `db:"stars_3"`
+		Stars4    int     `db:"stars_4"`
+		Stars5    int     `db:"stars_5"`
+		SumRating float64 `db:"sum_rating"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("%s, %w", op, err)
+	}
+
+	query = `SELECT id, user_id, mentor_id, mentor_email, rating, comment, user_contact, created_at
+			 FROM reviews
+			 WHERE mentor_id IS NOT NULL AND created_at > $1`
+	var recent []model.Review
+	if err := s.db.SelectContext(ctx, &recent, query, since); err != nil {
+		return nil, fmt.Errorf("%s, %w", op, err)
+	}
+
+	byMentor := make(map[int64][]model.Review)
+	for _, r := range recent {
+		byMentor[r.MentorID] = append(byMentor[r.MentorID], r)
+	}
+
+	ratings := make([]model.MentorRating, 0, len(rows))
+	for _, r := range rows {
+		ratings = append(ratings, model.MentorRating{
+			MentorID:  r.MentorID,
+			Stars:     [5]int{r.Stars1, r.Stars2, r.Stars3, r.Stars4, r.Stars5},
+			SumRating: r.SumRating,
+			Recent:    byMentor[r.MentorID],
+		})
+	}
+	return ratings, nil
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+	mwAuth "review/internal/middleware/auth"
+	mwLogger "review/internal/middleware/logger"
+	"review/pkg/token"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+// Handlers обработчики сервиса, собранные в main
+type Handlers struct {
+	Create http.HandlerFunc
+	Update http.HandlerFunc
+	Delete http.HandlerFunc
+	Get    http.HandlerFunc
+}
+
+// Route защищённый маршрут и право, без которого он недоступен
+type Route struct {
+	Method  string
+	Pattern string
+	Scope   string
+	Handler http.HandlerFunc
+}
+
+// Policy таблица защищённых маршрутов; маршрут без scope сюда не попадает
+func Policy(h Handlers) []Route {
+	return []Route{
+		{Method: http.MethodPost, Pattern: "/review/create", Scope: token.ScopeReviewWrite, Handler: h.Create},
+		{Method: http.MethodPut, Pattern: "/review/update", Scope: token.ScopeReviewWrite, Handler: h.Update},
+		// Чужой отзыв удаляется только с review:moderate, это проверяет сам обработчик
+		{Method: http.MethodDelete, Pattern: "/review/delete/{id}", Scope: token.ScopeReviewWrite, Handler: h.Delete},
+	}
+}
+
+func New(log *slog.Logger, tokenMn *token.TokenManager, auth mwAuth.AuthClient, h Handlers) http.Handler {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(middleware.URLFormat)
+	router.Use(mwLogger.New(log))
+
+	router.Group(func(r chi.Router) {
+		r.Use(mwAuth.AuthMiddleware(tokenMn, auth, log))
+		for _, route := range Policy(h) {
+			r.With(mwAuth.RequireScope(log, route.Scope)).Method(route.Method, route.Pattern, route.Handler)
+		}
+	})
+
+	router.Get("/review/get", h.Get)
+
+	return router
+}
@@ -0,0 +1,215 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"review/internal/lib/logger/slogdiscard"
+	"review/pkg/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type staticKeys struct {
+	key *rsa.PublicKey
+}
+
+func (k staticKeys) Key(string) (*rsa.PublicKey, error) {
+	return k.key, nil
+}
+
+// fakeKeys отвечает на интроспекцию по строке ключа, как сервис авторизации
+type fakeKeys map[string]*token.Claims
+
+func (k fakeKeys) IntrospectAPIKey(_ context.Context, key string) (*token.Claims, error) {
+	if key == "unavailable" {
+		return nil, errors.New("connection refused")
+	}
+	claims, ok := k[key]
+	if !ok {
+		return nil, token.ErrAPIKeyInactive
+	}
+	return claims, nil
+}
+
+// IsSessionActive: сессия "revoked" отозвана, на "unavailable" сервис авторизации не отвечает
+func (k fakeKeys) IsSessionActive(_ context.Context, _ int64, sessionID string) (bool, error) {
+	switch sessionID {
+	case "unavailable":
+		return false, errors.New("connection refused")
+	case "revoked":
+		return false, nil
+	}
+	return true, nil
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, tokenType, scope string) string {
+	t.Helper()
+	return signSessionToken(t, key, tokenType, scope, "")
+}
+
+func signSessionToken(t *testing.T, key *rsa.PrivateKey, tokenType, scope, sessionID string) string {
+	t.Helper()
+	claims := &token.Claims{
+		UserID:    1,
+		Role:      "user",
+		TokenType: tokenType,
+		FamilyID:  sessionID,
+		Scope:     scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func stub(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestPolicyCoversEveryProtectedRoute(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slogdiscard.NewDiscardLogger()
+	h := Handlers{Create: stub, Update: stub, Delete: stub, Get: stub}
+	srv := New(log, token.NewTokenManager(staticKeys{key: &key.PublicKey}), fakeKeys{}, h)
+
+	otherScope := map[string]string{
+		token.ScopeReviewWrite:    token.ScopeReviewModerate,
+		token.ScopeReviewModerate: token.ScopeReviewWrite,
+	}
+
+	for _, route := range Policy(h) {
+		path := strings.ReplaceAll(route.Pattern, "{id}", "1")
+
+		cases := []struct {
+			name   string
+			auth   string
+			status int
+		}{
+			{name: "no token", status: http.StatusUnauthorized},
+			{name: "refresh token", auth: signToken(t, key, token.TypeRefresh, route.Scope), status: http.StatusUnauthorized},
+			{name: "no scope", auth: signToken(t, key, token.TypeAccess, ""), status: http.StatusForbidden},
+			{name: "other scope", auth: signToken(t, key, token.TypeAccess, otherScope[route.Scope]), status: http.StatusForbidden},
+			{name: "required scope", auth: signToken(t, key, token.TypeAccess, route.Scope), status: http.StatusOK},
+			{name: "required among others", auth: signToken(t, key, token.TypeAccess, "mentor:edit_self "+route.Scope), status: http.StatusOK},
+		}
+
+		for _, tc := range cases {
+			t.Run(route.Method+" "+route.Pattern+" "+tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(route.Method, path, nil)
+				if tc.auth != "" {
+					req.Header.Set("Authorization", "Bearer "+tc.auth)
+				}
+				rr := httptest.NewRecorder()
+				srv.ServeHTTP(rr, req)
+
+				if rr.Code != tc.status {
+					t.Fatalf("expected %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestPolicyRoutesHaveScope(t *testing.T) {
+	for _, route := range Policy(Handlers{}) {
+		if route.Scope == "" {
+			t.Errorf("%s %s has no scope", route.Method, route.Pattern)
+		}
+	}
+}
+
+func TestPublicRouteNeedsNoToken(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+	srv := New(log, token.NewTokenManager(staticKeys{}), fakeKeys{}, Handlers{Get: stub})
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/review/get", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	log := slogdiscard.NewDiscardLogger()
+	h := Handlers{Create: stub, Update: stub, Delete: stub, Get: stub}
+	keys := fakeKeys{
+		"ml_write":    token.APIKeyClaims(1, "user", token.ScopeReviewWrite, 0),
+		"ml_readonly": token.APIKeyClaims(1, "user", "", 0),
+	}
+	srv := New(log, token.NewTokenManager(staticKeys{}), keys, h)
+
+	cases := []struct {
+		name   string
+		auth   string
+		status int
+	}{
+		{name: "active key with scope", auth: "ApiKey ml_write", status: http.StatusOK},
+		{name: "active key without scope", auth: "ApiKey ml_readonly", status: http.StatusForbidden},
+		{name: "inactive key", auth: "ApiKey ml_revoked", status: http.StatusUnauthorized},
+		{name: "auth service down", auth: "ApiKey unavailable", status: http.StatusServiceUnavailable},
+		// Ключ в Bearer не принимается: это не JWT
+		{name: "key as bearer", auth: "Bearer ml_write", status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/review/create", nil)
+			req.Header.Set("Authorization", tc.auth)
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if rr.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRevokedSession(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slogdiscard.NewDiscardLogger()
+	h := Handlers{Create: stub, Update: stub, Delete: stub, Get: stub}
+	srv := New(log, token.NewTokenManager(staticKeys{key: &key.PublicKey}), fakeKeys{}, h)
+
+	cases := []struct {
+		name    string
+		session string
+		status  int
+	}{
+		{name: "active session", session: "family_1", status: http.StatusOK},
+		{name: "revoked session", session: "revoked", status: http.StatusUnauthorized},
+		{name: "auth service down", session: "unavailable", status: http.StatusServiceUnavailable},
+		// Токены без семейства (сервисные) сессией не ограничены
+		{name: "no session", status: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/review/create", nil)
+			req.Header.Set("Authorization", "Bearer "+signSessionToken(t, key, token.TypeAccess, token.ScopeReviewWrite, tc.session))
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if rr.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
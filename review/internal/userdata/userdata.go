@@ -0,0 +1,188 @@
+// Package userdata обрабатывает события сервиса авторизации о пользователях:
+// удаляет отзывы удалённого пользователя, отдаёт их в выгрузку данных,
+// переносит отзывы о менторе на новый email и связывает старые отзывы с id ментора.
+package userdata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"review/internal/domain/model"
+	"time"
+)
+
+const (
+	EventUserDeleted     = "user.deleted"
+	EventExportRequested = "user.export_requested"
+	EventEmailChanged    = "user.email_changed"
+	EventIDBackfill      = "user.id_backfill"
+
+	// ServiceName имя части в архиве выгрузки
+	ServiceName = "review"
+)
+
+type Storage interface {
+	GetReviewsByUser(ctx context.Context, userID int64) ([]model.Review, error)
+	DeleteReviewsByUser(ctx context.Context, userID int64, beforeCommit func([]model.Review) error) error
+	DeleteReviewsAboutMentor(ctx context.Context, mentorID int64, mentorEmail string) (int64, error)
+	RekeyMentorEmail(ctx context.Context, mentorID int64, oldEmail, newEmail string) (int64, error)
+	LinkMentorID(ctx context.Context, mentorID int64, mentorEmail string) (int64, error)
+}
+
+type Producer interface {
+	SendReviewEvent(review *model.ReviewEvent) error
+	SendExportPart(part *model.ExportPart) error
+}
+
+type Processor struct {
+	log      *slog.Logger
+	storage  Storage
+	producer Producer
+}
+
+func NewProcessor(log *slog.Logger, storage Storage, producer Producer) *Processor {
+	return &Processor{
+		log:      log,
+		storage:  storage,
+		producer: producer,
+	}
+}
+
+// reviewView отзыв в выгрузке
+type reviewView struct {
+	ID          int64     `json:"id"`
+	MentorID    int64     `json:"mentor_id,omitempty"`
+	MentorEmail string    `json:"mentor_email"`
+	Rating      float32   `json:"rating"`
+	Comment     string    `json:"comment"`
+	UserContact string    `json:"user_contact"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Handle обрабатывает событие; при ошибке сообщение нужно прочитать повторно
+func (p *Processor) Handle(ctx context.Context, event *model.UserEvent) error {
+	switch event.Type {
+	case EventUserDeleted:
+		return p.deleteUser(ctx, event)
+	case EventExportRequested:
+		return p.export(ctx, event)
+	case EventEmailChanged:
+		return p.rekeyMentor(ctx, event)
+	case EventIDBackfill:
+		return p.linkMentorID(ctx, event)
+	default:
+		p.log.Debug("skipping user event", slog.String("type", event.Type))
+		return nil
+	}
+}
+
+func (p *Processor) deleteUser(ctx context.Context, event *model.UserEvent) error {
+	const op = "userdata.deleteUser"
+
+	// Рейтинг менторов пересчитывается тем же путём, что и при обычном удалении отзыва.
+	// События уходят до коммита: если Kafka недоступна, отзывы останутся и удаление повторится
+	var written int
+	err := p.storage.DeleteReviewsByUser(ctx, event.UserID, func(reviews []model.Review) error {
+		for _, rev := range reviews {
+			err := p.producer.SendReviewEvent(model.NewReviewEvent("deleted", &rev))
+			if err != nil {
+				return err
+			}
+		}
+		written = len(reviews)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Отзывы о самом менторе удаляются вместе с его профилем, рейтинг пересчитывать не для кого
+	var received int64
+	if event.Role == "mentor" {
+		if received, err = p.storage.DeleteReviewsAboutMentor(ctx, event.UserID, event.Email); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	p.log.Info("user reviews deleted",
+		slog.Int64("user_id", event.UserID),
+		slog.Int("written", written),
+		slog.Int64("received", received),
+	)
+	return nil
+}
+
+func (p *Processor) export(ctx context.Context, event *model.UserEvent) error {
+	const op = "userdata.export"
+
+	reviews, err := p.storage.GetReviewsByUser(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	views := make([]reviewView, 0, len(reviews))
+	for _, r := range reviews {
+		views = append(views, reviewView{
+			ID:          r.ID,
+			MentorID:    r.MentorID,
+			MentorEmail: r.MentorEmail,
+			Rating:      r.Rating,
+			Comment:     r.Comment,
+			UserContact: r.UserContact,
+			CreatedAt:   r.CreatedAt.UTC(),
+		})
+	}
+
+	err = p.producer.SendExportPart(&model.ExportPart{
+		ExportID: event.ExportID,
+		UserID:   event.UserID,
+		Service:  ServiceName,
+		Data:     map[string]any{"reviews": views},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// rekeyMentor по email отзывы ещё ищут старые клиенты, поэтому при смене адреса его нужно обновить.
+// Обновление идемпотентно: при повторной доставке старых записей уже нет
+func (p *Processor) rekeyMentor(ctx context.Context, event *model.UserEvent) error {
+	const op = "userdata.rekeyMentor"
+
+	if event.OldEmail == "" || event.OldEmail == event.Email {
+		return nil
+	}
+
+	moved, err := p.storage.RekeyMentorEmail(ctx, event.UserID, event.OldEmail, event.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	p.log.Info("mentor reviews moved to new email",
+		slog.Int64("user_id", event.UserID),
+		slog.Int64("moved", moved),
+	)
+	return nil
+}
+
+// linkMentorID проставляет id ментора отзывам, оставленным по email до перехода на id.
+// Отзывы, уже связанные с id, не трогаются, поэтому повторная доставка безопасна
+func (p *Processor) linkMentorID(ctx context.Context, event *model.UserEvent) error {
+	const op = "userdata.linkMentorID"
+
+	if event.Role != "mentor" || event.Email == "" {
+		return nil
+	}
+
+	linked, err := p.storage.LinkMentorID(ctx, event.UserID, event.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	p.log.Info("mentor reviews linked to id",
+		slog.Int64("user_id", event.UserID),
+		slog.Int64("linked", linked),
+	)
+	return nil
+}
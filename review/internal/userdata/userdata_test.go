@@ -0,0 +1,205 @@
+package userdata
+
+import (
+	"context"
+	"errors"
+	"review/internal/domain/model"
+	"review/internal/lib/logger/slogdiscard"
+	"testing"
+)
+
+type fakeStorage struct {
+	reviews       []model.Review
+	committed     bool
+	aboutDeleted  string
+	getByUserCall int64
+	rekeyed       [2]string
+	linked        string
+	mentorID      int64
+}
+
+func (s *fakeStorage) GetReviewsByUser(_ context.Context, userID int64) ([]model.Review, error) {
+	s.getByUserCall = userID
+	return s.reviews, nil
+}
+
+func (s *fakeStorage) DeleteReviewsByUser(_ context.Context, _ int64, beforeCommit func([]model.Review) error) error {
+	if err := beforeCommit(s.reviews); err != nil {
+		return err
+	}
+	s.committed = true
+	return nil
+}
+
+func (s *fakeStorage) DeleteReviewsAboutMentor(_ context.Context, mentorID int64, mentorEmail string) (int64, error) {
+	s.mentorID = mentorID
+	s.aboutDeleted = mentorEmail
+	return 2, nil
+}
+
+func (s *fakeStorage) RekeyMentorEmail(_ context.Context, mentorID int64, oldEmail, newEmail string) (int64, error) {
+	s.mentorID = mentorID
+	s.rekeyed = [2]string{oldEmail, newEmail}
+	return 3, nil
+}
+
+func (s *fakeStorage) LinkMentorID(_ context.Context, mentorID int64, mentorEmail string) (int64, error) {
+	s.mentorID = mentorID
+	s.linked = mentorEmail
+	return 4, nil
+}
+
+type fakeProducer struct {
+	events []model.ReviewEvent
+	parts  []model.ExportPart
+	err    error
+}
+
+func (p *fakeProducer) SendReviewEvent(review *model.ReviewEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, *review)
+	return nil
+}
+
+func (p *fakeProducer) SendExportPart(part *model.ExportPart) error {
+	p.parts = append(p.parts, *part)
+	return nil
+}
+
+func TestUserDeletedPublishesRatingEvents(t *testing.T) {
+	storage := &fakeStorage{reviews: []model.Review{
+		{ID: 1, UserID: 7, MentorID: 3, MentorEmail: "a@mail.com", Rating: 5},
+		{ID: 2, UserID: 7, MentorEmail: "b@mail.com", Rating: 3},
+	}}
+	producer := &fakeProducer{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, producer)
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventUserDeleted, UserID: 7, Email: "user@mail.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !storage.committed {
+		t.Fatal("reviews were not deleted")
+	}
+	if len(producer.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(producer.events))
+	}
+	// email ментора уходит в событие, только пока отзыв не связан с его id
+	want := []model.ReviewEvent{
+		{Action: "deleted", ID: 1, MentorID: 3, Score: 5},
+		{Action: "deleted", ID: 2, Email: "b@mail.com", Score: 3},
+	}
+	for i := range want {
+		if producer.events[i] != want[i] {
+			t.Fatalf("expected %+v, got %+v", want[i], producer.events[i])
+		}
+	}
+	if storage.aboutDeleted != "" {
+		t.Fatal("reviews about a non-mentor must not be touched")
+	}
+}
+
+func TestUserDeletedRollsBackWhenKafkaFails(t *testing.T) {
+	storage := &fakeStorage{reviews: []model.Review{{ID: 1, UserID: 7, MentorEmail: "a@mail.com", Rating: 5}}}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{err: errors.New("kafka down")})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventUserDeleted, UserID: 7})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if storage.committed {
+		t.Fatal("reviews must stay until rating events are published")
+	}
+}
+
+func TestMentorDeletedRemovesReviewsAboutMentor(t *testing.T) {
+	storage := &fakeStorage{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventUserDeleted, UserID: 7, Email: "mentor@mail.com", Role: "mentor"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.aboutDeleted != "mentor@mail.com" || storage.mentorID != 7 {
+		t.Fatalf("expected reviews about mentor 7 to be deleted, got %d %q", storage.mentorID, storage.aboutDeleted)
+	}
+}
+
+func TestExportRequestedSendsPart(t *testing.T) {
+	storage := &fakeStorage{reviews: []model.Review{{ID: 1, UserID: 7, MentorEmail: "a@mail.com", Rating: 5, Comment: "good"}}}
+	producer := &fakeProducer{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, producer)
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventExportRequested, UserID: 7, ExportID: "exp1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.getByUserCall != 7 {
+		t.Fatalf("expected reviews of user 7, got %d", storage.getByUserCall)
+	}
+	if len(producer.parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(producer.parts))
+	}
+	part := producer.parts[0]
+	if part.ExportID != "exp1" || part.Service != ServiceName || part.UserID != 7 {
+		t.Fatalf("unexpected part %+v", part)
+	}
+	reviews := part.Data.(map[string]any)["reviews"].([]reviewView)
+	if len(reviews) != 1 || reviews[0].Comment != "good" {
+		t.Fatalf("unexpected reviews %+v", reviews)
+	}
+}
+
+func TestEmailChangedMovesMentorReviews(t *testing.T) {
+	storage := &fakeStorage{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventEmailChanged, UserID: 7, Email: "new@mail.com", OldEmail: "old@mail.com", Role: "mentor"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [2]string{"old@mail.com", "new@mail.com"}; storage.rekeyed != want || storage.mentorID != 7 {
+		t.Fatalf("expected rekey %v of mentor 7, got %v of %d", want, storage.rekeyed, storage.mentorID)
+	}
+}
+
+func TestEmailChangedWithoutOldEmailIsSkipped(t *testing.T) {
+	storage := &fakeStorage{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventEmailChanged, UserID: 7, Email: "new@mail.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.rekeyed != [2]string{} {
+		t.Fatalf("unexpected rekey %v", storage.rekeyed)
+	}
+}
+
+func TestIDBackfillLinksMentorReviews(t *testing.T) {
+	storage := &fakeStorage{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventIDBackfill, UserID: 7, Email: "mentor@mail.com", Role: "mentor"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.linked != "mentor@mail.com" || storage.mentorID != 7 {
+		t.Fatalf("expected reviews about mentor@mail.com linked to 7, got %q to %d", storage.linked, storage.mentorID)
+	}
+}
+
+func TestIDBackfillSkipsNonMentors(t *testing.T) {
+	storage := &fakeStorage{}
+	p := NewProcessor(slogdiscard.NewDiscardLogger(), storage, &fakeProducer{})
+
+	err := p.Handle(context.Background(), &model.UserEvent{Type: EventIDBackfill, UserID: 7, Email: "user@mail.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storage.linked != "" {
+		t.Fatalf("unexpected link for %q", storage.linked)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS reviews_mentor_email_idx;
+DROP INDEX IF EXISTS reviews_mentor_id_idx;
+ALTER TABLE reviews DROP COLUMN IF EXISTS mentor_id;
@@ -0,0 +1,8 @@
+-- id ментора в сервисе авторизации. NULL у отзывов, оставленных по email
+-- до перехода на id; их связывает событие user.id_backfill.
+-- mentor_email остаётся, пока клиенты ищут отзывы по email
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS mentor_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS reviews_mentor_id_idx ON reviews (mentor_id);
+CREATE INDEX IF NOT EXISTS reviews_mentor_email_idx ON reviews (mentor_email);
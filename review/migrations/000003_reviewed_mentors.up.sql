@@ -0,0 +1,9 @@
+-- Менторы, у которых когда-либо были отзывы по id. Сверка рейтингов идёт по этому списку,
+-- чтобы ментор, у которого удалили все отзывы, получил нулевые счётчики
+CREATE TABLE IF NOT EXISTS reviewed_mentors (
+    mentor_id BIGINT PRIMARY KEY
+);
+
+INSERT INTO reviewed_mentors (mentor_id)
+SELECT DISTINCT mentor_id FROM reviews WHERE mentor_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,174 @@
+package token
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Не чаще, чем раз в minRefetch, идём за ключами из-за незнакомого kid
+const minRefetch = 10 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSCache хранит публичные ключи сервиса авторизации и периодически их обновляет
+type JWKSCache struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+
+	// refetch пускает за ключами по незнакомому kid только один запрос;
+	// attemptedAt помнит и неудачные попытки, чтобы не долбить упавший сервис
+	refetch     sync.Mutex
+	attemptedAt time.Time
+}
+
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		now:    time.Now,
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Start обновляет ключи каждые interval, пока не отменён ctx
+func (c *JWKSCache) Start(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Refresh(ctx); err != nil {
+					log.Warn("failed to refresh JWKS", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (c *JWKSCache) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks: fetch %s: %w", c.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: fetch %s: status %d", c.url, resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("jwks: decode: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return fmt.Errorf("jwks: key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = c.now()
+	c.mu.Unlock()
+	return nil
+}
+
+// Key возвращает ключ по kid; незнакомый kid означает ротацию, поэтому перечитываем набор
+func (c *JWKSCache) Key(kid string) (*rsa.PublicKey, error) {
+	if pub, ok := c.lookup(kid); ok {
+		return pub, nil
+	}
+
+	c.refetch.Lock()
+	defer c.refetch.Unlock()
+
+	// Пока ждали, ключи мог перечитать другой запрос с тем же kid
+	if pub, ok := c.lookup(kid); ok {
+		return pub, nil
+	}
+
+	now := c.now()
+	c.mu.RLock()
+	stale := now.Sub(c.fetchedAt) > minRefetch
+	c.mu.RUnlock()
+
+	if stale && now.Sub(c.attemptedAt) > minRefetch {
+		c.attemptedAt = now
+		if err := c.Refresh(context.Background()); err != nil {
+			return nil, err
+		}
+		if pub, ok := c.lookup(kid); ok {
+			return pub, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (c *JWKSCache) lookup(kid string) (*rsa.PublicKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Токены без kid выпущены до ротации: подходят, только если ключ один
+	if kid == "" && len(c.keys) == 1 {
+		for _, pub := range c.keys {
+			return pub, true
+		}
+	}
+	pub, ok := c.keys[kid]
+	return pub, ok
+}
+
+func (k jwk) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode e: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
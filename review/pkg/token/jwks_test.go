@@ -0,0 +1,147 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer отдаёт текущий набор ключей и считает обращения
+type jwksServer struct {
+	mu     sync.Mutex
+	keys   map[string]*rsa.PublicKey
+	broken bool
+	hits   atomic.Int32
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.hits.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var set jwks
+	for kid, pub := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+func (s *jwksServer) publish(kid string, pub *rsa.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = pub
+}
+
+func newKey(t *testing.T) *rsa.PublicKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &key.PublicKey
+}
+
+func TestJWKSCacheRotation(t *testing.T) {
+	first, second := newKey(t), newKey(t)
+	srv := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": first}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewJWKSCache(ts.URL)
+	cache.now = func() time.Time { return now }
+
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := cache.Key("k1")
+	if err != nil || pub.N.Cmp(first.N) != 0 {
+		t.Fatalf("known kid: got %v, %v", pub, err)
+	}
+	if hits := srv.hits.Load(); hits != 1 {
+		t.Fatalf("known kid must not refetch, got %d hits", hits)
+	}
+
+	// Ключ ротирован сразу после загрузки: перечитаем набор не раньше minRefetch
+	srv.publish("k2", second)
+	if _, err := cache.Key("k2"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey within minRefetch, got %v", err)
+	}
+	if hits := srv.hits.Load(); hits != 1 {
+		t.Fatalf("refetch within minRefetch, got %d hits", hits)
+	}
+
+	now = now.Add(minRefetch + time.Second)
+	pub, err = cache.Key("k2")
+	if err != nil || pub.N.Cmp(second.N) != 0 {
+		t.Fatalf("rotated kid: got %v, %v", pub, err)
+	}
+	if hits := srv.hits.Load(); hits != 2 {
+		t.Fatalf("expected one refetch after rotation, got %d hits", hits)
+	}
+}
+
+func TestJWKSCacheRefetchRateLimit(t *testing.T) {
+	srv := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": newKey(t)}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewJWKSCache(ts.URL)
+	cache.now = func() time.Time { return now }
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(minRefetch + time.Second)
+
+	// Поток токенов с выдуманным kid приводит к одному запросу за ключами
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Key("forged"); !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("expected ErrUnknownKey, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if hits := srv.hits.Load(); hits != 2 {
+		t.Fatalf("concurrent unknown kids: expected 2 hits, got %d", hits)
+	}
+
+	// Неудачная попытка тоже считается: упавший сервис не заваливаем запросами
+	srv.mu.Lock()
+	srv.broken = true
+	srv.mu.Unlock()
+	now = now.Add(minRefetch + time.Second)
+	if _, err := cache.Key("forged"); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected fetch error, got %v", err)
+	}
+	if _, err := cache.Key("forged"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey after failed attempt, got %v", err)
+	}
+	if hits := srv.hits.Load(); hits != 3 {
+		t.Fatalf("failed fetch must be rate limited, got %d hits", hits)
+	}
+}
@@ -0,0 +1,20 @@
+package token
+
+import (
+	"slices"
+	"strings"
+)
+
+// Права из claim scope, которые выдаёт сервис авторизации
+const (
+	ScopeReviewWrite    = "review:write"
+	ScopeReviewModerate = "review:moderate"
+)
+
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
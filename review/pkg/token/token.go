@@ -4,7 +4,6 @@ import (
 	"crypto/rsa"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,32 +14,33 @@ var (
 	ErrTokenExpired         = errors.New("token expired")
 )
 
+// Типы токенов, которые выдаёт сервис авторизации
+const (
+	TypeAccess     = "access"
+	TypeRefresh    = "refresh"
+	TypeMFAPending = "mfa_pending"
+)
+
+// KeySource отдаёт публичный ключ по kid из заголовка токена
+type KeySource interface {
+	Key(kid string) (*rsa.PublicKey, error)
+}
+
 type TokenManager struct {
-	PublicKey *rsa.PublicKey
+	keys KeySource
 }
 
 type Claims struct {
 	UserID    int64  `json:"user_id"`
 	Role      string `json:"role"`
 	TokenType string `json:"token_type"`
+	FamilyID  string `json:"family_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewTokenManagerRSA(publicKeyPath string) (*TokenManager, error) {
-	pubData, err := os.ReadFile(publicKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read public key from %s: %v", publicKeyPath, err)
-	}
-
-	pubKey, err := jwt.ParseRSAPublicKeyFromPEM(pubData)
-	if err != nil {
-		return nil, err
-	}
-
-	return &TokenManager{
-		PublicKey: pubKey,
-	}, nil
-
+func NewTokenManager(keys KeySource) *TokenManager {
+	return &TokenManager{keys: keys}
 }
 
 func (tm *TokenManager) ParseToken(tokenStr string) (*Claims, error) {
@@ -50,7 +50,8 @@ func (tm *TokenManager) ParseToken(tokenStr string) (*Claims, error) {
 			return nil, ErrInvalidSigningMethod
 		}
 
-		return tm.PublicKey, nil
+		kid, _ := t.Header["kid"].(string)
+		return tm.keys.Key(kid)
 	})
 
 	if err != nil {